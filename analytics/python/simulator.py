@@ -7,6 +7,9 @@ import os
 
 TELEMETRY_URL = os.getenv("TELEMETRY_URL", "http://localhost:8081/ingest")
 ROUTING_URL = os.getenv("ROUTING_URL", "http://localhost:8080/route")
+# Needed once the telemetry service has API keys configured
+TELEMETRY_API_KEY = os.getenv("TELEMETRY_API_KEY")
+HEADERS = {"X-API-Key": TELEMETRY_API_KEY} if TELEMETRY_API_KEY else {}
 
 # Phoenix route nodes from main.go
 ROUTE_POINTS = [
@@ -41,7 +44,7 @@ def simulate_drive(vehicle_id="Phx-01"):
         }
         
         try:
-            resp = requests.post(TELEMETRY_URL, json=payload)
+            resp = requests.post(TELEMETRY_URL, json=payload, headers=HEADERS)
             if resp.status_code == 202:
                 print(f"Sent: {vehicle_id} @ {lat:.5f}, {lon:.5f}")
             else:
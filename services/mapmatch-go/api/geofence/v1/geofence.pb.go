@@ -0,0 +1,204 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: api/geofence/v1/geofence.proto
+
+package geofencev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Point struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lat           float64                `protobuf:"fixed64,1,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lon           float64                `protobuf:"fixed64,2,opt,name=lon,proto3" json:"lon,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Point) Reset() {
+	*x = Point{}
+	mi := &file_api_geofence_v1_geofence_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Point) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Point) ProtoMessage() {}
+
+func (x *Point) ProtoReflect() protoreflect.Message {
+	mi := &file_api_geofence_v1_geofence_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Point.ProtoReflect.Descriptor instead.
+func (*Point) Descriptor() ([]byte, []int) {
+	return file_api_geofence_v1_geofence_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Point) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *Point) GetLon() float64 {
+	if x != nil {
+		return x.Lon
+	}
+	return 0
+}
+
+type FenceResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// geofences are the IDs of every fence containing the point.
+	Geofences     []string `protobuf:"bytes,1,rep,name=geofences,proto3" json:"geofences,omitempty"`
+	IsInside      bool     `protobuf:"varint,2,opt,name=is_inside,json=isInside,proto3" json:"is_inside,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FenceResult) Reset() {
+	*x = FenceResult{}
+	mi := &file_api_geofence_v1_geofence_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FenceResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FenceResult) ProtoMessage() {}
+
+func (x *FenceResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_geofence_v1_geofence_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FenceResult.ProtoReflect.Descriptor instead.
+func (*FenceResult) Descriptor() ([]byte, []int) {
+	return file_api_geofence_v1_geofence_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *FenceResult) GetGeofences() []string {
+	if x != nil {
+		return x.Geofences
+	}
+	return nil
+}
+
+func (x *FenceResult) GetIsInside() bool {
+	if x != nil {
+		return x.IsInside
+	}
+	return false
+}
+
+var File_api_geofence_v1_geofence_proto protoreflect.FileDescriptor
+
+var file_api_geofence_v1_geofence_proto_rawDesc = string([]byte{
+	0x0a, 0x1e, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x65, 0x6f, 0x66, 0x65, 0x6e, 0x63, 0x65, 0x2f, 0x76,
+	0x31, 0x2f, 0x67, 0x65, 0x6f, 0x66, 0x65, 0x6e, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x13, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x67, 0x65, 0x6f, 0x66, 0x65, 0x6e,
+	0x63, 0x65, 0x2e, 0x76, 0x31, 0x22, 0x2b, 0x0a, 0x05, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x10,
+	0x0a, 0x03, 0x6c, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x61, 0x74,
+	0x12, 0x10, 0x0a, 0x03, 0x6c, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c,
+	0x6f, 0x6e, 0x22, 0x48, 0x0a, 0x0b, 0x46, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x12, 0x1c, 0x0a, 0x09, 0x67, 0x65, 0x6f, 0x66, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x67, 0x65, 0x6f, 0x66, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x12,
+	0x1b, 0x0a, 0x09, 0x69, 0x73, 0x5f, 0x69, 0x6e, 0x73, 0x69, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x08, 0x69, 0x73, 0x49, 0x6e, 0x73, 0x69, 0x64, 0x65, 0x32, 0x5e, 0x0a, 0x0f,
+	0x47, 0x65, 0x6f, 0x66, 0x65, 0x6e, 0x63, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x4b, 0x0a, 0x0b, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x46, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x12, 0x1a,
+	0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x67, 0x65, 0x6f, 0x66, 0x65, 0x6e, 0x63,
+	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x1a, 0x20, 0x2e, 0x6e, 0x61, 0x76,
+	0x69, 0x66, 0x6c, 0x79, 0x2e, 0x67, 0x65, 0x6f, 0x66, 0x65, 0x6e, 0x63, 0x65, 0x2e, 0x76, 0x31,
+	0x2e, 0x46, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x42, 0x2d, 0x5a, 0x2b,
+	0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2f, 0x6d, 0x61, 0x70, 0x6d, 0x61, 0x74, 0x63, 0x68,
+	0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x65, 0x6f, 0x66, 0x65, 0x6e, 0x63, 0x65, 0x2f, 0x76, 0x31,
+	0x3b, 0x67, 0x65, 0x6f, 0x66, 0x65, 0x6e, 0x63, 0x65, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+})
+
+var (
+	file_api_geofence_v1_geofence_proto_rawDescOnce sync.Once
+	file_api_geofence_v1_geofence_proto_rawDescData []byte
+)
+
+func file_api_geofence_v1_geofence_proto_rawDescGZIP() []byte {
+	file_api_geofence_v1_geofence_proto_rawDescOnce.Do(func() {
+		file_api_geofence_v1_geofence_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_geofence_v1_geofence_proto_rawDesc), len(file_api_geofence_v1_geofence_proto_rawDesc)))
+	})
+	return file_api_geofence_v1_geofence_proto_rawDescData
+}
+
+var file_api_geofence_v1_geofence_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_api_geofence_v1_geofence_proto_goTypes = []any{
+	(*Point)(nil),       // 0: navifly.geofence.v1.Point
+	(*FenceResult)(nil), // 1: navifly.geofence.v1.FenceResult
+}
+var file_api_geofence_v1_geofence_proto_depIdxs = []int32{
+	0, // 0: navifly.geofence.v1.GeofenceService.CheckFences:input_type -> navifly.geofence.v1.Point
+	1, // 1: navifly.geofence.v1.GeofenceService.CheckFences:output_type -> navifly.geofence.v1.FenceResult
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_api_geofence_v1_geofence_proto_init() }
+func file_api_geofence_v1_geofence_proto_init() {
+	if File_api_geofence_v1_geofence_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_geofence_v1_geofence_proto_rawDesc), len(file_api_geofence_v1_geofence_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_geofence_v1_geofence_proto_goTypes,
+		DependencyIndexes: file_api_geofence_v1_geofence_proto_depIdxs,
+		MessageInfos:      file_api_geofence_v1_geofence_proto_msgTypes,
+	}.Build()
+	File_api_geofence_v1_geofence_proto = out.File
+	file_api_geofence_v1_geofence_proto_goTypes = nil
+	file_api_geofence_v1_geofence_proto_depIdxs = nil
+}
@@ -0,0 +1,125 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: api/geofence/v1/geofence.proto
+
+package geofencev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	GeofenceService_CheckFences_FullMethodName = "/navifly.geofence.v1.GeofenceService/CheckFences"
+)
+
+// GeofenceServiceClient is the client API for GeofenceService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// GeofenceService is the gRPC twin of POST /geofence/check.
+type GeofenceServiceClient interface {
+	CheckFences(ctx context.Context, in *Point, opts ...grpc.CallOption) (*FenceResult, error)
+}
+
+type geofenceServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGeofenceServiceClient(cc grpc.ClientConnInterface) GeofenceServiceClient {
+	return &geofenceServiceClient{cc}
+}
+
+func (c *geofenceServiceClient) CheckFences(ctx context.Context, in *Point, opts ...grpc.CallOption) (*FenceResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FenceResult)
+	err := c.cc.Invoke(ctx, GeofenceService_CheckFences_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GeofenceServiceServer is the server API for GeofenceService service.
+// All implementations must embed UnimplementedGeofenceServiceServer
+// for forward compatibility.
+//
+// GeofenceService is the gRPC twin of POST /geofence/check.
+type GeofenceServiceServer interface {
+	CheckFences(context.Context, *Point) (*FenceResult, error)
+	mustEmbedUnimplementedGeofenceServiceServer()
+}
+
+// UnimplementedGeofenceServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGeofenceServiceServer struct{}
+
+func (UnimplementedGeofenceServiceServer) CheckFences(context.Context, *Point) (*FenceResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckFences not implemented")
+}
+func (UnimplementedGeofenceServiceServer) mustEmbedUnimplementedGeofenceServiceServer() {}
+func (UnimplementedGeofenceServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeGeofenceServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GeofenceServiceServer will
+// result in compilation errors.
+type UnsafeGeofenceServiceServer interface {
+	mustEmbedUnimplementedGeofenceServiceServer()
+}
+
+func RegisterGeofenceServiceServer(s grpc.ServiceRegistrar, srv GeofenceServiceServer) {
+	// If the following call pancis, it indicates UnimplementedGeofenceServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GeofenceService_ServiceDesc, srv)
+}
+
+func _GeofenceService_CheckFences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Point)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GeofenceServiceServer).CheckFences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GeofenceService_CheckFences_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GeofenceServiceServer).CheckFences(ctx, req.(*Point))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GeofenceService_ServiceDesc is the grpc.ServiceDesc for GeofenceService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GeofenceService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "navifly.geofence.v1.GeofenceService",
+	HandlerType: (*GeofenceServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CheckFences",
+			Handler:    _GeofenceService_CheckFences_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/geofence/v1/geofence.proto",
+}
@@ -0,0 +1,14 @@
+package main
+
+import "navifly/platform/config"
+
+// Config is the mapmatch service's configuration. config.Load reads it from
+// the YAML file named by CONFIG_FILE and then the environment variables
+// named in the tags.
+type Config struct {
+	config.Server `yaml:",inline"`
+}
+
+func defaultConfig() Config {
+	return Config{Server: config.DefaultServer(8082, 9092)}
+}
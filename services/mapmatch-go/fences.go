@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/paulmach/orb"
+
+	"navifly/platform/problem"
+)
+
+// ── Geofence Admin ──
+
+// ListFences returns every geofence.
+func ListFences(w http.ResponseWriter, r *http.Request) {
+	fencesMu.RLock()
+	list := append([]Geofence{}, fences...)
+	fencesMu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GetFence returns one geofence; the routing service uses it to resolve
+// avoid_fences.
+func GetFence(w http.ResponseWriter, r *http.Request) {
+	f, ok := findFence(mux.Vars(r)["id"])
+	if !ok {
+		problem.Write(w, r, problem.NotFound, "geofence not found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(f)
+}
+
+// PutFence creates or replaces a geofence, e.g. a wildfire closure.
+func PutFence(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var f Geofence
+	if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+		problem.Write(w, r, problem.InvalidRequest, "invalid JSON body: "+err.Error())
+		return
+	}
+	f.ID = id
+	if err := validatePolygon(f.Polygon); err != nil {
+		problem.WriteError(w, r, err, problem.InvalidRequest)
+		return
+	}
+
+	status := http.StatusCreated
+	fencesMu.Lock()
+	replaced := false
+	for i := range fences {
+		if fences[i].ID == id {
+			fences[i] = f
+			replaced = true
+			break
+		}
+	}
+	if replaced {
+		status = http.StatusOK
+	} else {
+		fences = append(fences, f)
+	}
+	fencesMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(f)
+}
+
+// DeleteFence removes a geofence.
+func DeleteFence(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	fencesMu.Lock()
+	defer fencesMu.Unlock()
+	for i := range fences {
+		if fences[i].ID == id {
+			fences = append(fences[:i], fences[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	problem.Write(w, r, problem.NotFound, "geofence not found")
+}
+
+func findFence(id string) (Geofence, bool) {
+	fencesMu.RLock()
+	defer fencesMu.RUnlock()
+	for _, f := range fences {
+		if f.ID == id {
+			return f, true
+		}
+	}
+	return Geofence{}, false
+}
+
+// validatePolygon requires closed [lon, lat] rings of at least 4 points.
+func validatePolygon(p orb.Polygon) error {
+	if len(p) == 0 {
+		return fmt.Errorf("polygon is required")
+	}
+	for _, ring := range p {
+		if len(ring) < 4 || !ring.Closed() {
+			return fmt.Errorf("rings need at least 4 points and must be closed")
+		}
+		for _, pt := range ring {
+			if pt.Lon() < -180 || pt.Lon() > 180 || pt.Lat() < -90 || pt.Lat() > 90 {
+				return problem.Errorf(problem.InvalidCoordinate, "%v is not a [lon, lat] position", pt)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package main
+
+//go:generate protoc -I . --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative api/geofence/v1/geofence.proto
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	geofencev1 "navifly/mapmatch/api/geofence/v1"
+	"navifly/platform/auth"
+	"navifly/platform/logging"
+	"navifly/platform/tracing"
+)
+
+// geofenceGRPC serves GeofenceService from the same fences as
+// POST /geofence/check.
+type geofenceGRPC struct {
+	geofencev1.UnimplementedGeofenceServiceServer
+}
+
+func newGRPCServer() *grpc.Server {
+	opts := logging.ServerOptions()
+	opts = append(opts, authn.ServerOptions(auth.MethodScopes{
+		geofencev1.GeofenceService_CheckFences_FullMethodName: auth.ScopeFencesRead,
+	})...)
+	gs := grpc.NewServer(append(opts, tracing.ServerOptions()...)...)
+	geofencev1.RegisterGeofenceServiceServer(gs, geofenceGRPC{})
+	reflection.Register(gs)
+	return gs
+}
+
+func (geofenceGRPC) CheckFences(_ context.Context, p *geofencev1.Point) (*geofencev1.FenceResult, error) {
+	inside := fencesContaining(Point{Lat: p.GetLat(), Lon: p.GetLon()})
+	countCheck("grpc", inside)
+	return &geofencev1.FenceResult{Geofences: inside, IsInside: len(inside) > 0}, nil
+}
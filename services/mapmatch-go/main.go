@@ -1,17 +1,29 @@
 package main
 
 import (
+	"context"
+	_ "embed"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 
 	"os"
+	"sync"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/paulmach/orb"
 	"github.com/paulmach/orb/planar"
+
+	"navifly/platform/auth"
+	"navifly/platform/config"
+	"navifly/platform/logging"
+	"navifly/platform/metrics"
+	"navifly/platform/openapi"
+	"navifly/platform/problem"
+	"navifly/platform/server"
+	"navifly/platform/tracing"
 )
 
 type Point struct {
@@ -24,7 +36,16 @@ type Geofence struct {
 	Polygon orb.Polygon `json:"polygon"`
 }
 
-var fences []Geofence
+var (
+	// authn checks credentials; main replaces it when keys are configured.
+	authn = auth.Disabled()
+	// probes answer /livez and /readyz; mapmatch has no dependencies to
+	// check, so readiness only fails while shutting down.
+	probes = server.NewProbes()
+
+	fencesMu sync.RWMutex
+	fences   []Geofence
+)
 
 func init() {
 	// Mock geofence for DT Phoenix
@@ -40,21 +61,31 @@ func init() {
 	fences = append(fences, Geofence{ID: "Downtown-Zone-1", Polygon: poly})
 }
 
-func CheckFences(w http.ResponseWriter, r *http.Request) {
-	var p Point
-	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-
+// fencesContaining returns the IDs of every geofence containing p. Both the
+// REST and gRPC checks use it.
+func fencesContaining(p Point) []string {
 	pt := orb.Point{p.Lon, p.Lat}
 	inside := []string{}
 
+	fencesMu.RLock()
+	defer fencesMu.RUnlock()
 	for _, f := range fences {
 		if planar.PolygonContains(f.Polygon, pt) {
 			inside = append(inside, f.ID)
 		}
 	}
+	return inside
+}
+
+func CheckFences(w http.ResponseWriter, r *http.Request) {
+	var p Point
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		problem.Write(w, r, problem.InvalidRequest, "invalid JSON body: "+err.Error())
+		return
+	}
+
+	inside := fencesContaining(p)
+	countCheck("http", inside)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -63,27 +94,85 @@ func CheckFences(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func main() {
+//go:embed openapi.yaml
+var openapiYAML []byte
+
+// apiSpec documents every route in newRouter; protect validates requests
+// against it.
+var apiSpec = openapi.MustLoad(openapiYAML)
+
+// protect requires scope and then validates the request against the API
+// spec.
+func protect(scope string, h http.HandlerFunc) http.Handler {
+	return authn.Require(scope, apiSpec.Middleware(h))
+}
+
+// newRouter registers every mapmatch endpoint on a fresh mux.Router.
+func newRouter() *mux.Router {
 	r := mux.NewRouter()
-	r.HandleFunc("/geofence/check", CheckFences).Methods("POST")
+	r.Use(tracing.Middleware("mapmatch"), logging.Middleware, metrics.Middleware)
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
+	apiSpec.Register(r)
+	r.Handle("/geofence/check", protect(auth.ScopeFencesRead, CheckFences)).Methods("POST")
+	r.Handle("/geofences", protect(auth.ScopeFencesRead, ListFences)).Methods("GET")
+	r.Handle("/geofences/{id}", protect(auth.ScopeFencesRead, GetFence)).Methods("GET")
+	r.Handle("/geofences/{id}", protect(auth.ScopeFencesAdmin, PutFence)).Methods("PUT")
+	r.Handle("/geofences/{id}", protect(auth.ScopeFencesAdmin, DeleteFence)).Methods("DELETE")
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, "OK")
 	}).Methods("GET")
-	
+	probes.Register(r)
+
 	// Add Root Handler
 	r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		fmt.Fprint(w, "NaviFly MapMatch Service Online 🗺️")
 	}).Methods("GET")
+	return r
+}
 
-	log.Println("Map-matching / Geo service starting on :8082...")
+func main() {
+	if err := logging.Setup("mapmatch"); err != nil {
+		logging.Fatal("invalid logging config", logging.Err(err))
+	}
+	cfg := defaultConfig()
+	if err := config.Load(&cfg); err != nil {
+		logging.Fatal("failed to load config", logging.Err(err))
+	}
+	traceCfg, err := tracing.ConfigFromEnv()
+	if err != nil {
+		logging.Fatal("invalid tracing config", logging.Err(err))
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), "mapmatch", traceCfg)
+	if err != nil {
+		logging.Fatal("failed to set up tracing", logging.Err(err))
+	}
+	defer shutdownTracing(context.Background())
+
+	if authn, err = auth.New(auth.ConfigFromEnv()); err != nil {
+		logging.Fatal("failed to load auth keys", logging.Err(err))
+	}
+	if !authn.Enabled() {
+		slog.Warn("no API_KEYS_PATH or JWKS_PATH set, authentication is disabled")
+	}
+	r := newRouter()
 
 	// CORS Headers
-	headersOk := handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization"})
+	headersOk := handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization", "X-API-Key", logging.HeaderRequestID})
 	originsOk := handlers.AllowedOrigins([]string{"*"})
-	methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "OPTIONS"})
+	methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "DELETE", "OPTIONS"})
 
-	// Wrap with Logging and CORS
-	loggedRouter := handlers.LoggingHandler(os.Stdout, r)
-	log.Fatal(http.ListenAndServe(":8082", handlers.CORS(originsOk, headersOk, methodsOk)(loggedRouter)))
+	// Wrap with CORS; the router logs each request itself. gRPC API
+	// alongside REST
+	app := server.New(cfg.Server, handlers.CORS(originsOk, headersOk, methodsOk)(r))
+	app.GRPC = newGRPCServer()
+	app.Probes = probes
+
+	ctx, stop := server.SignalContext()
+	defer stop()
+	slog.Info("map-matching / geo service starting")
+	if err := app.Run(ctx); err != nil {
+		slog.Error("map-matching service stopped", logging.Err(err))
+		os.Exit(1)
+	}
 }
@@ -2,30 +2,42 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	geofencev1 "navifly/mapmatch/api/geofence/v1"
+	"navifly/platform/problem"
 )
 
 func TestCheckFences_Inside(t *testing.T) {
 	// Point inside Downtown-Zone-1 (-112.07, 33.45)
 	p := Point{Lat: 33.45, Lon: -112.07}
 	body, _ := json.Marshal(p)
-	
+
 	req, _ := http.NewRequest("POST", "/geofence/check", bytes.NewBuffer(body))
 	rr := httptest.NewRecorder()
-	
+
 	handler := http.HandlerFunc(CheckFences)
 	handler.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusOK, rr.Code)
-	
+
 	var resp map[string]interface{}
 	json.Unmarshal(rr.Body.Bytes(), &resp)
-	
+
 	assert.True(t, resp["is_inside"].(bool))
 	fencesList := resp["geofences"].([]interface{})
 	assert.Contains(t, fencesList, "Downtown-Zone-1")
@@ -35,18 +47,18 @@ func TestCheckFences_Outside(t *testing.T) {
 	// Point outside Downtown-Zone-1 (-111.0, 34.0)
 	p := Point{Lat: 34.0, Lon: -111.0}
 	body, _ := json.Marshal(p)
-	
+
 	req, _ := http.NewRequest("POST", "/geofence/check", bytes.NewBuffer(body))
 	rr := httptest.NewRecorder()
-	
+
 	handler := http.HandlerFunc(CheckFences)
 	handler.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusOK, rr.Code)
-	
+
 	var resp map[string]interface{}
 	json.Unmarshal(rr.Body.Bytes(), &resp)
-	
+
 	assert.False(t, resp["is_inside"].(bool))
 	fencesList := resp["geofences"].([]interface{})
 	assert.Empty(t, fencesList)
@@ -55,7 +67,7 @@ func TestCheckFences_Outside(t *testing.T) {
 func TestCheckFences_InvalidJSON(t *testing.T) {
 	req, _ := http.NewRequest("POST", "/geofence/check", bytes.NewBufferString("invalid json"))
 	rr := httptest.NewRecorder()
-	
+
 	handler := http.HandlerFunc(CheckFences)
 	handler.ServeHTTP(rr, req)
 
@@ -114,3 +126,147 @@ func TestCheckFences_OriginPoint(t *testing.T) {
 	assert.False(t, resp["is_inside"].(bool))
 	assert.Empty(t, resp["geofences"].([]interface{}))
 }
+
+func TestGRPCCheckFences(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	gs := newGRPCServer()
+	go gs.Serve(lis)
+	defer gs.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	defer conn.Close()
+	client := geofencev1.NewGeofenceServiceClient(conn)
+
+	res, err := client.CheckFences(context.Background(), &geofencev1.Point{Lat: 33.45, Lon: -112.07})
+	assert.NoError(t, err)
+	assert.True(t, res.IsInside)
+	assert.Equal(t, []string{"Downtown-Zone-1"}, res.Geofences)
+
+	res, err = client.CheckFences(context.Background(), &geofencev1.Point{Lat: 34.0, Lon: -111.0})
+	assert.NoError(t, err)
+	assert.False(t, res.IsInside)
+	assert.Empty(t, res.Geofences)
+}
+
+func TestGeofenceCRUD(t *testing.T) {
+	router := newRouter()
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do("GET", "/geofences/Downtown-Zone-1", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var f Geofence
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &f))
+	assert.Len(t, f.Polygon[0], 5)
+
+	fire := `{"polygon":[[[-111.6,34.5],[-111.4,34.5],[-111.4,34.7],[-111.6,34.7],[-111.6,34.5]]]}`
+	assert.Equal(t, http.StatusCreated, do("PUT", "/geofences/wildfire-7", fire).Code)
+	assert.Equal(t, http.StatusOK, do("PUT", "/geofences/wildfire-7", fire).Code)
+	assert.Equal(t, []string{"wildfire-7"}, fencesContaining(Point{Lat: 34.6, Lon: -111.5}))
+
+	var list []Geofence
+	assert.NoError(t, json.Unmarshal(do("GET", "/geofences", "").Body.Bytes(), &list))
+	assert.Len(t, list, 2)
+
+	assert.Equal(t, http.StatusBadRequest, do("PUT", "/geofences/bad", `{"polygon":[[[-111.6,34.5],[-111.4,34.5],[-111.4,34.7]]]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do("PUT", "/geofences/bad", `{}`).Code)
+
+	assert.Equal(t, http.StatusNoContent, do("DELETE", "/geofences/wildfire-7", "").Code)
+	assert.Equal(t, http.StatusNotFound, do("DELETE", "/geofences/wildfire-7", "").Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/geofences/wildfire-7", "").Code)
+	assert.Empty(t, fencesContaining(Point{Lat: 34.6, Lon: -111.5}))
+}
+
+func TestMetrics_FenceChecks(t *testing.T) {
+	inside := testutil.ToFloat64(fenceChecks.WithLabelValues("http", "inside"))
+	outside := testutil.ToFloat64(fenceChecks.WithLabelValues("http", "outside"))
+	r := newRouter()
+
+	for _, p := range []Point{{Lat: 33.45, Lon: -112.07}, {Lat: 0, Lon: 0}} {
+		body, _ := json.Marshal(p)
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/geofence/check", bytes.NewReader(body)))
+	}
+	assert.Equal(t, inside+1, testutil.ToFloat64(fenceChecks.WithLabelValues("http", "inside")))
+	assert.Equal(t, outside+1, testutil.ToFloat64(fenceChecks.WithLabelValues("http", "outside")))
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, rr.Body.String(), `navifly_http_request_duration_seconds_count{code="200",method="POST",route="/geofence/check"}`)
+}
+
+func TestOpenAPI_DocumentsEveryRoute(t *testing.T) {
+	served := map[string]bool{}
+	err := newRouter().Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		require.NoError(t, err)
+		methods, err := route.GetMethods()
+		require.NoError(t, err)
+		for _, m := range methods {
+			served[m+" "+path] = true
+			assert.NotNil(t, apiSpec.Operation(m, path), "%s %s is not in openapi.yaml", m, path)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	for path, item := range apiSpec.Doc.Paths.Map() {
+		for m := range item.Operations() {
+			assert.True(t, served[m+" "+path], "openapi.yaml documents %s %s, which is not served", m, path)
+		}
+	}
+
+	var documented []problem.Code
+	for _, v := range apiSpec.Doc.Components.Schemas["ErrorCode"].Value.Enum {
+		documented = append(documented, problem.Code(v.(string)))
+	}
+	assert.ElementsMatch(t, problem.Codes(), documented, "openapi.yaml ErrorCode lists every problem code")
+}
+
+func TestOpenAPI_RejectsInvalidRequests(t *testing.T) {
+	r := newRouter()
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rr
+	}
+
+	rr := do("POST", "/geofence/check", `{"lat": "33.45", "lon": -112.07}`)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "lat: value must be a number")
+
+	rr = do("PUT", "/geofences/closure", `{"polygon": [[[-112, 33], [-111, 33]]]}`)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "minimum number of items is 4")
+
+	rr = do("POST", "/geofence/check", `{"lat": 33.45, "lon": -112.07}`)
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+}
+
+func TestFences_Problems(t *testing.T) {
+	r := newRouter()
+	do := func(method, target, body string) (int, problem.Problem) {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(method, target, strings.NewReader(body)))
+		assert.Equal(t, problem.ContentType, rr.Header().Get("Content-Type"))
+		var p problem.Problem
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &p), rr.Body.String())
+		return rr.Code, p
+	}
+
+	code, p := do("GET", "/geofences/nowhere", "")
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, problem.NotFound, p.Code)
+
+	code, p = do("PUT", "/geofences/closure", `{"polygon": [[[-112, 33], [-111, 95], [-111, 34], [-112, 33]]]}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, problem.InvalidCoordinate, p.Code)
+	assert.Contains(t, p.Detail, "is not a [lon, lat] position")
+}
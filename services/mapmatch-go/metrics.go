@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"navifly/platform/metrics"
+)
+
+var fenceChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Subsystem: "mapmatch",
+	Name:      "geofence_checks_total",
+	Help:      "Geofence checks, by transport (http, grpc) and result (inside, outside).",
+}, []string{"transport", "result"})
+
+// countCheck records one geofence check.
+func countCheck(transport string, inside []string) {
+	result := "outside"
+	if len(inside) > 0 {
+		result = "inside"
+	}
+	fenceChecks.WithLabelValues(transport, result).Inc()
+}
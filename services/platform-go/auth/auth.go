@@ -0,0 +1,178 @@
+// Package auth authenticates NaviFly API callers. Devices present hashed API
+// keys and users present JWT bearer tokens; both resolve to a Principal
+// whose scopes are checked per route.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Scopes granted to keys and tokens.
+const (
+	ScopeTelemetryWrite = "telemetry:write"
+	ScopeTelemetryRead  = "telemetry:read"
+	ScopeRoutesRead     = "routes:read"
+	ScopeRoutesWrite    = "routes:write"
+	ScopeFencesRead     = "fences:read"
+	ScopeFencesAdmin    = "fences:admin"
+	ScopeUsersAdmin     = "users:admin"
+)
+
+// Authentication errors. Both are answered with 401.
+var (
+	ErrNoCredentials      = errors.New("missing credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is an authenticated caller.
+type Principal struct {
+	// Subject is the API key ID or the token's sub claim.
+	Subject string
+	// Kind is "api_key" or "jwt", or "anonymous" when auth is disabled.
+	Kind   string
+	Scopes []string
+	// Vehicles limits which vehicles an API key may report; empty allows any.
+	Vehicles []string
+}
+
+// Has reports whether the principal holds scope. With auth disabled every
+// scope is held.
+func (p *Principal) Has(scope string) bool {
+	if p.Kind == KindAnonymous || scope == "" {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// MayReport reports whether the principal may send telemetry as vehicleID.
+func (p *Principal) MayReport(vehicleID string) bool {
+	if len(p.Vehicles) == 0 {
+		return true
+	}
+	for _, v := range p.Vehicles {
+		if v == vehicleID {
+			return true
+		}
+	}
+	return false
+}
+
+// Principal kinds.
+const (
+	KindAPIKey    = "api_key"
+	KindJWT       = "jwt"
+	KindAnonymous = "anonymous"
+)
+
+type principalKey struct{}
+
+// WithPrincipal returns ctx carrying p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal authenticated for a request.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+type Config struct {
+	// APIKeysPath is a JSON file of hashed API keys.
+	APIKeysPath string
+	// JWKSPath is a JWKS file with RSA (RS256) and oct (HS256) keys.
+	JWKSPath string
+	// Issuer and Audience, when set, must match the token's iss and aud.
+	Issuer   string
+	Audience string
+}
+
+// ConfigFromEnv reads API_KEYS_PATH, JWKS_PATH, JWT_ISSUER and JWT_AUDIENCE.
+func ConfigFromEnv() Config {
+	return Config{
+		APIKeysPath: os.Getenv("API_KEYS_PATH"),
+		JWKSPath:    os.Getenv("JWKS_PATH"),
+		Issuer:      os.Getenv("JWT_ISSUER"),
+		Audience:    os.Getenv("JWT_AUDIENCE"),
+	}
+}
+
+// Authenticator checks API keys and JWTs. Without any key source it is
+// disabled and lets every request through as anonymous.
+type Authenticator struct {
+	keys *KeySet
+	jwt  *JWTVerifier
+}
+
+// New loads the key sources named in cfg.
+func New(cfg Config) (*Authenticator, error) {
+	a := &Authenticator{}
+	if cfg.APIKeysPath != "" {
+		keys, err := LoadAPIKeys(cfg.APIKeysPath)
+		if err != nil {
+			return nil, err
+		}
+		a.keys = keys
+	}
+	if cfg.JWKSPath != "" {
+		jwks, err := LoadJWKS(cfg.JWKSPath)
+		if err != nil {
+			return nil, err
+		}
+		a.jwt = &JWTVerifier{JWKS: jwks, Issuer: cfg.Issuer, Audience: cfg.Audience}
+	}
+	return a, nil
+}
+
+// Disabled is an Authenticator that allows everything, for tests and local
+// development.
+func Disabled() *Authenticator { return &Authenticator{} }
+
+// Enabled reports whether any key source is configured.
+func (a *Authenticator) Enabled() bool {
+	return a.keys != nil || a.jwt != nil
+}
+
+// Authenticate resolves the request's credentials: an X-API-Key header,
+// "Authorization: ApiKey <key>" or "Authorization: Bearer <jwt>".
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	return a.authenticate(r.Header.Get("Authorization"), r.Header.Get("X-API-Key"))
+}
+
+func (a *Authenticator) authenticate(authorization, apiKey string) (*Principal, error) {
+	if !a.Enabled() {
+		return &Principal{Subject: KindAnonymous, Kind: KindAnonymous}, nil
+	}
+	scheme, cred, _ := strings.Cut(strings.TrimSpace(authorization), " ")
+	switch {
+	case apiKey != "":
+		return a.apiKey(apiKey)
+	case strings.EqualFold(scheme, "ApiKey"):
+		return a.apiKey(strings.TrimSpace(cred))
+	case strings.EqualFold(scheme, "Bearer"):
+		if a.jwt == nil {
+			return nil, fmt.Errorf("%w: bearer tokens are not accepted", ErrInvalidCredentials)
+		}
+		return a.jwt.Verify(strings.TrimSpace(cred))
+	case authorization != "":
+		return nil, fmt.Errorf("%w: unsupported authorization scheme %q", ErrInvalidCredentials, scheme)
+	}
+	return nil, ErrNoCredentials
+}
+
+func (a *Authenticator) apiKey(key string) (*Principal, error) {
+	if a.keys == nil {
+		return nil, fmt.Errorf("%w: API keys are not accepted", ErrInvalidCredentials)
+	}
+	return a.keys.Lookup(key)
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var hmacSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// testAuthenticator trusts one device key, an HS256 secret and an RSA key.
+func testAuthenticator(t *testing.T) (*Authenticator, *rsa.PrivateKey) {
+	dir := t.TempDir()
+	keys, _ := json.Marshal([]APIKey{
+		{ID: "truck-42", SHA256: HashKey("nfk_device_secret"), Scopes: []string{ScopeTelemetryWrite}, Vehicles: []string{"truck-42"}},
+		{ID: "old", SHA256: HashKey("nfk_revoked"), Scopes: []string{ScopeTelemetryWrite}, Disabled: true},
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "keys.json"), keys, 0o600))
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwks, _ := json.Marshal(map[string]interface{}{"keys": []JWK{
+		{Kty: "oct", Kid: "hs", K: base64.RawURLEncoding.EncodeToString(hmacSecret)},
+		{Kty: "RSA", Kid: "rs", N: base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+			E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes())},
+	}})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "jwks.json"), jwks, 0o600))
+
+	a, err := New(Config{APIKeysPath: filepath.Join(dir, "keys.json"), JWKSPath: filepath.Join(dir, "jwks.json"), Issuer: "navifly"})
+	require.NoError(t, err)
+	return a, rsaKey
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, c jwt.MapClaims) string {
+	tok := jwt.NewWithClaims(method, c)
+	tok.Header["kid"] = kid
+	s, err := tok.SignedString(key)
+	require.NoError(t, err)
+	return s
+}
+
+func TestAuthenticate_APIKeys(t *testing.T) {
+	a, _ := testAuthenticator(t)
+	req := httptest.NewRequest("POST", "/ingest", nil)
+
+	req.Header.Set("X-API-Key", "nfk_device_secret")
+	p, err := a.Authenticate(req)
+	require.NoError(t, err)
+	assert.Equal(t, "truck-42", p.Subject)
+	assert.True(t, p.Has(ScopeTelemetryWrite))
+	assert.False(t, p.Has(ScopeFencesAdmin))
+	assert.True(t, p.MayReport("truck-42"))
+	assert.False(t, p.MayReport("truck-7"))
+
+	req.Header.Del("X-API-Key")
+	req.Header.Set("Authorization", "ApiKey nfk_device_secret")
+	_, err = a.Authenticate(req)
+	assert.NoError(t, err)
+
+	for _, bad := range []string{"ApiKey nope", "ApiKey nfk_revoked", "Basic dXNlcjpwYXNz"} {
+		req.Header.Set("Authorization", bad)
+		_, err = a.Authenticate(req)
+		assert.ErrorIs(t, err, ErrInvalidCredentials, bad)
+	}
+	req.Header.Del("Authorization")
+	_, err = a.Authenticate(req)
+	assert.ErrorIs(t, err, ErrNoCredentials)
+}
+
+func TestAuthenticate_JWT(t *testing.T) {
+	a, rsaKey := testAuthenticator(t)
+	exp := time.Now().Add(time.Hour).Unix()
+	bearer := func(tok string) (*Principal, error) {
+		req := httptest.NewRequest("GET", "/route", nil)
+		req.Header.Set("Authorization", "Bearer "+tok)
+		return a.Authenticate(req)
+	}
+
+	p, err := bearer(sign(t, jwt.SigningMethodHS256, "hs", hmacSecret,
+		jwt.MapClaims{"sub": "ana", "iss": "navifly", "exp": exp, "scope": "routes:read routes:write"}))
+	require.NoError(t, err)
+	assert.Equal(t, KindJWT, p.Kind)
+	assert.True(t, p.Has(ScopeRoutesWrite))
+
+	p, err = bearer(sign(t, jwt.SigningMethodRS256, "rs", rsaKey,
+		jwt.MapClaims{"sub": "dispatch", "iss": "navifly", "exp": exp, "scp": []string{"fences:admin"}}))
+	require.NoError(t, err)
+	assert.True(t, p.Has(ScopeFencesAdmin))
+
+	for name, tok := range map[string]string{
+		"expired":      sign(t, jwt.SigningMethodHS256, "hs", hmacSecret, jwt.MapClaims{"sub": "ana", "iss": "navifly", "exp": time.Now().Add(-time.Hour).Unix()}),
+		"no exp":       sign(t, jwt.SigningMethodHS256, "hs", hmacSecret, jwt.MapClaims{"sub": "ana", "iss": "navifly"}),
+		"wrong issuer": sign(t, jwt.SigningMethodHS256, "hs", hmacSecret, jwt.MapClaims{"sub": "ana", "iss": "evil", "exp": exp}),
+		"wrong secret": sign(t, jwt.SigningMethodHS256, "hs", []byte("another-secret-another-secret-00"), jwt.MapClaims{"sub": "ana", "iss": "navifly", "exp": exp}),
+		"unknown kid":  sign(t, jwt.SigningMethodHS256, "other", hmacSecret, jwt.MapClaims{"sub": "ana", "iss": "navifly", "exp": exp}),
+		// An HS256 token must not verify against the RSA key's kid
+		"alg mismatch": sign(t, jwt.SigningMethodHS256, "rs", hmacSecret, jwt.MapClaims{"sub": "ana", "iss": "navifly", "exp": exp}),
+		"no sub":       sign(t, jwt.SigningMethodHS256, "hs", hmacSecret, jwt.MapClaims{"iss": "navifly", "exp": exp}),
+		"garbage":      "not.a.jwt",
+	} {
+		_, err := bearer(tok)
+		assert.ErrorIs(t, err, ErrInvalidCredentials, name)
+	}
+}
+
+func TestRequire(t *testing.T) {
+	a, _ := testAuthenticator(t)
+	h := a.Require(ScopeTelemetryWrite, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := FromContext(r.Context())
+		assert.True(t, ok)
+		w.Write([]byte(p.Subject))
+	}))
+	serve := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/ingest", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve("X-API-Key", "nfk_device_secret")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "truck-42", rr.Body.String())
+
+	rr = serve("", "")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Header().Get("WWW-Authenticate"), "Bearer")
+	assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Body.String(), `"code":"UNAUTHORIZED"`)
+
+	tok := sign(t, jwt.SigningMethodHS256, "hs", hmacSecret, jwt.MapClaims{"sub": "ana", "iss": "navifly", "exp": time.Now().Add(time.Hour).Unix(), "scope": "routes:read"})
+	assert.Equal(t, http.StatusForbidden, serve("Authorization", "Bearer "+tok).Code)
+
+	// Without key sources everything is allowed
+	rr = httptest.NewRecorder()
+	Disabled().Require(ScopeFencesAdmin, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).
+		ServeHTTP(rr, httptest.NewRequest("DELETE", "/geofences/x", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	a, _ := testAuthenticator(t)
+	intercept := a.UnaryInterceptor(MethodScopes{"/navifly.Telemetry/Ingest": ScopeTelemetryWrite, "/navifly.Geofence/Put": ScopeFencesAdmin})
+	call := func(method string, md metadata.MD) error {
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		_, err := intercept(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			_, ok := FromContext(ctx)
+			assert.True(t, ok)
+			return nil, nil
+		})
+		return err
+	}
+
+	assert.NoError(t, call("/navifly.Telemetry/Ingest", metadata.Pairs("x-api-key", "nfk_device_secret")))
+	assert.Equal(t, codes.PermissionDenied, status.Code(call("/navifly.Geofence/Put", metadata.Pairs("x-api-key", "nfk_device_secret"))))
+	assert.Equal(t, codes.Unauthenticated, status.Code(call("/navifly.Telemetry/Ingest", metadata.MD{})))
+}
+
+func TestParseJWKS_Rejects(t *testing.T) {
+	for _, doc := range []string{
+		`{"keys":[]}`,
+		`{"keys":[{"kty":"oct","kid":"short","k":"c2hvcnQ"}]}`,
+		`{"keys":[{"kty":"EC","kid":"ec"}]}`,
+		`{"keys":[{"kty":"RSA","kid":"small","n":"AQAB","e":"AQAB"}]}`,
+	} {
+		_, err := ParseJWKS([]byte(doc))
+		assert.Error(t, err, doc)
+	}
+}
+
+func TestNewKeySet_Rejects(t *testing.T) {
+	_, err := NewKeySet([]APIKey{{ID: "a", SHA256: "abc"}})
+	assert.Error(t, err)
+	_, err = NewKeySet([]APIKey{{ID: "a", SHA256: HashKey("x")}, {ID: "a", SHA256: HashKey("y")}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWK is one key of a JWKS document. RSA keys verify RS256 and oct keys
+// (shared secrets) verify HS256.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg,omitempty"`
+	// RSA public key
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// oct secret
+	K string `json:"k,omitempty"`
+}
+
+// JWKS holds verification keys by algorithm and key ID.
+type JWKS struct {
+	keys map[string]map[string]interface{}
+}
+
+// ParseJWKS decodes a {"keys": [...]} document.
+func ParseJWKS(data []byte) (*JWKS, error) {
+	var doc struct {
+		Keys []JWK `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse JWKS: %w", err)
+	}
+	set := &JWKS{keys: map[string]map[string]interface{}{"RS256": {}, "HS256": {}}}
+	for _, k := range doc.Keys {
+		switch k.Kty {
+		case "RSA":
+			n, err1 := base64.RawURLEncoding.DecodeString(k.N)
+			e, err2 := base64.RawURLEncoding.DecodeString(k.E)
+			if err1 != nil || err2 != nil || len(n) == 0 || len(e) == 0 {
+				return nil, fmt.Errorf("JWKS key %q: invalid RSA modulus or exponent", k.Kid)
+			}
+			pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+			if pub.N.BitLen() < 2048 {
+				return nil, fmt.Errorf("JWKS key %q: RSA keys must be at least 2048 bits", k.Kid)
+			}
+			set.keys["RS256"][k.Kid] = pub
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil || len(secret) < 32 {
+				return nil, fmt.Errorf("JWKS key %q: oct keys need at least 32 bytes of base64url secret", k.Kid)
+			}
+			set.keys["HS256"][k.Kid] = secret
+		default:
+			return nil, fmt.Errorf("JWKS key %q: unsupported kty %q", k.Kid, k.Kty)
+		}
+	}
+	if len(set.keys["RS256"])+len(set.keys["HS256"]) == 0 {
+		return nil, fmt.Errorf("JWKS has no keys")
+	}
+	return set, nil
+}
+
+// LoadJWKS reads a JWKS file.
+func LoadJWKS(path string) (*JWKS, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read JWKS: %w", err)
+	}
+	return ParseJWKS(data)
+}
+
+// key picks the verification key for a token header. A missing kid is
+// accepted when the algorithm has a single key.
+func (s *JWKS) key(alg, kid string) (interface{}, error) {
+	keys, ok := s.keys[alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported alg %q", alg)
+	}
+	if kid == "" {
+		if len(keys) == 1 {
+			for _, k := range keys {
+				return k, nil
+			}
+		}
+		return nil, fmt.Errorf("token has no kid")
+	}
+	if k, ok := keys[kid]; ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown kid %q", kid)
+}
+
+// JWTVerifier checks bearer tokens against a JWKS.
+type JWTVerifier struct {
+	JWKS     *JWKS
+	Issuer   string
+	Audience string
+}
+
+// claims are the registered claims plus OAuth-style scopes: "scope" as a
+// space-separated string or "scp" as a list.
+type claims struct {
+	jwt.RegisteredClaims
+	Scope string   `json:"scope,omitempty"`
+	Scp   []string `json:"scp,omitempty"`
+}
+
+// Verify validates a token's signature, expiry, issuer and audience.
+func (v *JWTVerifier) Verify(token string) (*Principal, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "HS256"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if v.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.Issuer))
+	}
+	if v.Audience != "" {
+		opts = append(opts, jwt.WithAudience(v.Audience))
+	}
+
+	var c claims
+	_, err := jwt.ParseWithClaims(token, &c, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.JWKS.key(t.Method.Alg(), kid)
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	if c.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no sub", ErrInvalidCredentials)
+	}
+
+	scopes := append(strings.Fields(c.Scope), c.Scp...)
+	return &Principal{Subject: c.Subject, Kind: KindJWT, Scopes: scopes}, nil
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// APIKey is one entry of the API key file. Only the SHA-256 of the key is
+// stored; keys are long random strings, so a fast hash is enough.
+type APIKey struct {
+	ID     string   `json:"id"`
+	SHA256 string   `json:"sha256"`
+	Scopes []string `json:"scopes"`
+	// Vehicles binds a device key to the vehicles it may report.
+	Vehicles []string `json:"vehicles,omitempty"`
+	Disabled bool     `json:"disabled,omitempty"`
+}
+
+// KeySet looks up API keys by hash.
+type KeySet struct {
+	byHash map[string]APIKey
+}
+
+// HashKey is the hex SHA-256 stored for key.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewKeySet indexes keys, rejecting duplicates and malformed hashes.
+func NewKeySet(keys []APIKey) (*KeySet, error) {
+	ks := &KeySet{byHash: make(map[string]APIKey, len(keys))}
+	ids := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		h := strings.ToLower(k.SHA256)
+		if b, err := hex.DecodeString(h); err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("API key %q: sha256 must be 64 hex characters", k.ID)
+		}
+		if k.ID == "" || ids[k.ID] {
+			return nil, fmt.Errorf("API key IDs must be unique and non-empty (%q)", k.ID)
+		}
+		if _, dup := ks.byHash[h]; dup {
+			return nil, fmt.Errorf("API key %q: duplicate hash", k.ID)
+		}
+		ids[k.ID] = true
+		ks.byHash[h] = k
+	}
+	return ks, nil
+}
+
+// LoadAPIKeys reads a JSON array of APIKey from path.
+func LoadAPIKeys(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read API keys: %w", err)
+	}
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("parse API keys %s: %w", path, err)
+	}
+	return NewKeySet(keys)
+}
+
+// Lookup authenticates a presented key.
+func (ks *KeySet) Lookup(key string) (*Principal, error) {
+	k, ok := ks.byHash[HashKey(key)]
+	if !ok || k.Disabled {
+		return nil, fmt.Errorf("%w: unknown API key", ErrInvalidCredentials)
+	}
+	return &Principal{Subject: k.ID, Kind: KindAPIKey, Scopes: k.Scopes, Vehicles: k.Vehicles}, nil
+}
+
+// Len reports how many keys are loaded.
+func (ks *KeySet) Len() int { return len(ks.byHash) }
@@ -0,0 +1,137 @@
+// Package config loads service settings from an optional YAML file and the
+// environment. Fields name their variable with an `env` tag; variables that
+// are set and non-empty override the file, and the result is validated.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the optional YAML file read by Load.
+const FileEnv = "CONFIG_FILE"
+
+// Validator is implemented by configs that check themselves once loaded.
+type Validator interface {
+	Validate() error
+}
+
+// Load fills dst, a pointer to a struct already holding defaults, from the
+// YAML file named by CONFIG_FILE (if set) and then the environment, and
+// validates it.
+func Load(dst any) error {
+	if path := os.Getenv(FileEnv); path != "" {
+		if err := LoadFile(path, dst); err != nil {
+			return err
+		}
+	}
+	return finish(dst, os.Getenv)
+}
+
+// LoadFile decodes the YAML file at path into dst. Unknown keys are errors
+// so a typo does not silently leave a default in place.
+func LoadFile(path string, dst any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(dst); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return nil
+}
+
+// finish applies environment overrides from lookup and validates dst.
+func finish(dst any, lookup func(string) string) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: want a pointer to a struct, got %T", dst)
+	}
+	if err := applyEnv(v.Elem(), lookup); err != nil {
+		return err
+	}
+	if val, ok := dst.(Validator); ok {
+		if err := val.Validate(); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv sets every tagged field whose variable is set, descending into
+// nested structs.
+func applyEnv(v reflect.Value, lookup func(string) string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f, fv := t.Field(i), v.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Tag.Get("env")
+		if name == "" {
+			if fv.Kind() == reflect.Struct && f.Type != durationType {
+				if err := applyEnv(fv, lookup); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		raw := strings.TrimSpace(lookup(name))
+		if raw == "" {
+			continue
+		}
+		if err := setField(fv, raw); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func setField(fv reflect.Value, raw string) error {
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("not an integer: %q", raw)
+		}
+		fv.SetInt(n)
+	case reflect.Float64:
+		x, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("not a number: %q", raw)
+		}
+		fv.SetFloat(x)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("not a boolean: %q", raw)
+		}
+		fv.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	Server   `yaml:",inline"`
+	Upstream string  `yaml:"upstream" env:"TEST_UPSTREAM"`
+	Ratio    float64 `yaml:"ratio" env:"TEST_RATIO"`
+	Debug    bool    `yaml:"debug" env:"TEST_DEBUG"`
+	Nested   struct {
+		Path string `yaml:"path" env:"TEST_PATH"`
+	} `yaml:"nested"`
+}
+
+func writeFile(t *testing.T, body string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+	return path
+}
+
+func TestLoad_DefaultsFileThenEnv(t *testing.T) {
+	t.Setenv(FileEnv, writeFile(t, `
+http_port: 9000
+write_timeout: 90s
+upstream: http://file
+nested:
+  path: /from/file
+`))
+	t.Setenv("TEST_UPSTREAM", "http://env")
+	t.Setenv("TEST_RATIO", "0.5")
+	t.Setenv("TEST_DEBUG", "true")
+	t.Setenv("TEST_PATH", "")
+	t.Setenv("SHUTDOWN_TIMEOUT", "5s")
+
+	cfg := testConfig{Server: DefaultServer(8080, 9090)}
+	require.NoError(t, Load(&cfg))
+
+	assert.Equal(t, 9000, cfg.HTTPPort, "file")
+	assert.Equal(t, 9090, cfg.GRPCPort, "default")
+	assert.Equal(t, 90*time.Second, cfg.WriteTimeout, "file duration")
+	assert.Equal(t, 5*time.Second, cfg.ShutdownTimeout, "env duration")
+	assert.Equal(t, "http://env", cfg.Upstream, "env beats file")
+	assert.Equal(t, 0.5, cfg.Ratio)
+	assert.True(t, cfg.Debug)
+	assert.Equal(t, "/from/file", cfg.Nested.Path, "empty env is unset")
+}
+
+func TestLoad_Errors(t *testing.T) {
+	cfg := testConfig{Server: DefaultServer(8080, 9090)}
+	t.Setenv(FileEnv, writeFile(t, "htp_port: 1\n"))
+	assert.ErrorContains(t, Load(&cfg), "htp_port", "unknown keys are rejected")
+
+	t.Setenv(FileEnv, "")
+	t.Setenv("HTTP_PORT", "eighty")
+	assert.ErrorContains(t, Load(&cfg), "HTTP_PORT")
+
+	t.Setenv("HTTP_PORT", "9090")
+	assert.ErrorContains(t, Load(&cfg), "both 9090", "validated")
+}
+
+func TestServer_Validate(t *testing.T) {
+	assert.NoError(t, DefaultServer(8080, 0).Validate())
+	bad := DefaultServer(0, 70000)
+	bad.ReadTimeout = 0
+	err := bad.Validate()
+	assert.ErrorContains(t, err, "http_port 0")
+	assert.ErrorContains(t, err, "grpc_port 70000")
+	assert.ErrorContains(t, err, "read_timeout")
+}
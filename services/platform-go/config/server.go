@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Server holds the listener settings shared by every service. Embed it with
+// `yaml:",inline"`.
+type Server struct {
+	HTTPPort int `yaml:"http_port" env:"HTTP_PORT"`
+	// GRPCPort is ignored by services without a gRPC API.
+	GRPCPort int `yaml:"grpc_port" env:"GRPC_PORT"`
+	// ReadTimeout bounds reading a request, WriteTimeout handling it and
+	// writing the response, IdleTimeout a kept-alive connection.
+	ReadTimeout  time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	// ShutdownTimeout is how long SIGTERM waits for requests to drain and
+	// jobs to checkpoint.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+}
+
+// DefaultServer is Server with the given ports and the default timeouts.
+// The write timeout leaves room for a 30 s upstream call.
+func DefaultServer(httpPort, grpcPort int) Server {
+	return Server{
+		HTTPPort:        httpPort,
+		GRPCPort:        grpcPort,
+		ReadTimeout:     10 * time.Second,
+		WriteTimeout:    60 * time.Second,
+		IdleTimeout:     2 * time.Minute,
+		ShutdownTimeout: 20 * time.Second,
+	}
+}
+
+func (s Server) Validate() error {
+	var errs []error
+	if s.HTTPPort < 1 || s.HTTPPort > 65535 {
+		errs = append(errs, fmt.Errorf("http_port %d out of range", s.HTTPPort))
+	}
+	if s.GRPCPort < 0 || s.GRPCPort > 65535 {
+		errs = append(errs, fmt.Errorf("grpc_port %d out of range", s.GRPCPort))
+	}
+	if s.GRPCPort != 0 && s.GRPCPort == s.HTTPPort {
+		errs = append(errs, fmt.Errorf("http_port and grpc_port are both %d", s.HTTPPort))
+	}
+	timeouts := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"read_timeout", s.ReadTimeout},
+		{"write_timeout", s.WriteTimeout},
+		{"idle_timeout", s.IdleTimeout},
+		{"shutdown_timeout", s.ShutdownTimeout},
+	}
+	for _, t := range timeouts {
+		if t.d <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", t.name))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,113 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capture makes a JSON logger writing to a buffer the default for the test.
+func capture(t *testing.T, level slog.Level) *bytes.Buffer {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(New(&buf, "test", level))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &rec), line)
+		out = append(out, rec)
+	}
+	return out
+}
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]slog.Level{"": slog.LevelInfo, "debug": slog.LevelDebug, "WARN": slog.LevelWarn, "error": slog.LevelError} {
+		got, err := ParseLevel(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	_, err := ParseLevel("loud")
+	assert.Error(t, err)
+}
+
+func TestLogger_ServiceLevelAndRequestID(t *testing.T) {
+	buf := capture(t, slog.LevelInfo)
+
+	slog.Debug("hidden")
+	slog.InfoContext(WithRequestID(context.Background(), "abc"), "route served", KeyCache, "hit")
+
+	recs := records(t, buf)
+	require.Len(t, recs, 1)
+	assert.Equal(t, "test", recs[0]["service"])
+	assert.Equal(t, "route served", recs[0]["msg"])
+	assert.Equal(t, "abc", recs[0][KeyRequestID])
+	assert.Equal(t, "hit", recs[0][KeyCache])
+}
+
+func TestMiddleware_RequestIDs(t *testing.T) {
+	buf := capture(t, slog.LevelInfo)
+
+	var seen string
+	r := mux.NewRouter()
+	r.Use(Middleware)
+	r.HandleFunc("/route/{id}", func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestID(r.Context())
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	// A caller's ID is kept and echoed
+	req := httptest.NewRequest("GET", "/route/42", nil)
+	req.Header.Set(HeaderRequestID, "caller-1")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, "caller-1", seen)
+	assert.Equal(t, "caller-1", rec.Header().Get(HeaderRequestID))
+
+	// A missing or unprintable ID is replaced
+	req = httptest.NewRequest("GET", "/route/42", nil)
+	req.Header.Set(HeaderRequestID, "bad id\n")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Len(t, seen, 32)
+	assert.Equal(t, seen, rec.Header().Get(HeaderRequestID))
+
+	recs := records(t, buf)
+	require.Len(t, recs, 2)
+	assert.Equal(t, "request", recs[0]["msg"])
+	assert.Equal(t, "WARN", recs[0]["level"])
+	assert.Equal(t, "/route/{id}", recs[0]["route"])
+	assert.Equal(t, float64(404), recs[0]["status"])
+	assert.Equal(t, "caller-1", recs[0][KeyRequestID])
+}
+
+func TestTransport_ForwardsRequestID(t *testing.T) {
+	var got string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(HeaderRequestID)
+	}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: Transport(nil)}
+	req, _ := http.NewRequestWithContext(WithRequestID(context.Background(), "abc"), "GET", upstream.URL, nil)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "abc", got)
+}
@@ -0,0 +1,157 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// HeaderRequestID carries the request ID between clients and services.
+const HeaderRequestID = "X-Request-ID"
+
+// Middleware takes the request ID from X-Request-ID, or generates one,
+// echoes it in the response and logs one access line per request. Install
+// it first so the ID reaches every later handler.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(HeaderRequestID)
+		if !validRequestID(id) {
+			id = NewRequestID()
+		}
+		w.Header().Set(HeaderRequestID, id)
+		ctx := WithRequestID(r.Context(), id)
+
+		rec := &responseRecorder{ResponseWriter: w, code: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		route := r.URL.Path
+		if cur := mux.CurrentRoute(r); cur != nil {
+			if tpl, err := cur.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+		level := slog.LevelInfo
+		switch {
+		case rec.code >= 500:
+			level = slog.LevelError
+		case rec.code >= 400:
+			level = slog.LevelWarn
+		}
+		slog.Log(ctx, level, "request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", route,
+			"status", rec.code,
+			"bytes", rec.bytes,
+			"duration_ms", float64(time.Since(start).Microseconds())/1000,
+			"remote", r.RemoteAddr,
+		)
+	})
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	code        int
+	bytes       int
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.code, r.wroteHeader = code, true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Transport wraps base (http.DefaultTransport when nil) so outbound requests
+// carry the request ID of their context.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripper{base}
+}
+
+type roundTripper struct {
+	base http.RoundTripper
+}
+
+func (t roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	if id := RequestID(r.Context()); id != "" && r.Header.Get(HeaderRequestID) == "" {
+		r = r.Clone(r.Context())
+		r.Header.Set(HeaderRequestID, id)
+	}
+	return t.base.RoundTrip(r)
+}
+
+// ── gRPC ──
+
+// grpcRequestID takes the request ID from x-request-id metadata or makes one.
+func grpcRequestID(ctx context.Context) context.Context {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(HeaderRequestID); len(v) > 0 {
+			id = v[0]
+		}
+	}
+	if !validRequestID(id) {
+		id = NewRequestID()
+	}
+	return WithRequestID(ctx, id)
+}
+
+// ServerOptions gives gRPC calls a request ID and logs each call. Install
+// them before other interceptors so their logs carry the ID.
+func ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			ctx = grpcRequestID(ctx)
+			start := time.Now()
+			resp, err := handler(ctx, req)
+			logCall(ctx, info.FullMethod, start, err)
+			return resp, err
+		}),
+		grpc.ChainStreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx := grpcRequestID(ss.Context())
+			start := time.Now()
+			err := handler(srv, &idStream{ServerStream: ss, ctx: ctx})
+			logCall(ctx, info.FullMethod, start, err)
+			return err
+		}),
+	}
+}
+
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	args := []any{"method", method, "duration_ms", float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		slog.WarnContext(ctx, "grpc call", append(args, Err(err))...)
+		return
+	}
+	slog.InfoContext(ctx, "grpc call", args...)
+}
+
+type idStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *idStream) Context() context.Context { return s.ctx }
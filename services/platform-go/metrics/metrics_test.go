@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware_RouteTemplates(t *testing.T) {
+	r := mux.NewRouter()
+	r.Use(Middleware)
+	r.HandleFunc("/vehicle/{id}", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusNotFound)
+	}).Methods("GET")
+	r.Handle("/metrics", Handler())
+
+	for _, id := range []string{"a", "b", "c"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/vehicle/"+id, nil))
+	}
+	assert.Equal(t, 1, testutil.CollectAndCount(requestDuration))
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	body := rr.Body.String()
+	assert.Contains(t, body, `navifly_http_request_duration_seconds_count{code="404",method="GET",route="/vehicle/{id}"} 3`)
+	assert.NotContains(t, body, `route="/vehicle/a"`)
+}
+
+func TestRedisHook_CountsErrors(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	rdb.AddHook(RedisHook{})
+	ctx := context.Background()
+
+	// Missing keys are not errors
+	assert.ErrorIs(t, rdb.Get(ctx, "missing").Err(), redis.Nil)
+	assert.Equal(t, 0.0, testutil.ToFloat64(redisErrors.WithLabelValues("get")))
+
+	mr.Close()
+	assert.Error(t, rdb.Set(ctx, "k", "v", 0).Err())
+	assert.Equal(t, 1.0, testutil.ToFloat64(redisErrors.WithLabelValues("set")))
+
+	_, err = rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Incr(ctx, "n")
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(redisErrors.WithLabelValues("incr")))
+}
@@ -0,0 +1,247 @@
+// Package openapi serves each service's OpenAPI 3 document and checks
+// requests against it, so every service rejects malformed parameters and
+// bodies with the same problem response before its handlers run.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gorilla/mux"
+
+	"navifly/platform/problem"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+// Spec is a validated OpenAPI document.
+type Spec struct {
+	Doc  *openapi3.T
+	json []byte
+}
+
+// Load parses a YAML or JSON OpenAPI 3 document and checks that it is
+// valid.
+func Load(data []byte) (*Spec, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse OpenAPI document: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	js, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Spec{Doc: doc, json: js}, nil
+}
+
+// MustLoad is Load for documents embedded in the binary, where an error is
+// a programming mistake.
+func MustLoad(data []byte) *Spec {
+	s, err := Load(data)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Register adds GET /openapi.json and the GET /docs page that renders it.
+func (s *Spec) Register(r *mux.Router) {
+	r.HandleFunc("/openapi.json", s.ServeJSON).Methods("GET")
+	r.HandleFunc("/docs", ServeDocs).Methods("GET")
+}
+
+// ServeJSON writes the document as JSON.
+func (s *Spec) ServeJSON(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(s.json)
+}
+
+// ServeDocs writes a self-contained page that fetches openapi.json from
+// the same directory and lists its operations.
+func ServeDocs(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(docsPage)
+}
+
+// Operation returns the documented operation for a path template such as
+// /routes/{id}, or nil.
+func (s *Spec) Operation(method, path string) *openapi3.Operation {
+	item := s.Doc.Paths.Value(path)
+	if item == nil {
+		return nil
+	}
+	return item.GetOperation(method)
+}
+
+var filterOptions = &openapi3filter.Options{
+	MultiError: true,
+	// Credentials are checked by the auth package, which knows the keys
+	AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	// Handlers apply their own defaults; leave the request as sent
+	SkipSettingDefaults: true,
+}
+
+// Middleware validates the parameters and body of every request whose mux
+// route is documented, and answers with a problem listing every invalid
+// parameter when they do not match. Undocumented routes pass through. Wrap
+// handlers with it inside authentication and rate limiting, so callers
+// without access still get 401 or 403 and rejected requests count against
+// quotas.
+func (s *Spec) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cur := mux.CurrentRoute(r)
+		if cur == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		tpl, err := cur.GetPathTemplate()
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		item := s.Doc.Paths.Value(tpl)
+		if item == nil || item.GetOperation(r.Method) == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		op := item.GetOperation(r.Method)
+		defaultJSONBody(r, op)
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: mux.Vars(r),
+			Route: &routers.Route{
+				Spec:      s.Doc,
+				Path:      tpl,
+				PathItem:  item,
+				Method:    r.Method,
+				Operation: op,
+			},
+			Options: filterOptions,
+		}
+		if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+			problem.WriteError(w, r, Problem(err), problem.InvalidRequest)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// defaultJSONBody marks a body sent without a Content-Type as JSON when
+// JSON is all the operation accepts; the handlers have always decoded such
+// bodies.
+func defaultJSONBody(r *http.Request, op *openapi3.Operation) {
+	if op.RequestBody == nil || op.RequestBody.Value == nil || r.Header.Get("Content-Type") != "" {
+		return
+	}
+	content := op.RequestBody.Value.Content
+	if len(content) == 1 && content.Get("application/json") != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+}
+
+// ErrorCode is the schema or parameter extension naming the problem code
+// reported when a value does not match, e.g. "x-error-code:
+// INVALID_COORDINATE" on latitudes. Other mismatches are INVALID_REQUEST.
+const ErrorCode = "x-error-code"
+
+// Problem turns a validation error into a problem listing one invalid
+// parameter per mismatch, e.g. lat in query: "number must be at most 90".
+// When every mismatch carries the same x-error-code it becomes the code.
+func Problem(err error) *problem.Error {
+	return problem.Invalid(invalidParams(err)...)
+}
+
+// invalidParams flattens a validation error into one entry per mismatch,
+// each with the code it reports.
+func invalidParams(err error) []problem.InvalidParam {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		var params []problem.InvalidParam
+		for _, err := range e {
+			params = append(params, invalidParams(err)...)
+		}
+		return params
+	case *openapi3filter.RequestError:
+		var name, in string
+		var ext []map[string]any
+		switch {
+		case e.Parameter != nil:
+			name, in = e.Parameter.Name, e.Parameter.In
+			ext = append(ext, e.Parameter.Extensions)
+			if e.Parameter.Schema != nil && e.Parameter.Schema.Value != nil {
+				ext = append(ext, e.Parameter.Schema.Value.Extensions)
+			}
+		case e.RequestBody != nil:
+			in = "body"
+		default:
+			return []problem.InvalidParam{{Reason: e.Error(), Code: problem.InvalidRequest}}
+		}
+		if e.Err == nil {
+			return []problem.InvalidParam{{Name: name, In: in, Reason: e.Reason, Code: errorCode(ext...)}}
+		}
+		var params []problem.InvalidParam
+		for _, c := range causes(e.Err) {
+			p := problem.InvalidParam{Name: name, In: in, Reason: c.reason, Code: errorCode(append(ext, c.ext)...)}
+			if c.field != "" {
+				p.Name = c.field
+			}
+			params = append(params, p)
+		}
+		return params
+	}
+	return []problem.InvalidParam{{Reason: err.Error(), Code: problem.InvalidRequest}}
+}
+
+type cause struct {
+	field  string // dotted path inside a body
+	reason string
+	ext    map[string]any // extensions of the schema that failed
+}
+
+// causes explains the cause of a RequestError without the schema dump
+// kin-openapi includes in SchemaError.Error.
+func causes(err error) []cause {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		var out []cause
+		for _, err := range e {
+			out = append(out, causes(err)...)
+		}
+		return out
+	case *openapi3.SchemaError:
+		c := cause{field: strings.Join(e.JSONPointer(), "."), reason: e.Reason}
+		if e.Schema != nil {
+			c.ext = e.Schema.Extensions
+		}
+		return []cause{c}
+	case *openapi3filter.ParseError:
+		return []cause{{reason: e.Error()}}
+	}
+	if errors.Is(err, openapi3filter.ErrInvalidRequired) {
+		return []cause{{reason: "value is required"}}
+	}
+	return []cause{{reason: err.Error()}}
+}
+
+// errorCode returns the first x-error-code among exts, innermost last.
+func errorCode(exts ...map[string]any) problem.Code {
+	for i := len(exts) - 1; i >= 0; i-- {
+		if c, ok := exts[i][ErrorCode].(string); ok {
+			return problem.Code(c)
+		}
+	}
+	return problem.InvalidRequest
+}
@@ -0,0 +1,153 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"navifly/platform/problem"
+)
+
+const testSpec = `
+openapi: 3.0.3
+info: {title: Test, version: "1"}
+paths:
+  /near:
+    get:
+      parameters:
+        - {name: lat, in: query, required: true, schema: {type: number, minimum: -90, maximum: 90, x-error-code: INVALID_COORDINATE}}
+        - {name: radius, in: query, schema: {type: number, exclusiveMinimum: true, minimum: 0}}
+      responses: {"200": {description: OK}}
+  /things/{id}:
+    put:
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string, pattern: '^[a-z]+$'}}
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [lat]
+              properties:
+                lat: {type: number, minimum: -90, maximum: 90, x-error-code: INVALID_COORDINATE}
+      responses: {"200": {description: OK}}
+`
+
+func newTestRouter(t *testing.T) *mux.Router {
+	spec, err := Load([]byte(testSpec))
+	require.NoError(t, err)
+	r := mux.NewRouter()
+	r.Use(spec.Middleware)
+	echo := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}
+	r.HandleFunc("/near", echo).Methods("GET")
+	r.HandleFunc("/things/{id}", echo).Methods("PUT")
+	r.HandleFunc("/undocumented", echo).Methods("GET")
+	spec.Register(r)
+	return r
+}
+
+func do(r http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rr
+}
+
+func decode(t *testing.T, rr *httptest.ResponseRecorder) problem.Problem {
+	require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+	assert.Equal(t, problem.ContentType, rr.Header().Get("Content-Type"))
+	var p problem.Problem
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &p))
+	return p
+}
+
+func details(t *testing.T, rr *httptest.ResponseRecorder) []string {
+	var out []string
+	for _, p := range decode(t, rr).InvalidParams {
+		out = append(out, p.String())
+	}
+	return out
+}
+
+func TestMiddleware_Query(t *testing.T) {
+	r := newTestRouter(t)
+	assert.Equal(t, http.StatusOK, do(r, "GET", "/near?lat=33.4", "").Code)
+
+	assert.Equal(t, []string{`query parameter "lat": value is required`}, details(t, do(r, "GET", "/near", "")))
+
+	got := details(t, do(r, "GET", "/near?lat=91&radius=0", ""))
+	require.Len(t, got, 2, "every problem is reported")
+	assert.Contains(t, got[0], `query parameter "lat": number must be at most 90`)
+	assert.Contains(t, got[1], `query parameter "radius"`)
+
+	got = details(t, do(r, "GET", "/near?lat=abc", ""))
+	require.Len(t, got, 1)
+	assert.Contains(t, got[0], `query parameter "lat"`)
+}
+
+func TestMiddleware_ErrorCode(t *testing.T) {
+	r := newTestRouter(t)
+	p := decode(t, do(r, "GET", "/near?lat=91", ""))
+	assert.Equal(t, problem.InvalidCoordinate, p.Code)
+	assert.Equal(t, []problem.InvalidParam{{Name: "lat", In: "query", Reason: "number must be at most 90", Code: problem.InvalidCoordinate}}, p.InvalidParams)
+	assert.Equal(t, `query parameter "lat": number must be at most 90`, p.Detail)
+	assert.Equal(t, "/near", p.Instance)
+
+	p = decode(t, do(r, "PUT", "/things/abc", `{"lat": -95}`))
+	assert.Equal(t, problem.InvalidCoordinate, p.Code)
+	assert.Equal(t, []problem.InvalidParam{{Name: "lat", In: "body", Reason: "number must be at least -90", Code: problem.InvalidCoordinate}}, p.InvalidParams)
+
+	// Mixed problems fall back to the generic code
+	assert.Equal(t, problem.InvalidRequest, decode(t, do(r, "GET", "/near?lat=91&radius=0", "")).Code)
+	assert.Equal(t, problem.InvalidRequest, decode(t, do(r, "PUT", "/things/ABC", `{"lat": 1}`)).Code)
+}
+
+func TestMiddleware_Body(t *testing.T) {
+	r := newTestRouter(t)
+	rr := do(r, "PUT", "/things/abc", `{"lat": 33.4}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.JSONEq(t, `{"lat": 33.4}`, rr.Body.String(), "handlers still read the body")
+
+	got := details(t, do(r, "PUT", "/things/abc", `{"lat": 95}`))
+	assert.Equal(t, []string{`request body: lat: number must be at most 90`}, got)
+
+	got = details(t, do(r, "PUT", "/things/abc", ""))
+	assert.Equal(t, []string{`request body: value is required`}, got)
+
+	got = details(t, do(r, "PUT", "/things/ABC", `{"lat": 1}`))
+	require.Len(t, got, 1)
+	assert.Contains(t, got[0], `path parameter "id"`)
+}
+
+func TestMiddleware_UndocumentedPassesThrough(t *testing.T) {
+	r := newTestRouter(t)
+	assert.Equal(t, http.StatusOK, do(r, "GET", "/undocumented?lat=abc", "").Code)
+}
+
+func TestRegister(t *testing.T) {
+	r := newTestRouter(t)
+	rr := do(r, "GET", "/openapi.json", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &doc))
+	assert.Equal(t, "3.0.3", doc["openapi"])
+
+	rr = do(r, "GET", "/docs", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `fetch("openapi.json")`)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	_, err := Load([]byte("openapi: 3.0.3\ninfo: {title: Test}\npaths: {}\n"))
+	assert.ErrorContains(t, err, "version")
+}
@@ -0,0 +1,259 @@
+// Package problem reports API errors as RFC 7807 application/problem+json
+// with a stable machine-readable code, so clients can tell an unknown
+// location from an unreachable upstream without parsing messages. The same
+// errors map to gRPC status codes.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"navifly/platform/logging"
+)
+
+// ContentType is the media type of problem responses.
+const ContentType = "application/problem+json"
+
+// Code identifies a kind of error. Codes are part of the API: clients switch
+// on them, so never change the meaning of one.
+type Code string
+
+const (
+	// InvalidRequest is a malformed parameter or body.
+	InvalidRequest Code = "INVALID_REQUEST"
+	// InvalidCoordinate is a latitude, longitude or bounding box out of
+	// range.
+	InvalidCoordinate Code = "INVALID_COORDINATE"
+	// UnknownLocation is a start, stop or end that is neither a catalog
+	// location nor one of the user's places.
+	UnknownLocation Code = "UNKNOWN_LOCATION"
+	// UnknownGeofence is an avoid_fences ID the mapmatch service does not
+	// know.
+	UnknownGeofence Code = "UNKNOWN_GEOFENCE"
+	// TooManyStops is a trip with more stops than the service routes in one
+	// request.
+	TooManyStops Code = "TOO_MANY_STOPS"
+	// DuplicateStop is a stop that repeats the waypoint before it.
+	DuplicateStop Code = "DUPLICATE_STOP"
+	// NoRoute means no route satisfies the request, e.g. every alternative
+	// breaks a truck restriction or enters an avoid area.
+	NoRoute Code = "NO_ROUTE"
+
+	Unauthorized   Code = "UNAUTHORIZED"
+	Forbidden      Code = "FORBIDDEN"
+	NotFound       Code = "NOT_FOUND"
+	Expired        Code = "EXPIRED"
+	RateLimited    Code = "RATE_LIMITED"
+	Internal       Code = "INTERNAL"
+	NotImplemented Code = "NOT_IMPLEMENTED"
+
+	// UpstreamUnavailable means OSRM, another NaviFly service or a third
+	// party API failed or could not be reached.
+	UpstreamUnavailable Code = "UPSTREAM_UNAVAILABLE"
+	// Unavailable means this service cannot serve the request right now,
+	// e.g. its Redis is down or a feature is not configured.
+	Unavailable Code = "UNAVAILABLE"
+)
+
+type codeInfo struct {
+	status int
+	title  string
+	grpc   codes.Code
+}
+
+var catalog = map[Code]codeInfo{
+	InvalidRequest:      {http.StatusBadRequest, "Invalid request", codes.InvalidArgument},
+	InvalidCoordinate:   {http.StatusBadRequest, "Invalid coordinate", codes.InvalidArgument},
+	UnknownLocation:     {http.StatusBadRequest, "Unknown location", codes.InvalidArgument},
+	UnknownGeofence:     {http.StatusBadRequest, "Unknown geofence", codes.InvalidArgument},
+	TooManyStops:        {http.StatusBadRequest, "Too many stops", codes.InvalidArgument},
+	DuplicateStop:       {http.StatusBadRequest, "Duplicate stop", codes.InvalidArgument},
+	NoRoute:             {http.StatusUnprocessableEntity, "No route", codes.FailedPrecondition},
+	Unauthorized:        {http.StatusUnauthorized, "Unauthorized", codes.Unauthenticated},
+	Forbidden:           {http.StatusForbidden, "Forbidden", codes.PermissionDenied},
+	NotFound:            {http.StatusNotFound, "Not found", codes.NotFound},
+	Expired:             {http.StatusGone, "Expired", codes.NotFound},
+	RateLimited:         {http.StatusTooManyRequests, "Rate limit exceeded", codes.ResourceExhausted},
+	Internal:            {http.StatusInternalServerError, "Internal error", codes.Internal},
+	NotImplemented:      {http.StatusNotImplemented, "Not implemented", codes.Unimplemented},
+	UpstreamUnavailable: {http.StatusBadGateway, "Upstream unavailable", codes.Unavailable},
+	Unavailable:         {http.StatusServiceUnavailable, "Service unavailable", codes.Unavailable},
+}
+
+func (c Code) info() codeInfo {
+	if info, ok := catalog[c]; ok {
+		return info
+	}
+	return catalog[Internal]
+}
+
+// Status is the HTTP status for the code.
+func (c Code) Status() int { return c.info().status }
+
+// Title is a short summary of the code that does not change between
+// occurrences.
+func (c Code) Title() string { return c.info().title }
+
+// Type is the RFC 7807 problem type URI, e.g.
+// urn:navifly:problem:unknown-location.
+func (c Code) Type() string {
+	return "urn:navifly:problem:" + strings.ToLower(strings.ReplaceAll(string(c), "_", "-"))
+}
+
+// Codes lists every code, for documentation and tests.
+func Codes() []Code {
+	return []Code{
+		InvalidRequest, InvalidCoordinate, UnknownLocation, UnknownGeofence,
+		TooManyStops, DuplicateStop, NoRoute,
+		Unauthorized, Forbidden, NotFound, Expired, RateLimited,
+		Internal, NotImplemented, UpstreamUnavailable, Unavailable,
+	}
+}
+
+// InvalidParam explains why one parameter or body field was rejected.
+type InvalidParam struct {
+	// Name is the parameter name or the dotted path of a body field.
+	Name string `json:"name,omitempty"`
+	// In is query, path, header or body.
+	In     string `json:"in,omitempty"`
+	Reason string `json:"reason"`
+	// Code is the code this parameter alone would be reported under.
+	Code Code `json:"code,omitempty"`
+	// Suggestion is the closest valid value, e.g. a location ID for a typo.
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// String reads like `query parameter "lat": number must be at most 90` or
+// `request body: end: property "end" is missing`.
+func (p InvalidParam) String() string {
+	reason := p.Reason
+	if p.Suggestion != "" {
+		reason += fmt.Sprintf(" (did you mean %q?)", p.Suggestion)
+	}
+	switch {
+	case p.In == "body" && p.Name != "":
+		return "request body: " + p.Name + ": " + reason
+	case p.In == "body":
+		return "request body: " + reason
+	case p.In != "" && p.Name != "":
+		return fmt.Sprintf("%s parameter %q: %s", p.In, p.Name, reason)
+	case p.Name != "":
+		return p.Name + ": " + reason
+	}
+	return reason
+}
+
+// Problem is the application/problem+json body.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      Code   `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+	// InvalidParams lists every rejected parameter of an invalid request.
+	InvalidParams []InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// Error is an error with a code. Return it from anywhere below a handler and
+// WriteError keeps its code.
+type Error struct {
+	Code   Code
+	Detail string
+	// InvalidParams are reported as invalid_params.
+	InvalidParams []InvalidParam
+
+	err error
+}
+
+// New returns an error with code and a message for humans.
+func New(code Code, detail string) *Error {
+	return &Error{Code: code, Detail: detail}
+}
+
+// Errorf is New with a formatted message; %w wraps an error as with
+// fmt.Errorf.
+func Errorf(code Code, format string, args ...any) *Error {
+	err := fmt.Errorf(format, args...)
+	return &Error{Code: code, Detail: err.Error(), err: errors.Unwrap(err)}
+}
+
+func (e *Error) Error() string { return e.Detail }
+
+func (e *Error) Unwrap() error { return e.err }
+
+// GRPCStatus lets gRPC handlers return an *Error as is. The code travels as
+// the reason of an ErrorInfo detail.
+func (e *Error) GRPCStatus() *status.Status {
+	st := status.New(e.Code.info().grpc, e.Detail)
+	if withInfo, err := st.WithDetails(&errdetails.ErrorInfo{Reason: string(e.Code), Domain: "navifly"}); err == nil {
+		return withInfo
+	}
+	return st
+}
+
+// Invalid reports every rejected parameter at once. The code is the one
+// the parameters share, or InvalidRequest when they differ; parameters
+// without a code count as InvalidRequest.
+func Invalid(params ...InvalidParam) *Error {
+	if len(params) == 0 {
+		return New(InvalidRequest, "invalid request")
+	}
+	code := params[0].Code
+	msgs := make([]string, len(params))
+	for i, p := range params {
+		if p.Code != code {
+			code = InvalidRequest
+		}
+		msgs[i] = p.String()
+	}
+	if code == "" {
+		code = InvalidRequest
+	}
+	return &Error{Code: code, Detail: strings.Join(msgs, "; "), InvalidParams: params}
+}
+
+// As returns the *Error in err's chain, or err reported under fallback.
+func As(err error, fallback Code) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return &Error{Code: fallback, Detail: err.Error(), err: err}
+}
+
+// Write replies to r with a problem for code. It is the problem+json
+// counterpart of http.Error.
+func Write(w http.ResponseWriter, r *http.Request, code Code, detail string) {
+	WriteError(w, r, New(code, detail), code)
+}
+
+// WriteError replies to r with err as a problem. Errors without a code are
+// reported under fallback.
+func WriteError(w http.ResponseWriter, r *http.Request, err error, fallback Code) {
+	e := As(err, fallback)
+	p := Problem{
+		Type:          e.Code.Type(),
+		Title:         e.Code.Title(),
+		Status:        e.Code.Status(),
+		Detail:        e.Detail,
+		Instance:      r.URL.Path,
+		Code:          e.Code,
+		RequestID:     logging.RequestID(r.Context()),
+		InvalidParams: e.InvalidParams,
+	}
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", ContentType)
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
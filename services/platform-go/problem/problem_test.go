@@ -0,0 +1,109 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"navifly/platform/logging"
+)
+
+func decode(t *testing.T, rr *httptest.ResponseRecorder) Problem {
+	assert.Equal(t, ContentType, rr.Header().Get("Content-Type"))
+	var p Problem
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &p))
+	assert.Equal(t, rr.Code, p.Status)
+	return p
+}
+
+func TestWrite(t *testing.T) {
+	req := httptest.NewRequest("GET", "/route?start=nowhere", nil)
+	req = req.WithContext(logging.WithRequestID(req.Context(), "req-1"))
+	rr := httptest.NewRecorder()
+	Write(rr, req, UnknownLocation, `unknown start location "nowhere"`)
+
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, Problem{
+		Type:      "urn:navifly:problem:unknown-location",
+		Title:     "Unknown location",
+		Status:    http.StatusBadRequest,
+		Detail:    `unknown start location "nowhere"`,
+		Instance:  "/route",
+		Code:      UnknownLocation,
+		RequestID: "req-1",
+	}, decode(t, rr))
+}
+
+func TestWriteError(t *testing.T) {
+	req := httptest.NewRequest("GET", "/route", nil)
+
+	// The code survives wrapping
+	rr := httptest.NewRecorder()
+	WriteError(rr, req, fmt.Errorf("plan trip: %w", New(UpstreamUnavailable, "OSRM unreachable")), Internal)
+	assert.Equal(t, http.StatusBadGateway, rr.Code)
+	assert.Equal(t, UpstreamUnavailable, decode(t, rr).Code)
+
+	rr = httptest.NewRecorder()
+	WriteError(rr, req, errors.New("no segment near start"), NoRoute)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Equal(t, NoRoute, decode(t, rr).Code)
+
+	rr = httptest.NewRecorder()
+	e := New(InvalidRequest, "2 problems")
+	e.InvalidParams = []InvalidParam{{Name: "lat", In: "query", Reason: "value is required"}}
+	WriteError(rr, req, e, Internal)
+	assert.Equal(t, e.InvalidParams, decode(t, rr).InvalidParams)
+}
+
+func TestErrorf_Wraps(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := Errorf(UpstreamUnavailable, "OSRM: %w", cause)
+	assert.Equal(t, "OSRM: connection refused", err.Error())
+	assert.ErrorIs(t, err, cause)
+
+	assert.Same(t, err, As(fmt.Errorf("route: %w", err), Internal))
+	assert.Equal(t, NoRoute, As(cause, NoRoute).Code)
+}
+
+func TestInvalid(t *testing.T) {
+	e := Invalid(
+		InvalidParam{Name: "stops[0]", In: "query", Reason: `unknown location "tuscon"`, Code: UnknownLocation, Suggestion: "tucson"},
+		InvalidParam{Name: "end", In: "query", Reason: `unknown location "flagstaf"`, Code: UnknownLocation},
+	)
+	assert.Equal(t, UnknownLocation, e.Code)
+	assert.Equal(t, `query parameter "stops[0]": unknown location "tuscon" (did you mean "tucson"?); `+
+		`query parameter "end": unknown location "flagstaf"`, e.Detail)
+	assert.Len(t, e.InvalidParams, 2)
+
+	// Different codes, or none, report the generic one
+	e = Invalid(InvalidParam{Reason: "a", Code: UnknownLocation}, InvalidParam{Reason: "b", Code: DuplicateStop})
+	assert.Equal(t, InvalidRequest, e.Code)
+	assert.Equal(t, InvalidRequest, Invalid(InvalidParam{Reason: "a"}).Code)
+}
+
+func TestGRPCStatus(t *testing.T) {
+	st := status.Convert(fmt.Errorf("wrapped: %w", New(UnknownLocation, "unknown end location")))
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, "UNKNOWN_LOCATION", info.Reason)
+}
+
+func TestCodes_AreCatalogued(t *testing.T) {
+	for _, c := range Codes() {
+		_, ok := catalog[c]
+		assert.True(t, ok, c)
+	}
+	assert.Len(t, catalog, len(Codes()))
+	assert.Equal(t, http.StatusInternalServerError, Code("NOPE").Status())
+}
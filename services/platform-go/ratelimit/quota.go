@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// QuotaStore counts requests per key. Counters expire after ttl so old days
+// clean themselves up.
+type QuotaStore interface {
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+}
+
+// RedisQuotas keeps quota counters in Redis, shared by every replica.
+type RedisQuotas struct {
+	Client *redis.Client
+}
+
+// Incr counts and sets the expiry in one MULTI, so a counter never outlives
+// its ttl even if the process dies between the two. EXPIRE NX keeps the
+// expiry set by the first request of the day.
+func (q *RedisQuotas) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := q.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.ExpireNX(ctx, key, ttl)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// MemoryQuotas keeps quota counters in process, for single-instance
+// deployments without Redis.
+type MemoryQuotas struct {
+	mu     sync.Mutex
+	counts map[string]memoryCount
+	now    func() time.Time
+}
+
+type memoryCount struct {
+	n       int64
+	expires time.Time
+}
+
+func NewMemoryQuotas() *MemoryQuotas {
+	return &MemoryQuotas{counts: make(map[string]memoryCount), now: time.Now}
+}
+
+func (q *MemoryQuotas) Incr(_ context.Context, key string, ttl time.Duration) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := q.now()
+	c, ok := q.counts[key]
+	if !ok || now.After(c.expires) {
+		c = memoryCount{expires: now.Add(ttl)}
+		// Drop expired counters while we hold the lock anyway
+		for k, old := range q.counts {
+			if now.After(old.expires) {
+				delete(q.counts, k)
+			}
+		}
+	}
+	c.n++
+	q.counts[key] = c
+	return c.n, nil
+}
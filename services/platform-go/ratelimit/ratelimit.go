@@ -0,0 +1,312 @@
+// Package ratelimit throttles NaviFly API clients with a token bucket per
+// API key (or per IP for anonymous callers) and an optional daily quota
+// counted in Redis.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	"navifly/platform/auth"
+	"navifly/platform/problem"
+)
+
+// Config sets the limits applied to every client.
+type Config struct {
+	// Rate is the sustained requests per second; 0 disables the bucket.
+	Rate float64
+	// Burst is the bucket size.
+	Burst int
+	// DailyQuota caps requests per client per UTC day; 0 disables it.
+	DailyQuota int64
+	// TrustProxy takes the client IP from X-Forwarded-For.
+	TrustProxy bool
+	// Scope keeps this limiter's quota counters apart from those of other
+	// limiters sharing the QuotaStore; empty for a service's main limiter.
+	Scope string
+}
+
+// ConfigFromEnv overrides defaults with RATE_LIMIT_RPS, RATE_LIMIT_BURST,
+// DAILY_QUOTA and TRUST_PROXY.
+func ConfigFromEnv(defaults Config) (Config, error) {
+	cfg := defaults
+	if v := os.Getenv("RATE_LIMIT_RPS"); v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil || rate < 0 {
+			return cfg, fmt.Errorf("RATE_LIMIT_RPS must be a non-negative number")
+		}
+		cfg.Rate = rate
+	}
+	if v := os.Getenv("RATE_LIMIT_BURST"); v != "" {
+		burst, err := strconv.Atoi(v)
+		if err != nil || burst < 1 {
+			return cfg, fmt.Errorf("RATE_LIMIT_BURST must be a positive integer")
+		}
+		cfg.Burst = burst
+	}
+	if v := os.Getenv("DAILY_QUOTA"); v != "" {
+		quota, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || quota < 0 {
+			return cfg, fmt.Errorf("DAILY_QUOTA must be a non-negative integer")
+		}
+		cfg.DailyQuota = quota
+	}
+	if v := os.Getenv("TRUST_PROXY"); v != "" {
+		cfg.TrustProxy, _ = strconv.ParseBool(v)
+	}
+	if cfg.Burst < 1 {
+		cfg.Burst = int(math.Max(1, math.Ceil(cfg.Rate)))
+	}
+	return cfg, nil
+}
+
+// bucketIdle is how long a client's bucket is kept after its last request.
+const bucketIdle = 10 * time.Minute
+
+// Limiter enforces a Config. It is safe for concurrent use.
+type Limiter struct {
+	cfg    Config
+	quotas QuotaStore
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// New returns a Limiter counting daily quotas in quotas, which may be nil
+// when cfg has no quota.
+func New(cfg Config, quotas QuotaStore) *Limiter {
+	if cfg.Burst < 1 {
+		cfg.Burst = 1
+	}
+	return &Limiter{cfg: cfg, quotas: quotas, buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Decision is the outcome of one request, with the figures reported in the
+// RateLimit-* headers.
+type Decision struct {
+	Allowed   bool
+	Limit     int64
+	Remaining int64
+	// Reset is when Remaining next grows; RetryAfter is set on denials.
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Allow charges one request to client. The bucket is checked first so
+// throttled requests do not use up the daily quota. Quota store errors fail
+// open.
+func (l *Limiter) Allow(ctx context.Context, client string) Decision {
+	now := l.now()
+	d := Decision{Allowed: true, Limit: math.MaxInt64, Remaining: math.MaxInt64}
+
+	if l.cfg.Rate > 0 {
+		tokens, wait := l.take(client, now)
+		d.Limit, d.Remaining = int64(l.cfg.Burst), int64(tokens)
+		d.Reset = time.Duration(math.Ceil((1-math.Mod(tokens, 1))/l.cfg.Rate*1e9)) * time.Nanosecond
+		if wait > 0 {
+			d.Allowed, d.Remaining, d.Reset, d.RetryAfter = false, 0, wait, wait
+			return d
+		}
+	}
+
+	if l.cfg.DailyQuota > 0 && l.quotas != nil {
+		day := now.UTC().Truncate(24 * time.Hour)
+		untilMidnight := day.Add(24 * time.Hour).Sub(now)
+		key := "quota:" + client + ":" + day.Format("2006-01-02")
+		if l.cfg.Scope != "" {
+			key = "quota:" + l.cfg.Scope + ":" + client + ":" + day.Format("2006-01-02")
+		}
+		n, err := l.quotas.Incr(ctx, key, 48*time.Hour)
+		if err != nil {
+			slog.WarnContext(ctx, "quota store unavailable, not enforcing daily quota", "error", err)
+			return d
+		}
+		remaining := l.cfg.DailyQuota - n
+		if remaining < 0 {
+			return Decision{Limit: l.cfg.DailyQuota, Reset: untilMidnight, RetryAfter: untilMidnight}
+		}
+		// Report whichever limit is closer to running out
+		if remaining < d.Remaining {
+			d.Limit, d.Remaining, d.Reset = l.cfg.DailyQuota, remaining, untilMidnight
+		}
+	}
+	return d
+}
+
+// take refills client's bucket and removes a token. It returns the tokens
+// left, or how long to wait when the bucket is empty.
+func (l *Limiter) take(client string, now time.Time) (float64, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > bucketIdle {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > bucketIdle {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	burst := float64(l.cfg.Burst)
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*l.cfg.Rate)
+	b.last = now
+	if b.tokens < 1 {
+		return b.tokens, time.Duration(math.Ceil((1-b.tokens)/l.cfg.Rate*1e9)) * time.Nanosecond
+	}
+	b.tokens--
+	return b.tokens, 0
+}
+
+// Policy describes the limits for the RateLimit-Policy header, e.g.
+// "20;w=1, 5000;w=86400".
+func (l *Limiter) Policy() string {
+	var parts []string
+	if l.cfg.Rate > 0 {
+		parts = append(parts, fmt.Sprintf("%d;w=%d", l.cfg.Burst, int(math.Ceil(float64(l.cfg.Burst)/l.cfg.Rate))))
+	}
+	if l.cfg.DailyQuota > 0 {
+		parts = append(parts, fmt.Sprintf("%d;w=86400", l.cfg.DailyQuota))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Enabled reports whether any limit is configured.
+func (l *Limiter) Enabled() bool {
+	return l.cfg.Rate > 0 || (l.cfg.DailyQuota > 0 && l.quotas != nil)
+}
+
+// ClientID identifies the caller: its API key or token subject when
+// authenticated, its IP otherwise.
+func (l *Limiter) ClientID(r *http.Request) string {
+	if p, ok := auth.FromContext(r.Context()); ok && p.Kind != auth.KindAnonymous {
+		return p.Kind + ":" + p.Subject
+	}
+	if l.cfg.TrustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			first, _, _ := strings.Cut(fwd, ",")
+			return "ip:" + strings.TrimSpace(first)
+		}
+	}
+	return "ip:" + hostOnly(r.RemoteAddr)
+}
+
+func hostOnly(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// Limit wraps h with the limiter. Every response carries RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset; denials are 429 with Retry-After.
+// Place it inside auth.Require so keys are limited by ID.
+func (l *Limiter) Limit(h http.Handler) http.Handler {
+	if !l.Enabled() {
+		return h
+	}
+	policy := l.Policy()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := l.ClientID(r)
+		d := l.Allow(r.Context(), client)
+		hdr := w.Header()
+		hdr.Set("RateLimit-Policy", policy)
+		hdr.Set("RateLimit-Limit", strconv.FormatInt(d.Limit, 10))
+		hdr.Set("RateLimit-Remaining", strconv.FormatInt(d.Remaining, 10))
+		hdr.Set("RateLimit-Reset", strconv.Itoa(seconds(d.Reset)))
+		if !d.Allowed {
+			slog.InfoContext(r.Context(), "request throttled", "method", r.Method, "path", r.URL.Path, "client", client)
+			hdr.Set("Retry-After", strconv.Itoa(seconds(d.RetryAfter)))
+			problem.Write(w, r, problem.RateLimited, "rate limit exceeded")
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// seconds rounds d up to whole seconds, as the headers require.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// ── gRPC ──
+
+func (l *Limiter) grpcClient(ctx context.Context) string {
+	if p, ok := auth.FromContext(ctx); ok && p.Kind != auth.KindAnonymous {
+		return p.Kind + ":" + p.Subject
+	}
+	if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
+		return "ip:" + hostOnly(pr.Addr.String())
+	}
+	return "ip:unknown"
+}
+
+func (l *Limiter) allowGRPC(ctx context.Context) error {
+	if d := l.Allow(ctx, l.grpcClient(ctx)); !d.Allowed {
+		return problem.Errorf(problem.RateLimited, "rate limit exceeded, retry in %ds", seconds(d.RetryAfter))
+	}
+	return nil
+}
+
+// ServerOptions limits unary calls, stream openings and every message a
+// client streams in. Install them after the auth interceptors so keys are
+// limited by ID.
+func (l *Limiter) ServerOptions() []grpc.ServerOption {
+	if !l.Enabled() {
+		return nil
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := l.allowGRPC(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(l.limitStream),
+	}
+}
+
+func (l *Limiter) limitStream(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := l.allowGRPC(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, &limitedStream{ServerStream: ss, l: l})
+}
+
+// limitedStream charges each received message like a request, so a
+// client-streaming call cannot send past the limit once it is open.
+type limitedStream struct {
+	grpc.ServerStream
+	l *Limiter
+}
+
+func (s *limitedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.l.allowGRPC(s.Context())
+}
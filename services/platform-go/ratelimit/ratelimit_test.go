@@ -0,0 +1,210 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"navifly/platform/auth"
+)
+
+func fixedClock(l *Limiter, start time.Time) *time.Time {
+	now := start
+	l.now = func() time.Time { return now }
+	return &now
+}
+
+func TestAllow_TokenBucket(t *testing.T) {
+	l := New(Config{Rate: 2, Burst: 3}, nil)
+	now := fixedClock(l, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	for i := 2; i >= 0; i-- {
+		d := l.Allow(context.Background(), "ip:1.2.3.4")
+		assert.True(t, d.Allowed)
+		assert.Equal(t, int64(i), d.Remaining)
+	}
+	d := l.Allow(context.Background(), "ip:1.2.3.4")
+	assert.False(t, d.Allowed)
+	assert.Equal(t, 500*time.Millisecond, d.RetryAfter)
+
+	// Other clients have their own bucket
+	assert.True(t, l.Allow(context.Background(), "ip:5.6.7.8").Allowed)
+
+	*now = now.Add(500 * time.Millisecond)
+	assert.True(t, l.Allow(context.Background(), "ip:1.2.3.4").Allowed)
+	assert.False(t, l.Allow(context.Background(), "ip:1.2.3.4").Allowed)
+
+	// Idle buckets refill to the burst, not beyond
+	*now = now.Add(time.Hour)
+	assert.Equal(t, int64(2), l.Allow(context.Background(), "ip:1.2.3.4").Remaining)
+}
+
+func TestAllow_DailyQuotaInRedis(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	quotas := &RedisQuotas{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+
+	l := New(Config{DailyQuota: 2}, quotas)
+	now := fixedClock(l, time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC))
+
+	d := l.Allow(context.Background(), "api_key:fleet")
+	assert.True(t, d.Allowed)
+	assert.Equal(t, int64(1), d.Remaining)
+	assert.Equal(t, time.Hour, d.Reset)
+	assert.True(t, l.Allow(context.Background(), "api_key:fleet").Allowed)
+	d = l.Allow(context.Background(), "api_key:fleet")
+	assert.False(t, d.Allowed)
+	assert.Equal(t, time.Hour, d.RetryAfter)
+
+	assert.Equal(t, "3", mustGet(t, mr, "quota:api_key:fleet:2026-03-01"))
+	assert.True(t, mr.TTL("quota:api_key:fleet:2026-03-01") > 24*time.Hour)
+
+	// Scoped limiters count separately on the same store
+	scoped := New(Config{DailyQuota: 2, Scope: "upstream"}, quotas)
+	fixedClock(scoped, *now)
+	assert.True(t, scoped.Allow(context.Background(), "api_key:fleet").Allowed)
+	assert.Equal(t, "1", mustGet(t, mr, "quota:upstream:api_key:fleet:2026-03-01"))
+
+	// A new UTC day starts a new counter
+	*now = now.Add(time.Hour)
+	assert.True(t, l.Allow(context.Background(), "api_key:fleet").Allowed)
+
+	// Redis outages fail open
+	mr.Close()
+	assert.True(t, l.Allow(context.Background(), "api_key:fleet").Allowed)
+}
+
+func TestRedisQuotas_ExpireSetWithCount(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	q := &RedisQuotas{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+
+	// A counter left without an expiry still gets one on the next request
+	mr.Set("quota:ip:1.2.3.4:2026-03-01", "5")
+	n, err := q.Incr(context.Background(), "quota:ip:1.2.3.4:2026-03-01", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), n)
+	assert.Equal(t, time.Hour, mr.TTL("quota:ip:1.2.3.4:2026-03-01"))
+
+	// Later requests keep the first expiry
+	mr.FastForward(time.Minute)
+	_, err = q.Incr(context.Background(), "quota:ip:1.2.3.4:2026-03-01", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 59*time.Minute, mr.TTL("quota:ip:1.2.3.4:2026-03-01"))
+}
+
+func mustGet(t *testing.T, mr *miniredis.Miniredis, key string) string {
+	v, err := mr.Get(key)
+	require.NoError(t, err)
+	return v
+}
+
+func TestMemoryQuotas_Expire(t *testing.T) {
+	q := NewMemoryQuotas()
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	q.now = func() time.Time { return now }
+
+	n, _ := q.Incr(context.Background(), "k", time.Minute)
+	assert.Equal(t, int64(1), n)
+	n, _ = q.Incr(context.Background(), "k", time.Minute)
+	assert.Equal(t, int64(2), n)
+	now = now.Add(2 * time.Minute)
+	n, _ = q.Incr(context.Background(), "k", time.Minute)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestLimit_HeadersAnd429(t *testing.T) {
+	l := New(Config{Rate: 1, Burst: 1, DailyQuota: 100}, NewMemoryQuotas())
+	fixedClock(l, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	h := l.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }))
+
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, r)
+		return rr
+	}
+	req := httptest.NewRequest("GET", "/route", nil)
+	req.RemoteAddr = "10.0.0.1:5555"
+
+	rr := serve(req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1;w=1, 100;w=86400", rr.Header().Get("RateLimit-Policy"))
+
+	rr = serve(req)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Reset"))
+	assert.Contains(t, rr.Body.String(), `"code":"RATE_LIMITED"`)
+
+	// Authenticated callers are limited by key, not by the shared IP
+	keyed := req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: "truck-17", Kind: auth.KindAPIKey}))
+	assert.Equal(t, http.StatusOK, serve(keyed).Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(keyed).Code)
+}
+
+func TestClientID_TrustProxy(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.1:5555"
+	req.Header.Set("X-Forwarded-For", "203.0.113.9, 10.0.0.1")
+
+	assert.Equal(t, "ip:10.0.0.1", New(Config{Rate: 1}, nil).ClientID(req))
+	assert.Equal(t, "ip:203.0.113.9", New(Config{Rate: 1, TrustProxy: true}, nil).ClientID(req))
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("RATE_LIMIT_RPS", "5")
+	t.Setenv("DAILY_QUOTA", "1000")
+	cfg, err := ConfigFromEnv(Config{Rate: 1, Burst: 10})
+	require.NoError(t, err)
+	assert.Equal(t, Config{Rate: 5, Burst: 10, DailyQuota: 1000}, cfg)
+
+	t.Setenv("RATE_LIMIT_BURST", "0")
+	_, err = ConfigFromEnv(Config{})
+	assert.Error(t, err)
+}
+
+func TestLimit_DisabledPassesThrough(t *testing.T) {
+	l := New(Config{}, nil)
+	assert.False(t, l.Enabled())
+	rr := httptest.NewRecorder()
+	l.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	assert.Empty(t, rr.Header().Get("RateLimit-Limit"))
+}
+
+// recvStream delivers an endless stream of empty messages.
+type recvStream struct {
+	grpc.ServerStream
+}
+
+func (recvStream) Context() context.Context  { return context.Background() }
+func (recvStream) RecvMsg(interface{}) error { return nil }
+
+func TestLimitStream_ChargesEachMessage(t *testing.T) {
+	l := New(Config{Rate: 1, Burst: 3}, nil)
+	fixedClock(l, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	received := 0
+	err := l.limitStream(nil, recvStream{}, nil, func(_ interface{}, ss grpc.ServerStream) error {
+		for {
+			if err := ss.RecvMsg(nil); err != nil {
+				return err
+			}
+			received++
+		}
+	})
+	// Opening the stream takes one token and each message another
+	assert.Equal(t, 2, received)
+	assert.Contains(t, err.Error(), "rate limit exceeded")
+}
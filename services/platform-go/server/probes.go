@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Probes answers the liveness and readiness probes. Liveness only says the
+// process is serving; readiness runs the registered dependency checks and
+// fails once shutdown starts, so load balancers stop sending traffic first.
+type Probes struct {
+	// Timeout bounds all checks of one readiness probe.
+	Timeout time.Duration
+
+	mu       sync.Mutex
+	checks   []check
+	draining atomic.Bool
+}
+
+type check struct {
+	name string
+	fn   func(context.Context) error
+}
+
+// NewProbes returns probes with no checks and a 2 s readiness timeout.
+func NewProbes() *Probes {
+	return &Probes{Timeout: 2 * time.Second}
+}
+
+// Check adds a dependency that must be reachable for the service to be
+// ready.
+func (p *Probes) Check(name string, fn func(context.Context) error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checks = append(p.checks, check{name, fn})
+}
+
+// Drain makes readiness fail from now on.
+func (p *Probes) Drain() {
+	p.draining.Store(true)
+}
+
+// Register adds GET /livez and GET /readyz to r.
+func (p *Probes) Register(r *mux.Router) {
+	r.HandleFunc("/livez", p.Livez).Methods("GET")
+	r.HandleFunc("/readyz", p.Readyz).Methods("GET")
+}
+
+// ProbeReport is the body of /livez and /readyz.
+type ProbeReport struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+func (p *Probes) Livez(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, http.StatusOK, ProbeReport{Status: "ok"})
+}
+
+func (p *Probes) Readyz(w http.ResponseWriter, r *http.Request) {
+	if p.draining.Load() {
+		writeReport(w, http.StatusServiceUnavailable, ProbeReport{Status: "draining"})
+		return
+	}
+
+	p.mu.Lock()
+	checks := append([]check(nil), p.checks...)
+	p.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(r.Context(), p.Timeout)
+	defer cancel()
+	report := ProbeReport{Status: "ok", Checks: make(map[string]string, len(checks))}
+	results := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.fn(ctx)
+		}()
+	}
+	wg.Wait()
+
+	code := http.StatusOK
+	for i, c := range checks {
+		if err := results[i]; err != nil {
+			report.Status, report.Checks[c.name] = "unavailable", err.Error()
+			code = http.StatusServiceUnavailable
+			continue
+		}
+		report.Checks[c.name] = "ok"
+	}
+	writeReport(w, code, report)
+}
+
+func writeReport(w http.ResponseWriter, code int, report ProbeReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,144 @@
+// Package server runs a service's HTTP and gRPC listeners and shuts them
+// down gracefully: on SIGTERM readiness fails, in-flight requests drain and
+// background jobs checkpoint before the process exits.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"google.golang.org/grpc"
+
+	"navifly/platform/config"
+	"navifly/platform/logging"
+)
+
+// Server runs one service until its context ends.
+type Server struct {
+	HTTP *http.Server
+	// GRPC is served on the configured gRPC port when set.
+	GRPC *grpc.Server
+	// Probes, when set, start failing readiness as shutdown begins.
+	Probes *Probes
+
+	cfg   config.Server
+	hooks []func(context.Context) error
+}
+
+// New returns a Server for h with cfg's port and timeouts.
+func New(cfg config.Server, h http.Handler) *Server {
+	return &Server{
+		HTTP: &http.Server{
+			Addr:              ":" + strconv.Itoa(cfg.HTTPPort),
+			Handler:           h,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+		},
+		cfg: cfg,
+	}
+}
+
+// OnShutdown registers fn to run once requests have drained, e.g. to stop a
+// background job and save its progress. Hooks run in order and share the
+// shutdown deadline.
+func (s *Server) OnShutdown(fn func(context.Context) error) {
+	s.hooks = append(s.hooks, fn)
+}
+
+// SignalContext is cancelled by SIGINT or SIGTERM.
+func SignalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// Run listens on the configured ports and serves until ctx ends, then shuts
+// down gracefully.
+func (s *Server) Run(ctx context.Context) error {
+	httpLis, err := net.Listen("tcp", s.HTTP.Addr)
+	if err != nil {
+		return fmt.Errorf("listen for HTTP: %w", err)
+	}
+	var grpcLis net.Listener
+	if s.GRPC != nil {
+		if grpcLis, err = net.Listen("tcp", ":"+strconv.Itoa(s.cfg.GRPCPort)); err != nil {
+			httpLis.Close()
+			return fmt.Errorf("listen for gRPC: %w", err)
+		}
+	}
+	return s.Serve(ctx, httpLis, grpcLis)
+}
+
+// Serve is Run on existing listeners; grpcLis is unused without GRPC.
+func (s *Server) Serve(ctx context.Context, httpLis, grpcLis net.Listener) error {
+	errc := make(chan error, 2)
+	go func() {
+		slog.Info("HTTP server starting", "addr", httpLis.Addr().String())
+		if err := s.HTTP.Serve(httpLis); !errors.Is(err, http.ErrServerClosed) {
+			errc <- fmt.Errorf("HTTP server: %w", err)
+		}
+	}()
+	if s.GRPC != nil {
+		go func() {
+			slog.Info("gRPC server starting", "addr", grpcLis.Addr().String())
+			if err := s.GRPC.Serve(grpcLis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+				errc <- fmt.Errorf("gRPC server: %w", err)
+			}
+		}()
+	}
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+		slog.Info("shutting down", "timeout", s.cfg.ShutdownTimeout.String())
+	case serveErr = <-errc:
+		slog.Error("server failed, shutting down", logging.Err(serveErr))
+	}
+	return errors.Join(serveErr, s.shutdown())
+}
+
+// shutdown fails readiness, drains HTTP and gRPC and then runs the hooks,
+// all within the shutdown timeout.
+func (s *Server) shutdown() error {
+	if s.Probes != nil {
+		s.Probes.Drain()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	if err := s.HTTP.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("drain HTTP: %w", err))
+	}
+	if s.GRPC != nil {
+		stopped := make(chan struct{})
+		go func() {
+			s.GRPC.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			// Long-lived streams never finish on their own
+			s.GRPC.Stop()
+		}
+	}
+	for _, hook := range s.hooks {
+		if err := hook(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	slog.Info("shutdown complete")
+	return nil
+}
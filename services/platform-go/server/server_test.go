@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"navifly/platform/config"
+)
+
+func TestProbes(t *testing.T) {
+	p := NewProbes()
+	var dbErr error
+	p.Check("db", func(context.Context) error { return dbErr })
+	r := mux.NewRouter()
+	p.Register(r)
+
+	get := func(path string) (int, ProbeReport) {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		var report ProbeReport
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+		return rr.Code, report
+	}
+
+	code, report := get("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]string{"db": "ok"}, report.Checks)
+
+	dbErr = errors.New("connection refused")
+	code, report = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "unavailable", report.Status)
+	assert.Equal(t, "connection refused", report.Checks["db"])
+
+	code, _ = get("/livez")
+	assert.Equal(t, http.StatusOK, code, "liveness ignores dependencies")
+
+	p.Drain()
+	dbErr = nil
+	code, report = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "draining", report.Status)
+}
+
+func TestServer_DrainsRequestsAndRunsHooks(t *testing.T) {
+	started := make(chan struct{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		io.WriteString(w, "done")
+	})
+	srv := New(config.DefaultServer(8080, 0), h)
+	srv.Probes = NewProbes()
+	var hookRan bool
+	srv.OnShutdown(func(ctx context.Context) error {
+		hookRan = true
+		return ctx.Err()
+	})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ctx, lis, nil) }()
+
+	// A request in flight when shutdown starts still completes
+	body := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + lis.Addr().String())
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		body <- string(b)
+	}()
+	<-started
+	cancel()
+
+	assert.Equal(t, "done", <-body)
+	require.NoError(t, <-served)
+	assert.True(t, hookRan)
+	assert.True(t, srv.Probes.draining.Load())
+}
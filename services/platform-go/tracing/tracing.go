@@ -0,0 +1,153 @@
+// Package tracing sets up OpenTelemetry for NaviFly services: the exporter,
+// W3C trace context propagation, and spans for HTTP servers and clients,
+// gRPC and Redis.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.38.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// Exporters accepted in OTEL_TRACES_EXPORTER.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterOTLP   = "otlp"
+)
+
+// Config selects where spans go.
+type Config struct {
+	// Exporter is none, stdout, file or otlp. With none, incoming trace
+	// context is still passed on to downstream calls.
+	Exporter string
+	// File is the JSON-lines output for the file exporter.
+	File string
+	// SampleRatio is the fraction of new traces recorded; requests that
+	// arrive sampled stay sampled.
+	SampleRatio float64
+}
+
+// ConfigFromEnv reads OTEL_TRACES_EXPORTER (default none; "console" is an
+// alias for stdout), OTEL_TRACES_FILE and OTEL_TRACES_SAMPLER_ARG. The OTLP
+// exporter reads the standard OTEL_EXPORTER_OTLP_* variables itself.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{Exporter: strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")), File: os.Getenv("OTEL_TRACES_FILE"), SampleRatio: 1}
+	switch cfg.Exporter {
+	case "":
+		cfg.Exporter = ExporterNone
+	case "console":
+		cfg.Exporter = ExporterStdout
+	case ExporterNone, ExporterStdout, ExporterOTLP:
+	case ExporterFile:
+		if cfg.File == "" {
+			return cfg, fmt.Errorf("OTEL_TRACES_EXPORTER=file needs OTEL_TRACES_FILE")
+		}
+	default:
+		return cfg, fmt.Errorf("unknown OTEL_TRACES_EXPORTER %q (want none, stdout, file or otlp)", cfg.Exporter)
+	}
+	if v := os.Getenv("OTEL_TRACES_SAMPLER_ARG"); v != "" {
+		ratio, err := strconv.ParseFloat(v, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return cfg, fmt.Errorf("OTEL_TRACES_SAMPLER_ARG must be between 0 and 1")
+		}
+		cfg.SampleRatio = ratio
+	}
+	return cfg, nil
+}
+
+// Setup installs the global tracer provider and propagator for service. The
+// returned shutdown flushes pending spans.
+func Setup(ctx context.Context, service string, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if cfg.Exporter == ExporterNone || cfg.Exporter == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var (
+		exp    sdktrace.SpanExporter
+		closer io.Closer
+		err    error
+	)
+	switch cfg.Exporter {
+	case ExporterStdout:
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterFile:
+		var f *os.File
+		if f, err = os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644); err != nil {
+			return nil, fmt.Errorf("open trace file: %w", err)
+		}
+		closer = f
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	case ExporterOTLP:
+		exp, err = otlptracehttp.New(ctx)
+	default:
+		err = fmt.Errorf("unknown exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(service)))
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closer != nil {
+			closer.Close()
+		}
+		return err
+	}, nil
+}
+
+// Tracer returns a named tracer from the global provider.
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer(name)
+}
+
+// Middleware starts a server span per request, named after the method and
+// matched mux route ("GET /vehicle/{id}"), continuing any incoming
+// traceparent.
+func Middleware(service string) func(http.Handler) http.Handler {
+	return otelmux.Middleware(service)
+}
+
+// Transport wraps base (http.DefaultTransport when nil) so outbound requests
+// get client spans and a traceparent header.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base)
+}
+
+// ServerOptions traces gRPC calls.
+func ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{grpc.StatsHandler(otelgrpc.NewServerHandler())}
+}
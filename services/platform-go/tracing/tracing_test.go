@@ -0,0 +1,125 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans installs an in-memory tracer provider for one test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	_, err := Setup(context.Background(), "test", Config{Exporter: ExporterNone})
+	require.NoError(t, err)
+	return rec
+}
+
+func TestMiddlewareAndTransport_PropagateTraceContext(t *testing.T) {
+	rec := recordSpans(t)
+
+	var upstreamParent string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamParent = r.Header.Get("traceparent")
+	}))
+	defer upstream.Close()
+	client := &http.Client{Transport: Transport(nil)}
+
+	r := mux.NewRouter()
+	r.Use(Middleware("routing"))
+	r.HandleFunc("/route/{id}", func(w http.ResponseWriter, r *http.Request) {
+		req, _ := http.NewRequestWithContext(r.Context(), "GET", upstream.URL, nil)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	})
+
+	req := httptest.NewRequest("GET", "/route/7", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	client0, server := spans[0], spans[1]
+	assert.Equal(t, "GET /route/{id}", server.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Equal(t, server.SpanContext().SpanID(), client0.Parent().SpanID())
+	assert.Contains(t, upstreamParent, "4bf92f3577b34da6a3ce929d0e0e4736-"+client0.SpanContext().SpanID().String())
+}
+
+func TestRedisHook_Spans(t *testing.T) {
+	rec := recordSpans(t)
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	rdb.AddHook(RedisHook{})
+	ctx := context.Background()
+
+	rdb.Set(ctx, "k", "v", 0)
+	rdb.Get(ctx, "missing")
+	rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Incr(ctx, "n")
+		p.Expire(ctx, "n", 0)
+		return nil
+	})
+
+	spans := rec.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "redis set", spans[0].Name())
+	assert.Equal(t, "redis get", spans[1].Name())
+	assert.Equal(t, "Unset", spans[1].Status().Code.String(), "redis.Nil is not an error")
+	assert.Equal(t, "redis pipeline", spans[2].Name())
+}
+
+func TestSetup_FileExporter(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(prev)
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+
+	shutdown, err := Setup(context.Background(), "telemetry", Config{Exporter: ExporterFile, File: path, SampleRatio: 1})
+	require.NoError(t, err)
+	_, span := Tracer("test").Start(context.Background(), "ingest")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"Name":"ingest"`)
+	assert.Contains(t, string(data), `"telemetry"`)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	cfg, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, ExporterNone, cfg.Exporter)
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "console")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.25")
+	cfg, err = ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Config{Exporter: ExporterStdout, SampleRatio: 0.25}, cfg)
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "file")
+	_, err = ConfigFromEnv()
+	assert.Error(t, err, "file exporter without OTEL_TRACES_FILE")
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "jaeger")
+	_, err = ConfigFromEnv()
+	assert.Error(t, err)
+}
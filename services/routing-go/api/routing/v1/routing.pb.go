@@ -0,0 +1,1986 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: api/routing/v1/routing.proto
+
+package routingv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GeometryFormat int32
+
+const (
+	GeometryFormat_GEOMETRY_FORMAT_UNSPECIFIED GeometryFormat = 0 // same as coordinates
+	GeometryFormat_GEOMETRY_FORMAT_COORDINATES GeometryFormat = 1
+	GeometryFormat_GEOMETRY_FORMAT_POLYLINE5   GeometryFormat = 2
+	GeometryFormat_GEOMETRY_FORMAT_POLYLINE6   GeometryFormat = 3
+)
+
+// Enum value maps for GeometryFormat.
+var (
+	GeometryFormat_name = map[int32]string{
+		0: "GEOMETRY_FORMAT_UNSPECIFIED",
+		1: "GEOMETRY_FORMAT_COORDINATES",
+		2: "GEOMETRY_FORMAT_POLYLINE5",
+		3: "GEOMETRY_FORMAT_POLYLINE6",
+	}
+	GeometryFormat_value = map[string]int32{
+		"GEOMETRY_FORMAT_UNSPECIFIED": 0,
+		"GEOMETRY_FORMAT_COORDINATES": 1,
+		"GEOMETRY_FORMAT_POLYLINE5":   2,
+		"GEOMETRY_FORMAT_POLYLINE6":   3,
+	}
+)
+
+func (x GeometryFormat) Enum() *GeometryFormat {
+	p := new(GeometryFormat)
+	*p = x
+	return p
+}
+
+func (x GeometryFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GeometryFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_routing_v1_routing_proto_enumTypes[0].Descriptor()
+}
+
+func (GeometryFormat) Type() protoreflect.EnumType {
+	return &file_api_routing_v1_routing_proto_enumTypes[0]
+}
+
+func (x GeometryFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GeometryFormat.Descriptor instead.
+func (GeometryFormat) EnumDescriptor() ([]byte, []int) {
+	return file_api_routing_v1_routing_proto_rawDescGZIP(), []int{0}
+}
+
+type RouteRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// start, end and stops are location IDs from GET /locations.
+	Start string   `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	End   string   `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
+	Stops []string `protobuf:"bytes,3,rep,name=stops,proto3" json:"stops,omitempty"`
+	// vehicle is car (default), truck or motorcycle.
+	Vehicle string `protobuf:"bytes,4,opt,name=vehicle,proto3" json:"vehicle,omitempty"`
+	// Set at most one of depart_at and arrive_by.
+	DepartAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=depart_at,json=departAt,proto3" json:"depart_at,omitempty"`
+	ArriveBy *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=arrive_by,json=arriveBy,proto3" json:"arrive_by,omitempty"`
+	Geometry GeometryFormat         `protobuf:"varint,7,opt,name=geometry,proto3,enum=navifly.routing.v1.GeometryFormat" json:"geometry,omitempty"`
+	// simplify_m is the Douglas-Peucker tolerance in metres.
+	SimplifyM float64 `protobuf:"fixed64,8,opt,name=simplify_m,json=simplifyM,proto3" json:"simplify_m,omitempty"`
+	// max_grade_pct drops alternatives with a steeper climb or descent.
+	MaxGradePct float64 `protobuf:"fixed64,9,opt,name=max_grade_pct,json=maxGradePct,proto3" json:"max_grade_pct,omitempty"`
+	// ev is car, van or truck and enables energy and charging planning. The
+	// remaining fields override the preset and planner defaults when set.
+	Ev            string  `protobuf:"bytes,10,opt,name=ev,proto3" json:"ev,omitempty"`
+	BatteryKwh    float64 `protobuf:"fixed64,11,opt,name=battery_kwh,json=batteryKwh,proto3" json:"battery_kwh,omitempty"`
+	SocPct        float64 `protobuf:"fixed64,12,opt,name=soc_pct,json=socPct,proto3" json:"soc_pct,omitempty"`
+	ReserveSocPct float64 `protobuf:"fixed64,13,opt,name=reserve_soc_pct,json=reserveSocPct,proto3" json:"reserve_soc_pct,omitempty"`
+	ChargeToPct   float64 `protobuf:"fixed64,14,opt,name=charge_to_pct,json=chargeToPct,proto3" json:"charge_to_pct,omitempty"`
+	// Truck dimensions in metres and tonnes; they need vehicle=truck and
+	// default to a US legal tractor-trailer.
+	HeightM float64 `protobuf:"fixed64,15,opt,name=height_m,json=heightM,proto3" json:"height_m,omitempty"`
+	WeightT float64 `protobuf:"fixed64,16,opt,name=weight_t,json=weightT,proto3" json:"weight_t,omitempty"`
+	LengthM float64 `protobuf:"fixed64,17,opt,name=length_m,json=lengthM,proto3" json:"length_m,omitempty"`
+	Hazmat  bool    `protobuf:"varint,18,opt,name=hazmat,proto3" json:"hazmat,omitempty"`
+	// avoid_geojson is a Polygon, MultiPolygon, Feature or FeatureCollection
+	// to route around; avoid_fences are mapmatch geofence IDs.
+	AvoidGeojson string   `protobuf:"bytes,19,opt,name=avoid_geojson,json=avoidGeojson,proto3" json:"avoid_geojson,omitempty"`
+	AvoidFences  []string `protobuf:"bytes,20,rep,name=avoid_fences,json=avoidFences,proto3" json:"avoid_fences,omitempty"`
+	// on_unknown is fail (default), rejecting unknown stops, or skip, which
+	// routes without them and lists them in skipped_stops.
+	OnUnknown     string `protobuf:"bytes,21,opt,name=on_unknown,json=onUnknown,proto3" json:"on_unknown,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RouteRequest) Reset() {
+	*x = RouteRequest{}
+	mi := &file_api_routing_v1_routing_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RouteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RouteRequest) ProtoMessage() {}
+
+func (x *RouteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_routing_v1_routing_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RouteRequest.ProtoReflect.Descriptor instead.
+func (*RouteRequest) Descriptor() ([]byte, []int) {
+	return file_api_routing_v1_routing_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RouteRequest) GetStart() string {
+	if x != nil {
+		return x.Start
+	}
+	return ""
+}
+
+func (x *RouteRequest) GetEnd() string {
+	if x != nil {
+		return x.End
+	}
+	return ""
+}
+
+func (x *RouteRequest) GetStops() []string {
+	if x != nil {
+		return x.Stops
+	}
+	return nil
+}
+
+func (x *RouteRequest) GetVehicle() string {
+	if x != nil {
+		return x.Vehicle
+	}
+	return ""
+}
+
+func (x *RouteRequest) GetDepartAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DepartAt
+	}
+	return nil
+}
+
+func (x *RouteRequest) GetArriveBy() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ArriveBy
+	}
+	return nil
+}
+
+func (x *RouteRequest) GetGeometry() GeometryFormat {
+	if x != nil {
+		return x.Geometry
+	}
+	return GeometryFormat_GEOMETRY_FORMAT_UNSPECIFIED
+}
+
+func (x *RouteRequest) GetSimplifyM() float64 {
+	if x != nil {
+		return x.SimplifyM
+	}
+	return 0
+}
+
+func (x *RouteRequest) GetMaxGradePct() float64 {
+	if x != nil {
+		return x.MaxGradePct
+	}
+	return 0
+}
+
+func (x *RouteRequest) GetEv() string {
+	if x != nil {
+		return x.Ev
+	}
+	return ""
+}
+
+func (x *RouteRequest) GetBatteryKwh() float64 {
+	if x != nil {
+		return x.BatteryKwh
+	}
+	return 0
+}
+
+func (x *RouteRequest) GetSocPct() float64 {
+	if x != nil {
+		return x.SocPct
+	}
+	return 0
+}
+
+func (x *RouteRequest) GetReserveSocPct() float64 {
+	if x != nil {
+		return x.ReserveSocPct
+	}
+	return 0
+}
+
+func (x *RouteRequest) GetChargeToPct() float64 {
+	if x != nil {
+		return x.ChargeToPct
+	}
+	return 0
+}
+
+func (x *RouteRequest) GetHeightM() float64 {
+	if x != nil {
+		return x.HeightM
+	}
+	return 0
+}
+
+func (x *RouteRequest) GetWeightT() float64 {
+	if x != nil {
+		return x.WeightT
+	}
+	return 0
+}
+
+func (x *RouteRequest) GetLengthM() float64 {
+	if x != nil {
+		return x.LengthM
+	}
+	return 0
+}
+
+func (x *RouteRequest) GetHazmat() bool {
+	if x != nil {
+		return x.Hazmat
+	}
+	return false
+}
+
+func (x *RouteRequest) GetAvoidGeojson() string {
+	if x != nil {
+		return x.AvoidGeojson
+	}
+	return ""
+}
+
+func (x *RouteRequest) GetAvoidFences() []string {
+	if x != nil {
+		return x.AvoidFences
+	}
+	return nil
+}
+
+func (x *RouteRequest) GetOnUnknown() string {
+	if x != nil {
+		return x.OnUnknown
+	}
+	return ""
+}
+
+type RouteResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Routes []*Route               `protobuf:"bytes,1,rep,name=routes,proto3" json:"routes,omitempty"`
+	// restrictions is set when truck restrictions or avoid areas forced a
+	// detour.
+	Restrictions *RestrictionReport `protobuf:"bytes,2,opt,name=restrictions,proto3" json:"restrictions,omitempty"`
+	// skipped_stops are the unknown stops left out with on_unknown=skip.
+	SkippedStops  []string `protobuf:"bytes,3,rep,name=skipped_stops,json=skippedStops,proto3" json:"skipped_stops,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RouteResponse) Reset() {
+	*x = RouteResponse{}
+	mi := &file_api_routing_v1_routing_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RouteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RouteResponse) ProtoMessage() {}
+
+func (x *RouteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_routing_v1_routing_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RouteResponse.ProtoReflect.Descriptor instead.
+func (*RouteResponse) Descriptor() ([]byte, []int) {
+	return file_api_routing_v1_routing_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RouteResponse) GetRoutes() []*Route {
+	if x != nil {
+		return x.Routes
+	}
+	return nil
+}
+
+func (x *RouteResponse) GetRestrictions() *RestrictionReport {
+	if x != nil {
+		return x.Restrictions
+	}
+	return nil
+}
+
+func (x *RouteResponse) GetSkippedStops() []string {
+	if x != nil {
+		return x.SkippedStops
+	}
+	return nil
+}
+
+type Route struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Label     string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	DistanceM float64                `protobuf:"fixed64,2,opt,name=distance_m,json=distanceM,proto3" json:"distance_m,omitempty"`
+	// duration_s is the traffic-adjusted ETA.
+	DurationS         float64                `protobuf:"fixed64,3,opt,name=duration_s,json=durationS,proto3" json:"duration_s,omitempty"`
+	FreeFlowDurationS float64                `protobuf:"fixed64,4,opt,name=free_flow_duration_s,json=freeFlowDurationS,proto3" json:"free_flow_duration_s,omitempty"`
+	Geometry          *LineGeometry          `protobuf:"bytes,5,opt,name=geometry,proto3" json:"geometry,omitempty"`
+	Segments          []*TrafficSegment      `protobuf:"bytes,6,rep,name=segments,proto3" json:"segments,omitempty"`
+	Instructions      []*TurnInstruction     `protobuf:"bytes,7,rep,name=instructions,proto3" json:"instructions,omitempty"`
+	DepartAt          *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=depart_at,json=departAt,proto3" json:"depart_at,omitempty"`
+	ArriveAt          *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=arrive_at,json=arriveAt,proto3" json:"arrive_at,omitempty"`
+	Eta               *EtaBreakdown          `protobuf:"bytes,10,opt,name=eta,proto3" json:"eta,omitempty"`
+	// elevation is unset when no DEM tiles cover the route.
+	Elevation *ElevationProfile `protobuf:"bytes,11,opt,name=elevation,proto3" json:"elevation,omitempty"`
+	// energy is set when the request names an EV.
+	Energy        *EnergyReport `protobuf:"bytes,12,opt,name=energy,proto3" json:"energy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Route) Reset() {
+	*x = Route{}
+	mi := &file_api_routing_v1_routing_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Route) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Route) ProtoMessage() {}
+
+func (x *Route) ProtoReflect() protoreflect.Message {
+	mi := &file_api_routing_v1_routing_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Route.ProtoReflect.Descriptor instead.
+func (*Route) Descriptor() ([]byte, []int) {
+	return file_api_routing_v1_routing_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Route) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *Route) GetDistanceM() float64 {
+	if x != nil {
+		return x.DistanceM
+	}
+	return 0
+}
+
+func (x *Route) GetDurationS() float64 {
+	if x != nil {
+		return x.DurationS
+	}
+	return 0
+}
+
+func (x *Route) GetFreeFlowDurationS() float64 {
+	if x != nil {
+		return x.FreeFlowDurationS
+	}
+	return 0
+}
+
+func (x *Route) GetGeometry() *LineGeometry {
+	if x != nil {
+		return x.Geometry
+	}
+	return nil
+}
+
+func (x *Route) GetSegments() []*TrafficSegment {
+	if x != nil {
+		return x.Segments
+	}
+	return nil
+}
+
+func (x *Route) GetInstructions() []*TurnInstruction {
+	if x != nil {
+		return x.Instructions
+	}
+	return nil
+}
+
+func (x *Route) GetDepartAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DepartAt
+	}
+	return nil
+}
+
+func (x *Route) GetArriveAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ArriveAt
+	}
+	return nil
+}
+
+func (x *Route) GetEta() *EtaBreakdown {
+	if x != nil {
+		return x.Eta
+	}
+	return nil
+}
+
+func (x *Route) GetElevation() *ElevationProfile {
+	if x != nil {
+		return x.Elevation
+	}
+	return nil
+}
+
+func (x *Route) GetEnergy() *EnergyReport {
+	if x != nil {
+		return x.Energy
+	}
+	return nil
+}
+
+type Coordinate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lat           float64                `protobuf:"fixed64,1,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lon           float64                `protobuf:"fixed64,2,opt,name=lon,proto3" json:"lon,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Coordinate) Reset() {
+	*x = Coordinate{}
+	mi := &file_api_routing_v1_routing_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Coordinate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Coordinate) ProtoMessage() {}
+
+func (x *Coordinate) ProtoReflect() protoreflect.Message {
+	mi := &file_api_routing_v1_routing_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Coordinate.ProtoReflect.Descriptor instead.
+func (*Coordinate) Descriptor() ([]byte, []int) {
+	return file_api_routing_v1_routing_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Coordinate) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *Coordinate) GetLon() float64 {
+	if x != nil {
+		return x.Lon
+	}
+	return 0
+}
+
+// LineGeometry holds either coordinates or an encoded polyline, depending on
+// the requested GeometryFormat.
+type LineGeometry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Coordinates   []*Coordinate          `protobuf:"bytes,1,rep,name=coordinates,proto3" json:"coordinates,omitempty"`
+	Polyline      string                 `protobuf:"bytes,2,opt,name=polyline,proto3" json:"polyline,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LineGeometry) Reset() {
+	*x = LineGeometry{}
+	mi := &file_api_routing_v1_routing_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LineGeometry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LineGeometry) ProtoMessage() {}
+
+func (x *LineGeometry) ProtoReflect() protoreflect.Message {
+	mi := &file_api_routing_v1_routing_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LineGeometry.ProtoReflect.Descriptor instead.
+func (*LineGeometry) Descriptor() ([]byte, []int) {
+	return file_api_routing_v1_routing_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *LineGeometry) GetCoordinates() []*Coordinate {
+	if x != nil {
+		return x.Coordinates
+	}
+	return nil
+}
+
+func (x *LineGeometry) GetPolyline() string {
+	if x != nil {
+		return x.Polyline
+	}
+	return ""
+}
+
+type TrafficSegment struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// congestion is low, moderate, high or unknown.
+	Congestion  string        `protobuf:"bytes,1,opt,name=congestion,proto3" json:"congestion,omitempty"`
+	Geometry    *LineGeometry `protobuf:"bytes,2,opt,name=geometry,proto3" json:"geometry,omitempty"`
+	FreeFlowKmh float64       `protobuf:"fixed64,3,opt,name=free_flow_kmh,json=freeFlowKmh,proto3" json:"free_flow_kmh,omitempty"`
+	// observed_kmh and speed_ratio are zero when congestion is unknown.
+	ObservedKmh   float64 `protobuf:"fixed64,4,opt,name=observed_kmh,json=observedKmh,proto3" json:"observed_kmh,omitempty"`
+	SpeedRatio    float64 `protobuf:"fixed64,5,opt,name=speed_ratio,json=speedRatio,proto3" json:"speed_ratio,omitempty"`
+	Samples       int32   `protobuf:"varint,6,opt,name=samples,proto3" json:"samples,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrafficSegment) Reset() {
+	*x = TrafficSegment{}
+	mi := &file_api_routing_v1_routing_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrafficSegment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrafficSegment) ProtoMessage() {}
+
+func (x *TrafficSegment) ProtoReflect() protoreflect.Message {
+	mi := &file_api_routing_v1_routing_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrafficSegment.ProtoReflect.Descriptor instead.
+func (*TrafficSegment) Descriptor() ([]byte, []int) {
+	return file_api_routing_v1_routing_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TrafficSegment) GetCongestion() string {
+	if x != nil {
+		return x.Congestion
+	}
+	return ""
+}
+
+func (x *TrafficSegment) GetGeometry() *LineGeometry {
+	if x != nil {
+		return x.Geometry
+	}
+	return nil
+}
+
+func (x *TrafficSegment) GetFreeFlowKmh() float64 {
+	if x != nil {
+		return x.FreeFlowKmh
+	}
+	return 0
+}
+
+func (x *TrafficSegment) GetObservedKmh() float64 {
+	if x != nil {
+		return x.ObservedKmh
+	}
+	return 0
+}
+
+func (x *TrafficSegment) GetSpeedRatio() float64 {
+	if x != nil {
+		return x.SpeedRatio
+	}
+	return 0
+}
+
+func (x *TrafficSegment) GetSamples() int32 {
+	if x != nil {
+		return x.Samples
+	}
+	return 0
+}
+
+type TurnInstruction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	DistanceM     float64                `protobuf:"fixed64,2,opt,name=distance_m,json=distanceM,proto3" json:"distance_m,omitempty"`
+	Location      *Coordinate            `protobuf:"bytes,3,opt,name=location,proto3" json:"location,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TurnInstruction) Reset() {
+	*x = TurnInstruction{}
+	mi := &file_api_routing_v1_routing_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TurnInstruction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TurnInstruction) ProtoMessage() {}
+
+func (x *TurnInstruction) ProtoReflect() protoreflect.Message {
+	mi := &file_api_routing_v1_routing_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TurnInstruction.ProtoReflect.Descriptor instead.
+func (*TurnInstruction) Descriptor() ([]byte, []int) {
+	return file_api_routing_v1_routing_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TurnInstruction) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *TurnInstruction) GetDistanceM() float64 {
+	if x != nil {
+		return x.DistanceM
+	}
+	return 0
+}
+
+func (x *TurnInstruction) GetLocation() *Coordinate {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+type EtaBreakdown struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Vehicle            string                 `protobuf:"bytes,1,opt,name=vehicle,proto3" json:"vehicle,omitempty"`
+	DrivingS           float64                `protobuf:"fixed64,2,opt,name=driving_s,json=drivingS,proto3" json:"driving_s,omitempty"`
+	UrbanPenaltyS      float64                `protobuf:"fixed64,3,opt,name=urban_penalty_s,json=urbanPenaltyS,proto3" json:"urban_penalty_s,omitempty"`
+	IntersectionDelayS float64                `protobuf:"fixed64,4,opt,name=intersection_delay_s,json=intersectionDelayS,proto3" json:"intersection_delay_s,omitempty"`
+	Intersections      int32                  `protobuf:"varint,5,opt,name=intersections,proto3" json:"intersections,omitempty"`
+	BreakS             float64                `protobuf:"fixed64,6,opt,name=break_s,json=breakS,proto3" json:"break_s,omitempty"`
+	TotalS             float64                `protobuf:"fixed64,7,opt,name=total_s,json=totalS,proto3" json:"total_s,omitempty"`
+	UpstreamS          float64                `protobuf:"fixed64,8,opt,name=upstream_s,json=upstreamS,proto3" json:"upstream_s,omitempty"`
+	// by_class is keyed by road class: motorway, highway, arterial or local.
+	ByClass       map[string]*ClassBreakdown `protobuf:"bytes,9,rep,name=by_class,json=byClass,proto3" json:"by_class,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EtaBreakdown) Reset() {
+	*x = EtaBreakdown{}
+	mi := &file_api_routing_v1_routing_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EtaBreakdown) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EtaBreakdown) ProtoMessage() {}
+
+func (x *EtaBreakdown) ProtoReflect() protoreflect.Message {
+	mi := &file_api_routing_v1_routing_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EtaBreakdown.ProtoReflect.Descriptor instead.
+func (*EtaBreakdown) Descriptor() ([]byte, []int) {
+	return file_api_routing_v1_routing_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *EtaBreakdown) GetVehicle() string {
+	if x != nil {
+		return x.Vehicle
+	}
+	return ""
+}
+
+func (x *EtaBreakdown) GetDrivingS() float64 {
+	if x != nil {
+		return x.DrivingS
+	}
+	return 0
+}
+
+func (x *EtaBreakdown) GetUrbanPenaltyS() float64 {
+	if x != nil {
+		return x.UrbanPenaltyS
+	}
+	return 0
+}
+
+func (x *EtaBreakdown) GetIntersectionDelayS() float64 {
+	if x != nil {
+		return x.IntersectionDelayS
+	}
+	return 0
+}
+
+func (x *EtaBreakdown) GetIntersections() int32 {
+	if x != nil {
+		return x.Intersections
+	}
+	return 0
+}
+
+func (x *EtaBreakdown) GetBreakS() float64 {
+	if x != nil {
+		return x.BreakS
+	}
+	return 0
+}
+
+func (x *EtaBreakdown) GetTotalS() float64 {
+	if x != nil {
+		return x.TotalS
+	}
+	return 0
+}
+
+func (x *EtaBreakdown) GetUpstreamS() float64 {
+	if x != nil {
+		return x.UpstreamS
+	}
+	return 0
+}
+
+func (x *EtaBreakdown) GetByClass() map[string]*ClassBreakdown {
+	if x != nil {
+		return x.ByClass
+	}
+	return nil
+}
+
+type ClassBreakdown struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DistanceM     float64                `protobuf:"fixed64,1,opt,name=distance_m,json=distanceM,proto3" json:"distance_m,omitempty"`
+	DurationS     float64                `protobuf:"fixed64,2,opt,name=duration_s,json=durationS,proto3" json:"duration_s,omitempty"`
+	SpeedKmh      float64                `protobuf:"fixed64,3,opt,name=speed_kmh,json=speedKmh,proto3" json:"speed_kmh,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClassBreakdown) Reset() {
+	*x = ClassBreakdown{}
+	mi := &file_api_routing_v1_routing_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClassBreakdown) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClassBreakdown) ProtoMessage() {}
+
+func (x *ClassBreakdown) ProtoReflect() protoreflect.Message {
+	mi := &file_api_routing_v1_routing_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClassBreakdown.ProtoReflect.Descriptor instead.
+func (*ClassBreakdown) Descriptor() ([]byte, []int) {
+	return file_api_routing_v1_routing_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ClassBreakdown) GetDistanceM() float64 {
+	if x != nil {
+		return x.DistanceM
+	}
+	return 0
+}
+
+func (x *ClassBreakdown) GetDurationS() float64 {
+	if x != nil {
+		return x.DurationS
+	}
+	return 0
+}
+
+func (x *ClassBreakdown) GetSpeedKmh() float64 {
+	if x != nil {
+		return x.SpeedKmh
+	}
+	return 0
+}
+
+type ElevationProfile struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Points          []*ProfilePoint        `protobuf:"bytes,1,rep,name=points,proto3" json:"points,omitempty"`
+	AscentM         float64                `protobuf:"fixed64,2,opt,name=ascent_m,json=ascentM,proto3" json:"ascent_m,omitempty"`
+	DescentM        float64                `protobuf:"fixed64,3,opt,name=descent_m,json=descentM,proto3" json:"descent_m,omitempty"`
+	MinM            float64                `protobuf:"fixed64,4,opt,name=min_m,json=minM,proto3" json:"min_m,omitempty"`
+	MaxM            float64                `protobuf:"fixed64,5,opt,name=max_m,json=maxM,proto3" json:"max_m,omitempty"`
+	MaxGradePct     float64                `protobuf:"fixed64,6,opt,name=max_grade_pct,json=maxGradePct,proto3" json:"max_grade_pct,omitempty"`
+	MaxDowngradePct float64                `protobuf:"fixed64,7,opt,name=max_downgrade_pct,json=maxDowngradePct,proto3" json:"max_downgrade_pct,omitempty"`
+	// coverage is the share of samples with DEM data.
+	Coverage      float64 `protobuf:"fixed64,8,opt,name=coverage,proto3" json:"coverage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ElevationProfile) Reset() {
+	*x = ElevationProfile{}
+	mi := &file_api_routing_v1_routing_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ElevationProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ElevationProfile) ProtoMessage() {}
+
+func (x *ElevationProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_api_routing_v1_routing_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ElevationProfile.ProtoReflect.Descriptor instead.
+func (*ElevationProfile) Descriptor() ([]byte, []int) {
+	return file_api_routing_v1_routing_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ElevationProfile) GetPoints() []*ProfilePoint {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+func (x *ElevationProfile) GetAscentM() float64 {
+	if x != nil {
+		return x.AscentM
+	}
+	return 0
+}
+
+func (x *ElevationProfile) GetDescentM() float64 {
+	if x != nil {
+		return x.DescentM
+	}
+	return 0
+}
+
+func (x *ElevationProfile) GetMinM() float64 {
+	if x != nil {
+		return x.MinM
+	}
+	return 0
+}
+
+func (x *ElevationProfile) GetMaxM() float64 {
+	if x != nil {
+		return x.MaxM
+	}
+	return 0
+}
+
+func (x *ElevationProfile) GetMaxGradePct() float64 {
+	if x != nil {
+		return x.MaxGradePct
+	}
+	return 0
+}
+
+func (x *ElevationProfile) GetMaxDowngradePct() float64 {
+	if x != nil {
+		return x.MaxDowngradePct
+	}
+	return 0
+}
+
+func (x *ElevationProfile) GetCoverage() float64 {
+	if x != nil {
+		return x.Coverage
+	}
+	return 0
+}
+
+type ProfilePoint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DistanceM     float64                `protobuf:"fixed64,1,opt,name=distance_m,json=distanceM,proto3" json:"distance_m,omitempty"`
+	ElevationM    float64                `protobuf:"fixed64,2,opt,name=elevation_m,json=elevationM,proto3" json:"elevation_m,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProfilePoint) Reset() {
+	*x = ProfilePoint{}
+	mi := &file_api_routing_v1_routing_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProfilePoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProfilePoint) ProtoMessage() {}
+
+func (x *ProfilePoint) ProtoReflect() protoreflect.Message {
+	mi := &file_api_routing_v1_routing_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProfilePoint.ProtoReflect.Descriptor instead.
+func (*ProfilePoint) Descriptor() ([]byte, []int) {
+	return file_api_routing_v1_routing_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ProfilePoint) GetDistanceM() float64 {
+	if x != nil {
+		return x.DistanceM
+	}
+	return 0
+}
+
+func (x *ProfilePoint) GetElevationM() float64 {
+	if x != nil {
+		return x.ElevationM
+	}
+	return 0
+}
+
+type EnergyReport struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Vehicle    string                 `protobuf:"bytes,1,opt,name=vehicle,proto3" json:"vehicle,omitempty"`
+	BatteryKwh float64                `protobuf:"fixed64,2,opt,name=battery_kwh,json=batteryKwh,proto3" json:"battery_kwh,omitempty"`
+	// kwh is net of regeneration: traction - regen + aux.
+	Kwh           float64         `protobuf:"fixed64,3,opt,name=kwh,proto3" json:"kwh,omitempty"`
+	TractionKwh   float64         `protobuf:"fixed64,4,opt,name=traction_kwh,json=tractionKwh,proto3" json:"traction_kwh,omitempty"`
+	RegenKwh      float64         `protobuf:"fixed64,5,opt,name=regen_kwh,json=regenKwh,proto3" json:"regen_kwh,omitempty"`
+	AuxKwh        float64         `protobuf:"fixed64,6,opt,name=aux_kwh,json=auxKwh,proto3" json:"aux_kwh,omitempty"`
+	WhPerKm       float64         `protobuf:"fixed64,7,opt,name=wh_per_km,json=whPerKm,proto3" json:"wh_per_km,omitempty"`
+	StartSocPct   float64         `protobuf:"fixed64,8,opt,name=start_soc_pct,json=startSocPct,proto3" json:"start_soc_pct,omitempty"`
+	ArrivalSocPct float64         `protobuf:"fixed64,9,opt,name=arrival_soc_pct,json=arrivalSocPct,proto3" json:"arrival_soc_pct,omitempty"`
+	ChargingStops []*ChargingStop `protobuf:"bytes,10,rep,name=charging_stops,json=chargingStops,proto3" json:"charging_stops,omitempty"`
+	// charging_s is charging plus detour time, already in duration_s.
+	ChargingS     float64 `protobuf:"fixed64,11,opt,name=charging_s,json=chargingS,proto3" json:"charging_s,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnergyReport) Reset() {
+	*x = EnergyReport{}
+	mi := &file_api_routing_v1_routing_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnergyReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnergyReport) ProtoMessage() {}
+
+func (x *EnergyReport) ProtoReflect() protoreflect.Message {
+	mi := &file_api_routing_v1_routing_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnergyReport.ProtoReflect.Descriptor instead.
+func (*EnergyReport) Descriptor() ([]byte, []int) {
+	return file_api_routing_v1_routing_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *EnergyReport) GetVehicle() string {
+	if x != nil {
+		return x.Vehicle
+	}
+	return ""
+}
+
+func (x *EnergyReport) GetBatteryKwh() float64 {
+	if x != nil {
+		return x.BatteryKwh
+	}
+	return 0
+}
+
+func (x *EnergyReport) GetKwh() float64 {
+	if x != nil {
+		return x.Kwh
+	}
+	return 0
+}
+
+func (x *EnergyReport) GetTractionKwh() float64 {
+	if x != nil {
+		return x.TractionKwh
+	}
+	return 0
+}
+
+func (x *EnergyReport) GetRegenKwh() float64 {
+	if x != nil {
+		return x.RegenKwh
+	}
+	return 0
+}
+
+func (x *EnergyReport) GetAuxKwh() float64 {
+	if x != nil {
+		return x.AuxKwh
+	}
+	return 0
+}
+
+func (x *EnergyReport) GetWhPerKm() float64 {
+	if x != nil {
+		return x.WhPerKm
+	}
+	return 0
+}
+
+func (x *EnergyReport) GetStartSocPct() float64 {
+	if x != nil {
+		return x.StartSocPct
+	}
+	return 0
+}
+
+func (x *EnergyReport) GetArrivalSocPct() float64 {
+	if x != nil {
+		return x.ArrivalSocPct
+	}
+	return 0
+}
+
+func (x *EnergyReport) GetChargingStops() []*ChargingStop {
+	if x != nil {
+		return x.ChargingStops
+	}
+	return nil
+}
+
+func (x *EnergyReport) GetChargingS() float64 {
+	if x != nil {
+		return x.ChargingS
+	}
+	return 0
+}
+
+type ChargingStop struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ChargerId       string                 `protobuf:"bytes,1,opt,name=charger_id,json=chargerId,proto3" json:"charger_id,omitempty"`
+	Name            string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Location        *Coordinate            `protobuf:"bytes,3,opt,name=location,proto3" json:"location,omitempty"`
+	PowerKw         float64                `protobuf:"fixed64,4,opt,name=power_kw,json=powerKw,proto3" json:"power_kw,omitempty"`
+	DistanceAlongM  float64                `protobuf:"fixed64,5,opt,name=distance_along_m,json=distanceAlongM,proto3" json:"distance_along_m,omitempty"`
+	DetourM         float64                `protobuf:"fixed64,6,opt,name=detour_m,json=detourM,proto3" json:"detour_m,omitempty"`
+	ArrivalSocPct   float64                `protobuf:"fixed64,7,opt,name=arrival_soc_pct,json=arrivalSocPct,proto3" json:"arrival_soc_pct,omitempty"`
+	DepartureSocPct float64                `protobuf:"fixed64,8,opt,name=departure_soc_pct,json=departureSocPct,proto3" json:"departure_soc_pct,omitempty"`
+	ChargeKwh       float64                `protobuf:"fixed64,9,opt,name=charge_kwh,json=chargeKwh,proto3" json:"charge_kwh,omitempty"`
+	ChargeS         float64                `protobuf:"fixed64,10,opt,name=charge_s,json=chargeS,proto3" json:"charge_s,omitempty"`
+	DetourS         float64                `protobuf:"fixed64,11,opt,name=detour_s,json=detourS,proto3" json:"detour_s,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ChargingStop) Reset() {
+	*x = ChargingStop{}
+	mi := &file_api_routing_v1_routing_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChargingStop) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChargingStop) ProtoMessage() {}
+
+func (x *ChargingStop) ProtoReflect() protoreflect.Message {
+	mi := &file_api_routing_v1_routing_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChargingStop.ProtoReflect.Descriptor instead.
+func (*ChargingStop) Descriptor() ([]byte, []int) {
+	return file_api_routing_v1_routing_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ChargingStop) GetChargerId() string {
+	if x != nil {
+		return x.ChargerId
+	}
+	return ""
+}
+
+func (x *ChargingStop) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ChargingStop) GetLocation() *Coordinate {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *ChargingStop) GetPowerKw() float64 {
+	if x != nil {
+		return x.PowerKw
+	}
+	return 0
+}
+
+func (x *ChargingStop) GetDistanceAlongM() float64 {
+	if x != nil {
+		return x.DistanceAlongM
+	}
+	return 0
+}
+
+func (x *ChargingStop) GetDetourM() float64 {
+	if x != nil {
+		return x.DetourM
+	}
+	return 0
+}
+
+func (x *ChargingStop) GetArrivalSocPct() float64 {
+	if x != nil {
+		return x.ArrivalSocPct
+	}
+	return 0
+}
+
+func (x *ChargingStop) GetDepartureSocPct() float64 {
+	if x != nil {
+		return x.DepartureSocPct
+	}
+	return 0
+}
+
+func (x *ChargingStop) GetChargeKwh() float64 {
+	if x != nil {
+		return x.ChargeKwh
+	}
+	return 0
+}
+
+func (x *ChargingStop) GetChargeS() float64 {
+	if x != nil {
+		return x.ChargeS
+	}
+	return 0
+}
+
+func (x *ChargingStop) GetDetourS() float64 {
+	if x != nil {
+		return x.DetourS
+	}
+	return 0
+}
+
+type RestrictionReport struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// vehicle is set for trucks.
+	Vehicle *TruckDimensions `protobuf:"bytes,1,opt,name=vehicle,proto3" json:"vehicle,omitempty"`
+	Avoided []*AvoidedRoad   `protobuf:"bytes,2,rep,name=avoided,proto3" json:"avoided,omitempty"`
+	// via are the location IDs added as stops to go around avoided roads.
+	Via           []string `protobuf:"bytes,3,rep,name=via,proto3" json:"via,omitempty"`
+	DetourKm      float64  `protobuf:"fixed64,4,opt,name=detour_km,json=detourKm,proto3" json:"detour_km,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestrictionReport) Reset() {
+	*x = RestrictionReport{}
+	mi := &file_api_routing_v1_routing_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestrictionReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestrictionReport) ProtoMessage() {}
+
+func (x *RestrictionReport) ProtoReflect() protoreflect.Message {
+	mi := &file_api_routing_v1_routing_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestrictionReport.ProtoReflect.Descriptor instead.
+func (*RestrictionReport) Descriptor() ([]byte, []int) {
+	return file_api_routing_v1_routing_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RestrictionReport) GetVehicle() *TruckDimensions {
+	if x != nil {
+		return x.Vehicle
+	}
+	return nil
+}
+
+func (x *RestrictionReport) GetAvoided() []*AvoidedRoad {
+	if x != nil {
+		return x.Avoided
+	}
+	return nil
+}
+
+func (x *RestrictionReport) GetVia() []string {
+	if x != nil {
+		return x.Via
+	}
+	return nil
+}
+
+func (x *RestrictionReport) GetDetourKm() float64 {
+	if x != nil {
+		return x.DetourKm
+	}
+	return 0
+}
+
+type TruckDimensions struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HeightM       float64                `protobuf:"fixed64,1,opt,name=height_m,json=heightM,proto3" json:"height_m,omitempty"`
+	WeightT       float64                `protobuf:"fixed64,2,opt,name=weight_t,json=weightT,proto3" json:"weight_t,omitempty"`
+	LengthM       float64                `protobuf:"fixed64,3,opt,name=length_m,json=lengthM,proto3" json:"length_m,omitempty"`
+	Hazmat        bool                   `protobuf:"varint,4,opt,name=hazmat,proto3" json:"hazmat,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TruckDimensions) Reset() {
+	*x = TruckDimensions{}
+	mi := &file_api_routing_v1_routing_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TruckDimensions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TruckDimensions) ProtoMessage() {}
+
+func (x *TruckDimensions) ProtoReflect() protoreflect.Message {
+	mi := &file_api_routing_v1_routing_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TruckDimensions.ProtoReflect.Descriptor instead.
+func (*TruckDimensions) Descriptor() ([]byte, []int) {
+	return file_api_routing_v1_routing_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TruckDimensions) GetHeightM() float64 {
+	if x != nil {
+		return x.HeightM
+	}
+	return 0
+}
+
+func (x *TruckDimensions) GetWeightT() float64 {
+	if x != nil {
+		return x.WeightT
+	}
+	return 0
+}
+
+func (x *TruckDimensions) GetLengthM() float64 {
+	if x != nil {
+		return x.LengthM
+	}
+	return 0
+}
+
+func (x *TruckDimensions) GetHazmat() bool {
+	if x != nil {
+		return x.Hazmat
+	}
+	return false
+}
+
+type AvoidedRoad struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// edge is the corridor edge ID, e.g. "phx>tempe".
+	Edge       string                  `protobuf:"bytes,1,opt,name=edge,proto3" json:"edge,omitempty"`
+	From       string                  `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To         string                  `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Violations []*RestrictionViolation `protobuf:"bytes,4,rep,name=violations,proto3" json:"violations,omitempty"`
+	// areas are the avoid areas the road crosses.
+	Areas         []string `protobuf:"bytes,5,rep,name=areas,proto3" json:"areas,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AvoidedRoad) Reset() {
+	*x = AvoidedRoad{}
+	mi := &file_api_routing_v1_routing_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AvoidedRoad) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AvoidedRoad) ProtoMessage() {}
+
+func (x *AvoidedRoad) ProtoReflect() protoreflect.Message {
+	mi := &file_api_routing_v1_routing_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AvoidedRoad.ProtoReflect.Descriptor instead.
+func (*AvoidedRoad) Descriptor() ([]byte, []int) {
+	return file_api_routing_v1_routing_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *AvoidedRoad) GetEdge() string {
+	if x != nil {
+		return x.Edge
+	}
+	return ""
+}
+
+func (x *AvoidedRoad) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *AvoidedRoad) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *AvoidedRoad) GetViolations() []*RestrictionViolation {
+	if x != nil {
+		return x.Violations
+	}
+	return nil
+}
+
+func (x *AvoidedRoad) GetAreas() []string {
+	if x != nil {
+		return x.Areas
+	}
+	return nil
+}
+
+type RestrictionViolation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// tag is maxheight, maxweight, maxlength or hazmat.
+	Tag           string  `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Limit         float64 `protobuf:"fixed64,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Vehicle       float64 `protobuf:"fixed64,3,opt,name=vehicle,proto3" json:"vehicle,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestrictionViolation) Reset() {
+	*x = RestrictionViolation{}
+	mi := &file_api_routing_v1_routing_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestrictionViolation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestrictionViolation) ProtoMessage() {}
+
+func (x *RestrictionViolation) ProtoReflect() protoreflect.Message {
+	mi := &file_api_routing_v1_routing_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestrictionViolation.ProtoReflect.Descriptor instead.
+func (*RestrictionViolation) Descriptor() ([]byte, []int) {
+	return file_api_routing_v1_routing_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RestrictionViolation) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *RestrictionViolation) GetLimit() float64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *RestrictionViolation) GetVehicle() float64 {
+	if x != nil {
+		return x.Vehicle
+	}
+	return 0
+}
+
+var File_api_routing_v1_routing_proto protoreflect.FileDescriptor
+
+var file_api_routing_v1_routing_proto_rawDesc = string([]byte{
+	0x0a, 0x1c, 0x61, 0x70, 0x69, 0x2f, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2f, 0x76, 0x31,
+	0x2f, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x12,
+	0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e,
+	0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0xc1, 0x05, 0x0a, 0x0c, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6e,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x65, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x73, 0x74, 0x6f, 0x70, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x6f,
+	0x70, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x37, 0x0a, 0x09,
+	0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x5f, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x64, 0x65, 0x70,
+	0x61, 0x72, 0x74, 0x41, 0x74, 0x12, 0x37, 0x0a, 0x09, 0x61, 0x72, 0x72, 0x69, 0x76, 0x65, 0x5f,
+	0x62, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x61, 0x72, 0x72, 0x69, 0x76, 0x65, 0x42, 0x79, 0x12, 0x3e,
+	0x0a, 0x08, 0x67, 0x65, 0x6f, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x22, 0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69,
+	0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x6f, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x46, 0x6f,
+	0x72, 0x6d, 0x61, 0x74, 0x52, 0x08, 0x67, 0x65, 0x6f, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x12, 0x1d,
+	0x0a, 0x0a, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x69, 0x66, 0x79, 0x5f, 0x6d, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x09, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x69, 0x66, 0x79, 0x4d, 0x12, 0x22, 0x0a,
+	0x0d, 0x6d, 0x61, 0x78, 0x5f, 0x67, 0x72, 0x61, 0x64, 0x65, 0x5f, 0x70, 0x63, 0x74, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x47, 0x72, 0x61, 0x64, 0x65, 0x50, 0x63,
+	0x74, 0x12, 0x0e, 0x0a, 0x02, 0x65, 0x76, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x65,
+	0x76, 0x12, 0x1f, 0x0a, 0x0b, 0x62, 0x61, 0x74, 0x74, 0x65, 0x72, 0x79, 0x5f, 0x6b, 0x77, 0x68,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x62, 0x61, 0x74, 0x74, 0x65, 0x72, 0x79, 0x4b,
+	0x77, 0x68, 0x12, 0x17, 0x0a, 0x07, 0x73, 0x6f, 0x63, 0x5f, 0x70, 0x63, 0x74, 0x18, 0x0c, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x06, 0x73, 0x6f, 0x63, 0x50, 0x63, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x72,
+	0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x5f, 0x73, 0x6f, 0x63, 0x5f, 0x70, 0x63, 0x74, 0x18, 0x0d,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x53, 0x6f, 0x63,
+	0x50, 0x63, 0x74, 0x12, 0x22, 0x0a, 0x0d, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x5f, 0x74, 0x6f,
+	0x5f, 0x70, 0x63, 0x74, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x63, 0x68, 0x61, 0x72,
+	0x67, 0x65, 0x54, 0x6f, 0x50, 0x63, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x68, 0x65, 0x69, 0x67, 0x68,
+	0x74, 0x5f, 0x6d, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x68, 0x65, 0x69, 0x67, 0x68,
+	0x74, 0x4d, 0x12, 0x19, 0x0a, 0x08, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x74, 0x18, 0x10,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x54, 0x12, 0x19, 0x0a,
+	0x08, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x5f, 0x6d, 0x18, 0x11, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x07, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x4d, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x61, 0x7a, 0x6d,
+	0x61, 0x74, 0x18, 0x12, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x68, 0x61, 0x7a, 0x6d, 0x61, 0x74,
+	0x12, 0x23, 0x0a, 0x0d, 0x61, 0x76, 0x6f, 0x69, 0x64, 0x5f, 0x67, 0x65, 0x6f, 0x6a, 0x73, 0x6f,
+	0x6e, 0x18, 0x13, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x61, 0x76, 0x6f, 0x69, 0x64, 0x47, 0x65,
+	0x6f, 0x6a, 0x73, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x76, 0x6f, 0x69, 0x64, 0x5f, 0x66,
+	0x65, 0x6e, 0x63, 0x65, 0x73, 0x18, 0x14, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x76, 0x6f,
+	0x69, 0x64, 0x46, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6f, 0x6e, 0x5f, 0x75,
+	0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x18, 0x15, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6f, 0x6e,
+	0x55, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x22, 0xb2, 0x01, 0x0a, 0x0d, 0x52, 0x6f, 0x75, 0x74,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x31, 0x0a, 0x06, 0x72, 0x6f, 0x75,
+	0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6e, 0x61, 0x76, 0x69,
+	0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52,
+	0x6f, 0x75, 0x74, 0x65, 0x52, 0x06, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x73, 0x12, 0x49, 0x0a, 0x0c,
+	0x72, 0x65, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x25, 0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75,
+	0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x0c, 0x72, 0x65, 0x73, 0x74, 0x72,
+	0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x6b, 0x69, 0x70, 0x70,
+	0x65, 0x64, 0x5f, 0x73, 0x74, 0x6f, 0x70, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c,
+	0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x53, 0x74, 0x6f, 0x70, 0x73, 0x22, 0xf7, 0x04, 0x0a,
+	0x05, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x1d, 0x0a, 0x0a,
+	0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x09, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x4d, 0x12, 0x1d, 0x0a, 0x0a, 0x64,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x09, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x12, 0x2f, 0x0a, 0x14, 0x66, 0x72,
+	0x65, 0x65, 0x5f, 0x66, 0x6c, 0x6f, 0x77, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x5f, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x11, 0x66, 0x72, 0x65, 0x65, 0x46, 0x6c,
+	0x6f, 0x77, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x12, 0x3c, 0x0a, 0x08, 0x67,
+	0x65, 0x6f, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e,
+	0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e,
+	0x76, 0x31, 0x2e, 0x4c, 0x69, 0x6e, 0x65, 0x47, 0x65, 0x6f, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x52,
+	0x08, 0x67, 0x65, 0x6f, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x12, 0x3e, 0x0a, 0x08, 0x73, 0x65, 0x67,
+	0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x6e, 0x61,
+	0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31,
+	0x2e, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x53, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x52,
+	0x08, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x47, 0x0a, 0x0c, 0x69, 0x6e, 0x73,
+	0x74, 0x72, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x23, 0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e,
+	0x67, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x75, 0x72, 0x6e, 0x49, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x69, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x37, 0x0a, 0x09, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x5f, 0x61, 0x74, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x08, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x41, 0x74, 0x12, 0x37, 0x0a, 0x09, 0x61,
+	0x72, 0x72, 0x69, 0x76, 0x65, 0x5f, 0x61, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x61, 0x72, 0x72, 0x69,
+	0x76, 0x65, 0x41, 0x74, 0x12, 0x32, 0x0a, 0x03, 0x65, 0x74, 0x61, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x20, 0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75, 0x74,
+	0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x74, 0x61, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x64,
+	0x6f, 0x77, 0x6e, 0x52, 0x03, 0x65, 0x74, 0x61, 0x12, 0x42, 0x0a, 0x09, 0x65, 0x6c, 0x65, 0x76,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x6e, 0x61,
+	0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31,
+	0x2e, 0x45, 0x6c, 0x65, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c,
+	0x65, 0x52, 0x09, 0x65, 0x6c, 0x65, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x38, 0x0a, 0x06,
+	0x65, 0x6e, 0x65, 0x72, 0x67, 0x79, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x6e,
+	0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76,
+	0x31, 0x2e, 0x45, 0x6e, 0x65, 0x72, 0x67, 0x79, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x06,
+	0x65, 0x6e, 0x65, 0x72, 0x67, 0x79, 0x22, 0x30, 0x0a, 0x0a, 0x43, 0x6f, 0x6f, 0x72, 0x64, 0x69,
+	0x6e, 0x61, 0x74, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x03, 0x6c, 0x61, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x6f, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x6f, 0x6e, 0x22, 0x6c, 0x0a, 0x0c, 0x4c, 0x69, 0x6e, 0x65,
+	0x47, 0x65, 0x6f, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x12, 0x40, 0x0a, 0x0b, 0x63, 0x6f, 0x6f, 0x72,
+	0x64, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e,
+	0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x52, 0x0b, 0x63,
+	0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6f,
+	0x6c, 0x79, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x6f,
+	0x6c, 0x79, 0x6c, 0x69, 0x6e, 0x65, 0x22, 0xf0, 0x01, 0x0a, 0x0e, 0x54, 0x72, 0x61, 0x66, 0x66,
+	0x69, 0x63, 0x53, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e,
+	0x67, 0x65, 0x73, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63,
+	0x6f, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3c, 0x0a, 0x08, 0x67, 0x65, 0x6f,
+	0x6d, 0x65, 0x74, 0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x6e, 0x61,
+	0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31,
+	0x2e, 0x4c, 0x69, 0x6e, 0x65, 0x47, 0x65, 0x6f, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x52, 0x08, 0x67,
+	0x65, 0x6f, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x12, 0x22, 0x0a, 0x0d, 0x66, 0x72, 0x65, 0x65, 0x5f,
+	0x66, 0x6c, 0x6f, 0x77, 0x5f, 0x6b, 0x6d, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b,
+	0x66, 0x72, 0x65, 0x65, 0x46, 0x6c, 0x6f, 0x77, 0x4b, 0x6d, 0x68, 0x12, 0x21, 0x0a, 0x0c, 0x6f,
+	0x62, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x5f, 0x6b, 0x6d, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x0b, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x4b, 0x6d, 0x68, 0x12, 0x1f,
+	0x0a, 0x0b, 0x73, 0x70, 0x65, 0x65, 0x64, 0x5f, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0a, 0x73, 0x70, 0x65, 0x65, 0x64, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x12,
+	0x18, 0x0a, 0x07, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x07, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x22, 0x80, 0x01, 0x0a, 0x0f, 0x54, 0x75,
+	0x72, 0x6e, 0x49, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a,
+	0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78,
+	0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x6d, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x4d,
+	0x12, 0x3a, 0x0a, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75,
+	0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61,
+	0x74, 0x65, 0x52, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xc0, 0x03, 0x0a,
+	0x0c, 0x45, 0x74, 0x61, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x64, 0x6f, 0x77, 0x6e, 0x12, 0x18, 0x0a,
+	0x07, 0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x72, 0x69, 0x76, 0x69,
+	0x6e, 0x67, 0x5f, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x64, 0x72, 0x69, 0x76,
+	0x69, 0x6e, 0x67, 0x53, 0x12, 0x26, 0x0a, 0x0f, 0x75, 0x72, 0x62, 0x61, 0x6e, 0x5f, 0x70, 0x65,
+	0x6e, 0x61, 0x6c, 0x74, 0x79, 0x5f, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x75,
+	0x72, 0x62, 0x61, 0x6e, 0x50, 0x65, 0x6e, 0x61, 0x6c, 0x74, 0x79, 0x53, 0x12, 0x30, 0x0a, 0x14,
+	0x69, 0x6e, 0x74, 0x65, 0x72, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x65, 0x6c,
+	0x61, 0x79, 0x5f, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x12, 0x69, 0x6e, 0x74, 0x65,
+	0x72, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x65, 0x6c, 0x61, 0x79, 0x53, 0x12, 0x24,
+	0x0a, 0x0d, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x73, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x62, 0x72, 0x65, 0x61, 0x6b, 0x5f, 0x73, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x62, 0x72, 0x65, 0x61, 0x6b, 0x53, 0x12, 0x17, 0x0a,
+	0x07, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x53, 0x12, 0x1d, 0x0a, 0x0a, 0x75, 0x70, 0x73, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x5f, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x75, 0x70, 0x73, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x53, 0x12, 0x48, 0x0a, 0x08, 0x62, 0x79, 0x5f, 0x63, 0x6c, 0x61, 0x73,
+	0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c,
+	0x79, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x74, 0x61,
+	0x42, 0x72, 0x65, 0x61, 0x6b, 0x64, 0x6f, 0x77, 0x6e, 0x2e, 0x42, 0x79, 0x43, 0x6c, 0x61, 0x73,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x62, 0x79, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x1a,
+	0x5e, 0x0a, 0x0c, 0x42, 0x79, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x38, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x22, 0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69,
+	0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x42, 0x72, 0x65, 0x61, 0x6b,
+	0x64, 0x6f, 0x77, 0x6e, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22,
+	0x6b, 0x0a, 0x0e, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x64, 0x6f, 0x77,
+	0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x6d, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x4d,
+	0x12, 0x1d, 0x0a, 0x0a, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x12,
+	0x1b, 0x0a, 0x09, 0x73, 0x70, 0x65, 0x65, 0x64, 0x5f, 0x6b, 0x6d, 0x68, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x08, 0x73, 0x70, 0x65, 0x65, 0x64, 0x4b, 0x6d, 0x68, 0x22, 0x9a, 0x02, 0x0a,
+	0x10, 0x45, 0x6c, 0x65, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c,
+	0x65, 0x12, 0x38, 0x0a, 0x06, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x20, 0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75, 0x74,
+	0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x50, 0x6f,
+	0x69, 0x6e, 0x74, 0x52, 0x06, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x61,
+	0x73, 0x63, 0x65, 0x6e, 0x74, 0x5f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x61,
+	0x73, 0x63, 0x65, 0x6e, 0x74, 0x4d, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x73, 0x63, 0x65, 0x6e,
+	0x74, 0x5f, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x64, 0x65, 0x73, 0x63, 0x65,
+	0x6e, 0x74, 0x4d, 0x12, 0x13, 0x0a, 0x05, 0x6d, 0x69, 0x6e, 0x5f, 0x6d, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x04, 0x6d, 0x69, 0x6e, 0x4d, 0x12, 0x13, 0x0a, 0x05, 0x6d, 0x61, 0x78, 0x5f,
+	0x6d, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x6d, 0x61, 0x78, 0x4d, 0x12, 0x22, 0x0a,
+	0x0d, 0x6d, 0x61, 0x78, 0x5f, 0x67, 0x72, 0x61, 0x64, 0x65, 0x5f, 0x70, 0x63, 0x74, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x47, 0x72, 0x61, 0x64, 0x65, 0x50, 0x63,
+	0x74, 0x12, 0x2a, 0x0a, 0x11, 0x6d, 0x61, 0x78, 0x5f, 0x64, 0x6f, 0x77, 0x6e, 0x67, 0x72, 0x61,
+	0x64, 0x65, 0x5f, 0x70, 0x63, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x6d, 0x61,
+	0x78, 0x44, 0x6f, 0x77, 0x6e, 0x67, 0x72, 0x61, 0x64, 0x65, 0x50, 0x63, 0x74, 0x12, 0x1a, 0x0a,
+	0x08, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x08, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x22, 0x4e, 0x0a, 0x0c, 0x50, 0x72, 0x6f,
+	0x66, 0x69, 0x6c, 0x65, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x64, 0x69, 0x73,
+	0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x64,
+	0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x4d, 0x12, 0x1f, 0x0a, 0x0b, 0x65, 0x6c, 0x65, 0x76,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x65,
+	0x6c, 0x65, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x22, 0x84, 0x03, 0x0a, 0x0c, 0x45, 0x6e,
+	0x65, 0x72, 0x67, 0x79, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65,
+	0x68, 0x69, 0x63, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x68,
+	0x69, 0x63, 0x6c, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x62, 0x61, 0x74, 0x74, 0x65, 0x72, 0x79, 0x5f,
+	0x6b, 0x77, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x62, 0x61, 0x74, 0x74, 0x65,
+	0x72, 0x79, 0x4b, 0x77, 0x68, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x77, 0x68, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x03, 0x6b, 0x77, 0x68, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x72, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x5f, 0x6b, 0x77, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x74,
+	0x72, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x77, 0x68, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x65,
+	0x67, 0x65, 0x6e, 0x5f, 0x6b, 0x77, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x72,
+	0x65, 0x67, 0x65, 0x6e, 0x4b, 0x77, 0x68, 0x12, 0x17, 0x0a, 0x07, 0x61, 0x75, 0x78, 0x5f, 0x6b,
+	0x77, 0x68, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x61, 0x75, 0x78, 0x4b, 0x77, 0x68,
+	0x12, 0x1a, 0x0a, 0x09, 0x77, 0x68, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x6b, 0x6d, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x07, 0x77, 0x68, 0x50, 0x65, 0x72, 0x4b, 0x6d, 0x12, 0x22, 0x0a, 0x0d,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x73, 0x6f, 0x63, 0x5f, 0x70, 0x63, 0x74, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0b, 0x73, 0x74, 0x61, 0x72, 0x74, 0x53, 0x6f, 0x63, 0x50, 0x63, 0x74,
+	0x12, 0x26, 0x0a, 0x0f, 0x61, 0x72, 0x72, 0x69, 0x76, 0x61, 0x6c, 0x5f, 0x73, 0x6f, 0x63, 0x5f,
+	0x70, 0x63, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x61, 0x72, 0x72, 0x69, 0x76,
+	0x61, 0x6c, 0x53, 0x6f, 0x63, 0x50, 0x63, 0x74, 0x12, 0x47, 0x0a, 0x0e, 0x63, 0x68, 0x61, 0x72,
+	0x67, 0x69, 0x6e, 0x67, 0x5f, 0x73, 0x74, 0x6f, 0x70, 0x73, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x20, 0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69,
+	0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x61, 0x72, 0x67, 0x69, 0x6e, 0x67, 0x53, 0x74,
+	0x6f, 0x70, 0x52, 0x0d, 0x63, 0x68, 0x61, 0x72, 0x67, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x6f, 0x70,
+	0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x68, 0x61, 0x72, 0x67, 0x69, 0x6e, 0x67, 0x5f, 0x73, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x63, 0x68, 0x61, 0x72, 0x67, 0x69, 0x6e, 0x67, 0x53,
+	0x22, 0x86, 0x03, 0x0a, 0x0c, 0x43, 0x68, 0x61, 0x72, 0x67, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x6f,
+	0x70, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x72, 0x49, 0x64,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x3a, 0x0a, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79,
+	0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6f, 0x72,
+	0x64, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x52, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x19, 0x0a, 0x08, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x5f, 0x6b, 0x77, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x07, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x4b, 0x77, 0x12, 0x28, 0x0a, 0x10, 0x64,
+	0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x61, 0x6c, 0x6f, 0x6e, 0x67, 0x5f, 0x6d, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0e, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x41,
+	0x6c, 0x6f, 0x6e, 0x67, 0x4d, 0x12, 0x19, 0x0a, 0x08, 0x64, 0x65, 0x74, 0x6f, 0x75, 0x72, 0x5f,
+	0x6d, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x64, 0x65, 0x74, 0x6f, 0x75, 0x72, 0x4d,
+	0x12, 0x26, 0x0a, 0x0f, 0x61, 0x72, 0x72, 0x69, 0x76, 0x61, 0x6c, 0x5f, 0x73, 0x6f, 0x63, 0x5f,
+	0x70, 0x63, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x61, 0x72, 0x72, 0x69, 0x76,
+	0x61, 0x6c, 0x53, 0x6f, 0x63, 0x50, 0x63, 0x74, 0x12, 0x2a, 0x0a, 0x11, 0x64, 0x65, 0x70, 0x61,
+	0x72, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x73, 0x6f, 0x63, 0x5f, 0x70, 0x63, 0x74, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0f, 0x64, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x53, 0x6f,
+	0x63, 0x50, 0x63, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x5f, 0x6b,
+	0x77, 0x68, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65,
+	0x4b, 0x77, 0x68, 0x12, 0x19, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x5f, 0x73, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x53, 0x12, 0x19,
+	0x0a, 0x08, 0x64, 0x65, 0x74, 0x6f, 0x75, 0x72, 0x5f, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x07, 0x64, 0x65, 0x74, 0x6f, 0x75, 0x72, 0x53, 0x22, 0xbc, 0x01, 0x0a, 0x11, 0x52, 0x65,
+	0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12,
+	0x3d, 0x0a, 0x07, 0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x23, 0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69,
+	0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x75, 0x63, 0x6b, 0x44, 0x69, 0x6d, 0x65, 0x6e,
+	0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x07, 0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x39,
+	0x0a, 0x07, 0x61, 0x76, 0x6f, 0x69, 0x64, 0x65, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1f, 0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e,
+	0x67, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x76, 0x6f, 0x69, 0x64, 0x65, 0x64, 0x52, 0x6f, 0x61, 0x64,
+	0x52, 0x07, 0x61, 0x76, 0x6f, 0x69, 0x64, 0x65, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x76, 0x69, 0x61,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x03, 0x76, 0x69, 0x61, 0x12, 0x1b, 0x0a, 0x09, 0x64,
+	0x65, 0x74, 0x6f, 0x75, 0x72, 0x5f, 0x6b, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08,
+	0x64, 0x65, 0x74, 0x6f, 0x75, 0x72, 0x4b, 0x6d, 0x22, 0x7a, 0x0a, 0x0f, 0x54, 0x72, 0x75, 0x63,
+	0x6b, 0x44, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x68,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x68,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x4d, 0x12, 0x19, 0x0a, 0x08, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x5f, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x54, 0x12, 0x19, 0x0a, 0x08, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x5f, 0x6d, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x07, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x4d, 0x12, 0x16, 0x0a, 0x06,
+	0x68, 0x61, 0x7a, 0x6d, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x68, 0x61,
+	0x7a, 0x6d, 0x61, 0x74, 0x22, 0xa5, 0x01, 0x0a, 0x0b, 0x41, 0x76, 0x6f, 0x69, 0x64, 0x65, 0x64,
+	0x52, 0x6f, 0x61, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x65, 0x64, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x65, 0x64, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02,
+	0x74, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x48, 0x0a, 0x0a,
+	0x76, 0x69, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x28, 0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69,
+	0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x56, 0x69, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x76, 0x69, 0x6f, 0x6c,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x72, 0x65, 0x61, 0x73, 0x18,
+	0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x61, 0x72, 0x65, 0x61, 0x73, 0x22, 0x58, 0x0a, 0x14,
+	0x52, 0x65, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x56, 0x69, 0x6f, 0x6c, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x61, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x74, 0x61, 0x67, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x18, 0x0a, 0x07,
+	0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x76,
+	0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x2a, 0x90, 0x01, 0x0a, 0x0e, 0x47, 0x65, 0x6f, 0x6d, 0x65,
+	0x74, 0x72, 0x79, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x1f, 0x0a, 0x1b, 0x47, 0x45, 0x4f,
+	0x4d, 0x45, 0x54, 0x52, 0x59, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x55, 0x4e, 0x53,
+	0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1f, 0x0a, 0x1b, 0x47, 0x45,
+	0x4f, 0x4d, 0x45, 0x54, 0x52, 0x59, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x43, 0x4f,
+	0x4f, 0x52, 0x44, 0x49, 0x4e, 0x41, 0x54, 0x45, 0x53, 0x10, 0x01, 0x12, 0x1d, 0x0a, 0x19, 0x47,
+	0x45, 0x4f, 0x4d, 0x45, 0x54, 0x52, 0x59, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x50,
+	0x4f, 0x4c, 0x59, 0x4c, 0x49, 0x4e, 0x45, 0x35, 0x10, 0x02, 0x12, 0x1d, 0x0a, 0x19, 0x47, 0x45,
+	0x4f, 0x4d, 0x45, 0x54, 0x52, 0x59, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x50, 0x4f,
+	0x4c, 0x59, 0x4c, 0x49, 0x4e, 0x45, 0x36, 0x10, 0x03, 0x32, 0x5e, 0x0a, 0x0e, 0x52, 0x6f, 0x75,
+	0x74, 0x69, 0x6e, 0x67, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x4c, 0x0a, 0x05, 0x52,
+	0x6f, 0x75, 0x74, 0x65, 0x12, 0x20, 0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x72,
+	0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79,
+	0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x6f, 0x75, 0x74,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x2a, 0x5a, 0x28, 0x6e, 0x61, 0x76,
+	0x69, 0x66, 0x6c, 0x79, 0x2f, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2f, 0x61, 0x70, 0x69,
+	0x2f, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2f, 0x76, 0x31, 0x3b, 0x72, 0x6f, 0x75, 0x74,
+	0x69, 0x6e, 0x67, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_api_routing_v1_routing_proto_rawDescOnce sync.Once
+	file_api_routing_v1_routing_proto_rawDescData []byte
+)
+
+func file_api_routing_v1_routing_proto_rawDescGZIP() []byte {
+	file_api_routing_v1_routing_proto_rawDescOnce.Do(func() {
+		file_api_routing_v1_routing_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_routing_v1_routing_proto_rawDesc), len(file_api_routing_v1_routing_proto_rawDesc)))
+	})
+	return file_api_routing_v1_routing_proto_rawDescData
+}
+
+var file_api_routing_v1_routing_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_routing_v1_routing_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_api_routing_v1_routing_proto_goTypes = []any{
+	(GeometryFormat)(0),           // 0: navifly.routing.v1.GeometryFormat
+	(*RouteRequest)(nil),          // 1: navifly.routing.v1.RouteRequest
+	(*RouteResponse)(nil),         // 2: navifly.routing.v1.RouteResponse
+	(*Route)(nil),                 // 3: navifly.routing.v1.Route
+	(*Coordinate)(nil),            // 4: navifly.routing.v1.Coordinate
+	(*LineGeometry)(nil),          // 5: navifly.routing.v1.LineGeometry
+	(*TrafficSegment)(nil),        // 6: navifly.routing.v1.TrafficSegment
+	(*TurnInstruction)(nil),       // 7: navifly.routing.v1.TurnInstruction
+	(*EtaBreakdown)(nil),          // 8: navifly.routing.v1.EtaBreakdown
+	(*ClassBreakdown)(nil),        // 9: navifly.routing.v1.ClassBreakdown
+	(*ElevationProfile)(nil),      // 10: navifly.routing.v1.ElevationProfile
+	(*ProfilePoint)(nil),          // 11: navifly.routing.v1.ProfilePoint
+	(*EnergyReport)(nil),          // 12: navifly.routing.v1.EnergyReport
+	(*ChargingStop)(nil),          // 13: navifly.routing.v1.ChargingStop
+	(*RestrictionReport)(nil),     // 14: navifly.routing.v1.RestrictionReport
+	(*TruckDimensions)(nil),       // 15: navifly.routing.v1.TruckDimensions
+	(*AvoidedRoad)(nil),           // 16: navifly.routing.v1.AvoidedRoad
+	(*RestrictionViolation)(nil),  // 17: navifly.routing.v1.RestrictionViolation
+	nil,                           // 18: navifly.routing.v1.EtaBreakdown.ByClassEntry
+	(*timestamppb.Timestamp)(nil), // 19: google.protobuf.Timestamp
+}
+var file_api_routing_v1_routing_proto_depIdxs = []int32{
+	19, // 0: navifly.routing.v1.RouteRequest.depart_at:type_name -> google.protobuf.Timestamp
+	19, // 1: navifly.routing.v1.RouteRequest.arrive_by:type_name -> google.protobuf.Timestamp
+	0,  // 2: navifly.routing.v1.RouteRequest.geometry:type_name -> navifly.routing.v1.GeometryFormat
+	3,  // 3: navifly.routing.v1.RouteResponse.routes:type_name -> navifly.routing.v1.Route
+	14, // 4: navifly.routing.v1.RouteResponse.restrictions:type_name -> navifly.routing.v1.RestrictionReport
+	5,  // 5: navifly.routing.v1.Route.geometry:type_name -> navifly.routing.v1.LineGeometry
+	6,  // 6: navifly.routing.v1.Route.segments:type_name -> navifly.routing.v1.TrafficSegment
+	7,  // 7: navifly.routing.v1.Route.instructions:type_name -> navifly.routing.v1.TurnInstruction
+	19, // 8: navifly.routing.v1.Route.depart_at:type_name -> google.protobuf.Timestamp
+	19, // 9: navifly.routing.v1.Route.arrive_at:type_name -> google.protobuf.Timestamp
+	8,  // 10: navifly.routing.v1.Route.eta:type_name -> navifly.routing.v1.EtaBreakdown
+	10, // 11: navifly.routing.v1.Route.elevation:type_name -> navifly.routing.v1.ElevationProfile
+	12, // 12: navifly.routing.v1.Route.energy:type_name -> navifly.routing.v1.EnergyReport
+	4,  // 13: navifly.routing.v1.LineGeometry.coordinates:type_name -> navifly.routing.v1.Coordinate
+	5,  // 14: navifly.routing.v1.TrafficSegment.geometry:type_name -> navifly.routing.v1.LineGeometry
+	4,  // 15: navifly.routing.v1.TurnInstruction.location:type_name -> navifly.routing.v1.Coordinate
+	18, // 16: navifly.routing.v1.EtaBreakdown.by_class:type_name -> navifly.routing.v1.EtaBreakdown.ByClassEntry
+	11, // 17: navifly.routing.v1.ElevationProfile.points:type_name -> navifly.routing.v1.ProfilePoint
+	13, // 18: navifly.routing.v1.EnergyReport.charging_stops:type_name -> navifly.routing.v1.ChargingStop
+	4,  // 19: navifly.routing.v1.ChargingStop.location:type_name -> navifly.routing.v1.Coordinate
+	15, // 20: navifly.routing.v1.RestrictionReport.vehicle:type_name -> navifly.routing.v1.TruckDimensions
+	16, // 21: navifly.routing.v1.RestrictionReport.avoided:type_name -> navifly.routing.v1.AvoidedRoad
+	17, // 22: navifly.routing.v1.AvoidedRoad.violations:type_name -> navifly.routing.v1.RestrictionViolation
+	9,  // 23: navifly.routing.v1.EtaBreakdown.ByClassEntry.value:type_name -> navifly.routing.v1.ClassBreakdown
+	1,  // 24: navifly.routing.v1.RoutingService.Route:input_type -> navifly.routing.v1.RouteRequest
+	2,  // 25: navifly.routing.v1.RoutingService.Route:output_type -> navifly.routing.v1.RouteResponse
+	25, // [25:26] is the sub-list for method output_type
+	24, // [24:25] is the sub-list for method input_type
+	24, // [24:24] is the sub-list for extension type_name
+	24, // [24:24] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
+}
+
+func init() { file_api_routing_v1_routing_proto_init() }
+func file_api_routing_v1_routing_proto_init() {
+	if File_api_routing_v1_routing_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_routing_v1_routing_proto_rawDesc), len(file_api_routing_v1_routing_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   18,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_routing_v1_routing_proto_goTypes,
+		DependencyIndexes: file_api_routing_v1_routing_proto_depIdxs,
+		EnumInfos:         file_api_routing_v1_routing_proto_enumTypes,
+		MessageInfos:      file_api_routing_v1_routing_proto_msgTypes,
+	}.Build()
+	File_api_routing_v1_routing_proto = out.File
+	file_api_routing_v1_routing_proto_goTypes = nil
+	file_api_routing_v1_routing_proto_depIdxs = nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"navifly/platform/problem"
+	"navifly/routing/internal/avoid"
+)
+
+// ── Avoid Areas ──
+
+// maxAvoidFences bounds the mapmatch lookups one request can cause.
+const maxAvoidFences = 20
+
+// parseAvoid reads inline avoid= GeoJSON and the avoid_fences= geofence IDs.
+func parseAvoid(q url.Values) ([]avoid.Area, []string, error) {
+	var areas []avoid.Area
+	if raw := q.Get("avoid"); raw != "" {
+		var err error
+		if areas, err = avoid.ParseGeoJSON([]byte(raw)); err != nil {
+			return nil, nil, fmt.Errorf("invalid avoid: %v", err)
+		}
+	}
+	fences := splitStops(q.Get("avoid_fences"))
+	if len(fences) > maxAvoidFences {
+		return nil, nil, fmt.Errorf("at most %d avoid_fences", maxAvoidFences)
+	}
+	return areas, fences, nil
+}
+
+// resolveAvoid fetches the avoid_fences polygons from the mapmatch service.
+func (s *Server) resolveAvoid(ctx context.Context, opts *routeOptions) error {
+	if len(opts.avoidFences) == 0 {
+		return nil
+	}
+	if s.fences == nil {
+		return problem.New(problem.Unavailable, "avoid_fences needs the mapmatch service; set MAPMATCH_URL")
+	}
+	areas, err := s.fences.Fetch(ctx, opts.avoidFences)
+	if errors.Is(err, avoid.ErrUnknownFence) {
+		// Unknown fences are the caller's mistake, anything else is the
+		// mapmatch service's
+		return problem.Errorf(problem.UnknownGeofence, "%w", err)
+	}
+	if err != nil {
+		return problem.Errorf(problem.UpstreamUnavailable, "%w", err)
+	}
+	opts.avoid = append(opts.avoid, areas...)
+	return nil
+}
+
+// rejectCrossing drops alternatives whose geometry enters an avoid area.
+func rejectCrossing(resp *EnhancedResponse, areas []avoid.Area) error {
+	if len(areas) == 0 {
+		return nil
+	}
+	kept := resp.Routes[:0]
+	crossed := map[string]bool{}
+	var names []string
+	for _, route := range resp.Routes {
+		clear := true
+		for _, a := range areas {
+			if a.CrossesLine(route.FullCoords) {
+				clear = false
+				if !crossed[a.ID] {
+					crossed[a.ID] = true
+					names = append(names, a.ID)
+				}
+			}
+		}
+		if clear {
+			kept = append(kept, route)
+		}
+	}
+	if len(kept) == 0 {
+		return problem.Errorf(problem.NoRoute, "every route crosses an avoid area (%s)", strings.Join(names, ", "))
+	}
+	resp.Routes = kept
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"navifly/platform/config"
+	"navifly/routing/internal/store"
+)
+
+// Config is the routing service's configuration. config.Load reads it from
+// the YAML file named by CONFIG_FILE and then the environment variables
+// named in the tags.
+type Config struct {
+	config.Server `yaml:",inline"`
+
+	Store StoreConfig `yaml:"store"`
+	// RedisURL counts rate-limit quotas in Redis; they are in process
+	// without it.
+	RedisURL string `yaml:"redis_url" env:"REDIS_URL"`
+
+	OSRMURL         string `yaml:"osrm_url" env:"OSRM_URL"`
+	MapmatchURL     string `yaml:"mapmatch_url" env:"MAPMATCH_URL"`
+	MapmatchAPIKey  string `yaml:"mapmatch_api_key" env:"MAPMATCH_API_KEY"`
+	TelemetryURL    string `yaml:"telemetry_url" env:"TELEMETRY_URL"`
+	TelemetryAPIKey string `yaml:"telemetry_api_key" env:"TELEMETRY_API_KEY"`
+
+	// Optional data files; each feature is off when its path is empty.
+	SpeedProfilesPath     string `yaml:"speed_profiles_path" env:"SPEED_PROFILES_PATH"`
+	ElevationDir          string `yaml:"elevation_dir" env:"ELEVATION_DIR"`
+	TruckRestrictionsPath string `yaml:"truck_restrictions_path" env:"TRUCK_RESTRICTIONS_PATH"`
+	ChargersPath          string `yaml:"chargers_path" env:"CHARGERS_PATH"`
+}
+
+// StoreConfig selects the route cache backend.
+type StoreConfig struct {
+	// Driver is postgres, sqlite or memory.
+	Driver     string `yaml:"driver" env:"ROUTE_STORE"`
+	DSN        string `yaml:"dsn" env:"DATABASE_URL"`
+	SQLitePath string `yaml:"sqlite_path" env:"SQLITE_PATH"`
+	// CacheSize bounds the memory driver.
+	CacheSize int `yaml:"cache_size" env:"ROUTE_CACHE_SIZE"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		Server:       config.DefaultServer(8080, 9090),
+		Store:        StoreConfig{Driver: store.DriverPostgres},
+		OSRMURL:      "http://router.project-osrm.org",
+		MapmatchURL:  "http://localhost:8082",
+		TelemetryURL: "http://localhost:8081",
+	}
+}
+
+func (c Config) Validate() error {
+	errs := []error{c.Server.Validate()}
+	switch c.Store.Driver {
+	case store.DriverPostgres, store.DriverSQLite, store.DriverMemory:
+	default:
+		errs = append(errs, fmt.Errorf("unknown store driver %q", c.Store.Driver))
+	}
+	if c.Store.CacheSize < 0 {
+		errs = append(errs, errors.New("store cache_size must not be negative"))
+	}
+	urls := []struct{ name, url string }{
+		{"osrm_url", c.OSRMURL},
+		{"mapmatch_url", c.MapmatchURL},
+		{"telemetry_url", c.TelemetryURL},
+	}
+	for _, u := range urls {
+		if err := checkURL(u.url); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", u.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkURL accepts absolute http and https URLs.
+func checkURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("want an http(s) URL, got %q", raw)
+	}
+	return nil
+}
+
+func (c StoreConfig) store() store.Config {
+	return store.Config{
+		Driver:         c.Driver,
+		DSN:            c.DSN,
+		SQLitePath:     c.SQLitePath,
+		MemoryCapacity: c.CacheSize,
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"navifly/platform/problem"
+	"navifly/routing/internal/avoid"
+	"navifly/routing/internal/routing"
+)
+
+// ── Corridor Detours ──
+
+// RestrictionReport explains a detour: the corridor roads on the direct path
+// the trip may not use, and the waypoints added to go around them.
+type RestrictionReport struct {
+	// Vehicle is set for trucks.
+	Vehicle *routing.Dimensions `json:"vehicle,omitempty"`
+	Avoided []AvoidedRoad       `json:"avoided"`
+	Via     []string            `json:"via"`
+	// DetourKm is the corridor distance the detour adds.
+	DetourKm float64 `json:"detour_km"`
+	// waypoints are the travel waypoints including the via points.
+	waypoints []string
+}
+
+// AvoidedRoad is a corridor edge the trip may not use, because the truck
+// breaks its restrictions or it crosses avoid areas.
+type AvoidedRoad struct {
+	Edge       string              `json:"edge"`
+	From       string              `json:"from"`
+	To         string              `json:"to"`
+	Violations []routing.Violation `json:"violations,omitempty"`
+	Areas      []string            `json:"areas,omitempty"`
+}
+
+// corridorRules decide which corridor edges a trip may use.
+type corridorRules struct {
+	truck *routing.Dimensions
+	areas []avoid.Area
+}
+
+// blocked explains why e is off limits; ok is false when it may be used.
+func (c corridorRules) blocked(g *routing.Graph, e *routing.Edge) (road AvoidedRoad, ok bool) {
+	road = AvoidedRoad{Edge: routing.EdgeID(e.From, e.To), From: e.From, To: e.To}
+	if c.truck != nil {
+		road.Violations = e.Restrictions.Violations(*c.truck)
+	}
+	if len(c.areas) > 0 {
+		from, to := g.Nodes[e.From], g.Nodes[e.To]
+		for _, a := range c.areas {
+			if a.CrossesSegment([2]float64{from.Lon, from.Lat}, [2]float64{to.Lon, to.Lat}) {
+				road.Areas = append(road.Areas, a.ID)
+			}
+		}
+	}
+	return road, len(road.Violations) > 0 || len(road.Areas) > 0
+}
+
+// planCorridor routes each leg of the trip over the corridor graph twice,
+// with and without the truck's restrictions and avoid areas. Where they
+// differ, the allowed path's intermediate nodes become extra stops so the
+// road route follows it. It returns the stops to route through and records
+// the detour on opts.
+func (s *Server) planCorridor(opts *routeOptions) (string, error) {
+	stopsParam := strings.Join(opts.waypoints[1:len(opts.waypoints)-1], ",")
+	rules := corridorRules{truck: opts.truck, areas: opts.avoid}
+	if rules.truck == nil && len(rules.areas) == 0 {
+		return stopsParam, nil
+	}
+	allow := func(e *routing.Edge) bool {
+		_, blocked := rules.blocked(s.graph, e)
+		return !blocked
+	}
+
+	report := &RestrictionReport{Vehicle: opts.truck, Avoided: []AvoidedRoad{}, Via: []string{}}
+	waypoints := []string{opts.waypoints[0]}
+	detoured := false
+
+	for i := 0; i < len(opts.waypoints)-1; i++ {
+		from, to := opts.waypoints[i], opts.waypoints[i+1]
+		direct, directKm := routing.AStar(s.graph, from, to)
+		if direct == nil {
+			// Not a catalog leg; nothing to restrict
+			waypoints = append(waypoints, to)
+			continue
+		}
+		blocked := blockedRoads(s.graph, direct, rules)
+		if len(blocked) == 0 {
+			waypoints = append(waypoints, to)
+			continue
+		}
+
+		legal, legalKm := routing.FilteredAStar(s.graph, from, to, allow)
+		if legal == nil {
+			return "", problem.Errorf(problem.NoRoute, "no allowed route %s → %s: every corridor path is blocked, e.g. %s",
+				from, to, describeRoads(blocked))
+		}
+		detoured = true
+		report.Avoided = append(report.Avoided, blocked...)
+		report.Via = append(report.Via, legal[1:len(legal)-1]...)
+		report.DetourKm += legalKm - directKm
+		waypoints = append(waypoints, legal[1:]...)
+	}
+	if !detoured {
+		return stopsParam, nil
+	}
+
+	report.DetourKm = math.Round(report.DetourKm*10) / 10
+	report.waypoints = waypoints
+	opts.detour = report
+	return strings.Join(waypoints[1:len(waypoints)-1], ","), nil
+}
+
+// stopNames names the place each stop planCorridor returned is, in order:
+// the catalog name of a trip stop, or "" for a via point it added.
+func (o routeOptions) stopNames() []string {
+	planned := o.waypoints
+	if o.detour != nil {
+		planned = o.detour.waypoints
+	}
+	names := make([]string, 0, len(planned))
+	next := 1 // next trip stop to match
+	for _, id := range planned[1 : len(planned)-1] {
+		if next < len(o.waypoints)-1 && id == o.waypoints[next] {
+			loc, _ := findLocation(id)
+			names = append(names, loc.Name)
+			next++
+			continue
+		}
+		names = append(names, "")
+	}
+	return names
+}
+
+// blockedRoads lists the edges along path the rules forbid.
+func blockedRoads(g *routing.Graph, path []string, rules corridorRules) []AvoidedRoad {
+	var out []AvoidedRoad
+	for i := 0; i < len(path)-1; i++ {
+		for _, e := range g.Edges[path[i]] {
+			if e.To != path[i+1] {
+				continue
+			}
+			if road, ok := rules.blocked(g, e); ok {
+				out = append(out, road)
+			}
+			break
+		}
+	}
+	return out
+}
+
+func describeRoads(roads []AvoidedRoad) string {
+	var parts []string
+	for _, road := range roads {
+		for _, v := range road.Violations {
+			parts = append(parts, fmt.Sprintf("%s (%s)", road.Edge, v))
+		}
+		for _, a := range road.Areas {
+			parts = append(parts, fmt.Sprintf("%s (crosses %s)", road.Edge, a))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+
+	"navifly/routing/internal/energy"
+	"navifly/routing/internal/eta"
+)
+
+// ── EV Energy & Charging ──
+
+// EnergyReport is the battery use and charging plan for one route.
+type EnergyReport struct {
+	Vehicle    string  `json:"vehicle"`
+	BatteryKWh float64 `json:"battery_kwh"`
+	energy.Estimate
+	StartSoCPct float64 `json:"start_soc_pct"`
+	energy.Plan
+}
+
+// evOptions are the ev= parameters of /route.
+type evOptions struct {
+	vehicle energy.Vehicle
+	plan    energy.PlanConfig
+}
+
+// parseEVOptions reads ev= and its battery parameters. It returns nil when
+// no EV was requested.
+func parseEVOptions(q url.Values) (*evOptions, error) {
+	name := q.Get("ev")
+	if name == "" {
+		for _, p := range []string{"battery_kwh", "soc", "reserve_soc", "charge_to"} {
+			if q.Get(p) != "" {
+				return nil, fmt.Errorf("%s needs ev=car, van or truck", p)
+			}
+		}
+		return nil, nil
+	}
+	v, err := energy.LookupVehicle(name)
+	if err != nil {
+		return nil, err
+	}
+	opts := &evOptions{vehicle: v, plan: energy.DefaultPlanConfig()}
+
+	for _, p := range []struct {
+		name string
+		into *float64
+	}{
+		{"battery_kwh", &opts.vehicle.BatteryKWh},
+		{"soc", &opts.plan.StartSoCPct},
+		{"reserve_soc", &opts.plan.ReserveSoCPct},
+		{"charge_to", &opts.plan.ChargeToPct},
+	} {
+		raw := q.Get(p.name)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v <= 0 || math.IsNaN(v) || math.IsInf(v, 0) || (p.name != "battery_kwh" && v > 100) {
+			if p.name == "battery_kwh" {
+				return nil, fmt.Errorf("battery_kwh must be a positive capacity")
+			}
+			return nil, fmt.Errorf("%s must be a percentage between 0 and 100", p.name)
+		}
+		*p.into = v
+	}
+	if opts.plan.ReserveSoCPct >= opts.plan.ChargeToPct {
+		return nil, fmt.Errorf("reserve_soc must be below charge_to")
+	}
+	return opts, nil
+}
+
+// applyEnergy estimates battery use for every route and plans charging
+// stops. Alternatives with no feasible plan are dropped. It needs the
+// duration segments, so it runs before estimateDurations.
+func (s *Server) applyEnergy(resp *EnhancedResponse, ev *evOptions, vehicle eta.VehicleProfile) error {
+	if ev == nil {
+		return nil
+	}
+
+	kept := resp.Routes[:0]
+	var lastErr error
+	for _, route := range resp.Routes {
+		legs := routeLegs(&route, vehicle)
+		dist, kwh := ev.vehicle.Cumulative(legs)
+		plan, err := energy.PlanCharging(energy.NewTrack(route.FullCoords, dist, kwh), s.chargers, ev.vehicle, ev.plan)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		route.Energy = &EnergyReport{
+			Vehicle:     ev.vehicle.Name,
+			BatteryKWh:  ev.vehicle.BatteryKWh,
+			Estimate:    ev.vehicle.Estimate(legs),
+			StartSoCPct: ev.plan.StartSoCPct,
+			Plan:        *plan,
+		}
+		route.Instructions = insertChargingInstructions(route.Instructions, plan.Stops)
+		kept = append(kept, route)
+	}
+	if len(kept) == 0 {
+		return lastErr
+	}
+	resp.Routes = kept
+	return nil
+}
+
+// routeLegs splits a route into constant-speed legs for the energy model,
+// using the duration model's speeds and, when available, the elevation
+// profile for climbs.
+func routeLegs(route *EnhancedRoute, vehicle eta.VehicleProfile) []energy.Leg {
+	segs := route.Segments
+	if len(segs) == 0 {
+		// Entries cached before the duration model have no segments
+		segs = []eta.Segment{{DistanceM: route.Distance, DurationS: route.FreeFlowDuration, Class: eta.Highway}}
+	}
+
+	total := 0.0
+	for _, seg := range segs {
+		total += math.Max(seg.DistanceM, 0)
+	}
+	prof := route.Elevation
+	scale := 1.0
+	if prof != nil && total > 0 {
+		// Step distances and the profile's geometry distances differ slightly
+		scale = prof.Points[len(prof.Points)-1][0] / total
+	}
+
+	var legs []energy.Leg
+	d := 0.0
+	for _, seg := range segs {
+		if seg.DistanceM <= 0 {
+			continue
+		}
+		speed := vehicle.SpeedOn(seg)
+		start, end := d, d+seg.DistanceM
+		cuts := []float64{start}
+		if prof != nil {
+			for _, p := range prof.Points {
+				if x := p[0] / scale; x > start && x < end {
+					cuts = append(cuts, x)
+				}
+			}
+		}
+		cuts = append(cuts, end)
+
+		for i := 1; i < len(cuts); i++ {
+			climb := 0.0
+			if prof != nil {
+				climb = prof.At(cuts[i]*scale) - prof.At(cuts[i-1]*scale)
+			}
+			legs = append(legs, energy.Leg{DistanceM: cuts[i] - cuts[i-1], SpeedKmh: speed, ClimbM: climb})
+		}
+		d = end
+	}
+	return legs
+}
+
+// insertChargingInstructions places a "Charge at" step at each stop,
+// splitting the instruction whose stretch of road the charger is on.
+func insertChargingInstructions(in []TurnInstruction, stops []energy.Stop) []TurnInstruction {
+	if len(stops) == 0 {
+		return in
+	}
+	out := make([]TurnInstruction, 0, len(in)+len(stops))
+	pos, si := 0.0, 0
+	for _, inst := range in {
+		end := pos + inst.DistanceM
+		for si < len(stops) && stops[si].DistanceAlongM < end {
+			st := stops[si]
+			inst.DistanceM = math.Max(st.DistanceAlongM-pos, 0)
+			out = append(out, inst)
+			inst = TurnInstruction{
+				Text: fmt.Sprintf("Charge at %s to %.0f%% (about %.0f min)",
+					chargerName(st.Charger), st.DepartureSoCPct, st.ChargeS/60),
+				Lat: st.Charger.Lat,
+				Lon: st.Charger.Lon,
+			}
+			pos = math.Max(st.DistanceAlongM, pos)
+			inst.DistanceM = end - pos
+			si++
+		}
+		out = append(out, inst)
+		pos = end
+	}
+	return out
+}
+
+func chargerName(c energy.Charger) string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.ID
+}
+
+// addChargingTime adds charging and detour time to the ETA. Timed trips
+// leave earlier (arrive_by) or arrive later (depart_at).
+func addChargingTime(resp *EnhancedResponse, arriveBy bool) {
+	for i := range resp.Routes {
+		route := &resp.Routes[i]
+		if route.Energy == nil || route.Energy.AddedS == 0 {
+			continue
+		}
+		added := time.Duration(route.Energy.AddedS) * time.Second
+		route.Duration += route.Energy.AddedS
+		route.FreeFlowDuration += route.Energy.AddedS
+		switch {
+		case arriveBy && route.DepartAt != nil:
+			depart := route.DepartAt.Add(-added)
+			route.DepartAt = &depart
+		case route.ArriveAt != nil:
+			arrive := route.ArriveAt.Add(added)
+			route.ArriveAt = &arrive
+		}
+	}
+}
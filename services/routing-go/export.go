@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"navifly/platform/problem"
+)
+
+// ── Route Export (GPX / KML / GeoJSON) ──
+
+type exportWaypoint struct {
+	Location
+	Role string // start, stop or end
+}
+
+type exportFormat struct {
+	contentType string
+	extension   string
+	render      func(w io.Writer, title string, route EnhancedRoute, waypoints []exportWaypoint) error
+}
+
+var exportFormats = map[string]exportFormat{
+	"gpx":     {"application/gpx+xml", "gpx", renderGPX},
+	"kml":     {"application/vnd.google-earth.kml+xml", "kml", renderKML},
+	"geojson": {"application/geo+json", "geojson", renderGeoJSON},
+}
+
+// HandleRouteExport renders a route as a downloadable file for handheld GPS
+// units and Google Earth. It accepts the same parameters as /route plus
+// format=gpx|kml|geojson and alternative=<index>.
+func (s *Server) HandleRouteExport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	startID, endID := q.Get("start"), q.Get("end")
+	if startID == "" || endID == "" {
+		problem.Write(w, r, problem.InvalidRequest, "Missing start or end parameter")
+		return
+	}
+
+	format, ok := exportFormats[q.Get("format")]
+	if !ok {
+		problem.Write(w, r, problem.InvalidRequest, "format must be gpx, kml or geojson")
+		return
+	}
+	alt := 0
+	if v := q.Get("alternative"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			problem.Write(w, r, problem.InvalidRequest, "alternative must be a non-negative route index")
+			return
+		}
+		alt = n
+	}
+
+	opts, err := parseRouteOptions(q)
+	if err != nil {
+		problem.WriteError(w, r, err, problem.InvalidRequest)
+		return
+	}
+	// Export files always carry plain coordinates
+	opts.format = formatGeoJSON
+
+	if err := s.resolveAvoid(r.Context(), &opts); err != nil {
+		problem.WriteError(w, r, err, problem.UpstreamUnavailable)
+		return
+	}
+	stopsParam, err := s.planCorridor(&opts)
+	if err != nil {
+		problem.WriteError(w, r, err, problem.NoRoute)
+		return
+	}
+	resp, err := s.loadRoute(r.Context(), startID, endID, stopsParam, opts.stopNames())
+	if err != nil {
+		problem.WriteError(w, r, err, problem.UpstreamUnavailable)
+		return
+	}
+	if err := s.finishRoute(resp, opts); err != nil {
+		problem.WriteError(w, r, err, problem.NoRoute)
+		return
+	}
+	if alt >= len(resp.Routes) {
+		problem.Write(w, r, problem.NotFound, fmt.Sprintf("route has %d alternatives", len(resp.Routes)))
+		return
+	}
+
+	waypoints := make([]exportWaypoint, 0, len(opts.waypoints))
+	for i, id := range opts.waypoints {
+		loc, ok := findLocation(id)
+		if !ok {
+			continue
+		}
+		role := "stop"
+		if i == 0 {
+			role = "start"
+		} else if i == len(opts.waypoints)-1 {
+			role = "end"
+		}
+		waypoints = append(waypoints, exportWaypoint{Location: loc, Role: role})
+	}
+
+	route := resp.Routes[alt]
+	title := exportTitle(waypoints, route.Label)
+	filename := fmt.Sprintf("navifly-%s.%s", strings.Join(opts.waypoints, "-"), format.extension)
+
+	// Render fully before sending, so a failure is a clean error response
+	// rather than a truncated download
+	var body bytes.Buffer
+	if err := format.render(&body, title, route, waypoints); err != nil {
+		problem.Write(w, r, problem.Internal, "Failed to render export")
+		return
+	}
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	body.WriteTo(w)
+}
+
+func exportTitle(waypoints []exportWaypoint, label string) string {
+	names := make([]string, 0, len(waypoints))
+	for _, wp := range waypoints {
+		names = append(names, wp.Name)
+	}
+	title := strings.Join(names, " → ")
+	if label != "" {
+		title += " (" + label + ")"
+	}
+	return title
+}
+
+func routeDescription(route EnhancedRoute) string {
+	return fmt.Sprintf("%.1f km, about %d min", route.Distance/1000, int(route.Duration/60+0.5))
+}
+
+// ── GPX 1.1 ──
+
+type gpxDoc struct {
+	XMLName xml.Name   `xml:"gpx"`
+	Xmlns   string     `xml:"xmlns,attr"`
+	Version string     `xml:"version,attr"`
+	Creator string     `xml:"creator,attr"`
+	Name    string     `xml:"metadata>name"`
+	Desc    string     `xml:"metadata>desc"`
+	Wpts    []gpxPoint `xml:"wpt"`
+	RteName string     `xml:"rte>name"`
+	RtePts  []gpxPoint `xml:"rte>rtept"`
+	TrkName string     `xml:"trk>name"`
+	TrkPts  []gpxPoint `xml:"trk>trkseg>trkpt"`
+}
+
+type gpxPoint struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Name string  `xml:"name,omitempty"`
+	Desc string  `xml:"desc,omitempty"`
+	Type string  `xml:"type,omitempty"`
+}
+
+func renderGPX(w io.Writer, title string, route EnhancedRoute, waypoints []exportWaypoint) error {
+	doc := gpxDoc{
+		Xmlns:   "http://www.topografix.com/GPX/1/1",
+		Version: "1.1",
+		Creator: "NaviFly",
+		Name:    title,
+		Desc:    routeDescription(route),
+		RteName: "Directions",
+		TrkName: title,
+	}
+	for _, wp := range waypoints {
+		doc.Wpts = append(doc.Wpts, gpxPoint{Lat: wp.Lat, Lon: wp.Lon, Name: wp.Name, Type: wp.Role})
+	}
+	for _, in := range route.Instructions {
+		doc.RtePts = append(doc.RtePts, gpxPoint{
+			Lat: in.Lat, Lon: in.Lon, Name: in.Text,
+			Desc: fmt.Sprintf("%.0f m", in.DistanceM),
+		})
+	}
+	for _, c := range route.FullCoords {
+		doc.TrkPts = append(doc.TrkPts, gpxPoint{Lat: c[1], Lon: c[0]})
+	}
+
+	io.WriteString(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
+
+// ── KML 2.2 ──
+
+type kmlDoc struct {
+	XMLName  xml.Name `xml:"kml"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Document kmlDocument
+}
+
+type kmlDocument struct {
+	XMLName     xml.Name       `xml:"Document"`
+	Name        string         `xml:"name"`
+	Description string         `xml:"description"`
+	Placemarks  []kmlPlacemark `xml:"Placemark"`
+	Folder      kmlFolder      `xml:"Folder"`
+}
+
+type kmlFolder struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name        string `xml:"name"`
+	Description string `xml:"description,omitempty"`
+	Point       *struct {
+		Coordinates string `xml:"coordinates"`
+	} `xml:"Point,omitempty"`
+	LineString *struct {
+		Tessellate  int    `xml:"tessellate"`
+		Coordinates string `xml:"coordinates"`
+	} `xml:"LineString,omitempty"`
+}
+
+func kmlPoint(name, desc string, lat, lon float64) kmlPlacemark {
+	pm := kmlPlacemark{Name: name, Description: desc}
+	pm.Point = &struct {
+		Coordinates string `xml:"coordinates"`
+	}{Coordinates: fmt.Sprintf("%g,%g", lon, lat)}
+	return pm
+}
+
+func renderKML(w io.Writer, title string, route EnhancedRoute, waypoints []exportWaypoint) error {
+	doc := kmlDoc{Xmlns: "http://www.opengis.net/kml/2.2"}
+	doc.Document.Name = title
+	doc.Document.Description = routeDescription(route)
+
+	coords := make([]string, len(route.FullCoords))
+	for i, c := range route.FullCoords {
+		coords[i] = fmt.Sprintf("%g,%g", c[0], c[1])
+	}
+	line := kmlPlacemark{Name: route.Label, Description: routeDescription(route)}
+	line.LineString = &struct {
+		Tessellate  int    `xml:"tessellate"`
+		Coordinates string `xml:"coordinates"`
+	}{Tessellate: 1, Coordinates: strings.Join(coords, " ")}
+	doc.Document.Placemarks = append(doc.Document.Placemarks, line)
+
+	for _, wp := range waypoints {
+		doc.Document.Placemarks = append(doc.Document.Placemarks, kmlPoint(wp.Name, wp.Role, wp.Lat, wp.Lon))
+	}
+
+	doc.Document.Folder.Name = "Directions"
+	for _, in := range route.Instructions {
+		doc.Document.Folder.Placemarks = append(doc.Document.Folder.Placemarks,
+			kmlPoint(in.Text, fmt.Sprintf("%.0f m", in.DistanceM), in.Lat, in.Lon))
+	}
+
+	io.WriteString(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
+
+// ── GeoJSON ──
+
+func renderGeoJSON(w io.Writer, title string, route EnhancedRoute, waypoints []exportWaypoint) error {
+	fc := FeatureCollection{Type: "FeatureCollection", Features: []Feature{{
+		Type: "Feature",
+		Properties: map[string]interface{}{
+			"name":     title,
+			"label":    route.Label,
+			"distance": route.Distance,
+			"duration": route.Duration,
+		},
+		Geometry: map[string]interface{}{
+			"type":        "LineString",
+			"coordinates": route.FullCoords,
+		},
+	}}}
+
+	for _, wp := range waypoints {
+		fc.Features = append(fc.Features, Feature{
+			Type:       "Feature",
+			Properties: map[string]interface{}{"id": wp.ID, "name": wp.Name, "role": wp.Role},
+			Geometry:   map[string]interface{}{"type": "Point", "coordinates": [2]float64{wp.Lon, wp.Lat}},
+		})
+	}
+	for i, in := range route.Instructions {
+		fc.Features = append(fc.Features, Feature{
+			Type:       "Feature",
+			Properties: map[string]interface{}{"step": i + 1, "instruction": in.Text, "distance_m": in.DistanceM},
+			Geometry:   map[string]interface{}{"type": "Point", "coordinates": [2]float64{in.Lon, in.Lat}},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(fc)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+
+	"navifly/routing/internal/polyline"
+)
+
+// ── Geometry Output Formats ──
+
+const (
+	formatGeoJSON   = "geojson"
+	formatPolyline5 = "polyline5"
+	formatPolyline6 = "polyline6"
+)
+
+// parseGeometryOptions reads geometry= and simplify= (metres).
+func parseGeometryOptions(q url.Values) (string, float64, error) {
+	format := q.Get("geometry")
+	switch format {
+	case "":
+		format = formatGeoJSON
+	case formatGeoJSON, formatPolyline5, formatPolyline6:
+	default:
+		return "", 0, fmt.Errorf("geometry must be geojson, polyline5 or polyline6")
+	}
+
+	tolerance := 0.0
+	if v := q.Get("simplify"); v != "" {
+		var err error
+		tolerance, err = strconv.ParseFloat(v, 64)
+		if err != nil || tolerance < 0 || math.IsNaN(tolerance) || math.IsInf(tolerance, 0) {
+			return "", 0, fmt.Errorf("simplify must be a non-negative distance in metres")
+		}
+	}
+	return format, tolerance, nil
+}
+
+// formatGeometry simplifies and, for polyline formats, encodes both the
+// traffic segments and full_coords. Segments are simplified one by one so
+// their shared endpoints still meet.
+func formatGeometry(resp *EnhancedResponse, format string, toleranceM float64) {
+	if format == "" {
+		format = formatGeoJSON
+	}
+	precision := 0
+	switch format {
+	case formatPolyline5:
+		precision = 5
+	case formatPolyline6:
+		precision = 6
+	}
+	if precision == 0 && toleranceM <= 0 {
+		return
+	}
+
+	for i := range resp.Routes {
+		route := &resp.Routes[i]
+		for j := range route.Geometry.Features {
+			geom, ok := route.Geometry.Features[j].Geometry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			coords, ok := geom["coordinates"].([][2]float64)
+			if !ok {
+				continue
+			}
+			coords = polyline.Simplify(coords, toleranceM)
+			if precision > 0 {
+				route.Geometry.Features[j].Geometry = map[string]interface{}{
+					"type":     "LineString",
+					"polyline": polyline.Encode(coords, precision),
+				}
+			} else {
+				geom["coordinates"] = coords
+			}
+		}
+
+		full := polyline.Simplify(route.FullCoords, toleranceM)
+		if precision > 0 {
+			route.FullPolyline = polyline.Encode(full, precision)
+			route.FullCoords = nil
+		} else {
+			route.FullCoords = full
+		}
+	}
+
+	if precision > 0 {
+		resp.GeometryFormat = format
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+
+	"navifly/platform/problem"
+	"navifly/routing/internal/elevation"
+)
+
+// ── Elevation & Grades ──
+
+// parseMaxGrade reads max_grade, the steepest climb or descent allowed in
+// percent.
+func parseMaxGrade(q url.Values) (float64, error) {
+	v := q.Get("max_grade")
+	if v == "" {
+		return 0, nil
+	}
+	grade, err := strconv.ParseFloat(v, 64)
+	if err != nil || grade <= 0 || math.IsNaN(grade) || math.IsInf(grade, 0) {
+		return 0, fmt.Errorf("max_grade must be a positive percentage")
+	}
+	return grade, nil
+}
+
+// applyElevation attaches a terrain profile to every route and, with
+// maxGrade set, drops alternatives steeper than allowed. Routes outside DEM
+// coverage have no profile and are kept.
+func (s *Server) applyElevation(resp *EnhancedResponse, maxGrade float64) error {
+	if s.dem == nil {
+		if maxGrade > 0 {
+			return problem.New(problem.Unavailable, "max_grade needs elevation data; set ELEVATION_DIR")
+		}
+		return nil
+	}
+
+	kept := resp.Routes[:0]
+	leastSteep := math.Inf(1)
+	for _, route := range resp.Routes {
+		route.Elevation = elevation.Build(s.dem, route.FullCoords, elevation.DefaultConfig())
+		if maxGrade > 0 && route.Elevation != nil && route.Elevation.Steepest() > maxGrade {
+			leastSteep = math.Min(leastSteep, route.Elevation.Steepest())
+			continue
+		}
+		kept = append(kept, route)
+	}
+	if len(kept) == 0 {
+		return problem.Errorf(problem.NoRoute, "every route exceeds max_grade %.1f%% (least steep is %.1f%%)", maxGrade, leastSteep)
+	}
+	resp.Routes = kept
+	return nil
+}
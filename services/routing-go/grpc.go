@@ -0,0 +1,299 @@
+package main
+
+//go:generate protoc -I . --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative api/routing/v1/routing.proto
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"navifly/platform/auth"
+	"navifly/platform/logging"
+	"navifly/platform/problem"
+	"navifly/platform/tracing"
+	routingv1 "navifly/routing/api/routing/v1"
+	"navifly/routing/internal/elevation"
+	"navifly/routing/internal/eta"
+)
+
+// ── gRPC API ──
+
+// routingGRPC serves RoutingService from the same pipeline as GET /route.
+type routingGRPC struct {
+	routingv1.UnimplementedRoutingServiceServer
+	srv *Server
+}
+
+// GRPCServer returns a gRPC server with RoutingService and reflection
+// registered.
+func (s *Server) GRPCServer() *grpc.Server {
+	opts := logging.ServerOptions()
+	opts = append(opts, s.auth.ServerOptions(auth.MethodScopes{
+		routingv1.RoutingService_Route_FullMethodName: auth.ScopeRoutesRead,
+	})...)
+	opts = append(opts, s.limiter.ServerOptions()...)
+	gs := grpc.NewServer(append(opts, tracing.ServerOptions()...)...)
+	routingv1.RegisterRoutingServiceServer(gs, &routingGRPC{srv: s})
+	reflection.Register(gs)
+	return gs
+}
+
+func (g *routingGRPC) Route(ctx context.Context, req *routingv1.RouteRequest) (*routingv1.RouteResponse, error) {
+	if req.GetStart() == "" || req.GetEnd() == "" {
+		return nil, problem.New(problem.InvalidRequest, "missing start or end")
+	}
+
+	// Errors carry their problem code, which also picks the gRPC status
+	q := routeQuery(req)
+	opts, err := parseRouteOptions(q)
+	if err != nil {
+		return nil, problem.As(err, problem.InvalidRequest)
+	}
+	if err := g.srv.resolveAvoid(ctx, &opts); err != nil {
+		return nil, problem.As(err, problem.UpstreamUnavailable)
+	}
+	stops, err := g.srv.planCorridor(&opts)
+	if err != nil {
+		return nil, problem.As(err, problem.NoRoute)
+	}
+	resp, err := g.srv.loadRoute(ctx, req.GetStart(), req.GetEnd(), stops, opts.stopNames())
+	if err != nil {
+		return nil, problem.As(err, problem.UpstreamUnavailable)
+	}
+	if err := g.srv.finishRoute(resp, opts); err != nil {
+		return nil, problem.As(err, problem.NoRoute)
+	}
+	return routeToProto(resp), nil
+}
+
+// routeQuery maps a RouteRequest onto the /route query parameters so both
+// transports validate input identically.
+func routeQuery(req *routingv1.RouteRequest) url.Values {
+	q := url.Values{}
+	q.Set("start", req.GetStart())
+	q.Set("end", req.GetEnd())
+	if len(req.GetStops()) > 0 {
+		q.Set("stops", strings.Join(req.GetStops(), ","))
+	}
+	if req.GetOnUnknown() != "" {
+		q.Set("on_unknown", req.GetOnUnknown())
+	}
+	if req.GetVehicle() != "" {
+		q.Set("vehicle", req.GetVehicle())
+	}
+	if req.DepartAt != nil {
+		q.Set("depart_at", req.GetDepartAt().AsTime().Format(time.RFC3339))
+	}
+	if req.ArriveBy != nil {
+		q.Set("arrive_by", req.GetArriveBy().AsTime().Format(time.RFC3339))
+	}
+	switch req.GetGeometry() {
+	case routingv1.GeometryFormat_GEOMETRY_FORMAT_POLYLINE5:
+		q.Set("geometry", formatPolyline5)
+	case routingv1.GeometryFormat_GEOMETRY_FORMAT_POLYLINE6:
+		q.Set("geometry", formatPolyline6)
+	}
+	if req.GetSimplifyM() != 0 {
+		q.Set("simplify", strconv.FormatFloat(req.GetSimplifyM(), 'f', -1, 64))
+	}
+	if req.GetMaxGradePct() != 0 {
+		q.Set("max_grade", strconv.FormatFloat(req.GetMaxGradePct(), 'f', -1, 64))
+	}
+	if req.GetEv() != "" {
+		q.Set("ev", req.GetEv())
+	}
+	for name, v := range map[string]float64{
+		"battery_kwh": req.GetBatteryKwh(),
+		"soc":         req.GetSocPct(),
+		"reserve_soc": req.GetReserveSocPct(),
+		"charge_to":   req.GetChargeToPct(),
+		"height_m":    req.GetHeightM(),
+		"weight_t":    req.GetWeightT(),
+		"length_m":    req.GetLengthM(),
+	} {
+		if v != 0 {
+			q.Set(name, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+	}
+	if req.GetHazmat() {
+		q.Set("hazmat", "true")
+	}
+	if req.GetAvoidGeojson() != "" {
+		q.Set("avoid", req.GetAvoidGeojson())
+	}
+	if len(req.GetAvoidFences()) > 0 {
+		q.Set("avoid_fences", strings.Join(req.GetAvoidFences(), ","))
+	}
+	return q
+}
+
+func routeToProto(resp *EnhancedResponse) *routingv1.RouteResponse {
+	out := &routingv1.RouteResponse{Routes: make([]*routingv1.Route, 0, len(resp.Routes)), SkippedStops: resp.SkippedStops}
+	for _, r := range resp.Routes {
+		route := &routingv1.Route{
+			Label:             r.Label,
+			DistanceM:         r.Distance,
+			DurationS:         r.Duration,
+			FreeFlowDurationS: r.FreeFlowDuration,
+			Geometry:          &routingv1.LineGeometry{Coordinates: coordsToProto(r.FullCoords), Polyline: r.FullPolyline},
+			Eta:               breakdownToProto(r.ETA),
+			Elevation:         profileToProto(r.Elevation),
+			Energy:            energyToProto(r.Energy),
+		}
+		for _, f := range r.Geometry.Features {
+			route.Segments = append(route.Segments, segmentToProto(f))
+		}
+		for _, in := range r.Instructions {
+			route.Instructions = append(route.Instructions, &routingv1.TurnInstruction{
+				Text:      in.Text,
+				DistanceM: in.DistanceM,
+				Location:  &routingv1.Coordinate{Lat: in.Lat, Lon: in.Lon},
+			})
+		}
+		if r.DepartAt != nil {
+			route.DepartAt = timestamppb.New(*r.DepartAt)
+		}
+		if r.ArriveAt != nil {
+			route.ArriveAt = timestamppb.New(*r.ArriveAt)
+		}
+		out.Routes = append(out.Routes, route)
+	}
+	out.Restrictions = restrictionsToProto(resp.Restrictions)
+	return out
+}
+
+func coordsToProto(coords [][2]float64) []*routingv1.Coordinate {
+	out := make([]*routingv1.Coordinate, 0, len(coords))
+	for _, c := range coords {
+		out = append(out, &routingv1.Coordinate{Lon: c[0], Lat: c[1]})
+	}
+	return out
+}
+
+// segmentToProto reads a traffic feature built by trafficGeometry and
+// possibly re-encoded by formatGeometry.
+func segmentToProto(f Feature) *routingv1.TrafficSegment {
+	seg := &routingv1.TrafficSegment{Geometry: &routingv1.LineGeometry{}}
+	seg.Congestion, _ = f.Properties["congestion"].(string)
+	seg.FreeFlowKmh, _ = f.Properties["free_flow_kmh"].(float64)
+	seg.ObservedKmh, _ = f.Properties["observed_kmh"].(float64)
+	seg.SpeedRatio, _ = f.Properties["speed_ratio"].(float64)
+	if n, ok := f.Properties["samples"].(int); ok {
+		seg.Samples = int32(n)
+	}
+	if geom, ok := f.Geometry.(map[string]interface{}); ok {
+		seg.Geometry.Polyline, _ = geom["polyline"].(string)
+		if coords, ok := geom["coordinates"].([][2]float64); ok {
+			seg.Geometry.Coordinates = coordsToProto(coords)
+		}
+	}
+	return seg
+}
+
+func breakdownToProto(b *eta.Breakdown) *routingv1.EtaBreakdown {
+	if b == nil {
+		return nil
+	}
+	out := &routingv1.EtaBreakdown{
+		Vehicle:            b.Vehicle,
+		DrivingS:           b.DrivingS,
+		UrbanPenaltyS:      b.UrbanPenaltyS,
+		IntersectionDelayS: b.IntersectionDelayS,
+		Intersections:      int32(b.Intersections),
+		BreakS:             b.BreakS,
+		TotalS:             b.TotalS,
+		UpstreamS:          b.UpstreamS,
+		ByClass:            make(map[string]*routingv1.ClassBreakdown, len(b.ByClass)),
+	}
+	for class, cb := range b.ByClass {
+		out.ByClass[string(class)] = &routingv1.ClassBreakdown{
+			DistanceM: cb.DistanceM,
+			DurationS: cb.DurationS,
+			SpeedKmh:  cb.SpeedKmh,
+		}
+	}
+	return out
+}
+
+func profileToProto(p *elevation.Profile) *routingv1.ElevationProfile {
+	if p == nil {
+		return nil
+	}
+	out := &routingv1.ElevationProfile{
+		Points:          make([]*routingv1.ProfilePoint, 0, len(p.Points)),
+		AscentM:         p.AscentM,
+		DescentM:        p.DescentM,
+		MinM:            p.MinM,
+		MaxM:            p.MaxM,
+		MaxGradePct:     p.MaxGradePct,
+		MaxDowngradePct: p.MaxDowngradePct,
+		Coverage:        p.Coverage,
+	}
+	for _, pt := range p.Points {
+		out.Points = append(out.Points, &routingv1.ProfilePoint{DistanceM: pt[0], ElevationM: pt[1]})
+	}
+	return out
+}
+
+func energyToProto(e *EnergyReport) *routingv1.EnergyReport {
+	if e == nil {
+		return nil
+	}
+	out := &routingv1.EnergyReport{
+		Vehicle:       e.Vehicle,
+		BatteryKwh:    e.BatteryKWh,
+		Kwh:           e.KWh,
+		TractionKwh:   e.TractionKWh,
+		RegenKwh:      e.RegenKWh,
+		AuxKwh:        e.AuxKWh,
+		WhPerKm:       e.WhPerKm,
+		StartSocPct:   e.StartSoCPct,
+		ArrivalSocPct: e.ArrivalSoCPct,
+		ChargingS:     e.AddedS,
+	}
+	for _, st := range e.Stops {
+		out.ChargingStops = append(out.ChargingStops, &routingv1.ChargingStop{
+			ChargerId:       st.Charger.ID,
+			Name:            st.Charger.Name,
+			Location:        &routingv1.Coordinate{Lat: st.Charger.Lat, Lon: st.Charger.Lon},
+			PowerKw:         st.Charger.PowerKw,
+			DistanceAlongM:  st.DistanceAlongM,
+			DetourM:         st.DetourM,
+			ArrivalSocPct:   st.ArrivalSoCPct,
+			DepartureSocPct: st.DepartureSoCPct,
+			ChargeKwh:       st.ChargeKWh,
+			ChargeS:         st.ChargeS,
+			DetourS:         st.DetourS,
+		})
+	}
+	return out
+}
+
+func restrictionsToProto(r *RestrictionReport) *routingv1.RestrictionReport {
+	if r == nil {
+		return nil
+	}
+	out := &routingv1.RestrictionReport{Via: r.Via, DetourKm: r.DetourKm}
+	if r.Vehicle != nil {
+		out.Vehicle = &routingv1.TruckDimensions{
+			HeightM: r.Vehicle.HeightM,
+			WeightT: r.Vehicle.WeightT,
+			LengthM: r.Vehicle.LengthM,
+			Hazmat:  r.Vehicle.Hazmat,
+		}
+	}
+	for _, road := range r.Avoided {
+		avoided := &routingv1.AvoidedRoad{Edge: road.Edge, From: road.From, To: road.To, Areas: road.Areas}
+		for _, v := range road.Violations {
+			avoided.Violations = append(avoided.Violations, &routingv1.RestrictionViolation{Tag: v.Tag, Limit: v.Limit, Vehicle: v.Vehicle})
+		}
+		out.Avoided = append(out.Avoided, avoided)
+	}
+	return out
+}
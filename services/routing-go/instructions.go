@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ── Turn Instructions ──
+
+// TurnInstruction is one maneuver along a route. DistanceM is the distance
+// driven after the maneuver until the next one.
+type TurnInstruction struct {
+	Text      string  `json:"text"`
+	DistanceM float64 `json:"distance_m"`
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+}
+
+// stepInstructions turns the steps of OSRM legs into readable directions.
+// stops names the place each leg but the last ends at, with "" for a detour
+// via point. Drivers pass via points without arriving and departing again,
+// so those steps are left out and their distance joins the instruction
+// before.
+func stepInstructions(legs []OSRMLeg, stops []string) []TurnInstruction {
+	instructions := []TurnInstruction{}
+	via := false // the previous leg ended at a via point
+	for i, leg := range legs {
+		stop := ""
+		if i < len(stops) {
+			stop = stops[i]
+		}
+		for _, st := range leg.Steps {
+			text := maneuverText(st)
+			if i < len(legs)-1 && st.Maneuver.Type == "arrive" {
+				if stop == "" {
+					passStep(instructions, st)
+					continue
+				}
+				text = arrivalText(stop, st.Maneuver.Modifier)
+			}
+			if via && st.Maneuver.Type == "depart" {
+				passStep(instructions, st)
+				continue
+			}
+			instructions = append(instructions, TurnInstruction{
+				Text:      text,
+				DistanceM: st.Distance,
+				Lon:       st.Maneuver.Location[0],
+				Lat:       st.Maneuver.Location[1],
+			})
+		}
+		via = i < len(legs)-1 && stop == ""
+	}
+	return instructions
+}
+
+// passStep adds the distance of a step left out to the instruction before.
+func passStep(instructions []TurnInstruction, st OSRMStep) {
+	if len(instructions) > 0 {
+		instructions[len(instructions)-1].DistanceM += st.Distance
+	}
+}
+
+// legSteps lists the steps of every leg in order.
+func legSteps(legs []OSRMLeg) []OSRMStep {
+	var steps []OSRMStep
+	for _, leg := range legs {
+		steps = append(steps, leg.Steps...)
+	}
+	return steps
+}
+
+func maneuverText(st OSRMStep) string {
+	road := st.Name
+	if st.Ref != "" {
+		if road == "" {
+			road = st.Ref
+		} else {
+			road = fmt.Sprintf("%s (%s)", road, st.Ref)
+		}
+	}
+	onto := ""
+	if road != "" {
+		onto = " onto " + road
+	}
+	modifier := st.Maneuver.Modifier
+
+	switch st.Maneuver.Type {
+	case "depart":
+		if road == "" {
+			return "Start journey"
+		}
+		return "Start journey on " + road
+	case "arrive":
+		return arrivalText("destination", modifier)
+	case "merge":
+		return strings.TrimSpace("Merge " + modifier + onto)
+	case "on ramp":
+		return "Take the ramp" + onto
+	case "off ramp":
+		if road == "" {
+			return "Take the exit"
+		}
+		return "Take the exit towards " + road
+	case "fork":
+		return "Keep " + orStraight(modifier) + onto
+	case "roundabout", "rotary":
+		if st.Maneuver.Exit > 0 {
+			return fmt.Sprintf("At the roundabout, take exit %d%s", st.Maneuver.Exit, onto)
+		}
+		return "Enter the roundabout" + onto
+	case "new name", "continue":
+		if modifier != "" && modifier != "straight" {
+			return "Continue " + modifier + onto
+		}
+		return "Continue" + onto
+	case "turn", "end of road":
+		if modifier == "uturn" {
+			return "Make a U-turn" + onto
+		}
+		if modifier == "straight" {
+			return "Continue straight" + onto
+		}
+		return "Turn " + orStraight(modifier) + onto
+	default:
+		return "Continue" + onto
+	}
+}
+
+func orStraight(modifier string) string {
+	if modifier == "" {
+		return "straight"
+	}
+	return modifier
+}
+
+// arrivalText reads like "Arrive at stop 1, on the right".
+func arrivalText(place, modifier string) string {
+	if modifier == "left" || modifier == "right" {
+		return fmt.Sprintf("Arrive at %s, on the %s", place, modifier)
+	}
+	return "Arrive at " + place
+}
@@ -0,0 +1,233 @@
+package avoid
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Polygon is an outer ring of [lon, lat] points followed by any holes.
+type Polygon [][][2]float64
+
+// Area is a region routes must stay out of, such as a wildfire closure or
+// an event zone.
+type Area struct {
+	ID       string
+	Polygons []Polygon
+	// Bounding box for cheap rejection
+	minLon, minLat, maxLon, maxLat float64
+}
+
+// NewArea validates the rings of polygons and indexes their bounds.
+func NewArea(id string, polygons []Polygon) (Area, error) {
+	a := Area{ID: id, Polygons: polygons,
+		minLon: math.Inf(1), minLat: math.Inf(1), maxLon: math.Inf(-1), maxLat: math.Inf(-1)}
+	if len(polygons) == 0 {
+		return a, fmt.Errorf("area %s has no polygons", id)
+	}
+	for _, poly := range polygons {
+		if len(poly) == 0 {
+			return a, fmt.Errorf("area %s has a polygon without rings", id)
+		}
+		for _, ring := range poly {
+			if len(ring) < 4 || ring[0] != ring[len(ring)-1] {
+				return a, fmt.Errorf("area %s: rings need at least 4 points and must be closed", id)
+			}
+			for _, p := range ring {
+				if p[0] < -180 || p[0] > 180 || p[1] < -90 || p[1] > 90 {
+					return a, fmt.Errorf("area %s: %v is not a [lon, lat] position", id, p)
+				}
+				a.minLon, a.maxLon = math.Min(a.minLon, p[0]), math.Max(a.maxLon, p[0])
+				a.minLat, a.maxLat = math.Min(a.minLat, p[1]), math.Max(a.maxLat, p[1])
+			}
+		}
+	}
+	return a, nil
+}
+
+// Contains reports whether p lies inside the area (and outside its holes).
+func (a Area) Contains(p [2]float64) bool {
+	if p[0] < a.minLon || p[0] > a.maxLon || p[1] < a.minLat || p[1] > a.maxLat {
+		return false
+	}
+	for _, poly := range a.Polygons {
+		if !ringContains(poly[0], p) {
+			continue
+		}
+		inHole := false
+		for _, hole := range poly[1:] {
+			if ringContains(hole, p) {
+				inHole = true
+				break
+			}
+		}
+		if !inHole {
+			return true
+		}
+	}
+	return false
+}
+
+// CrossesSegment reports whether the straight segment p → q enters the area.
+func (a Area) CrossesSegment(p, q [2]float64) bool {
+	if math.Max(p[0], q[0]) < a.minLon || math.Min(p[0], q[0]) > a.maxLon ||
+		math.Max(p[1], q[1]) < a.minLat || math.Min(p[1], q[1]) > a.maxLat {
+		return false
+	}
+	if a.Contains(p) || a.Contains(q) {
+		return true
+	}
+	// Both ends outside: it enters only by crossing a ring edge
+	for _, poly := range a.Polygons {
+		for _, ring := range poly {
+			for i := 1; i < len(ring); i++ {
+				if segmentsIntersect(p, q, ring[i-1], ring[i]) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// CrossesLine reports whether any part of a [lon, lat] line enters the area.
+func (a Area) CrossesLine(coords [][2]float64) bool {
+	if len(coords) == 1 {
+		return a.Contains(coords[0])
+	}
+	for i := 1; i < len(coords); i++ {
+		if a.CrossesSegment(coords[i-1], coords[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// ringContains is the even-odd ray casting test.
+func ringContains(ring [][2]float64, p [2]float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a[1] > p[1]) != (b[1] > p[1]) &&
+			p[0] < (b[0]-a[0])*(p[1]-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func segmentsIntersect(p1, p2, q1, q2 [2]float64) bool {
+	d1 := cross(q1, q2, p1)
+	d2 := cross(q1, q2, p2)
+	d3 := cross(p1, p2, q1)
+	d4 := cross(p1, p2, q2)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	return (d1 == 0 && onSegment(q1, q2, p1)) || (d2 == 0 && onSegment(q1, q2, p2)) ||
+		(d3 == 0 && onSegment(p1, p2, q1)) || (d4 == 0 && onSegment(p1, p2, q2))
+}
+
+func cross(a, b, c [2]float64) float64 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+func onSegment(a, b, p [2]float64) bool {
+	return math.Min(a[0], b[0]) <= p[0] && p[0] <= math.Max(a[0], b[0]) &&
+		math.Min(a[1], b[1]) <= p[1] && p[1] <= math.Max(a[1], b[1])
+}
+
+// ── GeoJSON ──
+
+type geoJSON struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    *geoJSON        `json:"geometry"`
+	Features    []geoJSON       `json:"features"`
+	ID          interface{}     `json:"id"`
+	Properties  struct {
+		Name string `json:"name"`
+	} `json:"properties"`
+}
+
+// ParseGeoJSON reads avoid areas from a Polygon, MultiPolygon, Feature or
+// FeatureCollection. Areas are named by feature id, then properties.name,
+// then position ("avoid-1").
+func ParseGeoJSON(data []byte) ([]Area, error) {
+	var doc geoJSON
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON: %v", err)
+	}
+	var areas []Area
+	add := func(g geoJSON, name string) error {
+		polys, err := polygons(g)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			name = fmt.Sprintf("avoid-%d", len(areas)+1)
+		}
+		a, err := NewArea(name, polys)
+		if err != nil {
+			return err
+		}
+		areas = append(areas, a)
+		return nil
+	}
+
+	switch doc.Type {
+	case "FeatureCollection":
+		for _, f := range doc.Features {
+			if f.Geometry == nil {
+				return nil, fmt.Errorf("feature without geometry")
+			}
+			if err := add(*f.Geometry, featureName(f)); err != nil {
+				return nil, err
+			}
+		}
+	case "Feature":
+		if doc.Geometry == nil {
+			return nil, fmt.Errorf("feature without geometry")
+		}
+		if err := add(*doc.Geometry, featureName(doc)); err != nil {
+			return nil, err
+		}
+	default:
+		if err := add(doc, ""); err != nil {
+			return nil, err
+		}
+	}
+	if len(areas) == 0 {
+		return nil, fmt.Errorf("GeoJSON has no polygons")
+	}
+	return areas, nil
+}
+
+func featureName(f geoJSON) string {
+	switch id := f.ID.(type) {
+	case string:
+		return id
+	case float64:
+		return fmt.Sprintf("%g", id)
+	}
+	return f.Properties.Name
+}
+
+func polygons(g geoJSON) ([]Polygon, error) {
+	switch g.Type {
+	case "Polygon":
+		var p Polygon
+		if err := json.Unmarshal(g.Coordinates, &p); err != nil {
+			return nil, fmt.Errorf("invalid Polygon coordinates: %v", err)
+		}
+		return []Polygon{p}, nil
+	case "MultiPolygon":
+		var mp []Polygon
+		if err := json.Unmarshal(g.Coordinates, &mp); err != nil {
+			return nil, fmt.Errorf("invalid MultiPolygon coordinates: %v", err)
+		}
+		return mp, nil
+	default:
+		return nil, fmt.Errorf("avoid areas must be Polygon or MultiPolygon, not %q", g.Type)
+	}
+}
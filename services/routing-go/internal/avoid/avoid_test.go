@@ -0,0 +1,86 @@
+package avoid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// square is a ring around (lon, lat) with half-width r.
+func square(lon, lat, r float64) [][2]float64 {
+	return [][2]float64{{lon - r, lat - r}, {lon + r, lat - r}, {lon + r, lat + r}, {lon - r, lat + r}, {lon - r, lat - r}}
+}
+
+func TestArea_ContainsWithHole(t *testing.T) {
+	a, err := NewArea("ring", []Polygon{{square(-112, 33, 1), square(-112, 33, 0.5)}})
+	require.NoError(t, err)
+
+	assert.True(t, a.Contains([2]float64{-112.8, 33}))
+	assert.False(t, a.Contains([2]float64{-112, 33}), "inside the hole")
+	assert.False(t, a.Contains([2]float64{-110, 33}))
+}
+
+func TestArea_CrossesSegment(t *testing.T) {
+	a, err := NewArea("fire", []Polygon{{square(-112, 33, 0.1)}})
+	require.NoError(t, err)
+
+	// Passes straight through with both ends outside
+	assert.True(t, a.CrossesSegment([2]float64{-113, 33}, [2]float64{-111, 33}))
+	// Passes north of it
+	assert.False(t, a.CrossesSegment([2]float64{-113, 33.5}, [2]float64{-111, 33.5}))
+	// Ends inside
+	assert.True(t, a.CrossesLine([][2]float64{{-113, 33}, {-112.5, 33}, {-112, 33}}))
+	assert.False(t, a.CrossesLine([][2]float64{{-113, 33}, {-112.5, 33}}))
+}
+
+func TestParseGeoJSON(t *testing.T) {
+	areas, err := ParseGeoJSON([]byte(`{"type":"Polygon","coordinates":[[[-112,33],[-111,33],[-111,34],[-112,33]]]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "avoid-1", areas[0].ID)
+
+	areas, err = ParseGeoJSON([]byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","id":"wildfire","geometry":{"type":"Polygon","coordinates":[[[-112,33],[-111,33],[-111,34],[-112,33]]]}},
+		{"type":"Feature","properties":{"name":"stadium"},"geometry":{"type":"MultiPolygon","coordinates":[[[[-112,33,300],[-111,33,300],[-111,34,300],[-112,33,300]]]]}}
+	]}`))
+	require.NoError(t, err)
+	require.Len(t, areas, 2)
+	assert.Equal(t, "wildfire", areas[0].ID)
+	assert.Equal(t, "stadium", areas[1].ID)
+	assert.True(t, areas[1].Contains([2]float64{-111.2, 33.5}))
+
+	for _, bad := range []string{
+		`{"type":"Point","coordinates":[-112,33]}`,
+		`{"type":"Polygon","coordinates":[[[-112,33],[-111,33],[-111,34]]]}`,
+		`{"type":"Polygon","coordinates":[[[33,-112],[33,-111],[34,-111],[33,-112]]]}`,
+		`{"type":"Feature"}`,
+		`not json`,
+	} {
+		_, err := ParseGeoJSON([]byte(bad))
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestFenceClient_Fetch(t *testing.T) {
+	mapmatch := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/geofences/Downtown-Zone-1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"id":"Downtown-Zone-1","polygon":[[[-112.08,33.44],[-112.06,33.44],[-112.06,33.46],[-112.08,33.46],[-112.08,33.44]]]}`))
+	}))
+	defer mapmatch.Close()
+	c := NewFenceClient(mapmatch.URL + "/")
+
+	areas, err := c.Fetch(context.Background(), []string{"Downtown-Zone-1"})
+	require.NoError(t, err)
+	assert.True(t, areas[0].Contains([2]float64{-112.07, 33.45}))
+
+	_, err = c.Fetch(context.Background(), []string{"Downtown-Zone-1", "nope"})
+	assert.True(t, errors.Is(err, ErrUnknownFence))
+	assert.EqualError(t, err, `unknown geofence "nope"`)
+}
@@ -0,0 +1,76 @@
+package avoid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrUnknownFence is returned for geofence IDs the mapmatch service does not
+// know.
+var ErrUnknownFence = errors.New("unknown geofence")
+
+// FenceClient looks up geofences in the mapmatch service.
+type FenceClient struct {
+	BaseURL string
+	HTTP    *http.Client
+	// APIKey, when set, is sent as X-API-Key.
+	APIKey string
+}
+
+// NewFenceClient talks to the mapmatch service at baseURL.
+func NewFenceClient(baseURL string) *FenceClient {
+	return &FenceClient{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		HTTP:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// fence mirrors the mapmatch Geofence JSON.
+type fence struct {
+	ID      string  `json:"id"`
+	Polygon Polygon `json:"polygon"`
+}
+
+// Fetch returns the areas of the named geofences, in order.
+func (c *FenceClient) Fetch(ctx context.Context, ids []string) ([]Area, error) {
+	areas := make([]Area, 0, len(ids))
+	for _, id := range ids {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/geofences/"+url.PathEscape(id), nil)
+		if err != nil {
+			return nil, err
+		}
+		if c.APIKey != "" {
+			req.Header.Set("X-API-Key", c.APIKey)
+		}
+		resp, err := c.HTTP.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("geofence lookup failed: %v", err)
+		}
+		var f fence
+		switch {
+		case resp.StatusCode == http.StatusNotFound:
+			err = fmt.Errorf("%w %q", ErrUnknownFence, id)
+		case resp.StatusCode != http.StatusOK:
+			err = fmt.Errorf("geofence lookup failed: %s returned %s", id, resp.Status)
+		default:
+			err = json.NewDecoder(resp.Body).Decode(&f)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		a, err := NewArea(id, []Polygon{f.Polygon})
+		if err != nil {
+			return nil, err
+		}
+		areas = append(areas, a)
+	}
+	return areas, nil
+}
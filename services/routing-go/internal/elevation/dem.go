@@ -0,0 +1,211 @@
+package elevation
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Source returns the ground height in metres at a point, or false where it
+// has no data.
+type Source interface {
+	Elevation(lat, lon float64) (float64, bool)
+}
+
+// grid is a regular lat/lon raster of heights. north/west locate the centre
+// of the first sample; rows run south and columns east.
+type grid struct {
+	north, west float64
+	dLat, dLon  float64
+	rows, cols  int
+	data        []float32
+	noData      float32
+	hasNoData   bool
+}
+
+func (g *grid) bounds() (minLat, minLon, maxLat, maxLon float64) {
+	return g.north - float64(g.rows-1)*g.dLat, g.west, g.north, g.west + float64(g.cols-1)*g.dLon
+}
+
+func (g *grid) valid(v float32) bool {
+	return !math.IsNaN(float64(v)) && !(g.hasNoData && v == g.noData)
+}
+
+// at interpolates bilinearly between the four surrounding samples, ignoring
+// voids.
+func (g *grid) at(lat, lon float64) (float64, bool) {
+	y := (g.north - lat) / g.dLat
+	x := (lon - g.west) / g.dLon
+	if y < 0 || x < 0 || y > float64(g.rows-1) || x > float64(g.cols-1) {
+		return 0, false
+	}
+	r0 := int(math.Min(math.Floor(y), float64(g.rows-2)))
+	c0 := int(math.Min(math.Floor(x), float64(g.cols-2)))
+	if r0 < 0 || c0 < 0 {
+		// Single-row or single-column grid
+		v := g.data[int(math.Round(y))*g.cols+int(math.Round(x))]
+		return float64(v), g.valid(v)
+	}
+	fy, fx := y-float64(r0), x-float64(c0)
+
+	sum, weight := 0.0, 0.0
+	for _, n := range [4]struct {
+		r, c int
+		w    float64
+	}{
+		{r0, c0, (1 - fy) * (1 - fx)},
+		{r0, c0 + 1, (1 - fy) * fx},
+		{r0 + 1, c0, fy * (1 - fx)},
+		{r0 + 1, c0 + 1, fy * fx},
+	} {
+		v := g.data[n.r*g.cols+n.c]
+		if g.valid(v) && n.w > 0 {
+			sum += float64(v) * n.w
+			weight += n.w
+		}
+	}
+	if weight == 0 {
+		return 0, false
+	}
+	return sum / weight, true
+}
+
+// ── Tile Directory ──
+
+// tile is one raster file, decoded on first use.
+type tile struct {
+	path                           string
+	minLat, minLon, maxLat, maxLon float64
+	decode                         func(path string) (*grid, error)
+
+	once sync.Once
+	grid *grid
+	err  error
+}
+
+func (t *tile) contains(lat, lon float64) bool {
+	return lat >= t.minLat && lat <= t.maxLat && lon >= t.minLon && lon <= t.maxLon
+}
+
+// DEM samples heights from a directory of SRTM .hgt and GeoTIFF tiles.
+// Tiles are indexed up front and decoded the first time a route touches
+// them, so a statewide directory costs little until it is used.
+type DEM struct {
+	tiles []*tile
+}
+
+// OpenDir indexes every .hgt, .tif and .tiff file in dir.
+func OpenDir(dir string) (*DEM, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	dem := &DEM{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		var t *tile
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".hgt":
+			t, err = hgtTile(path)
+		case ".tif", ".tiff":
+			t, err = geoTIFFTile(path)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		dem.tiles = append(dem.tiles, t)
+	}
+	if len(dem.tiles) == 0 {
+		return nil, fmt.Errorf("no .hgt or GeoTIFF tiles in %s", dir)
+	}
+	return dem, nil
+}
+
+// Tiles returns the number of indexed tiles.
+func (d *DEM) Tiles() int {
+	return len(d.tiles)
+}
+
+func (d *DEM) Elevation(lat, lon float64) (float64, bool) {
+	for _, t := range d.tiles {
+		if !t.contains(lat, lon) {
+			continue
+		}
+		t.once.Do(func() { t.grid, t.err = t.decode(t.path) })
+		if t.err != nil {
+			continue
+		}
+		if v, ok := t.grid.at(lat, lon); ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// ── SRTM .hgt ──
+
+var hgtName = regexp.MustCompile(`^([NS])(\d{2})([EW])(\d{3})$`)
+
+// hgtVoid marks missing samples in SRTM data.
+const hgtVoid = -32768
+
+// hgtTile indexes an SRTM tile from its name, e.g. N33W112.hgt covers
+// 33–34°N, 112–111°W.
+func hgtTile(path string) (*tile, error) {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	m := hgtName.FindStringSubmatch(strings.ToUpper(base))
+	if m == nil {
+		return nil, fmt.Errorf("hgt file name must look like N33W112")
+	}
+	lat, _ := strconv.Atoi(m[2])
+	lon, _ := strconv.Atoi(m[4])
+	if m[1] == "S" {
+		lat = -lat
+	}
+	if m[3] == "W" {
+		lon = -lon
+	}
+	return &tile{
+		path:   path,
+		minLat: float64(lat), minLon: float64(lon),
+		maxLat: float64(lat + 1), maxLon: float64(lon + 1),
+		decode: func(path string) (*grid, error) { return decodeHGT(path, float64(lat), float64(lon)) },
+	}, nil
+}
+
+// decodeHGT reads big-endian int16 samples. The size tells SRTM3 (1201²)
+// from SRTM1 (3601²).
+func decodeHGT(path string, south, west float64) (*grid, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	n := int(math.Sqrt(float64(len(raw) / 2)))
+	if n < 2 || n*n*2 != len(raw) {
+		return nil, fmt.Errorf("%s: %d bytes is not a square SRTM tile", filepath.Base(path), len(raw))
+	}
+
+	g := &grid{
+		north: south + 1, west: west,
+		dLat: 1 / float64(n-1), dLon: 1 / float64(n-1),
+		rows: n, cols: n,
+		data:   make([]float32, n*n),
+		noData: hgtVoid, hasNoData: true,
+	}
+	for i := range g.data {
+		g.data[i] = float32(int16(binary.BigEndian.Uint16(raw[i*2:])))
+	}
+	return g, nil
+}
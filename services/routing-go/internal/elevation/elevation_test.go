@@ -0,0 +1,253 @@
+package elevation
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeHGT writes an SRTM3 tile whose height is 2000 m at the north edge,
+// dropping 1 m per row.
+func writeHGT(t *testing.T, dir, name string, void func(r, c int) bool) {
+	const n = 1201
+	buf := make([]byte, n*n*2)
+	for r := 0; r < n; r++ {
+		for c := 0; c < n; c++ {
+			v := int16(2000 - r)
+			if void != nil && void(r, c) {
+				v = hgtVoid
+			}
+			binary.BigEndian.PutUint16(buf[(r*n+c)*2:], uint16(v))
+		}
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), buf, 0o644))
+}
+
+func TestDEM_HGT(t *testing.T) {
+	dir := t.TempDir()
+	writeHGT(t, dir, "N33W112.hgt", func(r, c int) bool { return r == 600 && c == 600 })
+
+	dem, err := OpenDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, dem.Tiles())
+
+	v, ok := dem.Elevation(34, -112)
+	assert.True(t, ok)
+	assert.InDelta(t, 2000, v, 0.01)
+
+	// Halfway between rows 300 and 301
+	v, ok = dem.Elevation(34-300.5/1200, -111.5)
+	assert.True(t, ok)
+	assert.InDelta(t, 1699.5, v, 0.01)
+
+	// A void sample is skipped in favour of its neighbours
+	v, ok = dem.Elevation(33.5+0.1/1200, -111.5+0.1/1200)
+	assert.True(t, ok)
+	assert.InDelta(t, 1400, v, 1)
+
+	_, ok = dem.Elevation(35.5, -111.5)
+	assert.False(t, ok, "outside every tile")
+}
+
+func TestOpenDir_Errors(t *testing.T) {
+	_, err := OpenDir(t.TempDir())
+	assert.Error(t, err, "no tiles")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "phoenix.hgt"), []byte{0, 0}, 0o644))
+	_, err = OpenDir(dir)
+	assert.Error(t, err, "bad tile name")
+}
+
+// writeGeoTIFF writes a little-endian single-strip int16 GeoTIFF whose top
+// left pixel corner is at (west, north).
+func writeGeoTIFF(t *testing.T, path string, samples [][]int16, west, north, pixel float64, deflate bool) {
+	h, w := len(samples), len(samples[0])
+	var pix bytes.Buffer
+	for _, row := range samples {
+		prev := int16(0)
+		for c, v := range row {
+			if deflate && c > 0 {
+				binary.Write(&pix, binary.LittleEndian, v-prev) // horizontal predictor
+			} else {
+				binary.Write(&pix, binary.LittleEndian, v)
+			}
+			prev = v
+		}
+	}
+	data := pix.Bytes()
+	compression, predictor := uint32(1), uint32(1)
+	if deflate {
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		zw.Write(data)
+		zw.Close()
+		data = z.Bytes()
+		compression, predictor = 8, 2
+	}
+
+	type field struct {
+		tag, typ uint16
+		vals     []byte
+		count    uint32
+	}
+	le := binary.LittleEndian
+	short := func(v uint32) []byte { b := make([]byte, 2); le.PutUint16(b, uint16(v)); return b }
+	long := func(v uint32) []byte { b := make([]byte, 4); le.PutUint32(b, v); return b }
+	doubles := func(vs ...float64) []byte {
+		b := make([]byte, 8*len(vs))
+		for i, v := range vs {
+			le.PutUint64(b[i*8:], math.Float64bits(v))
+		}
+		return b
+	}
+	shorts := func(vs ...uint32) []byte {
+		var b []byte
+		for _, v := range vs {
+			b = append(b, short(v)...)
+		}
+		return b
+	}
+
+	fields := []field{
+		{256, 3, short(uint32(w)), 1},
+		{257, 3, short(uint32(h)), 1},
+		{258, 3, short(16), 1},
+		{259, 3, short(compression), 1},
+		{273, 4, nil, 1}, // patched below
+		{277, 3, short(1), 1},
+		{278, 3, short(uint32(h)), 1},
+		{279, 4, long(uint32(len(data))), 1},
+		{317, 3, short(predictor), 1},
+		{339, 3, short(2), 1},
+		{33550, 12, doubles(pixel, pixel, 0), 3},
+		{33922, 12, doubles(0, 0, 0, west, north, 0), 6},
+		// GeoKeyDirectory: ModelType geographic, RasterType PixelIsArea
+		{34735, 3, shorts(1, 1, 0, 2, 1024, 0, 1, 2, 1025, 0, 1, 1), 12},
+		{42113, 2, []byte("-9999\x00"), 6},
+	}
+
+	ifdSize := 2 + len(fields)*12 + 4
+	extra := 8 + ifdSize
+	var out, tail bytes.Buffer
+	out.WriteString("II")
+	out.Write(short(42))
+	out.Write(long(8))
+	out.Write(short(uint32(len(fields))))
+	for _, f := range fields {
+		out.Write(short(uint32(f.tag)))
+		out.Write(short(uint32(f.typ)))
+		out.Write(long(f.count))
+		switch {
+		case f.tag == 273:
+			out.Write(long(0)) // placeholder
+		case len(f.vals) <= 4:
+			out.Write(append(f.vals, make([]byte, 4-len(f.vals))...))
+		default:
+			out.Write(long(uint32(extra + tail.Len())))
+			tail.Write(f.vals)
+		}
+	}
+	out.Write(long(0))
+	out.Write(tail.Bytes())
+	dataOffset := out.Len()
+	out.Write(data)
+
+	b := out.Bytes()
+	for i, f := range fields {
+		if f.tag == 273 {
+			le.PutUint32(b[8+2+i*12+8:], uint32(dataOffset))
+		}
+	}
+	require.NoError(t, os.WriteFile(path, b, 0o644))
+}
+
+func TestDEM_GeoTIFF(t *testing.T) {
+	samples := [][]int16{
+		{100, 200, 300},
+		{400, 500, -9999},
+	}
+	for _, deflate := range []bool{false, true} {
+		dir := t.TempDir()
+		writeGeoTIFF(t, filepath.Join(dir, "dem.tif"), samples, -112, 34, 0.5, deflate)
+
+		dem, err := OpenDir(dir)
+		require.NoError(t, err)
+
+		// Pixel centres sit half a pixel in from the tiepoint corner
+		v, ok := dem.Elevation(33.75, -111.75)
+		assert.True(t, ok)
+		assert.InDelta(t, 100, v, 0.01)
+
+		v, ok = dem.Elevation(33.5, -111.5)
+		assert.True(t, ok)
+		assert.InDelta(t, 300, v, 0.01, "midway between the first four samples")
+
+		// GDAL_NODATA is treated as a void
+		v, ok = dem.Elevation(33.25, -111.0)
+		assert.True(t, ok)
+		assert.InDelta(t, 500, v, 0.01)
+		_, ok = dem.Elevation(33.25, -110.75)
+		assert.False(t, ok)
+	}
+}
+
+// slope rises 50 m per km travelled north from 33.0°N.
+type slope struct{ noise float64 }
+
+func (s slope) Elevation(lat, lon float64) (float64, bool) {
+	if lat > 33.2 {
+		return 0, false
+	}
+	return 1000 + (lat-33)*111195/1000*50 + s.noise*math.Sin(lat*1e5), true
+}
+
+func TestBuild_Climb(t *testing.T) {
+	// ~11 km due north
+	coords := [][2]float64{{-112, 33.0}, {-112, 33.1}}
+	p := Build(slope{}, coords, DefaultConfig())
+	require.NotNil(t, p)
+
+	assert.InDelta(t, 5.0, p.MaxGradePct, 0.1)
+	assert.Equal(t, 0.0, p.MaxDowngradePct)
+	assert.InDelta(t, 556, p.AscentM, 6)
+	assert.Equal(t, 0.0, p.DescentM)
+	assert.InDelta(t, 1000, p.MinM, 0.1)
+	assert.Equal(t, 1.0, p.Coverage)
+	assert.Equal(t, 0.0, p.Points[0][0])
+	assert.InDelta(t, 11120, p.Points[len(p.Points)-1][0], 5)
+
+	// Reversed, the climb becomes a descent
+	back := Build(slope{}, [][2]float64{coords[1], coords[0]}, DefaultConfig())
+	assert.InDelta(t, 5.0, back.MaxDowngradePct, 0.1)
+	assert.InDelta(t, 556, back.DescentM, 6)
+}
+
+func TestBuild_NoiseAndGaps(t *testing.T) {
+	coords := [][2]float64{{-112, 33.0}, {-112, 33.3}}
+	p := Build(slope{noise: 2}, coords, DefaultConfig())
+	require.NotNil(t, p)
+
+	// Beyond 33.2°N there is no data; the last known height is carried on
+	assert.InDelta(t, 0.67, p.Coverage, 0.01)
+	assert.InDelta(t, 1000+22239*0.05, p.MaxM, 3)
+	// Hysteresis keeps the 2 m jitter out of the totals
+	assert.Less(t, p.DescentM, 10.0)
+
+	assert.Nil(t, Build(slope{}, [][2]float64{{-112, 34}, {-112, 34.1}}, DefaultConfig()))
+}
+
+func TestBuild_CapsPoints(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxPoints = 10
+	p := Build(slope{}, [][2]float64{{-112, 33.0}, {-112, 33.1}}, cfg)
+	assert.LessOrEqual(t, len(p.Points), 11)
+	assert.InDelta(t, 11120, p.Points[len(p.Points)-1][0], 5, "last point kept")
+}
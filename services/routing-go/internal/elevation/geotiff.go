@@ -0,0 +1,376 @@
+package elevation
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ── GeoTIFF ──
+//
+// This is a deliberately small reader for single-band DEM GeoTIFFs in
+// geographic coordinates (e.g. SRTM or Copernicus exports): classic TIFF,
+// stripped or tiled, uncompressed or Deflate (optionally with horizontal
+// predictor), and 16/32-bit integer or 32-bit float samples.
+
+const (
+	tagImageWidth      = 256
+	tagImageLength     = 257
+	tagBitsPerSample   = 258
+	tagCompression     = 259
+	tagStripOffsets    = 273
+	tagSamplesPerPixel = 277
+	tagRowsPerStrip    = 278
+	tagStripByteCounts = 279
+	tagPredictor       = 317
+	tagTileWidth       = 322
+	tagTileLength      = 323
+	tagTileOffsets     = 324
+	tagTileByteCounts  = 325
+	tagSampleFormat    = 339
+	tagPixelScale      = 33550
+	tagTiepoint        = 33922
+	tagGeoKeyDirectory = 34735
+	tagGDALNoData      = 42113
+
+	geoKeyModelType  = 1024
+	geoKeyRasterType = 1025
+	modelProjected   = 1
+	rasterPixelPoint = 2
+)
+
+type tiffEntry struct {
+	typ    uint16
+	count  uint32
+	offset []byte // inline value or 4-byte offset
+}
+
+type tiffInfo struct {
+	order                     binary.ByteOrder
+	width, height             int
+	bits, format, compression int
+	predictor                 int
+	rowsPerStrip              int
+	tileWidth, tileLength     int
+	offsets, byteCounts       []uint64
+	grid                      grid
+}
+
+// geoTIFFTile reads only the header to index the tile's bounds.
+func geoTIFFTile(path string) (*tile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := readTIFFInfo(f)
+	if err != nil {
+		return nil, err
+	}
+	minLat, minLon, maxLat, maxLon := info.grid.bounds()
+	return &tile{
+		path:   path,
+		minLat: minLat, minLon: minLon, maxLat: maxLat, maxLon: maxLon,
+		decode: decodeGeoTIFF,
+	}, nil
+}
+
+func decodeGeoTIFF(path string) (*grid, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := readTIFFInfo(f)
+	if err != nil {
+		return nil, err
+	}
+	g := info.grid
+	g.data = make([]float32, g.rows*g.cols)
+
+	// Strips are tiles as wide as the image
+	bw, bh := info.tileWidth, info.tileLength
+	if bw == 0 {
+		bw, bh = info.width, info.rowsPerStrip
+	}
+	across := (info.width + bw - 1) / bw
+	bytesPerSample := info.bits / 8
+
+	for i, off := range info.offsets {
+		buf := make([]byte, info.byteCounts[i])
+		if _, err := f.ReadAt(buf, int64(off)); err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		if buf, err = decompress(buf, info.compression); err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		x0, y0 := (i%across)*bw, (i/across)*bh
+		rows := len(buf) / (bw * bytesPerSample)
+		for r := 0; r < rows && y0+r < info.height; r++ {
+			row := buf[r*bw*bytesPerSample : (r+1)*bw*bytesPerSample]
+			if info.predictor == 2 {
+				undoPredictor(row, info.order, bytesPerSample)
+			}
+			for c := 0; c < bw && x0+c < info.width; c++ {
+				g.data[(y0+r)*g.cols+x0+c] = info.sample(row[c*bytesPerSample:])
+			}
+		}
+	}
+	return &g, nil
+}
+
+func (t *tiffInfo) sample(b []byte) float32 {
+	switch {
+	case t.bits == 16 && t.format == 2:
+		return float32(int16(t.order.Uint16(b)))
+	case t.bits == 16:
+		return float32(t.order.Uint16(b))
+	case t.bits == 32 && t.format == 3:
+		return math.Float32frombits(t.order.Uint32(b))
+	case t.bits == 32 && t.format == 2:
+		return float32(int32(t.order.Uint32(b)))
+	default:
+		return float32(t.order.Uint32(b))
+	}
+}
+
+func decompress(b []byte, compression int) ([]byte, error) {
+	switch compression {
+	case 1:
+		return b, nil
+	case 8, 32946:
+		zr, err := zlib.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	default:
+		return nil, fmt.Errorf("unsupported TIFF compression %d", compression)
+	}
+}
+
+// undoPredictor reverses horizontal differencing on one row of integers.
+func undoPredictor(row []byte, order binary.ByteOrder, size int) {
+	for i := size; i+size <= len(row); i += size {
+		switch size {
+		case 2:
+			order.PutUint16(row[i:], order.Uint16(row[i:])+order.Uint16(row[i-2:]))
+		case 4:
+			order.PutUint32(row[i:], order.Uint32(row[i:])+order.Uint32(row[i-4:]))
+		}
+	}
+}
+
+func readTIFFInfo(r io.ReaderAt) (*tiffInfo, error) {
+	head := make([]byte, 8)
+	if _, err := r.ReadAt(head, 0); err != nil {
+		return nil, err
+	}
+	info := &tiffInfo{bits: 16, format: 1, compression: 1, predictor: 1}
+	switch string(head[:2]) {
+	case "II":
+		info.order = binary.LittleEndian
+	case "MM":
+		info.order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("not a TIFF file")
+	}
+	if info.order.Uint16(head[2:]) != 42 {
+		return nil, fmt.Errorf("only classic TIFF is supported, not BigTIFF")
+	}
+
+	ifd := int64(info.order.Uint32(head[4:]))
+	countBuf := make([]byte, 2)
+	if _, err := r.ReadAt(countBuf, ifd); err != nil {
+		return nil, err
+	}
+	n := int(info.order.Uint16(countBuf))
+	raw := make([]byte, n*12)
+	if _, err := r.ReadAt(raw, ifd+2); err != nil {
+		return nil, err
+	}
+
+	entries := make(map[uint16]tiffEntry, n)
+	for i := 0; i < n; i++ {
+		e := raw[i*12 : (i+1)*12]
+		entries[info.order.Uint16(e)] = tiffEntry{
+			typ:    info.order.Uint16(e[2:]),
+			count:  info.order.Uint32(e[4:]),
+			offset: e[8:12],
+		}
+	}
+	ints := func(tag uint16) ([]uint64, error) {
+		e, ok := entries[tag]
+		if !ok {
+			return nil, nil
+		}
+		return readInts(r, info.order, e)
+	}
+	first := func(tag uint16, into *int) error {
+		v, err := ints(tag)
+		if err == nil && len(v) > 0 {
+			*into = int(v[0])
+		}
+		return err
+	}
+
+	for tag, into := range map[uint16]*int{
+		tagImageWidth: &info.width, tagImageLength: &info.height,
+		tagBitsPerSample: &info.bits, tagSampleFormat: &info.format,
+		tagCompression: &info.compression, tagPredictor: &info.predictor,
+		tagRowsPerStrip: &info.rowsPerStrip,
+		tagTileWidth:    &info.tileWidth, tagTileLength: &info.tileLength,
+	} {
+		if err := first(tag, into); err != nil {
+			return nil, err
+		}
+	}
+	spp := 1
+	if err := first(tagSamplesPerPixel, &spp); err != nil {
+		return nil, err
+	}
+	if spp != 1 {
+		return nil, fmt.Errorf("expected a single-band DEM, got %d samples per pixel", spp)
+	}
+	if info.bits != 16 && info.bits != 32 {
+		return nil, fmt.Errorf("unsupported %d-bit samples", info.bits)
+	}
+	if info.predictor != 1 && info.predictor != 2 {
+		return nil, fmt.Errorf("unsupported TIFF predictor %d", info.predictor)
+	}
+
+	offTag, countTag := uint16(tagStripOffsets), uint16(tagStripByteCounts)
+	if info.tileWidth > 0 {
+		offTag, countTag = tagTileOffsets, tagTileByteCounts
+	} else if info.rowsPerStrip == 0 {
+		info.rowsPerStrip = info.height
+	}
+	var err error
+	if info.offsets, err = ints(offTag); err != nil {
+		return nil, err
+	}
+	if info.byteCounts, err = ints(countTag); err != nil {
+		return nil, err
+	}
+	if len(info.offsets) == 0 || len(info.offsets) != len(info.byteCounts) {
+		return nil, fmt.Errorf("missing or inconsistent image data offsets")
+	}
+
+	// Georeferencing
+	scale, err := readDoubles(r, info.order, entries, tagPixelScale)
+	if err != nil {
+		return nil, err
+	}
+	tie, err := readDoubles(r, info.order, entries, tagTiepoint)
+	if err != nil {
+		return nil, err
+	}
+	if len(scale) < 2 || len(tie) < 6 || scale[0] <= 0 || scale[1] <= 0 {
+		return nil, fmt.Errorf("missing ModelPixelScale/ModelTiepoint; not a GeoTIFF")
+	}
+	keys, err := ints(tagGeoKeyDirectory)
+	if err != nil {
+		return nil, err
+	}
+	pixelIsPoint := false
+	for i := 4; i+3 < len(keys); i += 4 {
+		if keys[i+1] != 0 {
+			continue // value stored in another tag
+		}
+		switch keys[i] {
+		case geoKeyModelType:
+			if keys[i+3] == modelProjected {
+				return nil, fmt.Errorf("projected GeoTIFFs are not supported; reproject to EPSG:4326")
+			}
+		case geoKeyRasterType:
+			pixelIsPoint = keys[i+3] == rasterPixelPoint
+		}
+	}
+
+	// Tiepoint (I,J) → (X,Y). With PixelIsArea it names a pixel corner, so
+	// sample centres sit half a pixel in.
+	west := tie[3] - tie[0]*scale[0]
+	north := tie[4] + tie[1]*scale[1]
+	if !pixelIsPoint {
+		west += scale[0] / 2
+		north -= scale[1] / 2
+	}
+	info.grid = grid{
+		north: north, west: west,
+		dLat: scale[1], dLon: scale[0],
+		rows: info.height, cols: info.width,
+	}
+
+	if e, ok := entries[tagGDALNoData]; ok {
+		b, err := valueBytes(r, info.order, e, 1)
+		if err != nil {
+			return nil, err
+		}
+		s := strings.TrimRight(string(b), "\x00 ")
+		if v, err := strconv.ParseFloat(s, 32); err == nil {
+			info.grid.noData, info.grid.hasNoData = float32(v), true
+		}
+	}
+	return info, nil
+}
+
+func readInts(r io.ReaderAt, order binary.ByteOrder, e tiffEntry) ([]uint64, error) {
+	size := map[uint16]int{1: 1, 3: 2, 4: 4}[e.typ]
+	if size == 0 {
+		return nil, fmt.Errorf("unexpected TIFF field type %d", e.typ)
+	}
+	b, err := valueBytes(r, order, e, size)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]uint64, e.count)
+	for i := range out {
+		switch size {
+		case 1:
+			out[i] = uint64(b[i])
+		case 2:
+			out[i] = uint64(order.Uint16(b[i*2:]))
+		case 4:
+			out[i] = uint64(order.Uint32(b[i*4:]))
+		}
+	}
+	return out, nil
+}
+
+func readDoubles(r io.ReaderAt, order binary.ByteOrder, entries map[uint16]tiffEntry, tag uint16) ([]float64, error) {
+	e, ok := entries[tag]
+	if !ok {
+		return nil, nil
+	}
+	if e.typ != 12 {
+		return nil, fmt.Errorf("tag %d: expected DOUBLE values", tag)
+	}
+	b, err := valueBytes(r, order, e, 8)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]float64, e.count)
+	for i := range out {
+		out[i] = math.Float64frombits(order.Uint64(b[i*8:]))
+	}
+	return out, nil
+}
+
+func valueBytes(r io.ReaderAt, order binary.ByteOrder, e tiffEntry, size int) ([]byte, error) {
+	n := int(e.count) * size
+	if n <= 4 {
+		return e.offset[:n], nil
+	}
+	b := make([]byte, n)
+	_, err := r.ReadAt(b, int64(order.Uint32(e.offset)))
+	return b, err
+}
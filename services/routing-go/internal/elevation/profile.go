@@ -0,0 +1,213 @@
+package elevation
+
+import (
+	"math"
+	"sort"
+)
+
+type Config struct {
+	// SpacingM is the distance between height samples along a route.
+	SpacingM float64
+	// GradeWindowM is the run over which grades are measured, so a single
+	// noisy DEM cell cannot produce a 40% "grade".
+	GradeWindowM float64
+	// HysteresisM ignores climbs and drops smaller than this when summing
+	// ascent and descent.
+	HysteresisM float64
+	// MaxPoints caps the profile returned to clients.
+	MaxPoints int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		SpacingM:     90, // SRTM3 resolution
+		GradeWindowM: 500,
+		HysteresisM:  5,
+		MaxPoints:    500,
+	}
+}
+
+// Profile summarises the terrain along a route.
+type Profile struct {
+	// Points are [distance_m, elevation_m] pairs from the start of the route.
+	Points   [][2]float64 `json:"points"`
+	AscentM  float64      `json:"ascent_m"`
+	DescentM float64      `json:"descent_m"`
+	MinM     float64      `json:"min_m"`
+	MaxM     float64      `json:"max_m"`
+	// MaxGradePct is the steepest climb and MaxDowngradePct the steepest
+	// descent, both as positive percentages.
+	MaxGradePct     float64 `json:"max_grade_pct"`
+	MaxDowngradePct float64 `json:"max_downgrade_pct"`
+	// Coverage is the share of samples that had DEM data; gaps are
+	// interpolated.
+	Coverage float64 `json:"coverage"`
+}
+
+// Steepest returns the larger of the climb and descent grades.
+func (p *Profile) Steepest() float64 {
+	return math.Max(p.MaxGradePct, p.MaxDowngradePct)
+}
+
+// At interpolates the profile's height at a distance along the route,
+// clamping to the ends.
+func (p *Profile) At(distanceM float64) float64 {
+	pts := p.Points
+	i := sort.Search(len(pts), func(i int) bool { return pts[i][0] >= distanceM })
+	switch {
+	case i == 0:
+		return pts[0][1]
+	case i == len(pts):
+		return pts[len(pts)-1][1]
+	}
+	a, b := pts[i-1], pts[i]
+	return a[1] + (b[1]-a[1])*(distanceM-a[0])/(b[0]-a[0])
+}
+
+// Build samples src along coords ([lon, lat] pairs). It returns nil when src
+// has no data anywhere on the route.
+func Build(src Source, coords [][2]float64, cfg Config) *Profile {
+	def := DefaultConfig()
+	if cfg.SpacingM <= 0 {
+		cfg.SpacingM = def.SpacingM
+	}
+	if cfg.GradeWindowM <= 0 {
+		cfg.GradeWindowM = def.GradeWindowM
+	}
+	if cfg.MaxPoints < 2 {
+		cfg.MaxPoints = def.MaxPoints
+	}
+	if src == nil || len(coords) == 0 {
+		return nil
+	}
+
+	pts, dists := resample(coords, cfg.SpacingM)
+	elev := make([]float64, len(pts))
+	known := make([]bool, len(pts))
+	valid := 0
+	for i, p := range pts {
+		elev[i], known[i] = src.Elevation(p[1], p[0])
+		if known[i] {
+			valid++
+		}
+	}
+	if valid == 0 {
+		return nil
+	}
+	fillGaps(elev, known, dists)
+
+	prof := &Profile{
+		MinM:     elev[0],
+		MaxM:     elev[0],
+		Coverage: round(float64(valid)/float64(len(pts)), 100),
+	}
+
+	ref := elev[0]
+	for _, e := range elev {
+		prof.MinM = math.Min(prof.MinM, e)
+		prof.MaxM = math.Max(prof.MaxM, e)
+		switch {
+		case e-ref >= cfg.HysteresisM:
+			prof.AscentM += e - ref
+			ref = e
+		case ref-e >= cfg.HysteresisM:
+			prof.DescentM += ref - e
+			ref = e
+		}
+	}
+
+	// Grade over a sliding window of at least GradeWindowM
+	j := 0
+	for i := range elev {
+		for j < len(elev)-1 && dists[j]-dists[i] < cfg.GradeWindowM {
+			j++
+		}
+		run := dists[j] - dists[i]
+		if run <= 0 || (run < cfg.GradeWindowM && i > 0) {
+			break
+		}
+		grade := (elev[j] - elev[i]) / run * 100
+		prof.MaxGradePct = math.Max(prof.MaxGradePct, grade)
+		prof.MaxDowngradePct = math.Max(prof.MaxDowngradePct, -grade)
+	}
+
+	step := (len(pts) + cfg.MaxPoints - 1) / cfg.MaxPoints
+	for i := 0; i < len(pts); i += step {
+		prof.Points = append(prof.Points, [2]float64{math.Round(dists[i]), round(elev[i], 10)})
+	}
+	if last := len(pts) - 1; last%step != 0 {
+		prof.Points = append(prof.Points, [2]float64{math.Round(dists[last]), round(elev[last], 10)})
+	}
+
+	prof.AscentM = math.Round(prof.AscentM)
+	prof.DescentM = math.Round(prof.DescentM)
+	prof.MinM = round(prof.MinM, 10)
+	prof.MaxM = round(prof.MaxM, 10)
+	prof.MaxGradePct = round(prof.MaxGradePct, 10)
+	prof.MaxDowngradePct = round(prof.MaxDowngradePct, 10)
+	return prof
+}
+
+// resample walks coords and returns points every spacingM metres, plus the
+// final vertex, with their distance from the start.
+func resample(coords [][2]float64, spacingM float64) ([][2]float64, []float64) {
+	pts := [][2]float64{coords[0]}
+	dists := []float64{0}
+	travelled, next := 0.0, spacingM
+	for i := 1; i < len(coords); i++ {
+		a, b := coords[i-1], coords[i]
+		seg := distanceM(a, b)
+		for seg > 0 && next <= travelled+seg {
+			f := (next - travelled) / seg
+			pts = append(pts, [2]float64{a[0] + (b[0]-a[0])*f, a[1] + (b[1]-a[1])*f})
+			dists = append(dists, next)
+			next += spacingM
+		}
+		travelled += seg
+	}
+	if travelled > dists[len(dists)-1] {
+		pts = append(pts, coords[len(coords)-1])
+		dists = append(dists, travelled)
+	}
+	return pts, dists
+}
+
+// fillGaps interpolates voids linearly by distance and extends the nearest
+// known height past either end.
+func fillGaps(elev []float64, known []bool, dists []float64) {
+	prev := -1
+	for i := range elev {
+		if !known[i] {
+			continue
+		}
+		switch {
+		case prev < 0:
+			for k := 0; k < i; k++ {
+				elev[k] = elev[i]
+			}
+		case i-prev > 1:
+			for k := prev + 1; k < i; k++ {
+				f := (dists[k] - dists[prev]) / (dists[i] - dists[prev])
+				elev[k] = elev[prev] + (elev[i]-elev[prev])*f
+			}
+		}
+		prev = i
+	}
+	for k := prev + 1; k < len(elev); k++ {
+		elev[k] = elev[prev]
+	}
+}
+
+// distanceM is the haversine distance between [lon, lat] points.
+func distanceM(a, b [2]float64) float64 {
+	const R = 6371000.0
+	dLat := (b[1] - a[1]) * math.Pi / 180
+	dLon := (b[0] - a[0]) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(a[1]*math.Pi/180)*math.Cos(b[1]*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * R * math.Asin(math.Sqrt(h))
+}
+
+func round(v, scale float64) float64 {
+	return math.Round(v*scale) / scale
+}
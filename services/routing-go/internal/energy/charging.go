@@ -0,0 +1,257 @@
+package energy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+)
+
+// ── Charging Stops ──
+
+// Charger is a DC fast-charging site.
+type Charger struct {
+	ID      string  `json:"id"`
+	Name    string  `json:"name"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+	PowerKw float64 `json:"power_kw"`
+}
+
+// LoadChargers reads a JSON array of chargers.
+func LoadChargers(path string) ([]Charger, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var chargers []Charger
+	if err := json.Unmarshal(data, &chargers); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for i, c := range chargers {
+		if c.ID == "" || c.PowerKw <= 0 {
+			return nil, fmt.Errorf("charger %d: id and a positive power_kw are required", i)
+		}
+	}
+	return chargers, nil
+}
+
+// Track is a route's geometry with the net energy used to reach each vertex.
+type Track struct {
+	Coords [][2]float64
+	DistM  []float64
+	KWh    []float64
+}
+
+// NewTrack maps cumulative leg energy (see Vehicle.Cumulative) onto coords.
+// Leg distances are rescaled to the geometry's length, since upstream step
+// distances and the polyline rarely agree exactly.
+func NewTrack(coords [][2]float64, legDistM, legKWh []float64) Track {
+	t := Track{Coords: coords, DistM: make([]float64, len(coords)), KWh: make([]float64, len(coords))}
+	for i := 1; i < len(coords); i++ {
+		t.DistM[i] = t.DistM[i-1] + distanceM(coords[i-1], coords[i])
+	}
+	if len(coords) == 0 || len(legDistM) < 2 {
+		return t
+	}
+	scale := 1.0
+	if total := t.DistM[len(coords)-1]; total > 0 {
+		scale = legDistM[len(legDistM)-1] / total
+	}
+	for i, d := range t.DistM {
+		x := d * scale
+		j := sort.SearchFloat64s(legDistM, x)
+		switch {
+		case j == 0:
+			t.KWh[i] = legKWh[0]
+		case j >= len(legDistM):
+			t.KWh[i] = legKWh[len(legKWh)-1]
+		default:
+			span := legDistM[j] - legDistM[j-1]
+			f := 0.0
+			if span > 0 {
+				f = (x - legDistM[j-1]) / span
+			}
+			t.KWh[i] = legKWh[j-1] + (legKWh[j]-legKWh[j-1])*f
+		}
+	}
+	return t
+}
+
+// PlanConfig tunes the charging planner. SoC values are percentages.
+type PlanConfig struct {
+	StartSoCPct   float64
+	ReserveSoCPct float64
+	// ChargeToPct is the most a stop charges to; fast charging slows
+	// sharply above 80%.
+	ChargeToPct float64
+	// MaxDetourM is how far off the route a charger may be.
+	MaxDetourM float64
+	// DetourKmh is the assumed speed to and from an off-route charger.
+	DetourKmh float64
+}
+
+// DefaultPlanConfig starts at 90%, keeps a 10% reserve and fast-charges to
+// at most 80%.
+func DefaultPlanConfig() PlanConfig {
+	return PlanConfig{
+		StartSoCPct:   90,
+		ReserveSoCPct: 10,
+		ChargeToPct:   80,
+		MaxDetourM:    5000,
+		DetourKmh:     50,
+	}
+}
+
+// Stop is a charging stop inserted along a route.
+type Stop struct {
+	Charger         Charger `json:"charger"`
+	DistanceAlongM  float64 `json:"distance_along_m"`
+	DetourM         float64 `json:"detour_m"`
+	ArrivalSoCPct   float64 `json:"arrival_soc_pct"`
+	DepartureSoCPct float64 `json:"departure_soc_pct"`
+	ChargeKWh       float64 `json:"charge_kwh"`
+	// ChargeS is time plugged in; DetourS is the extra driving to reach it.
+	ChargeS float64 `json:"charge_s"`
+	DetourS float64 `json:"detour_s"`
+}
+
+// Plan is the charging schedule for one route.
+type Plan struct {
+	Stops         []Stop  `json:"charging_stops"`
+	ArrivalSoCPct float64 `json:"arrival_soc_pct"`
+	// AddedS is charging plus detour time.
+	AddedS float64 `json:"charging_s"`
+}
+
+type candidate struct {
+	charger Charger
+	idx     int
+	offM    float64
+}
+
+// PlanCharging walks the track and, whenever the battery would fall below
+// the reserve, stops at the farthest charger it can still reach. It fails if
+// some stretch has no reachable charger.
+func PlanCharging(track Track, chargers []Charger, v Vehicle, cfg PlanConfig) (*Plan, error) {
+	n := len(track.Coords)
+	if n == 0 {
+		return nil, fmt.Errorf("route has no geometry")
+	}
+	capKWh := v.BatteryKWh
+	reserve := capKWh * cfg.ReserveSoCPct / 100
+	soc := capKWh * cfg.StartSoCPct / 100
+	if soc < reserve {
+		return nil, fmt.Errorf("starting charge %.0f%% is below the %.0f%% reserve", cfg.StartSoCPct, cfg.ReserveSoCPct)
+	}
+
+	cands := nearRoute(track, chargers, cfg.MaxDetourM)
+	plan := &Plan{Stops: []Stop{}}
+	pos, next := 0, 0
+	for {
+		// First vertex where the battery would dip below the reserve
+		short := -1
+		for j := pos + 1; j < n; j++ {
+			if soc-(track.KWh[j]-track.KWh[pos]) < reserve {
+				short = j
+				break
+			}
+		}
+		if short < 0 {
+			plan.ArrivalSoCPct = round((soc-(track.KWh[n-1]-track.KWh[pos]))/capKWh*100, 10)
+			return plan, nil
+		}
+
+		// Farthest reachable charger before that point
+		best := -1
+		for ci := next; ci < len(cands) && cands[ci].idx < short; ci++ {
+			c := cands[ci]
+			detourKWh := v.detourKWh(c.offM, cfg.DetourKmh)
+			if soc-(track.KWh[c.idx]-track.KWh[pos])-detourKWh/2 < reserve {
+				continue
+			}
+			if best < 0 || c.idx > cands[best].idx ||
+				(c.idx == cands[best].idx && c.charger.PowerKw > cands[best].charger.PowerKw) {
+				best = ci
+			}
+		}
+		if best < 0 {
+			return nil, fmt.Errorf("no reachable charger: the battery reaches its %.0f%% reserve %.0f km into the route",
+				cfg.ReserveSoCPct, track.DistM[short]/1000)
+		}
+
+		c := cands[best]
+		detourKWh := v.detourKWh(c.offM, cfg.DetourKmh)
+		arrive := soc - (track.KWh[c.idx] - track.KWh[pos]) - detourKWh/2
+		// Charge only what the rest of the trip needs, up to ChargeToPct
+		need := track.KWh[n-1] - track.KWh[c.idx] + reserve + detourKWh/2
+		target := math.Max(arrive, math.Min(capKWh*cfg.ChargeToPct/100, need))
+
+		stop := Stop{
+			Charger:         c.charger,
+			DistanceAlongM:  math.Round(track.DistM[c.idx]),
+			DetourM:         math.Round(2 * c.offM),
+			ArrivalSoCPct:   round(arrive/capKWh*100, 10),
+			DepartureSoCPct: round(target/capKWh*100, 10),
+			ChargeKWh:       round(target-arrive, 10),
+			ChargeS:         math.Round(chargeSeconds(arrive, target, capKWh, math.Min(c.charger.PowerKw, v.MaxChargeKw))),
+			DetourS:         math.Round(2 * c.offM / (cfg.DetourKmh / 3.6)),
+		}
+		plan.Stops = append(plan.Stops, stop)
+		plan.AddedS += stop.ChargeS + stop.DetourS
+
+		soc = target - detourKWh/2
+		pos, next = c.idx, best+1
+	}
+}
+
+// nearRoute finds, for each charger within maxOffM of the track, its
+// nearest vertex, sorted along the route.
+func nearRoute(track Track, chargers []Charger, maxOffM float64) []candidate {
+	// Cheap bounding-box filter before measuring distances
+	minLon, minLat, maxLon, maxLat := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, c := range track.Coords {
+		minLon, maxLon = math.Min(minLon, c[0]), math.Max(maxLon, c[0])
+		minLat, maxLat = math.Min(minLat, c[1]), math.Max(maxLat, c[1])
+	}
+	padLat := maxOffM / 111000
+	padLon := padLat / math.Max(math.Cos(maxLat*math.Pi/180), 0.1)
+
+	var cands []candidate
+	for _, ch := range chargers {
+		if ch.Lat < minLat-padLat || ch.Lat > maxLat+padLat || ch.Lon < minLon-padLon || ch.Lon > maxLon+padLon {
+			continue
+		}
+		pt := [2]float64{ch.Lon, ch.Lat}
+		best, bestM := -1, math.Inf(1)
+		for i, c := range track.Coords {
+			if d := distanceM(pt, c); d < bestM {
+				best, bestM = i, d
+			}
+		}
+		if bestM <= maxOffM {
+			cands = append(cands, candidate{charger: ch, idx: best, offM: bestM})
+		}
+	}
+	sort.SliceStable(cands, func(i, j int) bool { return cands[i].idx < cands[j].idx })
+	return cands
+}
+
+// detourKWh is the energy to drive to an off-route charger and back.
+func (v Vehicle) detourKWh(offM, kmh float64) float64 {
+	t, _, a := v.legKWh(Leg{DistanceM: 2 * offM, SpeedKmh: kmh})
+	return t + a
+}
+
+// chargeSeconds models a DC charging curve: full power to 80%, half power
+// above.
+func chargeSeconds(fromKWh, toKWh, capKWh, powerKw float64) float64 {
+	if toKWh <= fromKWh || powerKw <= 0 {
+		return 0
+	}
+	knee := 0.8 * capKWh
+	fast := math.Max(0, math.Min(toKWh, knee)-fromKWh)
+	slow := math.Max(0, toKWh-math.Max(fromKWh, knee))
+	return (fast/powerKw + slow/(powerKw/2)) * 3600
+}
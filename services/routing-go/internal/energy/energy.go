@@ -0,0 +1,157 @@
+package energy
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	gravity    = 9.81 // m/s²
+	airDensity = 1.2  // kg/m³ near sea level
+	joulesKWh  = 3.6e6
+)
+
+// Vehicle holds the parameters of the energy model for one EV type.
+type Vehicle struct {
+	Name   string
+	MassKg float64
+	// DragArea is the drag coefficient times frontal area (CdA), in m².
+	DragArea          float64
+	RollingResistance float64
+	// DrivetrainEff is the share of battery energy that reaches the wheels;
+	// RegenEff the share of braking or descent energy recovered.
+	DrivetrainEff float64
+	RegenEff      float64
+	// AuxKw is the constant draw of HVAC and electronics.
+	AuxKw float64
+	// BatteryKWh is usable capacity; MaxChargeKw caps DC charging power.
+	BatteryKWh  float64
+	MaxChargeKw float64
+}
+
+// Presets are typical fleet EVs: a sedan, a delivery van and a medium-duty
+// box truck.
+var Presets = map[string]Vehicle{
+	"car": {
+		Name: "car", MassKg: 2100, DragArea: 0.6, RollingResistance: 0.009,
+		DrivetrainEff: 0.9, RegenEff: 0.65, AuxKw: 0.6,
+		BatteryKWh: 75, MaxChargeKw: 170,
+	},
+	"van": {
+		Name: "van", MassKg: 3500, DragArea: 1.3, RollingResistance: 0.01,
+		DrivetrainEff: 0.88, RegenEff: 0.6, AuxKw: 1,
+		BatteryKWh: 110, MaxChargeKw: 115,
+	},
+	"truck": {
+		Name: "truck", MassKg: 12000, DragArea: 5, RollingResistance: 0.007,
+		DrivetrainEff: 0.88, RegenEff: 0.6, AuxKw: 2,
+		BatteryKWh: 300, MaxChargeKw: 150,
+	},
+}
+
+// LookupVehicle returns the named preset.
+func LookupVehicle(name string) (Vehicle, error) {
+	v, ok := Presets[name]
+	if !ok {
+		names := make([]string, 0, len(Presets))
+		for n := range Presets {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Vehicle{}, fmt.Errorf("unknown EV %q (want %s)", name, strings.Join(names, ", "))
+	}
+	return v, nil
+}
+
+// Leg is a stretch driven at a constant speed with a net height change.
+type Leg struct {
+	DistanceM float64
+	SpeedKmh  float64
+	ClimbM    float64
+}
+
+// Estimate is the battery energy a trip needs. KWh is net of regeneration.
+type Estimate struct {
+	KWh         float64 `json:"kwh"`
+	TractionKWh float64 `json:"traction_kwh"`
+	RegenKWh    float64 `json:"regen_kwh"`
+	AuxKWh      float64 `json:"aux_kwh"`
+	WhPerKm     float64 `json:"wh_per_km"`
+}
+
+// legKWh splits a leg's battery energy into traction, regeneration
+// (recovered, positive) and auxiliary draw.
+func (v Vehicle) legKWh(l Leg) (traction, regen, aux float64) {
+	if l.DistanceM <= 0 || l.SpeedKmh <= 0 {
+		return 0, 0, 0
+	}
+	speed := l.SpeedKmh / 3.6
+	grade := math.Atan2(l.ClimbM, l.DistanceM)
+
+	rolling := v.MassKg * gravity * v.RollingResistance * math.Cos(grade) * l.DistanceM
+	drag := 0.5 * airDensity * v.DragArea * speed * speed * l.DistanceM
+	potential := v.MassKg * gravity * l.ClimbM
+
+	wheel := (rolling + drag + potential) / joulesKWh
+	if wheel >= 0 {
+		traction = wheel / v.DrivetrainEff
+	} else {
+		regen = -wheel * v.RegenEff
+	}
+	aux = v.AuxKw * (l.DistanceM / speed) / 3600
+	return traction, regen, aux
+}
+
+// Estimate sums the energy of every leg.
+func (v Vehicle) Estimate(legs []Leg) Estimate {
+	var e Estimate
+	distM := 0.0
+	for _, l := range legs {
+		t, r, a := v.legKWh(l)
+		e.TractionKWh += t
+		e.RegenKWh += r
+		e.AuxKWh += a
+		distM += math.Max(l.DistanceM, 0)
+	}
+	e.KWh = e.TractionKWh - e.RegenKWh + e.AuxKWh
+	if distM > 0 {
+		e.WhPerKm = round(e.KWh*1000/(distM/1000), 10)
+	}
+	e.KWh = round(e.KWh, 100)
+	e.TractionKWh = round(e.TractionKWh, 100)
+	e.RegenKWh = round(e.RegenKWh, 100)
+	e.AuxKWh = round(e.AuxKWh, 100)
+	return e
+}
+
+// Cumulative returns the distance and net battery energy used at the end of
+// each leg. Energy can fall on descents as regeneration recharges.
+func (v Vehicle) Cumulative(legs []Leg) (distM, kwh []float64) {
+	d, e := 0.0, 0.0
+	distM = make([]float64, 0, len(legs)+1)
+	kwh = make([]float64, 0, len(legs)+1)
+	distM, kwh = append(distM, 0), append(kwh, 0)
+	for _, l := range legs {
+		t, r, a := v.legKWh(l)
+		d += math.Max(l.DistanceM, 0)
+		e += t - r + a
+		distM, kwh = append(distM, d), append(kwh, e)
+	}
+	return distM, kwh
+}
+
+func round(v, scale float64) float64 {
+	return math.Round(v*scale) / scale
+}
+
+// distanceM is the haversine distance between [lon, lat] points.
+func distanceM(a, b [2]float64) float64 {
+	const R = 6371000.0
+	dLat := (b[1] - a[1]) * math.Pi / 180
+	dLon := (b[0] - a[0]) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(a[1]*math.Pi/180)*math.Cos(b[1]*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * R * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,137 @@
+package energy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimate_Flat(t *testing.T) {
+	car := Presets["car"]
+	e := car.Estimate([]Leg{{DistanceM: 100000, SpeedKmh: 110}})
+
+	// Rolling ~186 N + drag ~336 N at 110 km/h, through a 90% drivetrain
+	assert.InDelta(t, 167, e.WhPerKm, 5)
+	assert.Equal(t, 0.0, e.RegenKWh)
+	assert.InDelta(t, 0.55, e.AuxKWh, 0.01)
+
+	slower := car.Estimate([]Leg{{DistanceM: 100000, SpeedKmh: 80}})
+	assert.Less(t, slower.KWh, e.KWh, "drag grows with the square of speed")
+
+	truck := Presets["truck"].Estimate([]Leg{{DistanceM: 100000, SpeedKmh: 90}})
+	assert.Greater(t, truck.WhPerKm, 3*e.WhPerKm)
+}
+
+func TestEstimate_Hills(t *testing.T) {
+	car := Presets["car"]
+	flat := car.Estimate([]Leg{{DistanceM: 20000, SpeedKmh: 90}, {DistanceM: 20000, SpeedKmh: 90}})
+	climb := car.Estimate([]Leg{{DistanceM: 20000, SpeedKmh: 90, ClimbM: 600}})
+	overPass := car.Estimate([]Leg{
+		{DistanceM: 20000, SpeedKmh: 90, ClimbM: 600},
+		{DistanceM: 20000, SpeedKmh: 90, ClimbM: -600},
+	})
+
+	// 2100 kg × 9.81 × 600 m ≈ 3.4 kWh at the wheels
+	assert.InDelta(t, 3.43/0.9, climb.TractionKWh-car.Estimate([]Leg{{DistanceM: 20000, SpeedKmh: 90}}).TractionKWh, 0.05)
+	assert.Greater(t, overPass.RegenKWh, 0.0)
+	assert.Greater(t, overPass.KWh, flat.KWh, "regeneration never recovers everything")
+}
+
+func TestCumulative(t *testing.T) {
+	van := Presets["van"]
+	legs := []Leg{
+		{DistanceM: 5000, SpeedKmh: 60, ClimbM: 200},
+		{DistanceM: 5000, SpeedKmh: 60, ClimbM: -200},
+	}
+	dist, kwh := van.Cumulative(legs)
+	assert.Equal(t, []float64{0, 5000, 10000}, dist)
+	assert.Greater(t, kwh[1], kwh[2], "descent recharges the battery")
+	assert.InDelta(t, van.Estimate(legs).KWh, kwh[2], 0.01)
+}
+
+func TestLookupVehicle(t *testing.T) {
+	v, err := LookupVehicle("van")
+	assert.NoError(t, err)
+	assert.Equal(t, 110.0, v.BatteryKWh)
+
+	_, err = LookupVehicle("hovercraft")
+	assert.EqualError(t, err, `unknown EV "hovercraft" (want car, truck, van)`)
+}
+
+// eastbound builds a ~500 km track along 33°N using 200 Wh/km.
+func eastbound() Track {
+	var coords [][2]float64
+	for lon := -114.0; lon <= -108.6; lon += 0.01 {
+		coords = append(coords, [2]float64{lon, 33})
+	}
+	t := NewTrack(coords, nil, nil)
+	total := t.DistM[len(t.DistM)-1]
+	return NewTrack(coords, []float64{0, total}, []float64{0, total / 1000 * 0.2})
+}
+
+func chargerAtKm(tr Track, id string, km float64) Charger {
+	for i, d := range tr.DistM {
+		if d >= km*1000 {
+			// ~1 km north of the road
+			return Charger{ID: id, Name: id, Lat: tr.Coords[i][1] + 0.009, Lon: tr.Coords[i][0], PowerKw: 150}
+		}
+	}
+	panic("beyond the track")
+}
+
+func TestPlanCharging_FarthestReachable(t *testing.T) {
+	tr := eastbound()
+	assert.InDelta(t, 503, tr.DistM[len(tr.DistM)-1]/1000, 3)
+
+	chargers := []Charger{chargerAtKm(tr, "km150", 150), chargerAtKm(tr, "km280", 280), chargerAtKm(tr, "km400", 400)}
+	plan, err := PlanCharging(tr, chargers, Presets["car"], DefaultPlanConfig())
+	require.NoError(t, err)
+
+	// 67.5 kWh usable above reserve 7.5 kWh gives 300 km; km400 is out of reach
+	require.Len(t, plan.Stops, 1)
+	stop := plan.Stops[0]
+	assert.Equal(t, "km280", stop.Charger.ID)
+	assert.InDelta(t, 2000, stop.DetourM, 50)
+	assert.InDelta(t, 15, stop.ArrivalSoCPct, 1)
+	// Only enough for the remaining ~223 km plus reserve
+	assert.InDelta(t, 70, stop.DepartureSoCPct, 1.5)
+	assert.Greater(t, stop.ChargeS, 0.0)
+	assert.InDelta(t, 10, plan.ArrivalSoCPct, 0.5)
+	assert.Equal(t, stop.ChargeS+stop.DetourS, plan.AddedS)
+}
+
+func TestPlanCharging_NoStopNeeded(t *testing.T) {
+	tr := eastbound()
+	v := Presets["truck"] // 300 kWh
+	plan, err := PlanCharging(tr, nil, v, DefaultPlanConfig())
+	require.NoError(t, err)
+	assert.Empty(t, plan.Stops)
+	assert.InDelta(t, 90-100.6/3, plan.ArrivalSoCPct, 1)
+}
+
+func TestPlanCharging_Unreachable(t *testing.T) {
+	tr := eastbound()
+	_, err := PlanCharging(tr, []Charger{chargerAtKm(tr, "km400", 400)}, Presets["car"], DefaultPlanConfig())
+	assert.ErrorContains(t, err, "no reachable charger")
+
+	cfg := DefaultPlanConfig()
+	cfg.StartSoCPct = 5
+	_, err = PlanCharging(tr, nil, Presets["car"], cfg)
+	assert.ErrorContains(t, err, "below the 10% reserve")
+}
+
+func TestLoadChargers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "chargers.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"id":"flg-1","name":"Flagstaff","lat":35.19,"lon":-111.65,"power_kw":250}]`), 0o644))
+	chargers, err := LoadChargers(path)
+	require.NoError(t, err)
+	assert.Equal(t, 250.0, chargers[0].PowerKw)
+
+	require.NoError(t, os.WriteFile(path, []byte(`[{"id":"x"}]`), 0o644))
+	_, err = LoadChargers(path)
+	assert.Error(t, err)
+}
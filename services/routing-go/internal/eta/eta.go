@@ -0,0 +1,222 @@
+package eta
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// RoadClass is a coarse functional road class used to pick a cruising speed.
+type RoadClass string
+
+const (
+	Motorway RoadClass = "motorway"
+	Highway  RoadClass = "highway"
+	Arterial RoadClass = "arterial"
+	Local    RoadClass = "local"
+)
+
+// classSpeedKmh is the typical cruising speed of a car on each road class.
+var classSpeedKmh = map[RoadClass]float64{
+	Motorway: 110,
+	Highway:  95,
+	Arterial: 60,
+	Local:    40,
+}
+
+// Segment is one stretch of road along a route, usually an OSRM step.
+type Segment struct {
+	DistanceM float64   `json:"distance_m"`
+	DurationS float64   `json:"duration_s"` // upstream estimate, for comparison
+	Class     RoadClass `json:"class"`
+	// Intersections crossed along the segment.
+	Intersections int     `json:"intersections"`
+	Lat           float64 `json:"lat"`
+	Lon           float64 `json:"lon"`
+}
+
+// ClassifyStep infers a road class from an OSRM step's route reference,
+// intersection classes and upstream speed.
+func ClassifyStep(ref string, classes []string, speedKmh float64) RoadClass {
+	for _, c := range classes {
+		if c == "motorway" {
+			return Motorway
+		}
+	}
+	ref = strings.ToUpper(strings.TrimSpace(ref))
+	switch {
+	case strings.HasPrefix(ref, "I "), strings.HasPrefix(ref, "I-"):
+		return Motorway
+	case strings.HasPrefix(ref, "US"), strings.HasPrefix(ref, "SR"), strings.HasPrefix(ref, "AZ"):
+		return Highway
+	case speedKmh >= 70:
+		return Highway
+	case speedKmh >= 45:
+		return Arterial
+	default:
+		return Local
+	}
+}
+
+// VehicleProfile mirrors the head unit's VehicleFactory types.
+type VehicleProfile struct {
+	Type   string
+	MaxKmh float64
+	// ClassFactor scales the car cruising speed per road class.
+	ClassFactor map[RoadClass]float64
+	// UrbanPenalty is the extra share of driving time in fully dense areas.
+	UrbanPenalty float64
+	// IntersectionDelayS is the mean delay at an urban intersection.
+	IntersectionDelayS float64
+	// BreakProbability per 100 km and BreakDurationMin match the UI's ETA.
+	BreakProbability float64
+	BreakDurationMin float64
+}
+
+var Profiles = map[string]VehicleProfile{
+	"car": {
+		Type:               "car",
+		MaxKmh:             120,
+		ClassFactor:        map[RoadClass]float64{Motorway: 1, Highway: 1, Arterial: 1, Local: 1},
+		UrbanPenalty:       0.25,
+		IntersectionDelayS: 6,
+		BreakProbability:   0.05,
+		BreakDurationMin:   5,
+	},
+	"truck": {
+		Type:               "truck",
+		MaxKmh:             90,
+		ClassFactor:        map[RoadClass]float64{Motorway: 0.9, Highway: 0.85, Arterial: 0.85, Local: 0.8},
+		UrbanPenalty:       0.35,
+		IntersectionDelayS: 12,
+		BreakProbability:   0.15,
+		BreakDurationMin:   15,
+	},
+	"motorcycle": {
+		Type:               "motorcycle",
+		MaxKmh:             140,
+		ClassFactor:        map[RoadClass]float64{Motorway: 1.05, Highway: 1.05, Arterial: 1, Local: 1},
+		UrbanPenalty:       0.15,
+		IntersectionDelayS: 4,
+		BreakProbability:   0.03,
+		BreakDurationMin:   3,
+	},
+}
+
+// LookupProfile returns the profile for a vehicle type, defaulting to car.
+func LookupProfile(vehicle string) (VehicleProfile, error) {
+	if vehicle == "" {
+		vehicle = "car"
+	}
+	p, ok := Profiles[vehicle]
+	if !ok {
+		return VehicleProfile{}, fmt.Errorf("unknown vehicle %q (want car, truck or motorcycle)", vehicle)
+	}
+	return p, nil
+}
+
+// ClassBreakdown is the distance and time driven on one road class.
+type ClassBreakdown struct {
+	DistanceM float64 `json:"distance_m"`
+	DurationS float64 `json:"duration_s"`
+	SpeedKmh  float64 `json:"speed_kmh"`
+}
+
+// Breakdown explains how an ETA was derived. TotalS is the sum of driving,
+// urban penalty, intersection delay and expected breaks.
+type Breakdown struct {
+	Vehicle            string                       `json:"vehicle"`
+	DrivingS           float64                      `json:"driving_s"`
+	UrbanPenaltyS      float64                      `json:"urban_penalty_s"`
+	IntersectionDelayS float64                      `json:"intersection_delay_s"`
+	Intersections      int                          `json:"intersections"`
+	BreakS             float64                      `json:"break_s"`
+	TotalS             float64                      `json:"total_s"`
+	UpstreamS          float64                      `json:"upstream_s"`
+	ByClass            map[RoadClass]ClassBreakdown `json:"by_class"`
+}
+
+// Estimator combines road-class speeds with urban density. Density returns
+// 0 for open country up to 1 for a dense city centre; nil means rural.
+type Estimator struct {
+	Density func(lat, lon float64) float64
+}
+
+func (e *Estimator) density(lat, lon float64) float64 {
+	if e == nil || e.Density == nil {
+		return 0
+	}
+	return math.Max(0, math.Min(1, e.Density(lat, lon)))
+}
+
+// Estimate derives an ETA for the segments driven by vehicle v.
+func (e *Estimator) Estimate(segs []Segment, v VehicleProfile) Breakdown {
+	b := Breakdown{Vehicle: v.Type, ByClass: make(map[RoadClass]ClassBreakdown)}
+	totalM := 0.0
+
+	for _, seg := range segs {
+		if seg.DistanceM <= 0 {
+			continue
+		}
+		speed := v.SpeedOn(seg)
+		driving := seg.DistanceM / 1000 / speed * 3600
+		dens := e.density(seg.Lat, seg.Lon)
+
+		b.DrivingS += driving
+		b.UrbanPenaltyS += driving * v.UrbanPenalty * dens
+		// Rural junctions rarely stop traffic; urban ones usually have signals.
+		b.IntersectionDelayS += float64(seg.Intersections) * v.IntersectionDelayS * (0.2 + 0.8*dens)
+		b.Intersections += seg.Intersections
+		b.UpstreamS += seg.DurationS
+
+		cb := b.ByClass[seg.Class]
+		cb.DistanceM += seg.DistanceM
+		cb.DurationS += driving
+		b.ByClass[seg.Class] = cb
+		totalM += seg.DistanceM
+	}
+
+	for class, cb := range b.ByClass {
+		if cb.DurationS > 0 {
+			cb.SpeedKmh = round1(cb.DistanceM / 1000 / (cb.DurationS / 3600))
+		}
+		cb.DurationS = round1(cb.DurationS)
+		b.ByClass[class] = cb
+	}
+
+	// Same expectation as VehicleFactory.calculateETA in the head unit
+	b.BreakS = math.Floor(totalM/100000) * v.BreakProbability * v.BreakDurationMin * 60
+
+	b.TotalS = round1(b.DrivingS + b.UrbanPenaltyS + b.IntersectionDelayS + b.BreakS)
+	b.DrivingS = round1(b.DrivingS)
+	b.UrbanPenaltyS = round1(b.UrbanPenaltyS)
+	b.IntersectionDelayS = round1(b.IntersectionDelayS)
+	b.BreakS = round1(b.BreakS)
+	b.UpstreamS = round1(b.UpstreamS)
+	return b
+}
+
+// SpeedOn is the cruising speed for a segment: the class speed adjusted for
+// the vehicle, capped by the vehicle's top speed and by the upstream speed,
+// which reflects the posted limit.
+func (v VehicleProfile) SpeedOn(seg Segment) float64 {
+	class := seg.Class
+	if _, ok := classSpeedKmh[class]; !ok {
+		class = Arterial
+	}
+	factor := v.ClassFactor[class]
+	if factor == 0 {
+		factor = 1
+	}
+	speed := math.Min(classSpeedKmh[class]*factor, v.MaxKmh)
+	if seg.DurationS > 0 {
+		if upstream := seg.DistanceM / 1000 / (seg.DurationS / 3600); upstream > 5 && upstream < speed {
+			speed = upstream
+		}
+	}
+	return speed
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,68 @@
+package eta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyStep(t *testing.T) {
+	assert.Equal(t, Motorway, ClassifyStep("", []string{"motorway"}, 50))
+	assert.Equal(t, Motorway, ClassifyStep("I 10", nil, 0))
+	assert.Equal(t, Highway, ClassifyStep("SR 87", nil, 0))
+	assert.Equal(t, Highway, ClassifyStep("", nil, 85))
+	assert.Equal(t, Arterial, ClassifyStep("", nil, 55))
+	assert.Equal(t, Local, ClassifyStep("", nil, 25))
+}
+
+func TestLookupProfile(t *testing.T) {
+	p, err := LookupProfile("")
+	assert.NoError(t, err)
+	assert.Equal(t, "car", p.Type)
+
+	p, err = LookupProfile("truck")
+	assert.NoError(t, err)
+	assert.Equal(t, 90.0, p.MaxKmh)
+
+	_, err = LookupProfile("drone")
+	assert.Error(t, err)
+}
+
+func TestEstimate_RuralMotorway(t *testing.T) {
+	e := &Estimator{}
+	segs := []Segment{{DistanceM: 110000, DurationS: 3600, Class: Motorway, Intersections: 2}}
+
+	car := e.Estimate(segs, Profiles["car"])
+	// 110 km at 110 km/h, two rural junctions at 20% of 6 s, one 100 km break chance
+	assert.InDelta(t, 3600.0, car.DrivingS, 0.1)
+	assert.Equal(t, 0.0, car.UrbanPenaltyS)
+	assert.InDelta(t, 2.4, car.IntersectionDelayS, 0.01)
+	assert.InDelta(t, 15.0, car.BreakS, 0.01)
+	assert.InDelta(t, 3617.4, car.TotalS, 0.1)
+	assert.Equal(t, 3600.0, car.UpstreamS)
+	assert.Equal(t, 110.0, car.ByClass[Motorway].SpeedKmh)
+
+	truck := e.Estimate(segs, Profiles["truck"])
+	assert.Equal(t, 90.0, truck.ByClass[Motorway].SpeedKmh, "trucks are capped at 90 km/h")
+	assert.Greater(t, truck.TotalS, car.TotalS)
+}
+
+func TestEstimate_UrbanDensity(t *testing.T) {
+	segs := []Segment{{DistanceM: 6000, Class: Arterial, Intersections: 10}}
+
+	rural := (&Estimator{}).Estimate(segs, Profiles["car"])
+	city := (&Estimator{Density: func(lat, lon float64) float64 { return 1 }}).Estimate(segs, Profiles["car"])
+
+	assert.InDelta(t, 360.0, city.DrivingS, 0.1)
+	assert.InDelta(t, 90.0, city.UrbanPenaltyS, 0.1)
+	assert.InDelta(t, 60.0, city.IntersectionDelayS, 0.1)
+	assert.Equal(t, 10, city.Intersections)
+	assert.Greater(t, city.TotalS, rural.TotalS)
+}
+
+func TestEstimate_UpstreamSpeedCapsClassSpeed(t *testing.T) {
+	// A 50 km/h posted "highway" through a town
+	segs := []Segment{{DistanceM: 5000, DurationS: 360, Class: Highway}}
+	b := (&Estimator{}).Estimate(segs, Profiles["motorcycle"])
+	assert.InDelta(t, 360.0, b.DrivingS, 0.1)
+}
@@ -0,0 +1,128 @@
+package polyline
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Encode renders [lon, lat] coordinates in Google's encoded polyline format
+// with the given decimal precision (5 for Google, 6 for OSRM polyline6).
+// Encoded pairs are lat, lon as the format requires.
+func Encode(coords [][2]float64, precision int) string {
+	factor := math.Pow10(precision)
+	var b strings.Builder
+	var prevLat, prevLon int64
+	for _, c := range coords {
+		lat := int64(math.Round(c[1] * factor))
+		lon := int64(math.Round(c[0] * factor))
+		encodeValue(&b, lat-prevLat)
+		encodeValue(&b, lon-prevLon)
+		prevLat, prevLon = lat, lon
+	}
+	return b.String()
+}
+
+func encodeValue(b *strings.Builder, v int64) {
+	u := uint64(v) << 1
+	if v < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		b.WriteByte(byte((0x20 | (u & 0x1f)) + 63))
+		u >>= 5
+	}
+	b.WriteByte(byte(u + 63))
+}
+
+// Decode parses an encoded polyline back into [lon, lat] coordinates.
+func Decode(s string, precision int) ([][2]float64, error) {
+	factor := math.Pow10(precision)
+	var coords [][2]float64
+	var lat, lon int64
+	for i := 0; i < len(s); {
+		var deltas [2]int64
+		for k := range deltas {
+			var result uint64
+			var shift uint
+			for {
+				if i >= len(s) {
+					return nil, fmt.Errorf("truncated polyline at byte %d", i)
+				}
+				c := uint64(s[i]) - 63
+				i++
+				result |= (c & 0x1f) << shift
+				shift += 5
+				if c < 0x20 {
+					break
+				}
+			}
+			if result&1 != 0 {
+				deltas[k] = ^int64(result >> 1)
+			} else {
+				deltas[k] = int64(result >> 1)
+			}
+		}
+		lat += deltas[0]
+		lon += deltas[1]
+		coords = append(coords, [2]float64{float64(lon) / factor, float64(lat) / factor})
+	}
+	return coords, nil
+}
+
+// Simplify reduces [lon, lat] coordinates with Douglas-Peucker so that no
+// dropped vertex lies further than toleranceM metres from the result. The
+// first and last points are always kept.
+func Simplify(coords [][2]float64, toleranceM float64) [][2]float64 {
+	if toleranceM <= 0 || len(coords) < 3 {
+		return coords
+	}
+
+	// Project onto a local equirectangular plane in metres; accurate to well
+	// under 1% over the extent of a single route.
+	const metresPerDeg = 111320.0
+	midLat := coords[len(coords)/2][1] * math.Pi / 180
+	xy := make([][2]float64, len(coords))
+	for i, c := range coords {
+		xy[i] = [2]float64{c[0] * metresPerDeg * math.Cos(midLat), c[1] * metresPerDeg}
+	}
+
+	keep := make([]bool, len(coords))
+	keep[0], keep[len(coords)-1] = true, true
+	stack := [][2]int{{0, len(coords) - 1}}
+	for len(stack) > 0 {
+		span := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		first, last := span[0], span[1]
+
+		maxDist, index := 0.0, -1
+		for i := first + 1; i < last; i++ {
+			if d := segmentDistance(xy[i], xy[first], xy[last]); d > maxDist {
+				maxDist, index = d, i
+			}
+		}
+		if index >= 0 && maxDist > toleranceM {
+			keep[index] = true
+			stack = append(stack, [2]int{first, index}, [2]int{index, last})
+		}
+	}
+
+	out := make([][2]float64, 0, len(coords))
+	for i, c := range coords {
+		if keep[i] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// segmentDistance is the distance from p to the segment a-b.
+func segmentDistance(p, a, b [2]float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p[0]-a[0], p[1]-a[1])
+	}
+	t := ((p[0]-a[0])*dx + (p[1]-a[1])*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p[0]-(a[0]+t*dx), p[1]-(a[1]+t*dy))
+}
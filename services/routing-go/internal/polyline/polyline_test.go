@@ -0,0 +1,48 @@
+package polyline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Google's reference example
+var googleCoords = [][2]float64{{-120.2, 38.5}, {-120.95, 40.7}, {-126.453, 43.252}}
+
+func TestEncode_GoogleExample(t *testing.T) {
+	assert.Equal(t, "_p~iF~ps|U_ulLnnqC_mqNvxq`@", Encode(googleCoords, 5))
+}
+
+func TestDecode_RoundTrip(t *testing.T) {
+	for _, precision := range []int{5, 6} {
+		decoded, err := Decode(Encode(googleCoords, precision), precision)
+		assert.NoError(t, err)
+		assert.Len(t, decoded, len(googleCoords))
+		for i := range googleCoords {
+			assert.InDelta(t, googleCoords[i][0], decoded[i][0], 1e-6)
+			assert.InDelta(t, googleCoords[i][1], decoded[i][1], 1e-6)
+		}
+	}
+
+	_, err := Decode("_p~iF~ps|U_", 5)
+	assert.Error(t, err)
+}
+
+func TestSimplify(t *testing.T) {
+	// A straight line with a 50 m wiggle in the middle
+	line := [][2]float64{
+		{-112.0, 33.0},
+		{-111.99, 33.0},
+		{-111.98, 33.00045}, // ~50 m north
+		{-111.97, 33.0},
+		{-111.96, 33.0},
+	}
+
+	assert.Len(t, Simplify(line, 100), 2, "wiggle below tolerance is removed")
+	assert.Len(t, Simplify(line, 30), 3, "wiggle above tolerance is kept")
+	assert.Equal(t, line, Simplify(line, 0))
+
+	simplified := Simplify(line, 100)
+	assert.Equal(t, line[0], simplified[0])
+	assert.Equal(t, line[len(line)-1], simplified[len(simplified)-1])
+}
@@ -3,6 +3,7 @@ package routing
 import (
 	"container/heap"
 	"fmt"
+	"time"
 )
 
 type Item struct {
@@ -33,10 +34,26 @@ func (pq *PriorityQueue) Pop() interface{} {
 }
 
 func AStar(g *Graph, startID, goalID string) ([]string, float64) {
+	return FilteredAStar(g, startID, goalID, nil)
+}
+
+// RestrictedAStar is AStar over only the edges a vehicle with dimensions d
+// may legally use.
+func RestrictedAStar(g *Graph, startID, goalID string, d Dimensions) ([]string, float64) {
+	return FilteredAStar(g, startID, goalID, func(e *Edge) bool { return e.Permits(d) })
+}
+
+// FilteredAStar is AStar skipping edges for which allow returns false; nil
+// allows every edge.
+func FilteredAStar(g *Graph, startID, goalID string, allow func(*Edge) bool) ([]string, float64) {
 	startNode, ok := g.Nodes[startID]
-	if !ok { return nil, 0 }
+	if !ok {
+		return nil, 0
+	}
 	goalNode, ok := g.Nodes[goalID]
-	if !ok { return nil, 0 }
+	if !ok {
+		return nil, 0
+	}
 
 	pq := &PriorityQueue{}
 	heap.Init(pq)
@@ -62,6 +79,9 @@ func AStar(g *Graph, startID, goalID string) ([]string, float64) {
 		}
 
 		for _, edge := range g.Edges[currentID] {
+			if allow != nil && !allow(edge) {
+				continue
+			}
 			tentativeGScore := gScore[currentID] + edge.Weight
 			if tentativeGScore < gScore[edge.To] {
 				cameFrom[edge.To] = currentID
@@ -79,7 +99,9 @@ func reconstructPath(cameFrom map[string]string, current string) []string {
 	totalPath := []string{current}
 	for {
 		next, ok := cameFrom[current]
-		if !ok { break }
+		if !ok {
+			break
+		}
 		totalPath = append([]string{next}, totalPath...)
 		current = next
 	}
@@ -87,19 +109,21 @@ func reconstructPath(cameFrom map[string]string, current string) []string {
 }
 
 type Instruction struct {
-	Text     string `json:"text"`
+	Text     string  `json:"text"`
 	Distance float64 `json:"distance"`
 }
 
 func GenerateInstructions(path []string, g *Graph) []Instruction {
 	instructions := []Instruction{}
-	if len(path) < 2 { return instructions }
+	if len(path) < 2 {
+		return instructions
+	}
 
 	for i := 0; i < len(path)-1; i++ {
 		fromNode := g.Nodes[path[i]]
 		toNode := g.Nodes[path[i+1]]
 		dist := Distance(fromNode, toNode)
-		
+
 		text := fmt.Sprintf("Continue for %.1f km", dist)
 		if i == 0 {
 			text = fmt.Sprintf("Start journey. Drive %.1f km", dist)
@@ -114,3 +138,85 @@ func GenerateInstructions(path []string, g *Graph) []Instruction {
 	}
 	return instructions
 }
+
+// TimeDependentAStar finds the earliest-arrival path when leaving startID at
+// depart. Edge costs come from each edge's SpeedProfile at the moment the
+// vehicle reaches it. It returns the path and the arrival time.
+func TimeDependentAStar(g *Graph, startID, goalID string, depart time.Time) ([]string, time.Time, bool) {
+	startNode, ok := g.Nodes[startID]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	goalNode, ok := g.Nodes[goalID]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+
+	// Straight-line distance at the fastest speed anywhere in the graph
+	// never overestimates, which keeps the heuristic admissible.
+	maxKmh := 0.0
+	for _, edges := range g.Edges {
+		for _, e := range edges {
+			if e.FreeFlowKmh > maxKmh {
+				maxKmh = e.FreeFlowKmh
+			}
+		}
+	}
+	if maxKmh == 0 {
+		return nil, time.Time{}, false
+	}
+	heuristic := func(n *Node) float64 {
+		return Distance(n, goalNode) / maxKmh * 3600
+	}
+
+	pq := &PriorityQueue{}
+	heap.Init(pq)
+	heap.Push(pq, &Item{nodeID: startID, priority: heuristic(startNode)})
+
+	cameFrom := make(map[string]string)
+	// elapsed seconds since depart
+	gScore := map[string]float64{startID: 0}
+	closed := make(map[string]bool)
+
+	for pq.Len() > 0 {
+		currentID := heap.Pop(pq).(*Item).nodeID
+		if closed[currentID] {
+			continue
+		}
+		closed[currentID] = true
+
+		if currentID == goalID {
+			arrive := depart.Add(time.Duration(gScore[currentID] * float64(time.Second)))
+			return reconstructPath(cameFrom, currentID), arrive, true
+		}
+
+		now := depart.Add(time.Duration(gScore[currentID] * float64(time.Second)))
+		for _, edge := range g.Edges[currentID] {
+			if edge.FreeFlowKmh <= 0 || closed[edge.To] {
+				continue
+			}
+			tentative := gScore[currentID] + edge.TravelTime(now).Seconds()
+			if best, seen := gScore[edge.To]; !seen || tentative < best {
+				cameFrom[edge.To] = currentID
+				gScore[edge.To] = tentative
+				heap.Push(pq, &Item{nodeID: edge.To, priority: tentative + heuristic(g.Nodes[edge.To])})
+			}
+		}
+	}
+
+	return nil, time.Time{}, false
+}
+
+// FreeFlowPathTime sums the uncongested travel time along path.
+func FreeFlowPathTime(g *Graph, path []string) time.Duration {
+	var total time.Duration
+	for i := 0; i < len(path)-1; i++ {
+		for _, e := range g.Edges[path[i]] {
+			if e.To == path[i+1] {
+				total += e.FreeFlowTime()
+				break
+			}
+		}
+	}
+	return total
+}
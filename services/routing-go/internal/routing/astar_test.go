@@ -0,0 +1,124 @@
+package routing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var mst = time.FixedZone("MST", -7*60*60)
+
+func TestCommuterProfile_FactorAt(t *testing.T) {
+	p := CommuterProfile("test", 0.6, 0.5)
+
+	tuesday5pm := time.Date(2026, 10, 13, 17, 0, 0, 0, mst)
+	assert.Equal(t, 0.5, p.FactorAt(tuesday5pm))
+
+	tuesday3am := time.Date(2026, 10, 13, 3, 0, 0, 0, mst)
+	assert.Equal(t, 1.0, p.FactorAt(tuesday3am))
+
+	// Ramps in at the start of the peak
+	tuesday1530 := time.Date(2026, 10, 13, 15, 30, 0, 0, mst)
+	assert.Greater(t, p.FactorAt(tuesday1530), 0.5)
+	assert.Less(t, p.FactorAt(tuesday1530), 1.0)
+
+	var nilProfile *SpeedProfile
+	assert.Equal(t, 1.0, nilProfile.FactorAt(tuesday5pm))
+}
+
+func TestEdgeTravelTime_SpansSlots(t *testing.T) {
+	// 60 km at 60 km/h, but slowed to half speed from 17:00
+	p := FlatProfile("test")
+	day := time.Tuesday
+	for s := 17 * 4; s < SlotsPerDay; s++ {
+		p.Factors[day][s] = 0.5
+	}
+	e := &Edge{Weight: 60, FreeFlowKmh: 60, Profile: p}
+
+	// 30 min at full speed covers 30 km, the remaining 30 km take 1h
+	depart := time.Date(2026, 10, 13, 16, 30, 0, 0, mst)
+	assert.Equal(t, 90*time.Minute, e.TravelTime(depart))
+	assert.Equal(t, time.Hour, e.FreeFlowTime())
+}
+
+func TestTimeDependentAStar_AvoidsRushHour(t *testing.T) {
+	// a → b direct is a short metro freeway; a → c → b is a longer bypass
+	g := NewGraph()
+	g.AddNode(&Node{ID: "a", Lat: 33.0, Lon: -112.0})
+	g.AddNode(&Node{ID: "b", Lat: 33.0, Lon: -111.5})
+	g.AddNode(&Node{ID: "c", Lat: 33.2, Lon: -111.75})
+	g.AddRoad("a", "b", 50, 100, CommuterProfile("metro", 0.5, 0.3))
+	g.AddRoad("a", "c", 35, 100, FlatProfile("rural"))
+	g.AddRoad("c", "b", 35, 100, FlatProfile("rural"))
+
+	night := time.Date(2026, 10, 13, 2, 0, 0, 0, mst)
+	path, arrive, ok := TimeDependentAStar(g, "a", "b", night)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a", "b"}, path)
+	assert.Equal(t, 30*time.Minute, arrive.Sub(night))
+
+	rush := time.Date(2026, 10, 13, 17, 0, 0, 0, mst)
+	path, arrive, ok = TimeDependentAStar(g, "a", "b", rush)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a", "c", "b"}, path)
+	assert.Equal(t, 42*time.Minute, arrive.Sub(rush))
+	assert.Equal(t, 42*time.Minute, FreeFlowPathTime(g, path))
+}
+
+func TestTimeDependentAStar_Unreachable(t *testing.T) {
+	g := NewGraph()
+	g.AddNode(&Node{ID: "a"})
+	g.AddNode(&Node{ID: "b"})
+	g.AddRoad("a", "a", 1, 50, nil)
+
+	_, _, ok := TimeDependentAStar(g, "a", "b", time.Now())
+	assert.False(t, ok)
+	_, _, ok = TimeDependentAStar(g, "a", "missing", time.Now())
+	assert.False(t, ok)
+}
+
+func TestRestrictedAStar_AvoidsLowBridge(t *testing.T) {
+	g := NewGraph()
+	g.AddNode(&Node{ID: "a", Lat: 33.0, Lon: -112.0})
+	g.AddNode(&Node{ID: "b", Lat: 33.0, Lon: -111.5})
+	g.AddNode(&Node{ID: "c", Lat: 33.2, Lon: -111.75})
+	g.AddRoad("a", "b", 50, 100, nil)
+	g.AddRoad("a", "c", 35, 100, nil)
+	g.AddRoad("c", "b", 35, 100, nil)
+	assert.Equal(t, 2, g.ApplyRestrictions(map[string]*Restrictions{
+		"a>b": {MaxHeightM: 4.0, Hazmat: "no"},
+		"b>a": {MaxHeightM: 4.0, Hazmat: "no"},
+	}))
+
+	van := Dimensions{HeightM: 2.8, WeightT: 3.5, LengthM: 6}
+	path, km := RestrictedAStar(g, "a", "b", van)
+	assert.Equal(t, []string{"a", "b"}, path)
+	assert.Equal(t, 50.0, km)
+
+	truck := Dimensions{HeightM: 4.2, WeightT: 36, LengthM: 21}
+	path, km = RestrictedAStar(g, "a", "b", truck)
+	assert.Equal(t, []string{"a", "c", "b"}, path)
+	assert.Equal(t, 70.0, km)
+
+	path, _ = RestrictedAStar(g, "a", "b", Dimensions{HeightM: 2.8, Hazmat: true})
+	assert.Equal(t, []string{"a", "c", "b"}, path)
+
+	// Unrestricted search is unchanged
+	path, _ = AStar(g, "a", "b")
+	assert.Equal(t, []string{"a", "b"}, path)
+}
+
+func TestRestrictions_Violations(t *testing.T) {
+	r := &Restrictions{MaxHeightM: 4.0, MaxWeightT: 20, MaxLengthM: 18, Hazmat: "no"}
+	v := r.Violations(Dimensions{HeightM: 4.2, WeightT: 20, LengthM: 21, Hazmat: true})
+	assert.Equal(t, []Violation{
+		{Tag: "maxheight", Limit: 4.0, Vehicle: 4.2},
+		{Tag: "maxlength", Limit: 18, Vehicle: 21},
+		{Tag: "hazmat"},
+	}, v)
+	assert.Equal(t, "maxheight 4 m < 4.2 m", v[0].String())
+
+	var none *Restrictions
+	assert.Empty(t, none.Violations(Dimensions{HeightM: 10}))
+}
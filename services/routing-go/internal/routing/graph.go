@@ -2,6 +2,7 @@ package routing
 
 import (
 	"math"
+	"time"
 )
 
 type Node struct {
@@ -12,9 +13,16 @@ type Node struct {
 }
 
 type Edge struct {
+	ID     string
 	From   string
 	To     string
 	Weight float64
+	// FreeFlowKmh and Profile drive time-dependent routing. Edges added with
+	// AddEdge have neither and are skipped by TimeDependentAStar.
+	FreeFlowKmh float64
+	Profile     *SpeedProfile
+	// Restrictions is nil for roads open to every vehicle.
+	Restrictions *Restrictions
 }
 
 type Graph struct {
@@ -39,6 +47,80 @@ func (g *Graph) AddEdge(from, to string, weight float64) {
 	g.Edges[to] = append(g.Edges[to], &Edge{From: to, To: from, Weight: weight})
 }
 
+// AddRoad adds a two-way road of lengthKm. Each direction gets its own edge
+// ID ("from>to") so historical profiles can differ by direction.
+func (g *Graph) AddRoad(from, to string, lengthKm, freeFlowKmh float64, profile *SpeedProfile) {
+	g.Edges[from] = append(g.Edges[from], &Edge{
+		ID: EdgeID(from, to), From: from, To: to, Weight: lengthKm,
+		FreeFlowKmh: freeFlowKmh, Profile: profile,
+	})
+	g.Edges[to] = append(g.Edges[to], &Edge{
+		ID: EdgeID(to, from), From: to, To: from, Weight: lengthKm,
+		FreeFlowKmh: freeFlowKmh, Profile: profile,
+	})
+}
+
+func EdgeID(from, to string) string {
+	return from + ">" + to
+}
+
+// HasEdge reports whether a directed edge from → to exists.
+func (g *Graph) HasEdge(from, to string) bool {
+	for _, e := range g.Edges[from] {
+		if e.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyProfiles replaces edge profiles by edge ID and returns how many
+// edges matched.
+func (g *Graph) ApplyProfiles(profiles map[string]*SpeedProfile) int {
+	matched := 0
+	for _, edges := range g.Edges {
+		for _, e := range edges {
+			if p, ok := profiles[e.ID]; ok {
+				e.Profile = p
+				matched++
+			}
+		}
+	}
+	return matched
+}
+
+// FreeFlowTime is the edge travel time with no congestion.
+func (e *Edge) FreeFlowTime() time.Duration {
+	if e.FreeFlowKmh <= 0 {
+		return 0
+	}
+	return time.Duration(e.Weight / e.FreeFlowKmh * float64(time.Hour))
+}
+
+// TravelTime is the time to traverse the edge when entering it at t. Speed
+// changes at each profile slot boundary, so long edges that span a rush hour
+// are integrated slot by slot; leaving later never arrives earlier.
+func (e *Edge) TravelTime(t time.Time) time.Duration {
+	if e.FreeFlowKmh <= 0 {
+		return 0
+	}
+	remaining := e.Weight
+	clock := t
+	for remaining > 1e-9 {
+		speed := e.FreeFlowKmh * e.Profile.FactorAt(clock)
+		slotEnd := clock.Truncate(SlotDuration).Add(SlotDuration)
+		window := slotEnd.Sub(clock)
+		reach := speed * window.Hours()
+		if reach >= remaining {
+			clock = clock.Add(time.Duration(remaining / speed * float64(time.Hour)))
+			break
+		}
+		remaining -= reach
+		clock = slotEnd
+	}
+	return clock.Sub(t)
+}
+
 // Haversine distance for heuristic
 func Distance(n1, n2 *Node) float64 {
 	const R = 6371 // km
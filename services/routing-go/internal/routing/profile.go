@@ -0,0 +1,93 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// SlotsPerDay is the number of 15-minute slots in a speed profile day.
+const SlotsPerDay = 96
+
+// SlotDuration is the width of one profile slot.
+const SlotDuration = 15 * time.Minute
+
+// SpeedProfile is the historical speed on an edge as a fraction of its
+// free-flow speed, per weekday (Sunday first) and 15-minute slot of local time.
+type SpeedProfile struct {
+	Name    string                  `json:"name"`
+	Factors [7][SlotsPerDay]float64 `json:"factors"`
+}
+
+// FactorAt returns the speed factor for the slot containing t, using t's
+// location as local time.
+func (p *SpeedProfile) FactorAt(t time.Time) float64 {
+	if p == nil {
+		return 1
+	}
+	slot := (t.Hour()*60 + t.Minute()) / 15
+	f := p.Factors[t.Weekday()][slot]
+	if f <= 0 {
+		return 1
+	}
+	return f
+}
+
+// FlatProfile keeps free-flow speed at all times.
+func FlatProfile(name string) *SpeedProfile {
+	p := &SpeedProfile{Name: name}
+	for d := range p.Factors {
+		for s := range p.Factors[d] {
+			p.Factors[d][s] = 1
+		}
+	}
+	return p
+}
+
+// CommuterProfile models a metro freeway: weekday morning and evening rush
+// hours slow to amPeak and pmPeak of free flow, with ramps either side.
+func CommuterProfile(name string, amPeak, pmPeak float64) *SpeedProfile {
+	p := FlatProfile(name)
+	for d := time.Monday; d <= time.Friday; d++ {
+		rush(&p.Factors[d], 6*60+30, 9*60, amPeak)
+		rush(&p.Factors[d], 15*60+30, 18*60+30, pmPeak)
+	}
+	// Weekend midday shopping and event traffic
+	for _, d := range []time.Weekday{time.Saturday, time.Sunday} {
+		rush(&p.Factors[d], 11*60, 15*60, (1+pmPeak)/2)
+	}
+	return p
+}
+
+// rush lowers the factors between from and to (minutes after midnight) to
+// peak, easing in and out over the first and last half hour.
+func rush(day *[SlotsPerDay]float64, from, to int, peak float64) {
+	for m := from; m < to; m += 15 {
+		ramp := 1.0
+		if m-from < 30 {
+			ramp = float64(m-from+15) / 45
+		} else if to-m <= 30 {
+			ramp = float64(to-m) / 45
+		}
+		f := 1 - (1-peak)*ramp
+		slot := m / 15
+		if f < day[slot] {
+			day[slot] = f
+		}
+	}
+}
+
+// LoadProfiles reads edge-specific profiles from a JSON object keyed by edge
+// ID, e.g. {"phx>tempe": {"name": "I-10 EB", "factors": [[...96], ...7]}}.
+func LoadProfiles(path string) (map[string]*SpeedProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var profiles map[string]*SpeedProfile
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("parse speed profiles %s: %w", path, err)
+	}
+	return profiles, nil
+}
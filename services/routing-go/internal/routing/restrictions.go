@@ -0,0 +1,104 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Restrictions are the legal limits on an edge, named after the OSM tags
+// they come from. Zero values mean unrestricted.
+type Restrictions struct {
+	MaxHeightM float64 `json:"maxheight,omitempty"`
+	MaxWeightT float64 `json:"maxweight,omitempty"`
+	MaxLengthM float64 `json:"maxlength,omitempty"`
+	// Hazmat is "no" where hazardous loads are banned.
+	Hazmat string `json:"hazmat,omitempty"`
+}
+
+// Dimensions describe a vehicle and its load.
+type Dimensions struct {
+	HeightM float64 `json:"height_m"`
+	WeightT float64 `json:"weight_t"`
+	LengthM float64 `json:"length_m"`
+	Hazmat  bool    `json:"hazmat"`
+}
+
+// Violation is one restriction a vehicle breaks. Limit and Vehicle are unset
+// for hazmat.
+type Violation struct {
+	Tag     string  `json:"tag"`
+	Limit   float64 `json:"limit,omitempty"`
+	Vehicle float64 `json:"vehicle,omitempty"`
+}
+
+func (v Violation) String() string {
+	if v.Tag == "hazmat" {
+		return "no hazmat"
+	}
+	unit := "m"
+	if v.Tag == "maxweight" {
+		unit = "t"
+	}
+	return fmt.Sprintf("%s %g %s < %g %s", v.Tag, v.Limit, unit, v.Vehicle, unit)
+}
+
+// Violations lists the restrictions a vehicle with dimensions d breaks.
+func (r *Restrictions) Violations(d Dimensions) []Violation {
+	if r == nil {
+		return nil
+	}
+	var out []Violation
+	check := func(tag string, limit, value float64) {
+		if limit > 0 && value > limit {
+			out = append(out, Violation{Tag: tag, Limit: limit, Vehicle: value})
+		}
+	}
+	check("maxheight", r.MaxHeightM, d.HeightM)
+	check("maxweight", r.MaxWeightT, d.WeightT)
+	check("maxlength", r.MaxLengthM, d.LengthM)
+	if d.Hazmat && r.Hazmat == "no" {
+		out = append(out, Violation{Tag: "hazmat"})
+	}
+	return out
+}
+
+// Permits reports whether a vehicle with dimensions d may use the edge.
+func (e *Edge) Permits(d Dimensions) bool {
+	return len(e.Restrictions.Violations(d)) == 0
+}
+
+// ApplyRestrictions sets edge restrictions by edge ID and returns how many
+// edges matched.
+func (g *Graph) ApplyRestrictions(restrictions map[string]*Restrictions) int {
+	matched := 0
+	for _, edges := range g.Edges {
+		for _, e := range edges {
+			if r, ok := restrictions[e.ID]; ok {
+				e.Restrictions = r
+				matched++
+			}
+		}
+	}
+	return matched
+}
+
+// LoadRestrictions reads edge restrictions from a JSON object keyed by edge
+// ID, e.g. {"phx>tempe": {"maxheight": 4.1, "hazmat": "no"}}. Each
+// direction is keyed separately.
+func LoadRestrictions(path string) (map[string]*Restrictions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var restrictions map[string]*Restrictions
+	if err := json.Unmarshal(data, &restrictions); err != nil {
+		return nil, fmt.Errorf("parse restrictions %s: %w", path, err)
+	}
+	for id, r := range restrictions {
+		if r == nil || r.MaxHeightM < 0 || r.MaxWeightT < 0 || r.MaxLengthM < 0 {
+			return nil, fmt.Errorf("restrictions for %s: limits must be positive", id)
+		}
+	}
+	return restrictions, nil
+}
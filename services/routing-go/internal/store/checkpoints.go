@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNoCheckpoint is returned when a job has no saved progress.
+var ErrNoCheckpoint = errors.New("no checkpoint")
+
+// JobCheckpoint is the saved progress of a background job, so a restart can
+// resume where the last process stopped.
+type JobCheckpoint struct {
+	Job       string `gorm:"primaryKey"`
+	Data      []byte
+	UpdatedAt time.Time
+}
+
+// Checkpoints persists one checkpoint per job.
+type Checkpoints interface {
+	Load(ctx context.Context, job string) ([]byte, error)
+	Save(ctx context.Context, job string, data []byte) error
+	Delete(ctx context.Context, job string) error
+}
+
+// ── GORM ──
+
+// GormCheckpoints keeps checkpoints in the job_checkpoints table.
+type GormCheckpoints struct {
+	db *gorm.DB
+}
+
+// NewGormCheckpoints creates the job_checkpoints table on db if needed.
+func NewGormCheckpoints(db *gorm.DB) (*GormCheckpoints, error) {
+	if err := db.AutoMigrate(&JobCheckpoint{}); err != nil {
+		return nil, err
+	}
+	return &GormCheckpoints{db: db}, nil
+}
+
+func (c *GormCheckpoints) Load(ctx context.Context, job string) ([]byte, error) {
+	var cp JobCheckpoint
+	err := c.db.WithContext(ctx).Where("job = ?", job).First(&cp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoCheckpoint
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cp.Data, nil
+}
+
+func (c *GormCheckpoints) Save(ctx context.Context, job string, data []byte) error {
+	return c.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "job"}},
+		DoUpdates: clause.AssignmentColumns([]string{"data", "updated_at"}),
+	}).Create(&JobCheckpoint{Job: job, Data: data}).Error
+}
+
+func (c *GormCheckpoints) Delete(ctx context.Context, job string) error {
+	return c.db.WithContext(ctx).Where("job = ?", job).Delete(&JobCheckpoint{}).Error
+}
+
+// ── Memory ──
+
+// MemoryCheckpoints holds checkpoints in process, for tests and deployments
+// without a database.
+type MemoryCheckpoints struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func NewMemoryCheckpoints() *MemoryCheckpoints {
+	return &MemoryCheckpoints{data: make(map[string][]byte)}
+}
+
+func (c *MemoryCheckpoints) Load(_ context.Context, job string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.data[job]
+	if !ok {
+		return nil, ErrNoCheckpoint
+	}
+	return data, nil
+}
+
+func (c *MemoryCheckpoints) Save(_ context.Context, job string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[job] = append([]byte(nil), data...)
+	return nil
+}
+
+func (c *MemoryCheckpoints) Delete(_ context.Context, job string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.data, job)
+	return nil
+}
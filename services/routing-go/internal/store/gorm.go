@@ -0,0 +1,151 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ── Database Models ──
+
+// RouteCache holds one route per start/end pair.
+type RouteCache struct {
+	ID        uint   `gorm:"primaryKey"`
+	StartID   string `gorm:"uniqueIndex:idx_route_cache_pair"`
+	EndID     string `gorm:"uniqueIndex:idx_route_cache_pair"`
+	Data      []byte `gorm:"type:jsonb"`
+	CreatedAt time.Time
+}
+
+// GormStore is a RouteStore backed by a SQL database through GORM. It is
+// shared by the Postgres and SQLite drivers.
+type GormStore struct {
+	db *gorm.DB
+}
+
+// OpenPostgres connects to Postgres, retrying while the database starts up.
+func OpenPostgres(dsn string, retries int, delay time.Duration) (*GormStore, error) {
+	if dsn == "" {
+		dsn = "host=localhost user=admin password=navifly dbname=navifly port=5432 sslmode=disable"
+	}
+	if retries <= 0 {
+		retries = 10
+	}
+	if delay <= 0 {
+		delay = 2 * time.Second
+	}
+
+	var db *gorm.DB
+	var err error
+	for i := 0; i < retries; i++ {
+		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		if err == nil {
+			break
+		}
+		slog.Info("waiting for database", "attempt", i+1, "retries", retries)
+		time.Sleep(delay)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connect to postgres: %w", err)
+	}
+	return newGormStore(db)
+}
+
+// OpenSQLite opens (or creates) an embedded SQLite database at path. It
+// needs no external server, which suits in-vehicle deployments.
+func OpenSQLite(path string) (*GormStore, error) {
+	if path == "" {
+		path = "navifly.db"
+	}
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite %s: %w", path, err)
+	}
+	return newGormStore(db)
+}
+
+func newGormStore(db *gorm.DB) (*GormStore, error) {
+	if err := db.Use(tracePlugin{}); err != nil {
+		return nil, fmt.Errorf("install tracing: %w", err)
+	}
+	if err := migrateRouteCache(db); err != nil {
+		return nil, fmt.Errorf("migrate route cache: %w", err)
+	}
+	return &GormStore{db: db}, nil
+}
+
+// migrateRouteCache makes start/end pairs unique. Older databases indexed
+// them without a constraint and kept every refresh as a new row, so the
+// newest row of each pair is kept before the unique index is added.
+func migrateRouteCache(db *gorm.DB) error {
+	m := db.Migrator()
+	if m.HasTable(&RouteCache{}) && !m.HasIndex(&RouteCache{}, "idx_route_cache_pair") {
+		err := db.Exec(`DELETE FROM route_caches WHERE id NOT IN
+			(SELECT MAX(id) FROM route_caches GROUP BY start_id, end_id)`).Error
+		if err != nil {
+			return err
+		}
+		if m.HasIndex(&RouteCache{}, "idx_route_pair") {
+			if err := m.DropIndex(&RouteCache{}, "idx_route_pair"); err != nil {
+				return err
+			}
+		}
+	}
+	return db.AutoMigrate(&RouteCache{})
+}
+
+// DB exposes the underlying connection for subsystems that share it.
+func (s *GormStore) DB() *gorm.DB { return s.db }
+
+func (s *GormStore) Get(ctx context.Context, startID, endID string) ([]byte, error) {
+	var cached RouteCache
+	err := s.db.WithContext(ctx).
+		Where("start_id = ? AND end_id = ?", startID, endID).
+		First(&cached).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cached.Data, nil
+}
+
+// Put stores the route for a pair, replacing any earlier one.
+func (s *GormStore) Put(ctx context.Context, startID, endID string, data []byte) error {
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "start_id"}, {Name: "end_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"data", "created_at"}),
+	}).Create(&RouteCache{
+		StartID: startID,
+		EndID:   endID,
+		Data:    data,
+	}).Error
+}
+
+func (s *GormStore) Clear(ctx context.Context) error {
+	return s.db.WithContext(ctx).Where("1 = 1").Delete(&RouteCache{}).Error
+}
+
+func (s *GormStore) Ping(ctx context.Context) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func (s *GormStore) Close() error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
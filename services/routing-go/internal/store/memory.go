@@ -0,0 +1,91 @@
+package store
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// DefaultMemoryCapacity is used when NewMemory is given a non-positive size.
+const DefaultMemoryCapacity = 1000
+
+type memoryEntry struct {
+	key  string
+	data []byte
+}
+
+// MemoryStore is an in-process LRU RouteStore. Nothing survives a restart,
+// so it is meant for tests and deployments without any database.
+type MemoryStore struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+func NewMemory(capacity int) *MemoryStore {
+	if capacity <= 0 {
+		capacity = DefaultMemoryCapacity
+	}
+	return &MemoryStore{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func pairKey(startID, endID string) string {
+	return startID + "→" + endID
+}
+
+func (s *MemoryStore) Get(_ context.Context, startID, endID string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[pairKey(startID, endID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	s.order.MoveToFront(el)
+	return el.Value.(*memoryEntry).data, nil
+}
+
+func (s *MemoryStore) Put(_ context.Context, startID, endID string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := pairKey(startID, endID)
+	if el, ok := s.items[key]; ok {
+		el.Value.(*memoryEntry).data = data
+		s.order.MoveToFront(el)
+		return nil
+	}
+
+	s.items[key] = s.order.PushFront(&memoryEntry{key: key, data: data})
+	for s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+func (s *MemoryStore) Clear(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.order.Init()
+	s.items = make(map[string]*list.Element)
+	return nil
+}
+
+// Len reports how many pairs are currently cached.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+func (s *MemoryStore) Ping(context.Context) error { return nil }
+
+func (s *MemoryStore) Close() error { return nil }
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// RouteLine is the geometry of one alternative inside a cached response.
+type RouteLine struct {
+	Index  int
+	Label  string
+	Coords [][2]float64 // [lon, lat]
+}
+
+// BBox is a WGS84 bounding box.
+type BBox struct {
+	MinLon, MinLat, MaxLon, MaxLat float64
+}
+
+// RouteMatch identifies a cached route alternative returned by a spatial query.
+type RouteMatch struct {
+	StartID    string   `json:"start_id"`
+	EndID      string   `json:"end_id"`
+	RouteIndex int      `json:"route_index"`
+	Label      string   `json:"label"`
+	DistanceM  *float64 `json:"distance_m,omitempty"`
+}
+
+// SpatialIndex answers geometric questions about cached routes, such as
+// which planned trips cross a closed road.
+type SpatialIndex interface {
+	IndexRoutes(ctx context.Context, startID, endID string, lines []RouteLine) error
+	RoutesThrough(ctx context.Context, bbox BBox) ([]RouteMatch, error)
+	RoutesNear(ctx context.Context, lat, lon, radiusM float64) ([]RouteMatch, error)
+	Clear(ctx context.Context) error
+}
+
+// PostGISIndex keeps one geometry(LineString, 4326) row per cached route
+// alternative in the route_geometries table.
+type PostGISIndex struct {
+	db *gorm.DB
+}
+
+// NewPostGIS enables the postgis extension and creates the geometry table.
+// It fails on servers without PostGIS installed.
+func NewPostGIS(db *gorm.DB) (*PostGISIndex, error) {
+	stmts := []string{
+		`CREATE EXTENSION IF NOT EXISTS postgis`,
+		`CREATE TABLE IF NOT EXISTS route_geometries (
+			id          BIGSERIAL PRIMARY KEY,
+			start_id    TEXT NOT NULL,
+			end_id      TEXT NOT NULL,
+			route_index INTEGER NOT NULL,
+			label       TEXT NOT NULL DEFAULT '',
+			geom        geometry(LineString, 4326) NOT NULL,
+			created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_route_geometries_geom ON route_geometries USING GIST (geom)`,
+		// RoutesNear measures in metres on the geography, which the
+		// geometry index cannot serve
+		`CREATE INDEX IF NOT EXISTS idx_route_geometries_geog ON route_geometries USING GIST ((geom::geography))`,
+		`CREATE INDEX IF NOT EXISTS idx_route_geometries_pair ON route_geometries (start_id, end_id)`,
+	}
+	for _, stmt := range stmts {
+		if err := db.Exec(stmt).Error; err != nil {
+			return nil, fmt.Errorf("init postgis: %w", err)
+		}
+	}
+	return &PostGISIndex{db: db}, nil
+}
+
+// IndexRoutes replaces the stored geometry for a start/end pair.
+func (p *PostGISIndex) IndexRoutes(ctx context.Context, startID, endID string, lines []RouteLine) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`DELETE FROM route_geometries WHERE start_id = ? AND end_id = ?`, startID, endID).Error; err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if len(line.Coords) < 2 {
+				continue
+			}
+			err := tx.Exec(
+				`INSERT INTO route_geometries (start_id, end_id, route_index, label, geom)
+				 VALUES (?, ?, ?, ?, ST_GeomFromText(?, 4326))`,
+				startID, endID, line.Index, line.Label, lineStringWKT(line.Coords),
+			).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RoutesThrough lists the alternatives that intersect bbox.
+func (p *PostGISIndex) RoutesThrough(ctx context.Context, bbox BBox) ([]RouteMatch, error) {
+	var matches []RouteMatch
+	err := p.db.WithContext(ctx).Raw(
+		`SELECT start_id, end_id, route_index, label
+		   FROM route_geometries
+		  WHERE ST_Intersects(geom, ST_MakeEnvelope(?, ?, ?, ?, 4326))
+		  ORDER BY start_id, end_id, route_index`,
+		bbox.MinLon, bbox.MinLat, bbox.MaxLon, bbox.MaxLat,
+	).Scan(&matches).Error
+	return matches, err
+}
+
+// RoutesNear lists the alternatives passing within radiusM metres of
+// lat/lon, nearest first, with their distance.
+func (p *PostGISIndex) RoutesNear(ctx context.Context, lat, lon, radiusM float64) ([]RouteMatch, error) {
+	var matches []RouteMatch
+	err := p.db.WithContext(ctx).Raw(
+		`SELECT start_id, end_id, route_index, label,
+		        ST_Distance(geom::geography, pt::geography) AS distance_m
+		   FROM route_geometries, ST_SetSRID(ST_MakePoint(?, ?), 4326) AS pt
+		  WHERE ST_DWithin(geom::geography, pt::geography, ?)
+		  ORDER BY distance_m, start_id, end_id, route_index`,
+		lon, lat, radiusM,
+	).Scan(&matches).Error
+	return matches, err
+}
+
+// Clear removes every stored geometry.
+func (p *PostGISIndex) Clear(ctx context.Context) error {
+	return p.db.WithContext(ctx).Exec(`DELETE FROM route_geometries`).Error
+}
+
+// lineStringWKT renders [lon, lat] pairs as a WKT LINESTRING.
+func lineStringWKT(coords [][2]float64) string {
+	var b strings.Builder
+	b.WriteString("LINESTRING(")
+	for i, c := range coords {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(c[0], 'f', -1, 64))
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatFloat(c[1], 'f', -1, 64))
+	}
+	b.WriteByte(')')
+	return b.String()
+}
@@ -0,0 +1,430 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Profile lookup errors.
+var (
+	ErrNoUser  = errors.New("user not found")
+	ErrNoPlace = errors.New("place not found")
+	ErrNoTrip  = errors.New("trip not found")
+)
+
+// User owns saved places and trips.
+type User struct {
+	ID        string `gorm:"primaryKey"`
+	Name      string
+	CreatedAt time.Time
+}
+
+// Place is a user's own location, such as "home" or "depot".
+type Place struct {
+	UserID    string `gorm:"primaryKey"`
+	ID        string `gorm:"primaryKey"`
+	Label     string
+	Lat       float64
+	Lon       float64
+	CreatedAt time.Time
+}
+
+// Trip kinds.
+const (
+	TripFavorite = "favorite"
+	TripRecent   = "recent"
+)
+
+// Trip is a start/stops/end combination. Waypoints are catalog IDs or
+// "place:<id>" references; Options holds the other /route parameters,
+// URL-encoded.
+type Trip struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    string `gorm:"index:idx_trip_user_kind"`
+	Kind      string `gorm:"index:idx_trip_user_kind"`
+	Label     string
+	Start     string `gorm:"column:start_ref"`
+	Stops     string `gorm:"column:stop_refs"` // comma-separated
+	End       string `gorm:"column:end_ref"`
+	Options   string
+	CreatedAt time.Time
+}
+
+// sameAs reports whether two trips route the same way.
+func (t *Trip) sameAs(o *Trip) bool {
+	return t.Start == o.Start && t.Stops == o.Stops && t.End == o.End && t.Options == o.Options
+}
+
+// Profiles persists users with their places, favorite trips and recent
+// trips. Places and trips of an unknown user fail with ErrNoUser.
+type Profiles interface {
+	PutUser(ctx context.Context, u *User) error
+	GetUser(ctx context.Context, id string) (*User, error)
+	// DeleteUser removes the user with all places and trips.
+	DeleteUser(ctx context.Context, id string) error
+
+	Places(ctx context.Context, userID string) ([]Place, error)
+	PutPlace(ctx context.Context, p *Place) error
+	DeletePlace(ctx context.Context, userID, id string) error
+
+	// Trips lists a user's trips of one kind, newest first.
+	Trips(ctx context.Context, userID, kind string) ([]Trip, error)
+	GetTrip(ctx context.Context, userID string, id uint) (*Trip, error)
+	AddTrip(ctx context.Context, t *Trip) error
+	DeleteTrip(ctx context.Context, userID string, id uint) error
+	// RecordRecent adds a recent trip, replacing an identical older one, and
+	// keeps only the newest keep.
+	RecordRecent(ctx context.Context, t *Trip, keep int) error
+}
+
+// ── GORM ──
+
+// GormProfiles keeps profiles in the users, places and trips tables.
+type GormProfiles struct {
+	db *gorm.DB
+}
+
+// NewGormProfiles creates the profile tables on db if needed.
+func NewGormProfiles(db *gorm.DB) (*GormProfiles, error) {
+	if err := db.AutoMigrate(&User{}, &Place{}, &Trip{}); err != nil {
+		return nil, err
+	}
+	return &GormProfiles{db: db}, nil
+}
+
+func (s *GormProfiles) PutUser(ctx context.Context, u *User) error {
+	existing, err := s.GetUser(ctx, u.ID)
+	if errors.Is(err, ErrNoUser) {
+		return s.db.WithContext(ctx).Create(u).Error
+	}
+	if err != nil {
+		return err
+	}
+	u.CreatedAt = existing.CreatedAt
+	return s.db.WithContext(ctx).Model(&User{}).Where("id = ?", u.ID).Update("name", u.Name).Error
+}
+
+func (s *GormProfiles) GetUser(ctx context.Context, id string) (*User, error) {
+	var u User
+	err := s.db.WithContext(ctx).Where("id = ?", id).First(&u).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoUser
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (s *GormProfiles) DeleteUser(ctx context.Context, id string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ?", id).Delete(&User{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrNoUser
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&Place{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", id).Delete(&Trip{}).Error
+	})
+}
+
+func (s *GormProfiles) Places(ctx context.Context, userID string) ([]Place, error) {
+	if _, err := s.GetUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	var places []Place
+	err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&places).Error
+	return places, err
+}
+
+func (s *GormProfiles) PutPlace(ctx context.Context, p *Place) error {
+	if _, err := s.GetUser(ctx, p.UserID); err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND id = ?", p.UserID, p.ID).Delete(&Place{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(p).Error
+	})
+}
+
+func (s *GormProfiles) DeletePlace(ctx context.Context, userID, id string) error {
+	res := s.db.WithContext(ctx).Where("user_id = ? AND id = ?", userID, id).Delete(&Place{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNoPlace
+	}
+	return nil
+}
+
+func (s *GormProfiles) Trips(ctx context.Context, userID, kind string) ([]Trip, error) {
+	if _, err := s.GetUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	var trips []Trip
+	err := s.db.WithContext(ctx).Where("user_id = ? AND kind = ?", userID, kind).
+		Order("created_at DESC, id DESC").Find(&trips).Error
+	return trips, err
+}
+
+func (s *GormProfiles) GetTrip(ctx context.Context, userID string, id uint) (*Trip, error) {
+	var t Trip
+	err := s.db.WithContext(ctx).Where("user_id = ? AND id = ?", userID, id).First(&t).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoTrip
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *GormProfiles) AddTrip(ctx context.Context, t *Trip) error {
+	if _, err := s.GetUser(ctx, t.UserID); err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Create(t).Error
+}
+
+func (s *GormProfiles) DeleteTrip(ctx context.Context, userID string, id uint) error {
+	res := s.db.WithContext(ctx).Where("user_id = ? AND id = ?", userID, id).Delete(&Trip{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNoTrip
+	}
+	return nil
+}
+
+func (s *GormProfiles) RecordRecent(ctx context.Context, t *Trip, keep int) error {
+	if _, err := s.GetUser(ctx, t.UserID); err != nil {
+		return err
+	}
+	t.Kind = TripRecent
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND kind = ? AND start_ref = ? AND stop_refs = ? AND end_ref = ? AND options = ?",
+			t.UserID, TripRecent, t.Start, t.Stops, t.End, t.Options).Delete(&Trip{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(t).Error; err != nil {
+			return err
+		}
+		var stale []uint
+		if err := tx.Model(&Trip{}).Where("user_id = ? AND kind = ?", t.UserID, TripRecent).
+			Order("created_at DESC, id DESC").Offset(keep).Pluck("id", &stale).Error; err != nil {
+			return err
+		}
+		if len(stale) == 0 {
+			return nil
+		}
+		return tx.Where("id IN ?", stale).Delete(&Trip{}).Error
+	})
+}
+
+// ── Memory ──
+
+// MemoryProfiles holds profiles in process, for tests and deployments
+// without a database.
+type MemoryProfiles struct {
+	mu     sync.Mutex
+	users  map[string]User
+	places map[string]map[string]Place // user → place ID → place
+	trips  map[uint]Trip
+	nextID uint
+}
+
+func NewMemoryProfiles() *MemoryProfiles {
+	return &MemoryProfiles{
+		users:  make(map[string]User),
+		places: make(map[string]map[string]Place),
+		trips:  make(map[uint]Trip),
+	}
+}
+
+func (s *MemoryProfiles) PutUser(_ context.Context, u *User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.users[u.ID]; ok {
+		u.CreatedAt = existing.CreatedAt
+	} else if u.CreatedAt.IsZero() {
+		u.CreatedAt = time.Now()
+	}
+	s.users[u.ID] = *u
+	return nil
+}
+
+func (s *MemoryProfiles) GetUser(_ context.Context, id string) (*User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[id]
+	if !ok {
+		return nil, ErrNoUser
+	}
+	return &u, nil
+}
+
+func (s *MemoryProfiles) DeleteUser(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[id]; !ok {
+		return ErrNoUser
+	}
+	delete(s.users, id)
+	delete(s.places, id)
+	for tid, t := range s.trips {
+		if t.UserID == id {
+			delete(s.trips, tid)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryProfiles) Places(_ context.Context, userID string) ([]Place, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userID]; !ok {
+		return nil, ErrNoUser
+	}
+	places := make([]Place, 0, len(s.places[userID]))
+	for _, p := range s.places[userID] {
+		places = append(places, p)
+	}
+	sort.Slice(places, func(i, j int) bool { return places[i].ID < places[j].ID })
+	return places, nil
+}
+
+func (s *MemoryProfiles) PutPlace(_ context.Context, p *Place) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[p.UserID]; !ok {
+		return ErrNoUser
+	}
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = time.Now()
+	}
+	if s.places[p.UserID] == nil {
+		s.places[p.UserID] = make(map[string]Place)
+	}
+	s.places[p.UserID][p.ID] = *p
+	return nil
+}
+
+func (s *MemoryProfiles) DeletePlace(_ context.Context, userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.places[userID][id]; !ok {
+		return ErrNoPlace
+	}
+	delete(s.places[userID], id)
+	return nil
+}
+
+func (s *MemoryProfiles) Trips(_ context.Context, userID, kind string) ([]Trip, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userID]; !ok {
+		return nil, ErrNoUser
+	}
+	return s.tripsLocked(userID, kind), nil
+}
+
+func (s *MemoryProfiles) tripsLocked(userID, kind string) []Trip {
+	trips := []Trip{}
+	for _, t := range s.trips {
+		if t.UserID == userID && t.Kind == kind {
+			trips = append(trips, t)
+		}
+	}
+	sort.Slice(trips, func(i, j int) bool {
+		if !trips[i].CreatedAt.Equal(trips[j].CreatedAt) {
+			return trips[i].CreatedAt.After(trips[j].CreatedAt)
+		}
+		return trips[i].ID > trips[j].ID
+	})
+	return trips
+}
+
+func (s *MemoryProfiles) GetTrip(_ context.Context, userID string, id uint) (*Trip, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.trips[id]
+	if !ok || t.UserID != userID {
+		return nil, ErrNoTrip
+	}
+	return &t, nil
+}
+
+func (s *MemoryProfiles) AddTrip(_ context.Context, t *Trip) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[t.UserID]; !ok {
+		return ErrNoUser
+	}
+	s.addLocked(t)
+	return nil
+}
+
+func (s *MemoryProfiles) addLocked(t *Trip) {
+	s.nextID++
+	t.ID = s.nextID
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+	s.trips[t.ID] = *t
+}
+
+func (s *MemoryProfiles) DeleteTrip(_ context.Context, userID string, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.trips[id]
+	if !ok || t.UserID != userID {
+		return ErrNoTrip
+	}
+	delete(s.trips, id)
+	return nil
+}
+
+func (s *MemoryProfiles) RecordRecent(_ context.Context, t *Trip, keep int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[t.UserID]; !ok {
+		return ErrNoUser
+	}
+	t.Kind = TripRecent
+	for id, old := range s.trips {
+		if old.UserID == t.UserID && old.Kind == TripRecent && old.sameAs(t) {
+			delete(s.trips, id)
+		}
+	}
+	s.addLocked(t)
+	if recent := s.tripsLocked(t.UserID, TripRecent); len(recent) > keep {
+		for _, stale := range recent[keep:] {
+			delete(s.trips, stale.ID)
+		}
+	}
+	return nil
+}
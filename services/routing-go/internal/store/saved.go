@@ -0,0 +1,175 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrNoSavedRoute is returned when no saved route has the given ID or share
+// token.
+var ErrNoSavedRoute = errors.New("saved route not found")
+
+// SavedRoute is a computed EnhancedResponse kept under a generated ID so
+// dispatch can hand a driver the exact route. ShareToken grants read-only
+// access; ID also allows deletion.
+type SavedRoute struct {
+	ID         string `gorm:"primaryKey"`
+	ShareToken string `gorm:"uniqueIndex"`
+	Label      string
+	Data       []byte `gorm:"type:jsonb"`
+	CreatedAt  time.Time
+	// ExpiresAt is nil for routes kept until deleted.
+	ExpiresAt *time.Time `gorm:"index"`
+}
+
+// Expired reports whether the route has passed its expiry at now.
+func (r *SavedRoute) Expired(now time.Time) bool {
+	return r.ExpiresAt != nil && !now.Before(*r.ExpiresAt)
+}
+
+// SavedRoutes persists saved routes. Unlike RouteStore it is not a cache:
+// entries stay until deleted or purged after expiry.
+type SavedRoutes interface {
+	Save(ctx context.Context, r *SavedRoute) error
+	Get(ctx context.Context, id string) (*SavedRoute, error)
+	GetByToken(ctx context.Context, token string) (*SavedRoute, error)
+	Delete(ctx context.Context, id string) error
+	// PurgeExpired deletes routes expired at now and reports how many.
+	PurgeExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// ── GORM ──
+
+// GormSavedRoutes keeps saved routes in the saved_routes table.
+type GormSavedRoutes struct {
+	db *gorm.DB
+}
+
+// NewGormSavedRoutes creates the saved_routes table on db if needed.
+func NewGormSavedRoutes(db *gorm.DB) (*GormSavedRoutes, error) {
+	if err := db.AutoMigrate(&SavedRoute{}); err != nil {
+		return nil, err
+	}
+	return &GormSavedRoutes{db: db}, nil
+}
+
+func (s *GormSavedRoutes) Save(ctx context.Context, r *SavedRoute) error {
+	return s.db.WithContext(ctx).Create(r).Error
+}
+
+func (s *GormSavedRoutes) Get(ctx context.Context, id string) (*SavedRoute, error) {
+	return s.first(ctx, "id = ?", id)
+}
+
+func (s *GormSavedRoutes) GetByToken(ctx context.Context, token string) (*SavedRoute, error) {
+	return s.first(ctx, "share_token = ?", token)
+}
+
+func (s *GormSavedRoutes) first(ctx context.Context, query string, arg string) (*SavedRoute, error) {
+	var r SavedRoute
+	err := s.db.WithContext(ctx).Where(query, arg).First(&r).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoSavedRoute
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (s *GormSavedRoutes) Delete(ctx context.Context, id string) error {
+	res := s.db.WithContext(ctx).Where("id = ?", id).Delete(&SavedRoute{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNoSavedRoute
+	}
+	return nil
+}
+
+func (s *GormSavedRoutes) PurgeExpired(ctx context.Context, now time.Time) (int, error) {
+	res := s.db.WithContext(ctx).Where("expires_at IS NOT NULL AND expires_at <= ?", now).Delete(&SavedRoute{})
+	return int(res.RowsAffected), res.Error
+}
+
+// ── Memory ──
+
+// MemorySavedRoutes holds saved routes in process, for tests and
+// deployments without a database.
+type MemorySavedRoutes struct {
+	mu     sync.Mutex
+	byID   map[string]*SavedRoute
+	tokens map[string]string // share token → ID
+}
+
+func NewMemorySavedRoutes() *MemorySavedRoutes {
+	return &MemorySavedRoutes{byID: make(map[string]*SavedRoute), tokens: make(map[string]string)}
+}
+
+func (s *MemorySavedRoutes) Save(_ context.Context, r *SavedRoute) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now()
+	}
+	saved := *r
+	s.byID[r.ID] = &saved
+	s.tokens[r.ShareToken] = r.ID
+	return nil
+}
+
+func (s *MemorySavedRoutes) Get(_ context.Context, id string) (*SavedRoute, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.byID[id]
+	if !ok {
+		return nil, ErrNoSavedRoute
+	}
+	saved := *r
+	return &saved, nil
+}
+
+func (s *MemorySavedRoutes) GetByToken(ctx context.Context, token string) (*SavedRoute, error) {
+	s.mu.Lock()
+	id, ok := s.tokens[token]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrNoSavedRoute
+	}
+	return s.Get(ctx, id)
+}
+
+func (s *MemorySavedRoutes) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.byID[id]
+	if !ok {
+		return ErrNoSavedRoute
+	}
+	delete(s.tokens, r.ShareToken)
+	delete(s.byID, id)
+	return nil
+}
+
+func (s *MemorySavedRoutes) PurgeExpired(_ context.Context, now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for id, r := range s.byID {
+		if r.Expired(now) {
+			delete(s.tokens, r.ShareToken)
+			delete(s.byID, id)
+			n++
+		}
+	}
+	return n, nil
+}
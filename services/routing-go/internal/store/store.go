@@ -0,0 +1,56 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotFound is returned by Get when no route is cached for a pair.
+var ErrNotFound = errors.New("route not cached")
+
+// RouteStore persists computed EnhancedResponse payloads keyed by the
+// start/end location pair. Implementations must be safe for concurrent use.
+type RouteStore interface {
+	Get(ctx context.Context, startID, endID string) ([]byte, error)
+	Put(ctx context.Context, startID, endID string, data []byte) error
+	Clear(ctx context.Context) error
+	// Ping checks the backing database is reachable.
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// Driver names accepted by Open.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
+
+type Config struct {
+	Driver string
+	// DSN is the Postgres connection string.
+	DSN string
+	// SQLitePath is the database file used by the sqlite driver.
+	SQLitePath string
+	// MemoryCapacity bounds the number of pairs held by the memory driver.
+	MemoryCapacity int
+	// ConnectRetries and RetryDelay control how long Open waits for Postgres.
+	ConnectRetries int
+	RetryDelay     time.Duration
+}
+
+// Open builds the RouteStore selected by cfg.Driver.
+func Open(cfg Config) (RouteStore, error) {
+	switch cfg.Driver {
+	case DriverPostgres, "":
+		return OpenPostgres(cfg.DSN, cfg.ConnectRetries, cfg.RetryDelay)
+	case DriverSQLite:
+		return OpenSQLite(cfg.SQLitePath)
+	case DriverMemory:
+		return NewMemory(cfg.MemoryCapacity), nil
+	default:
+		return nil, fmt.Errorf("unknown route store driver %q", cfg.Driver)
+	}
+}
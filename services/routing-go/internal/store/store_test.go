@@ -0,0 +1,296 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/gorm"
+)
+
+func TestMemoryStore_GetPut(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemory(10)
+
+	_, err := s.Get(ctx, "phx", "tempe")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, s.Put(ctx, "phx", "tempe", []byte(`{"routes":[]}`)))
+	data, err := s.Get(ctx, "phx", "tempe")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"routes":[]}`, string(data))
+
+	// Direction matters: tempe → phx is a different pair
+	_, err = s.Get(ctx, "tempe", "phx")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemory(2)
+
+	s.Put(ctx, "a", "b", []byte("1"))
+	s.Put(ctx, "b", "c", []byte("2"))
+	s.Get(ctx, "a", "b") // touch a→b so b→c becomes the oldest
+	s.Put(ctx, "c", "d", []byte("3"))
+
+	assert.Equal(t, 2, s.Len())
+	_, err := s.Get(ctx, "b", "c")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Get(ctx, "a", "b")
+	assert.NoError(t, err)
+}
+
+func TestMemoryStore_Clear(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemory(0)
+
+	s.Put(ctx, "a", "b", []byte("1"))
+	assert.NoError(t, s.Clear(ctx))
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestSQLiteStore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s, err := OpenSQLite(filepath.Join(t.TempDir(), "routes.db"))
+	assert.NoError(t, err)
+	defer s.Close()
+
+	_, err = s.Get(ctx, "phx", "tucson")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, s.Put(ctx, "phx", "tucson", []byte(`{"routes":[]}`)))
+	data, err := s.Get(ctx, "phx", "tucson")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"routes":[]}`, string(data))
+
+	assert.NoError(t, s.Clear(ctx))
+	_, err = s.Get(ctx, "phx", "tucson")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSQLiteStore_PutReplaces(t *testing.T) {
+	ctx := context.Background()
+	s, err := OpenSQLite(filepath.Join(t.TempDir(), "routes.db"))
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Put(ctx, "phx", "tucson", []byte(`{"v":1}`)))
+	require.NoError(t, s.Put(ctx, "phx", "tucson", []byte(`{"v":2}`)))
+	data, err := s.Get(ctx, "phx", "tucson")
+	require.NoError(t, err)
+	assert.Equal(t, `{"v":2}`, string(data))
+
+	var rows int64
+	s.DB().Model(&RouteCache{}).Count(&rows)
+	assert.Equal(t, int64(1), rows)
+}
+
+func TestSQLiteStore_MigratesDuplicatePairs(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "routes.db")
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(`CREATE TABLE route_caches (id integer PRIMARY KEY AUTOINCREMENT, start_id text, end_id text, data jsonb, created_at datetime)`).Error)
+	require.NoError(t, db.Exec(`CREATE INDEX idx_route_pair ON route_caches (start_id, end_id)`).Error)
+	for _, v := range []string{`{"v":1}`, `{"v":2}`} {
+		require.NoError(t, db.Exec(`INSERT INTO route_caches (start_id, end_id, data) VALUES ('phx', 'tucson', ?)`, []byte(v)).Error)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.Close()
+
+	s, err := OpenSQLite(path)
+	require.NoError(t, err)
+	defer s.Close()
+	data, err := s.Get(ctx, "phx", "tucson")
+	require.NoError(t, err)
+	assert.Equal(t, `{"v":2}`, string(data), "the newest row survives")
+	require.NoError(t, s.Put(ctx, "phx", "tucson", []byte(`{"v":3}`)))
+	data, _ = s.Get(ctx, "phx", "tucson")
+	assert.Equal(t, `{"v":3}`, string(data))
+}
+
+func TestSQLiteStore_Ping(t *testing.T) {
+	ctx := context.Background()
+	s, err := OpenSQLite(filepath.Join(t.TempDir(), "routes.db"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Ping(ctx))
+	assert.NoError(t, s.Close())
+	assert.Error(t, s.Ping(ctx), "closed database")
+}
+
+func TestCheckpoints(t *testing.T) {
+	ctx := context.Background()
+	sqlite, err := OpenSQLite(filepath.Join(t.TempDir(), "routes.db"))
+	assert.NoError(t, err)
+	defer sqlite.Close()
+	gorm, err := NewGormCheckpoints(sqlite.DB())
+	assert.NoError(t, err)
+
+	for name, c := range map[string]Checkpoints{"memory": NewMemoryCheckpoints(), "gorm": gorm} {
+		_, err := c.Load(ctx, "precalc")
+		assert.ErrorIs(t, err, ErrNoCheckpoint, name)
+
+		assert.NoError(t, c.Save(ctx, "precalc", []byte(`{"next":3}`)), name)
+		assert.NoError(t, c.Save(ctx, "precalc", []byte(`{"next":7}`)), name)
+		data, err := c.Load(ctx, "precalc")
+		assert.NoError(t, err, name)
+		assert.Equal(t, `{"next":7}`, string(data), name)
+
+		assert.NoError(t, c.Delete(ctx, "precalc"), name)
+		_, err = c.Load(ctx, "precalc")
+		assert.ErrorIs(t, err, ErrNoCheckpoint, name)
+	}
+}
+
+func TestOpen_UnknownDriver(t *testing.T) {
+	_, err := Open(Config{Driver: "mongo"})
+	assert.Error(t, err)
+}
+
+func TestLineStringWKT(t *testing.T) {
+	wkt := lineStringWKT([][2]float64{{-112.074, 33.4484}, {-111.94, 33.4255}})
+	assert.Equal(t, "LINESTRING(-112.074 33.4484,-111.94 33.4255)", wkt)
+}
+
+func TestSavedRoutes(t *testing.T) {
+	ctx := context.Background()
+	sqlite, err := OpenSQLite(filepath.Join(t.TempDir(), "saved.db"))
+	assert.NoError(t, err)
+	defer sqlite.Close()
+	gormSaved, err := NewGormSavedRoutes(sqlite.DB())
+	assert.NoError(t, err)
+
+	for name, s := range map[string]SavedRoutes{"memory": NewMemorySavedRoutes(), "sqlite": gormSaved} {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now().Truncate(time.Second)
+			past, future := now.Add(-time.Hour), now.Add(time.Hour)
+			assert.NoError(t, s.Save(ctx, &SavedRoute{ID: "a", ShareToken: "tok-a", Data: []byte(`{"routes":[]}`), ExpiresAt: &future}))
+			assert.NoError(t, s.Save(ctx, &SavedRoute{ID: "b", ShareToken: "tok-b", Data: []byte(`{}`), ExpiresAt: &past}))
+			assert.NoError(t, s.Save(ctx, &SavedRoute{ID: "c", ShareToken: "tok-c", Data: []byte(`{}`)}))
+
+			r, err := s.Get(ctx, "a")
+			assert.NoError(t, err)
+			assert.Equal(t, `{"routes":[]}`, string(r.Data))
+			assert.False(t, r.Expired(now))
+			r, err = s.GetByToken(ctx, "tok-a")
+			assert.NoError(t, err)
+			assert.Equal(t, "a", r.ID)
+
+			n, err := s.PurgeExpired(ctx, now)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, n)
+			_, err = s.GetByToken(ctx, "tok-b")
+			assert.ErrorIs(t, err, ErrNoSavedRoute)
+
+			assert.NoError(t, s.Delete(ctx, "c"))
+			assert.ErrorIs(t, s.Delete(ctx, "c"), ErrNoSavedRoute)
+			_, err = s.Get(ctx, "c")
+			assert.ErrorIs(t, err, ErrNoSavedRoute)
+		})
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	ctx := context.Background()
+	sqlite, err := OpenSQLite(filepath.Join(t.TempDir(), "profiles.db"))
+	assert.NoError(t, err)
+	defer sqlite.Close()
+	gormProfiles, err := NewGormProfiles(sqlite.DB())
+	assert.NoError(t, err)
+
+	for name, s := range map[string]Profiles{"memory": NewMemoryProfiles(), "sqlite": gormProfiles} {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, s.PutPlace(ctx, &Place{UserID: "ana", ID: "home"}), ErrNoUser)
+			assert.NoError(t, s.PutUser(ctx, &User{ID: "ana", Name: "Ana"}))
+			assert.NoError(t, s.PutUser(ctx, &User{ID: "ana", Name: "Ana R."}))
+			u, err := s.GetUser(ctx, "ana")
+			assert.NoError(t, err)
+			assert.Equal(t, "Ana R.", u.Name)
+
+			assert.NoError(t, s.PutPlace(ctx, &Place{UserID: "ana", ID: "home", Label: "Home", Lat: 33.5, Lon: -112.1}))
+			assert.NoError(t, s.PutPlace(ctx, &Place{UserID: "ana", ID: "depot", Label: "Depot", Lat: 33.4, Lon: -112.0}))
+			assert.NoError(t, s.PutPlace(ctx, &Place{UserID: "ana", ID: "home", Label: "New home", Lat: 33.6, Lon: -112.2}))
+			places, err := s.Places(ctx, "ana")
+			assert.NoError(t, err)
+			assert.Len(t, places, 2)
+			assert.Equal(t, "New home", places[1].Label)
+			assert.NoError(t, s.DeletePlace(ctx, "ana", "depot"))
+			assert.ErrorIs(t, s.DeletePlace(ctx, "ana", "depot"), ErrNoPlace)
+
+			fav := &Trip{UserID: "ana", Kind: TripFavorite, Label: "Commute", Start: "place:home", End: "tempe"}
+			assert.NoError(t, s.AddTrip(ctx, fav))
+			got, err := s.GetTrip(ctx, "ana", fav.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, "place:home", got.Start)
+			_, err = s.GetTrip(ctx, "bob", fav.ID)
+			assert.ErrorIs(t, err, ErrNoTrip)
+
+			// Recent trips are deduplicated and capped
+			base := time.Now()
+			for i, end := range []string{"tempe", "mesa", "tempe", "tucson"} {
+				assert.NoError(t, s.RecordRecent(ctx, &Trip{UserID: "ana", Start: "phx", End: end, CreatedAt: base.Add(time.Duration(i) * time.Second)}, 2))
+			}
+			recent, err := s.Trips(ctx, "ana", TripRecent)
+			assert.NoError(t, err)
+			if assert.Len(t, recent, 2) {
+				assert.Equal(t, "tucson", recent[0].End)
+				assert.Equal(t, "tempe", recent[1].End)
+			}
+			favs, err := s.Trips(ctx, "ana", TripFavorite)
+			assert.NoError(t, err)
+			assert.Len(t, favs, 1)
+
+			assert.NoError(t, s.DeleteUser(ctx, "ana"))
+			_, err = s.Trips(ctx, "ana", TripRecent)
+			assert.ErrorIs(t, err, ErrNoUser)
+			_, err = s.GetTrip(ctx, "ana", fav.ID)
+			assert.ErrorIs(t, err, ErrNoTrip)
+		})
+	}
+}
+
+func TestGormStore_TracesStatements(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	defer otel.SetTracerProvider(prev)
+
+	s, err := OpenSQLite(filepath.Join(t.TempDir(), "traced.db"))
+	assert.NoError(t, err)
+	defer s.Close()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "GET /route")
+	assert.NoError(t, s.Put(ctx, "phx", "tucson", []byte(`{}`)))
+	_, err = s.Get(ctx, "phx", "yuma")
+	assert.ErrorIs(t, err, ErrNotFound)
+	parent.End()
+
+	var names []string
+	for _, span := range rec.Ended() {
+		if span.Parent().SpanID() == parent.SpanContext().SpanID() {
+			names = append(names, span.Name())
+			assert.Equal(t, "route_caches", attrValue(span, "db.collection.name"))
+			assert.Equal(t, codes.Unset, span.Status().Code, "a missing row is not an error")
+		}
+	}
+	assert.Equal(t, []string{"db create", "db query"}, names)
+}
+
+func attrValue(span sdktrace.ReadOnlySpan, key string) string {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value.AsString()
+		}
+	}
+	return ""
+}
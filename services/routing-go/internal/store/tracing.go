@@ -0,0 +1,71 @@
+package store
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"navifly/platform/tracing"
+)
+
+// tracePlugin records a client span for every GORM statement, parented to
+// the context passed with WithContext.
+type tracePlugin struct{}
+
+func (tracePlugin) Name() string { return "navifly:tracing" }
+
+const spanKey = "navifly:span"
+
+func (tracePlugin) Initialize(db *gorm.DB) error {
+	tracer := tracing.Tracer("navifly/store")
+	before := func(op string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			ctx, span := tracer.Start(tx.Statement.Context, "db "+op,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("db.system.name", tx.Dialector.Name()),
+					attribute.String("db.operation.name", op),
+				))
+			tx.Statement.Context = ctx
+			tx.InstanceSet(spanKey, span)
+		}
+	}
+	after := func(tx *gorm.DB) {
+		v, ok := tx.InstanceGet(spanKey)
+		if !ok {
+			return
+		}
+		span := v.(trace.Span)
+		if table := tx.Statement.Table; table != "" {
+			span.SetAttributes(attribute.String("db.collection.name", table))
+		}
+		span.SetAttributes(
+			attribute.String("db.query.text", tx.Statement.SQL.String()),
+			attribute.Int64("db.response.returned_rows", tx.RowsAffected),
+		)
+		if err := tx.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("navifly:trace_before_create", before("create")),
+		cb.Create().After("gorm:create").Register("navifly:trace_after_create", after),
+		cb.Query().Before("gorm:query").Register("navifly:trace_before_query", before("query")),
+		cb.Query().After("gorm:query").Register("navifly:trace_after_query", after),
+		cb.Update().Before("gorm:update").Register("navifly:trace_before_update", before("update")),
+		cb.Update().After("gorm:update").Register("navifly:trace_after_update", after),
+		cb.Delete().Before("gorm:delete").Register("navifly:trace_before_delete", before("delete")),
+		cb.Delete().After("gorm:delete").Register("navifly:trace_after_delete", after),
+		cb.Row().Before("gorm:row").Register("navifly:trace_before_row", before("row")),
+		cb.Row().After("gorm:row").Register("navifly:trace_after_row", after),
+		cb.Raw().Before("gorm:raw").Register("navifly:trace_before_raw", before("raw")),
+		cb.Raw().After("gorm:raw").Register("navifly:trace_after_raw", after),
+	)
+}
@@ -0,0 +1,234 @@
+package traffic
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Observation is a single speed reading reported by a vehicle.
+type Observation struct {
+	VehicleID string
+	Lat       float64
+	Lon       float64
+	SpeedKmh  float64
+	Time      time.Time
+}
+
+type Config struct {
+	// CellSizeDeg is the side of the lat/lon grid cell samples are bucketed into.
+	CellSizeDeg float64
+	// Window is how far back observations count towards the live speed.
+	Window time.Duration
+	// History is how far back observations count towards free-flow speed.
+	History time.Duration
+	// MaxSamplesPerCell caps memory for busy cells; the oldest are dropped.
+	MaxSamplesPerCell int
+	// MinSamples is the number of live samples needed before a segment is rated.
+	MinSamples int
+	// DefaultFreeFlowKmh is used until a cell has enough history of its own.
+	DefaultFreeFlowKmh float64
+}
+
+func DefaultConfig() Config {
+	return Config{
+		CellSizeDeg:        0.005, // ~500 m
+		Window:             15 * time.Minute,
+		History:            24 * time.Hour,
+		MaxSamplesPerCell:  500,
+		MinSamples:         3,
+		DefaultFreeFlowKmh: 90,
+	}
+}
+
+type cellKey struct{ x, y int32 }
+
+type sample struct {
+	speed float64
+	at    time.Time
+}
+
+// Model aggregates recent telemetry speeds into per-cell estimates. A nil
+// *Model is valid and rates every segment as unknown.
+type Model struct {
+	mu    sync.RWMutex
+	cfg   Config
+	cells map[cellKey][]sample
+	now   func() time.Time
+}
+
+func NewModel(cfg Config) *Model {
+	def := DefaultConfig()
+	if cfg.CellSizeDeg <= 0 {
+		cfg.CellSizeDeg = def.CellSizeDeg
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = def.Window
+	}
+	if cfg.History < cfg.Window {
+		cfg.History = def.History
+	}
+	if cfg.MaxSamplesPerCell <= 0 {
+		cfg.MaxSamplesPerCell = def.MaxSamplesPerCell
+	}
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = def.MinSamples
+	}
+	if cfg.DefaultFreeFlowKmh <= 0 {
+		cfg.DefaultFreeFlowKmh = def.DefaultFreeFlowKmh
+	}
+	return &Model{cfg: cfg, cells: make(map[cellKey][]sample), now: time.Now}
+}
+
+func (m *Model) cellOf(lat, lon float64) cellKey {
+	return cellKey{
+		x: int32(math.Floor(lon / m.cfg.CellSizeDeg)),
+		y: int32(math.Floor(lat / m.cfg.CellSizeDeg)),
+	}
+}
+
+// Add records an observation. Readings outside the history window or with
+// a negative speed are ignored. Late readings are inserted in time order,
+// which Prune and the MaxSamplesPerCell cap rely on.
+func (m *Model) Add(obs Observation) {
+	if obs.SpeedKmh < 0 || m.now().Sub(obs.Time) > m.cfg.History {
+		return
+	}
+	key := m.cellOf(obs.Lat, obs.Lon)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	samples := m.cells[key]
+	i := sort.Search(len(samples), func(i int) bool { return samples[i].at.After(obs.Time) })
+	samples = append(samples, sample{})
+	copy(samples[i+1:], samples[i:])
+	samples[i] = sample{speed: obs.SpeedKmh, at: obs.Time}
+	if len(samples) > m.cfg.MaxSamplesPerCell {
+		samples = samples[len(samples)-m.cfg.MaxSamplesPerCell:]
+	}
+	m.cells[key] = samples
+}
+
+// Prune drops samples older than the history window and empty cells.
+// Samples are kept in time order, so the stale ones are a prefix.
+func (m *Model) Prune() {
+	cutoff := m.now().Add(-m.cfg.History)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, samples := range m.cells {
+		i := 0
+		for i < len(samples) && samples[i].at.Before(cutoff) {
+			i++
+		}
+		if i == len(samples) {
+			delete(m.cells, key)
+		} else if i > 0 {
+			m.cells[key] = append([]sample(nil), samples[i:]...)
+		}
+	}
+}
+
+// Estimate is the observed traffic state along a stretch of road.
+type Estimate struct {
+	ObservedKmh float64
+	FreeFlowKmh float64
+	// Ratio is observed over free-flow speed, clamped to [0.05, 1].
+	Ratio   float64
+	Samples int
+	Known   bool
+}
+
+// Congestion maps the speed ratio onto the UI's low/moderate/high scale.
+func (e Estimate) Congestion() string {
+	switch {
+	case !e.Known:
+		return "unknown"
+	case e.Ratio >= 0.75:
+		return "low"
+	case e.Ratio >= 0.5:
+		return "moderate"
+	default:
+		return "high"
+	}
+}
+
+// EstimateLine rates the road under a [lon, lat] polyline using every grid
+// cell it passes through.
+func (m *Model) EstimateLine(coords [][2]float64) Estimate {
+	if m == nil || len(coords) == 0 {
+		return Estimate{Ratio: 1}
+	}
+
+	keys := m.cellsAlong(coords)
+	now := m.now()
+	liveCutoff := now.Add(-m.cfg.Window)
+	histCutoff := now.Add(-m.cfg.History)
+
+	var live, history []float64
+	m.mu.RLock()
+	for key := range keys {
+		for _, s := range m.cells[key] {
+			if s.at.Before(histCutoff) {
+				continue
+			}
+			history = append(history, s.speed)
+			if !s.at.Before(liveCutoff) {
+				live = append(live, s.speed)
+			}
+		}
+	}
+	m.mu.RUnlock()
+
+	est := Estimate{FreeFlowKmh: m.cfg.DefaultFreeFlowKmh, Ratio: 1, Samples: len(live)}
+	// Free flow is the 85th percentile of what this road has seen, the usual
+	// traffic-engineering proxy for unimpeded speed.
+	if len(history) >= 4*m.cfg.MinSamples {
+		if p85 := percentile(history, 0.85); p85 > 0 {
+			est.FreeFlowKmh = p85
+		}
+	}
+	if len(live) < m.cfg.MinSamples {
+		return est
+	}
+
+	sum := 0.0
+	for _, v := range live {
+		sum += v
+	}
+	est.ObservedKmh = sum / float64(len(live))
+	est.Ratio = math.Max(0.05, math.Min(1, est.ObservedKmh/est.FreeFlowKmh))
+	est.Known = true
+	return est
+}
+
+// cellsAlong returns the grid cells touched by the polyline, interpolating
+// long edges so no cell in between is skipped.
+func (m *Model) cellsAlong(coords [][2]float64) map[cellKey]struct{} {
+	keys := make(map[cellKey]struct{})
+	keys[m.cellOf(coords[0][1], coords[0][0])] = struct{}{}
+	for i := 1; i < len(coords); i++ {
+		a, b := coords[i-1], coords[i]
+		span := math.Max(math.Abs(b[0]-a[0]), math.Abs(b[1]-a[1]))
+		steps := int(math.Ceil(span / (m.cfg.CellSizeDeg / 2)))
+		if steps < 1 {
+			steps = 1
+		}
+		for s := 1; s <= steps; s++ {
+			t := float64(s) / float64(steps)
+			keys[m.cellOf(a[1]+(b[1]-a[1])*t, a[0]+(b[0]-a[0])*t)] = struct{}{}
+		}
+	}
+	return keys
+}
+
+func percentile(values []float64, p float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
@@ -0,0 +1,128 @@
+package traffic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testLine = [][2]float64{{-112.0740, 33.4484}, {-112.0700, 33.4484}}
+
+func newTestModel(now time.Time) *Model {
+	m := NewModel(DefaultConfig())
+	m.now = func() time.Time { return now }
+	return m
+}
+
+func TestEstimateLine_NoData(t *testing.T) {
+	m := newTestModel(time.Now())
+	est := m.EstimateLine(testLine)
+	assert.False(t, est.Known)
+	assert.Equal(t, 1.0, est.Ratio)
+	assert.Equal(t, "unknown", est.Congestion())
+}
+
+func TestEstimateLine_NilModel(t *testing.T) {
+	var m *Model
+	assert.Equal(t, "unknown", m.EstimateLine(testLine).Congestion())
+}
+
+func TestEstimateLine_LiveSpeeds(t *testing.T) {
+	now := time.Now()
+	m := newTestModel(now)
+	for _, v := range []float64{40, 45, 50} {
+		m.Add(Observation{Lat: 33.4484, Lon: -112.0720, SpeedKmh: v, Time: now.Add(-time.Minute)})
+	}
+
+	est := m.EstimateLine(testLine)
+	assert.True(t, est.Known)
+	assert.Equal(t, 3, est.Samples)
+	assert.InDelta(t, 45.0, est.ObservedKmh, 0.001)
+	assert.InDelta(t, 0.5, est.Ratio, 0.001)
+	assert.Equal(t, "moderate", est.Congestion())
+}
+
+func TestEstimateLine_LearnsFreeFlowFromHistory(t *testing.T) {
+	now := time.Now()
+	m := newTestModel(now)
+	// A 60 km/h road seen all day, now crawling at 15 km/h
+	for i := 0; i < 20; i++ {
+		m.Add(Observation{Lat: 33.4484, Lon: -112.0720, SpeedKmh: 60, Time: now.Add(-time.Duration(i+1) * time.Hour)})
+	}
+	for i := 0; i < 3; i++ {
+		m.Add(Observation{Lat: 33.4484, Lon: -112.0720, SpeedKmh: 15, Time: now})
+	}
+
+	est := m.EstimateLine(testLine)
+	assert.Equal(t, 60.0, est.FreeFlowKmh)
+	assert.InDelta(t, 0.25, est.Ratio, 0.001)
+	assert.Equal(t, "high", est.Congestion())
+}
+
+func TestEstimateLine_IgnoresStaleAndDistantSamples(t *testing.T) {
+	now := time.Now()
+	m := newTestModel(now)
+	for i := 0; i < 3; i++ {
+		m.Add(Observation{Lat: 33.4484, Lon: -112.0720, SpeedKmh: 10, Time: now.Add(-time.Hour)})
+		m.Add(Observation{Lat: 32.2226, Lon: -110.9747, SpeedKmh: 10, Time: now})
+	}
+	assert.False(t, m.EstimateLine(testLine).Known)
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Now()
+	m := newTestModel(now)
+	m.Add(Observation{Lat: 33.4484, Lon: -112.0720, SpeedKmh: 50, Time: now.Add(-23 * time.Hour)})
+	m.now = func() time.Time { return now.Add(2 * time.Hour) }
+	m.Prune()
+	assert.Empty(t, m.cells)
+}
+
+func TestAdd_KeepsSamplesInTimeOrder(t *testing.T) {
+	now := time.Now()
+	m := newTestModel(now)
+	m.cfg.MaxSamplesPerCell = 3
+	// A late reading arrives after newer ones
+	for _, age := range []time.Duration{time.Minute, 2 * time.Minute, 22 * time.Hour, 0} {
+		m.Add(Observation{Lat: 33.4484, Lon: -112.0720, SpeedKmh: 50, Time: now.Add(-age)})
+	}
+	samples := m.cells[m.cellOf(33.4484, -112.0720)]
+	assert.Len(t, samples, 3, "the oldest reading is the one capped")
+	for i := 1; i < len(samples); i++ {
+		assert.False(t, samples[i].at.Before(samples[i-1].at))
+	}
+
+	m = newTestModel(now)
+	for _, age := range []time.Duration{time.Minute, 0, 22 * time.Hour} {
+		m.Add(Observation{Lat: 33.4484, Lon: -112.0720, SpeedKmh: 50, Time: now.Add(-age)})
+	}
+	m.now = func() time.Time { return now.Add(3 * time.Hour) }
+	m.Prune()
+	assert.Len(t, m.cells[m.cellOf(33.4484, -112.0720)], 2, "the late stale reading is pruned")
+}
+
+func TestPoller_PollOnce(t *testing.T) {
+	ts := time.Now().Unix()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/vehicles", r.URL.Path)
+		fmt.Fprintf(w, `[{"vehicle_id":"car-001","lat":33.4484,"lon":-112.072,"speed":42,"timestamp":%d}]`, ts)
+	}))
+	defer srv.Close()
+
+	m := NewModel(DefaultConfig())
+	p := NewPoller(srv.URL, time.Second, m)
+
+	n, err := p.PollOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// Same ping again is not double counted
+	n, err = p.PollOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
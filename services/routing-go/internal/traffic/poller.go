@@ -0,0 +1,117 @@
+package traffic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ping mirrors the telemetry service's TelemetryPing payload.
+type ping struct {
+	VehicleID string  `json:"vehicle_id"`
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+	Speed     float64 `json:"speed"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+// Poller feeds a Model from the telemetry service's GET /vehicles endpoint.
+type Poller struct {
+	TelemetryURL string
+	Interval     time.Duration
+	Model        *Model
+	Client       *http.Client
+	// APIKey, when set, is sent as X-API-Key.
+	APIKey string
+
+	mu       sync.Mutex
+	lastSeen map[string]int64
+	failing  bool
+}
+
+func NewPoller(telemetryURL string, interval time.Duration, model *Model) *Poller {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	return &Poller{
+		TelemetryURL: strings.TrimRight(telemetryURL, "/"),
+		Interval:     interval,
+		Model:        model,
+		Client:       &http.Client{Timeout: 5 * time.Second},
+		lastSeen:     make(map[string]int64),
+	}
+}
+
+// Run polls until ctx is cancelled.
+func (p *Poller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := p.PollOnce(ctx)
+		p.mu.Lock()
+		if err != nil && !p.failing {
+			slog.WarnContext(ctx, "traffic poll failed", "retry_every", p.Interval.String(), "error", err)
+		} else if err == nil && p.failing {
+			slog.InfoContext(ctx, "traffic poll recovered", "observations", n)
+		}
+		p.failing = err != nil
+		p.mu.Unlock()
+		p.Model.Prune()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PollOnce fetches the latest vehicle states and records pings not seen
+// before. It returns the number of new observations.
+func (p *Poller) PollOnce(ctx context.Context) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.TelemetryURL+"/vehicles", nil)
+	if err != nil {
+		return 0, err
+	}
+	if p.APIKey != "" {
+		req.Header.Set("X-API-Key", p.APIKey)
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("telemetry returned %s", resp.Status)
+	}
+
+	var pings []ping
+	if err := json.NewDecoder(resp.Body).Decode(&pings); err != nil {
+		return 0, fmt.Errorf("decode vehicles: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	added := 0
+	for _, pg := range pings {
+		if pg.VehicleID == "" || p.lastSeen[pg.VehicleID] == pg.Timestamp {
+			continue
+		}
+		p.lastSeen[pg.VehicleID] = pg.Timestamp
+		p.Model.Add(Observation{
+			VehicleID: pg.VehicleID,
+			Lat:       pg.Lat,
+			Lon:       pg.Lon,
+			SpeedKmh:  pg.Speed,
+			Time:      time.Unix(pg.Timestamp, 0),
+		})
+		added++
+	}
+	return added, nil
+}
@@ -1,33 +1,87 @@
 package main
 
 import (
+	"context"
+	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
-	"log"
+	"log/slog"
 	"math"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
-)
 
-var (
-	db *gorm.DB
+	"navifly/platform/auth"
+	"navifly/platform/config"
+	"navifly/platform/logging"
+	"navifly/platform/metrics"
+	"navifly/platform/openapi"
+	"navifly/platform/problem"
+	"navifly/platform/ratelimit"
+	"navifly/platform/server"
+	"navifly/platform/tracing"
+	"navifly/routing/internal/avoid"
+	"navifly/routing/internal/elevation"
+	"navifly/routing/internal/energy"
+	"navifly/routing/internal/eta"
+	"navifly/routing/internal/routing"
+	"navifly/routing/internal/store"
+	"navifly/routing/internal/traffic"
 )
 
-// ── Database Models ──
+// Server carries the dependencies shared by the HTTP handlers so they can be
+// exercised in tests without a live database.
+type Server struct {
+	store   store.RouteStore
+	traffic *traffic.Model
+	// graph is the corridor network used for time-dependent ETAs.
+	graph *routing.Graph
+	eta   *eta.Estimator
+	// spatial is nil unless the store is Postgres with PostGIS available.
+	spatial store.SpatialIndex
+	// dem is nil unless ELEVATION_DIR points at DEM tiles.
+	dem elevation.Source
+	// chargers are loaded from CHARGERS_PATH for EV charging stops.
+	chargers []energy.Charger
+	// fences resolves avoid_fences against the mapmatch service.
+	fences *avoid.FenceClient
+	// saved holds routes saved with POST /routes.
+	saved store.SavedRoutes
+	// profiles holds users' places, favorites and recent trips.
+	profiles store.Profiles
+	// auth checks API keys and JWTs; disabled unless keys are configured.
+	auth *auth.Authenticator
+	// limiter throttles each API key or IP; off until main configures it.
+	limiter *ratelimit.Limiter
+	// checkpoints saves background job progress across restarts.
+	checkpoints store.Checkpoints
+	// probes answer /livez and /readyz; readiness pings the route store.
+	probes *server.Probes
+}
 
-type RouteCache struct {
-	ID        uint      `gorm:"primaryKey"`
-	StartID   string    `gorm:"index:idx_route_pair"`
-	EndID     string    `gorm:"index:idx_route_pair"`
-	Data      []byte    `gorm:"type:jsonb"`
-	CreatedAt time.Time
+func NewServer(routeStore store.RouteStore) *Server {
+	s := &Server{
+		store:       routeStore,
+		traffic:     traffic.NewModel(traffic.DefaultConfig()),
+		graph:       buildCorridorGraph(locations),
+		eta:         &eta.Estimator{Density: catalogDensity},
+		saved:       store.NewMemorySavedRoutes(),
+		profiles:    store.NewMemoryProfiles(),
+		auth:        auth.Disabled(),
+		limiter:     ratelimit.New(ratelimit.Config{}, nil),
+		checkpoints: store.NewMemoryCheckpoints(),
+		probes:      server.NewProbes(),
+	}
+	s.probes.Check("db", routeStore.Ping)
+	return s
 }
 
 // ── Shared Types ──
@@ -44,15 +98,39 @@ type FeatureCollection struct {
 }
 
 type EnhancedRoute struct {
-	Geometry   FeatureCollection `json:"geometry"`
-	Distance   float64           `json:"distance"`
-	Duration   float64           `json:"duration"`
-	Label      string            `json:"label"`
-	FullCoords [][2]float64      `json:"full_coords"`
+	Geometry FeatureCollection `json:"geometry"`
+	Distance float64           `json:"distance"`
+	// Duration is the traffic-adjusted ETA; FreeFlowDuration ignores traffic.
+	Duration         float64      `json:"duration"`
+	FreeFlowDuration float64      `json:"free_flow_duration"`
+	Label            string       `json:"label"`
+	FullCoords       [][2]float64 `json:"full_coords,omitempty"`
+	// FullPolyline replaces FullCoords for polyline5/polyline6 output.
+	FullPolyline string `json:"full_polyline,omitempty"`
+	// DepartAt and ArriveAt are set for depart_at / arrive_by requests.
+	DepartAt *time.Time `json:"depart_at,omitempty"`
+	ArriveAt *time.Time `json:"arrive_at,omitempty"`
+	// ETA explains how FreeFlowDuration was derived.
+	ETA *eta.Breakdown `json:"eta_breakdown,omitempty"`
+	// Instructions are turn-by-turn directions from OSRM steps.
+	Instructions []TurnInstruction `json:"instructions,omitempty"`
+	// Elevation is set when DEM tiles cover the route.
+	Elevation *elevation.Profile `json:"elevation,omitempty"`
+	// Energy is set for ev= requests.
+	Energy *EnergyReport `json:"energy,omitempty"`
+	// Segments feed the duration model. They are cached but stripped from
+	// responses.
+	Segments []eta.Segment `json:"segments,omitempty"`
 }
 
 type EnhancedResponse struct {
 	Routes []EnhancedRoute `json:"routes"`
+	// GeometryFormat is set when geometry is not plain GeoJSON coordinates.
+	GeometryFormat string `json:"geometry_format,omitempty"`
+	// Restrictions explains a truck detour around restricted roads.
+	Restrictions *RestrictionReport `json:"restrictions,omitempty"`
+	// SkippedStops are the unknown stops left out with on_unknown=skip.
+	SkippedStops []string `json:"skipped_stops,omitempty"`
 }
 
 type Location struct {
@@ -131,46 +209,234 @@ var locations = []Location{
 }
 
 func main() {
-	// Initialize Database
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		dsn = "host=localhost user=admin password=navifly dbname=navifly port=5432 sslmode=disable"
+	if err := logging.Setup("routing"); err != nil {
+		logging.Fatal("invalid logging config", logging.Err(err))
+	}
+	cfg := defaultConfig()
+	if err := config.Load(&cfg); err != nil {
+		logging.Fatal("failed to load config", logging.Err(err))
+	}
+	traceCfg, err := tracing.ConfigFromEnv()
+	if err != nil {
+		logging.Fatal("invalid tracing config", logging.Err(err))
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), "routing", traceCfg)
+	if err != nil {
+		logging.Fatal("failed to set up tracing", logging.Err(err))
 	}
+	defer shutdownTracing(context.Background())
 
-	var err error
-	for i := 0; i < 10; i++ {
-		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
-		if err == nil {
-			break
-		}
-		log.Printf("Waiting for database... (%d/10)", i+1)
-		time.Sleep(2 * time.Second)
+	// Initialize route store
+	routeStore, err := store.Open(cfg.Store.store())
+	if err != nil {
+		logging.Fatal("failed to open route store", "driver", cfg.Store.Driver, logging.Err(err))
 	}
+	defer routeStore.Close()
+	slog.Info("route store ready", "driver", cfg.Store.Driver)
+
+	srv := NewServer(routeStore)
+	if srv.auth, err = auth.New(auth.ConfigFromEnv()); err != nil {
+		logging.Fatal("failed to load credentials", logging.Err(err))
+	}
+	if !srv.auth.Enabled() {
+		slog.Warn("no API_KEYS_PATH or JWKS_PATH set, authentication is disabled")
+	}
+
+	// Cache misses go to the public OSRM server, so clients are throttled
+	// and get a daily quota, counted in Redis when REDIS_URL is set
+	limits, err := ratelimit.ConfigFromEnv(ratelimit.Config{Rate: 5, Burst: 10, DailyQuota: 5000})
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		logging.Fatal("invalid rate limits", logging.Err(err))
+	}
+	var quotas ratelimit.QuotaStore = ratelimit.NewMemoryQuotas()
+	if cfg.RedisURL != "" {
+		rdb := redis.NewClient(&redis.Options{Addr: cfg.RedisURL})
+		rdb.AddHook(metrics.RedisHook{})
+		rdb.AddHook(tracing.RedisHook{})
+		quotas = &ratelimit.RedisQuotas{Client: rdb}
+	}
+	srv.limiter = ratelimit.New(limits, quotas)
+	if gs, ok := routeStore.(*store.GormStore); ok && gs.DB().Dialector.Name() == "postgres" {
+		idx, err := store.NewPostGIS(gs.DB())
+		if err != nil {
+			slog.Warn("PostGIS unavailable, spatial route queries disabled", logging.Err(err))
+		} else {
+			srv.spatial = idx
+		}
+	}
+
+	// Saved routes, user profiles and job checkpoints share the database;
+	// the memory driver keeps them in process
+	if gs, ok := routeStore.(*store.GormStore); ok {
+		saved, err := store.NewGormSavedRoutes(gs.DB())
+		if err != nil {
+			logging.Fatal("failed to set up saved routes", logging.Err(err))
+		}
+		srv.saved = saved
+		profiles, err := store.NewGormProfiles(gs.DB())
+		if err != nil {
+			logging.Fatal("failed to set up user profiles", logging.Err(err))
+		}
+		srv.profiles = profiles
+		checkpoints, err := store.NewGormCheckpoints(gs.DB())
+		if err != nil {
+			logging.Fatal("failed to set up job checkpoints", logging.Err(err))
+		}
+		srv.checkpoints = checkpoints
+	}
+
+	// Historical speed profiles override the built-in commuter profiles
+	if path := cfg.SpeedProfilesPath; path != "" {
+		profiles, err := routing.LoadProfiles(path)
+		if err != nil {
+			logging.Fatal("failed to load speed profiles", "path", path, logging.Err(err))
+		}
+		slog.Info("loaded speed profiles", "count", len(profiles), "edges_matched", srv.graph.ApplyProfiles(profiles))
+	}
+
+	// Terrain profiles from local SRTM/GeoTIFF tiles
+	if dir := cfg.ElevationDir; dir != "" {
+		dem, err := elevation.OpenDir(dir)
+		if err != nil {
+			slog.Warn("elevation data unavailable, profiles disabled", logging.Err(err))
+		} else {
+			slog.Info("indexed DEM tiles", "count", dem.Tiles(), "dir", dir)
+			srv.dem = dem
+		}
+	}
+
+	// Truck height, weight, length and hazmat limits on corridor roads
+	if path := cfg.TruckRestrictionsPath; path != "" {
+		restrictions, err := routing.LoadRestrictions(path)
+		if err != nil {
+			logging.Fatal("failed to load truck restrictions", "path", path, logging.Err(err))
+		}
+		slog.Info("loaded truck restrictions", "count", len(restrictions), "edges_matched", srv.graph.ApplyRestrictions(restrictions))
+	}
+
+	// EV charging sites
+	if path := cfg.ChargersPath; path != "" {
+		chargers, err := energy.LoadChargers(path)
+		if err != nil {
+			logging.Fatal("failed to load chargers", "path", path, logging.Err(err))
+		}
+		slog.Info("loaded EV chargers", "count", len(chargers))
+		srv.chargers = chargers
+	}
+
+	osrmURL = strings.TrimRight(cfg.OSRMURL, "/")
+
+	// Geofences for avoid_fences
+	srv.fences = avoid.NewFenceClient(cfg.MapmatchURL)
+	srv.fences.APIKey = cfg.MapmatchAPIKey
+	srv.fences.HTTP.Transport = logging.Transport(tracing.Transport(nil))
+
+	ctx, stop := server.SignalContext()
+	defer stop()
+
+	// Background jobs stop once requests have drained
+	jobs, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go srv.purgeSavedRoutes(jobs, time.Hour)
+
+	// Feed the traffic model from live telemetry
+	poller := traffic.NewPoller(cfg.TelemetryURL, 10*time.Second, srv.traffic)
+	poller.APIKey = cfg.TelemetryAPIKey
+	poller.Client.Transport = logging.Transport(tracing.Transport(nil))
+	go poller.Run(jobs)
+
+	// Pre-populate cache with REAL OSRM road geometry, resuming from the
+	// last checkpoint
+	precalcDone := make(chan struct{})
+	go func() {
+		defer close(precalcDone)
+		srv.preCalculateRealRoutes(jobs)
+	}()
+
+	corsObj := handlers.CORS(
+		handlers.AllowedOrigins([]string{"*"}),
+		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
+		handlers.AllowedHeaders([]string{"Content-Type", "Authorization", "X-API-Key", logging.HeaderRequestID}),
+		handlers.ExposedHeaders([]string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After", logging.HeaderRequestID}),
+	)
+
+	// gRPC API alongside REST
+	app := server.New(cfg.Server, corsObj(srv.Router()))
+	app.GRPC = srv.GRPCServer()
+	app.Probes = srv.probes
+	app.OnShutdown(func(ctx context.Context) error {
+		stopJobs()
+		select {
+		case <-precalcDone:
+			return nil
+		case <-ctx.Done():
+			return fmt.Errorf("pre-calculation did not checkpoint in time: %w", ctx.Err())
+		}
+	})
+
+	slog.Info("routing service starting")
+	if err := app.Run(ctx); err != nil {
+		slog.Error("routing service stopped", logging.Err(err))
+		os.Exit(1)
 	}
+}
 
-	// Migrate schema
-	db.AutoMigrate(&RouteCache{})
+// protect requires scope, charges the caller's rate limit and then
+// validates the request against the API spec.
+func (s *Server) protect(scope string, h http.HandlerFunc) http.Handler {
+	return s.auth.Require(scope, s.limiter.Limit(apiSpec.Middleware(h)))
+}
 
-	// Pre-populate cache with REAL OSRM road geometry
-	go preCalculateRealRoutes()
+//go:embed openapi.yaml
+var openapiYAML []byte
 
-	log.Println("Routing service starting on :8080...")
+// apiSpec documents every route in Router; protect and userOnly validate
+// requests against it.
+var apiSpec = openapi.MustLoad(openapiYAML)
 
+// Router registers every routing endpoint on a fresh mux.Router.
+func (s *Server) Router() *mux.Router {
 	r := mux.NewRouter()
+	r.Use(tracing.Middleware("routing"), logging.Middleware, metrics.Middleware)
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
+	apiSpec.Register(r)
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	}).Methods("GET")
+	s.probes.Register(r)
 
 	r.HandleFunc("/locations", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(locations)
 	}).Methods("GET")
 
-	r.HandleFunc("/osrm-route", HandleRoute).Methods("GET")
-	r.HandleFunc("/route", HandleRoute).Methods("GET")
+	r.Handle("/osrm-route", s.protect(auth.ScopeRoutesRead, s.HandleRoute)).Methods("GET")
+	r.Handle("/route", s.protect(auth.ScopeRoutesRead, s.HandleRoute)).Methods("GET")
+	r.Handle("/route/export", s.protect(auth.ScopeRoutesRead, s.HandleRouteExport)).Methods("GET")
+	r.Handle("/reroute", s.protect(auth.ScopeRoutesRead, s.HandleReroute)).Methods("POST")
+	r.Handle("/routes/through", s.protect(auth.ScopeRoutesRead, s.HandleRoutesThrough)).Methods("GET")
+	r.Handle("/routes/near", s.protect(auth.ScopeRoutesRead, s.HandleRoutesNear)).Methods("GET")
+	r.Handle("/routes", s.protect(auth.ScopeRoutesWrite, s.HandleSaveRoute)).Methods("POST")
+	r.Handle("/routes/{id}", s.protect(auth.ScopeRoutesRead, s.HandleGetSavedRoute)).Methods("GET")
+	r.Handle("/routes/{id}", s.protect(auth.ScopeRoutesWrite, s.HandleDeleteSavedRoute)).Methods("DELETE")
+	r.HandleFunc("/share/{token}", s.HandleSharedRoute).Methods("GET")
+
+	// Profiles are private to their user unless the caller holds users:admin
+	r.Handle("/users/{id}", s.userOnly(s.HandlePutUser)).Methods("PUT")
+	r.Handle("/users/{id}", s.userOnly(s.HandleGetUser)).Methods("GET")
+	r.Handle("/users/{id}", s.userOnly(s.HandleDeleteUser)).Methods("DELETE")
+	r.Handle("/users/{id}/route", s.userOnly(s.HandleUserRoute)).Methods("GET")
+	r.Handle("/users/{id}/places", s.userOnly(s.HandleListPlaces)).Methods("GET")
+	r.Handle("/users/{id}/places/{place}", s.userOnly(s.HandlePutPlace)).Methods("PUT")
+	r.Handle("/users/{id}/places/{place}", s.userOnly(s.HandleDeletePlace)).Methods("DELETE")
+	r.Handle("/users/{id}/favorites", s.userOnly(s.HandleListTrips(store.TripFavorite))).Methods("GET")
+	r.Handle("/users/{id}/favorites", s.userOnly(s.HandleAddFavorite)).Methods("POST")
+	r.Handle("/users/{id}/favorites/{trip}", s.userOnly(s.HandleDeleteTrip)).Methods("DELETE")
+	r.Handle("/users/{id}/favorites/{trip}/route", s.userOnly(s.HandleReplayTrip)).Methods("GET")
+	r.Handle("/users/{id}/trips", s.userOnly(s.HandleListTrips(store.TripRecent))).Methods("GET")
+	r.Handle("/users/{id}/trips/{trip}", s.userOnly(s.HandleDeleteTrip)).Methods("DELETE")
+	r.Handle("/users/{id}/trips/{trip}/route", s.userOnly(s.HandleReplayTrip)).Methods("GET")
 
 	// Add Root Handler for health checks
 	r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -178,52 +444,41 @@ func main() {
 		fmt.Fprint(w, "NaviFly Routing Service Online 🛣️")
 	}).Methods("GET")
 
-	corsObj := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-	)
-
-	log.Fatal(http.ListenAndServe(":8080", corsObj(r)))
+	return r
 }
 
-func HandleRoute(w http.ResponseWriter, r *http.Request) {
+func (s *Server) HandleRoute(w http.ResponseWriter, r *http.Request) {
 	startID := r.URL.Query().Get("start")
 	endID := r.URL.Query().Get("end")
-	stopsParam := r.URL.Query().Get("stops") // comma-separated location IDs
 
 	if startID == "" || endID == "" {
-		http.Error(w, "Missing start or end parameter", http.StatusBadRequest)
+		problem.Write(w, r, problem.InvalidRequest, "Missing start or end parameter")
 		return
 	}
 
-	// If stops are provided, use multi-waypoint routing (skip cache)
-	if stopsParam != "" {
-		log.Printf("Multi-stop route: %s → [%s] → %s", startID, stopsParam, endID)
-		resp, err := fetchMultiStopRoute(startID, endID, stopsParam)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(resp)
+	opts, err := parseRouteOptions(r.URL.Query())
+	if err != nil {
+		problem.WriteError(w, r, err, problem.InvalidRequest)
 		return
 	}
 
-	// 1. Check DB Cache (only for direct A→B routes)
-	var cached RouteCache
-	if err := db.Where("start_id = ? AND end_id = ?", startID, endID).First(&cached).Error; err == nil {
-		log.Printf("✅ DB hit: %s → %s", startID, endID)
-		w.Header().Set("Content-Type", "application/json")
-		w.Write(cached.Data)
+	if err := s.resolveAvoid(r.Context(), &opts); err != nil {
+		problem.WriteError(w, r, err, problem.UpstreamUnavailable)
+		return
+	}
+	stopsParam, err := s.planCorridor(&opts)
+	if err != nil {
+		problem.WriteError(w, r, err, problem.NoRoute)
 		return
 	}
 
-	// 2. Fetch from OSRM on cache miss
-	log.Printf("DB miss. Fetching real route from OSRM: %s → %s", startID, endID)
-	resp, err := fetchAndCacheRoute(startID, endID)
+	resp, err := s.loadRoute(r.Context(), startID, endID, stopsParam, opts.stopNames())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		problem.WriteError(w, r, err, problem.UpstreamUnavailable)
+		return
+	}
+	if err := s.finishRoute(resp, opts); err != nil {
+		problem.WriteError(w, r, err, problem.NoRoute)
 		return
 	}
 
@@ -231,19 +486,185 @@ func HandleRoute(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+// loadRoute returns the untimed base route, from the route store when
+// possible and from OSRM otherwise. stopNames names the stops as
+// stepInstructions wants them.
+func (s *Server) loadRoute(ctx context.Context, startID, endID, stopsParam string, stopNames []string) (*EnhancedResponse, error) {
+	// If stops are provided, use multi-waypoint routing (skip cache)
+	if stopsParam != "" {
+		slog.InfoContext(ctx, "route lookup", logging.KeyStart, startID, logging.KeyEnd, endID, "stops", stopsParam, logging.KeyCache, "bypass")
+		return fetchMultiStopRoute(ctx, startID, endID, stopsParam, stopNames)
+	}
+
+	// 1. Check route store (only for direct A→B routes)
+	cached, err := s.store.Get(ctx, startID, endID)
+	if err == nil {
+		var resp EnhancedResponse
+		if err := json.Unmarshal(cached, &resp); err == nil {
+			slog.InfoContext(ctx, "route lookup", logging.KeyStart, startID, logging.KeyEnd, endID, logging.KeyCache, "hit")
+			cacheLookups.WithLabelValues("hit").Inc()
+			return &resp, nil
+		}
+		slog.WarnContext(ctx, "discarding unreadable cache entry", logging.KeyStart, startID, logging.KeyEnd, endID)
+		cacheLookups.WithLabelValues("miss").Inc()
+	} else if errors.Is(err, store.ErrNotFound) {
+		cacheLookups.WithLabelValues("miss").Inc()
+	} else {
+		slog.WarnContext(ctx, "route store lookup failed", logging.KeyStart, startID, logging.KeyEnd, endID, logging.Err(err))
+		cacheLookups.WithLabelValues("error").Inc()
+	}
+
+	// 2. Fetch from OSRM on cache miss
+	slog.InfoContext(ctx, "route lookup", logging.KeyStart, startID, logging.KeyEnd, endID, logging.KeyCache, "miss")
+	return s.fetchAndCacheRoute(ctx, startID, endID)
+}
+
+// routeOptions are the optional /route query parameters that shape how a
+// computed route is finished before it is sent.
+type routeOptions struct {
+	// waypoints are start, stops and end, in travel order.
+	waypoints []string
+	// skippedStops are the unknown stops on_unknown=skip left out.
+	skippedStops []string
+	departAt     time.Time
+	arriveBy     time.Time
+	vehicle      eta.VehicleProfile
+	// format is geojson, polyline5 or polyline6; simplifyM is the
+	// Douglas-Peucker tolerance in metres (0 keeps every vertex).
+	format    string
+	simplifyM float64
+	// maxGrade drops alternatives steeper than this percentage.
+	maxGrade float64
+	// ev is nil unless an EV energy estimate was requested.
+	ev *evOptions
+	// truck is nil for vehicles without size restrictions.
+	truck *routing.Dimensions
+	// avoid are inline areas plus, after resolveAvoid, the avoidFences.
+	avoid       []avoid.Area
+	avoidFences []string
+	// detour is set by planCorridor when restrictions or avoid areas
+	// change the corridor path.
+	detour *RestrictionReport
+}
+
+func (o routeOptions) timed() bool {
+	return !o.departAt.IsZero() || !o.arriveBy.IsZero()
+}
+
+// parseRouteOptions checks the start, stops and end against the catalog
+// and parses the other options.
+func parseRouteOptions(q url.Values) (routeOptions, error) {
+	stops, skipped, err := catalogWaypoints.check(q.Get("start"), splitStops(q.Get("stops")), q.Get("end"), q.Get("on_unknown"))
+	if err != nil {
+		return routeOptions{}, err
+	}
+	opts, err := parseTripOptions(q, append(append([]string{q.Get("start")}, stops...), q.Get("end")))
+	opts.skippedStops = skipped
+	return opts, err
+}
+
+// parseTripOptions parses the options for waypoints that are already
+// checked.
+func parseTripOptions(q url.Values, waypoints []string) (routeOptions, error) {
+	opts := routeOptions{waypoints: waypoints}
+
+	var err error
+	if opts.vehicle, err = eta.LookupProfile(q.Get("vehicle")); err != nil {
+		return opts, err
+	}
+	if opts.truck, err = parseTruckOptions(q, opts.vehicle.Type); err != nil {
+		return opts, err
+	}
+	if opts.avoid, opts.avoidFences, err = parseAvoid(q); err != nil {
+		return opts, err
+	}
+	if opts.format, opts.simplifyM, err = parseGeometryOptions(q); err != nil {
+		return opts, err
+	}
+	if opts.maxGrade, err = parseMaxGrade(q); err != nil {
+		return opts, err
+	}
+	if opts.ev, err = parseEVOptions(q); err != nil {
+		return opts, err
+	}
+	opts.departAt, opts.arriveBy, err = parseTripTime(q)
+	return opts, err
+}
+
+// finishRoute drops alternatives that enter avoid areas or exceed
+// max_grade, plans EV charging, estimates durations for the vehicle, applies
+// live traffic or historical profiles for timed trips, records any corridor
+// detour, and formats the geometry.
+func (s *Server) finishRoute(resp *EnhancedResponse, opts routeOptions) error {
+	if err := rejectCrossing(resp, opts.avoid); err != nil {
+		return err
+	}
+	if err := s.applyElevation(resp, opts.maxGrade); err != nil {
+		return err
+	}
+	if err := s.applyEnergy(resp, opts.ev, opts.vehicle); err != nil {
+		return err
+	}
+	s.estimateDurations(resp, opts.vehicle)
+	s.applyTraffic(resp)
+	waypoints := opts.waypoints
+	if opts.detour != nil {
+		resp.Restrictions = opts.detour
+		waypoints = opts.detour.waypoints
+	}
+	if opts.timed() {
+		if err := s.scheduleRoutes(resp, waypoints, opts.departAt, opts.arriveBy); err != nil {
+			return err
+		}
+	}
+	addChargingTime(resp, !opts.arriveBy.IsZero())
+	formatGeometry(resp, opts.format, opts.simplifyM)
+	resp.SkippedStops = opts.skippedStops
+	return nil
+}
+
 // ── OSRM Integration ──
 
+// osrmURL is the OSRM server, overridable with OSRM_URL.
+var osrmURL = "http://router.project-osrm.org"
+
+// osrmClient traces OSRM calls and passes the trace context upstream.
+var osrmClient = &http.Client{Timeout: 30 * time.Second, Transport: tracing.Transport(nil)}
+
 type OSRMResponse struct {
 	Routes []struct {
 		Geometry struct {
 			Coordinates [][]float64 `json:"coordinates"`
 		} `json:"geometry"`
-		Distance float64 `json:"distance"`
-		Duration float64 `json:"duration"`
+		Distance float64   `json:"distance"`
+		Duration float64   `json:"duration"`
+		Legs     []OSRMLeg `json:"legs"`
 	} `json:"routes"`
 	Code string `json:"code"`
 }
 
+// OSRMLeg is the part of a route between two consecutive waypoints.
+type OSRMLeg struct {
+	Steps []OSRMStep `json:"steps"`
+}
+
+type OSRMStep struct {
+	Distance float64 `json:"distance"`
+	Duration float64 `json:"duration"`
+	Name     string  `json:"name"`
+	Ref      string  `json:"ref"`
+	Maneuver struct {
+		Type     string     `json:"type"`
+		Modifier string     `json:"modifier"`
+		Location [2]float64 `json:"location"`
+		Exit     int        `json:"exit"`
+	} `json:"maneuver"`
+	Intersections []struct {
+		Location [2]float64 `json:"location"`
+		Classes  []string   `json:"classes"`
+	} `json:"intersections"`
+}
+
 func findLocation(id string) (Location, bool) {
 	for _, loc := range locations {
 		if loc.ID == id {
@@ -253,14 +674,14 @@ func findLocation(id string) (Location, bool) {
 	return Location{}, false
 }
 
-func fetchMultiStopRoute(startID, endID, stopsParam string) (*EnhancedResponse, error) {
+func fetchMultiStopRoute(ctx context.Context, startID, endID, stopsParam string, stopNames []string) (*EnhancedResponse, error) {
 	startLoc, ok := findLocation(startID)
 	if !ok {
-		return nil, fmt.Errorf("unknown start location: %s", startID)
+		return nil, problem.Errorf(problem.UnknownLocation, "unknown start location: %s", startID)
 	}
 	endLoc, ok := findLocation(endID)
 	if !ok {
-		return nil, fmt.Errorf("unknown end location: %s", endID)
+		return nil, problem.Errorf(problem.UnknownLocation, "unknown end location: %s", endID)
 	}
 
 	// Build coordinate string: start;stop1;stop2;...;end
@@ -270,8 +691,7 @@ func fetchMultiStopRoute(startID, endID, stopsParam string) (*EnhancedResponse,
 	for _, sid := range stopIDs {
 		sLoc, ok := findLocation(sid)
 		if !ok {
-			log.Printf("⚠️ Unknown stop ID %s, skipping", sid)
-			continue
+			return nil, problem.Errorf(problem.UnknownLocation, "unknown stop location: %s", sid)
 		}
 		coordParts = append(coordParts, fmt.Sprintf("%f,%f", sLoc.Lon, sLoc.Lat))
 	}
@@ -288,54 +708,41 @@ func fetchMultiStopRoute(startID, endID, stopsParam string) (*EnhancedResponse,
 	}
 
 	url := fmt.Sprintf(
-		"http://router.project-osrm.org/route/v1/driving/%s?overview=full&geometries=geojson&alternatives=false",
-		coordStr,
+		"%s/route/v1/driving/%s?overview=full&geometries=geojson&steps=true&alternatives=false",
+		osrmURL, coordStr,
 	)
 
-	log.Printf("🗺️ Multi-stop OSRM URL: %s", url)
+	slog.DebugContext(ctx, "multi-stop OSRM request", "url", url)
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(url)
+	osrmResp, err := getOSRM(ctx, url)
 	if err != nil {
-		return nil, fmt.Errorf("OSRM multi-stop request failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read OSRM response: %v", err)
-	}
-
-	var osrmResp OSRMResponse
-	if err := json.Unmarshal(body, &osrmResp); err != nil {
-		return nil, fmt.Errorf("failed to parse OSRM response: %v", err)
-	}
-
-	if osrmResp.Code != "Ok" || len(osrmResp.Routes) == 0 {
-		return nil, fmt.Errorf("OSRM returned no routes (code: %s)", osrmResp.Code)
+		return nil, err
 	}
 
 	// Build enhanced response
 	enhancedResp := EnhancedResponse{Routes: []EnhancedRoute{}}
 
-	for i, osrmRoute := range osrmResp.Routes {
+	for _, osrmRoute := range osrmResp.Routes {
 		coords := make([][2]float64, len(osrmRoute.Geometry.Coordinates))
 		for j, c := range osrmRoute.Geometry.Coordinates {
 			coords[j] = [2]float64{c[0], c[1]}
 		}
 
-		fc := buildTrafficFeatureCollection(coords, i)
+		steps := legSteps(osrmRoute.Legs)
 
 		enhancedResp.Routes = append(enhancedResp.Routes, EnhancedRoute{
-			Geometry:   fc,
-			Distance:   osrmRoute.Distance,
-			Duration:   osrmRoute.Duration,
-			Label:      "Multi-Stop Route",
-			FullCoords: coords,
+			Geometry:         buildTrafficFeatureCollection(coords, nil),
+			Distance:         osrmRoute.Distance,
+			Duration:         osrmRoute.Duration,
+			FreeFlowDuration: osrmRoute.Duration,
+			Label:            "Multi-Stop Route",
+			FullCoords:       coords,
+			Segments:         stepSegments(steps),
+			Instructions:     stepInstructions(osrmRoute.Legs, stopNames),
 		})
 	}
 
-	log.Printf("✅ Multi-stop route: %d waypoints, %.1f km", len(coordParts), osrmResp.Routes[0].Distance/1000)
+	slog.InfoContext(ctx, "multi-stop route", logging.KeyStart, startID, logging.KeyEnd, endID, "waypoints", len(coordParts), "distance_km", math.Round(osrmResp.Routes[0].Distance/100)/10)
 	return &enhancedResp, nil
 }
 
@@ -374,56 +781,74 @@ func trimSpace(s string) string {
 	return s[start:end]
 }
 
-func fetchOSRMRoute(startLon, startLat, endLon, endLat float64, alternatives bool) (*OSRMResponse, error) {
+func fetchOSRMRoute(ctx context.Context, startLon, startLat, endLon, endLat float64, alternatives bool) (*OSRMResponse, error) {
 	altParam := "false"
 	if alternatives {
 		altParam = "true"
 	}
 	url := fmt.Sprintf(
-		"http://router.project-osrm.org/route/v1/driving/%f,%f;%f,%f?overview=full&geometries=geojson&alternatives=%s",
-		startLon, startLat, endLon, endLat, altParam,
+		"%s/route/v1/driving/%f,%f;%f,%f?overview=full&geometries=geojson&steps=true&alternatives=%s",
+		osrmURL, startLon, startLat, endLon, endLat, altParam,
 	)
+	return getOSRM(ctx, url)
+}
+
+// getOSRM fetches and decodes an OSRM route service response.
+func getOSRM(ctx context.Context, url string) (_ *OSRMResponse, err error) {
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		osrmDuration.Observe(elapsed.Seconds())
+		latency := slog.Float64(logging.KeyUpstreamMS, float64(elapsed.Microseconds())/1000)
+		if err != nil {
+			slog.WarnContext(ctx, "upstream request failed", logging.KeyUpstream, "osrm", latency, logging.Err(err))
+			return
+		}
+		slog.InfoContext(ctx, "upstream request", logging.KeyUpstream, "osrm", latency)
+	}()
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("OSRM request failed: %v", err)
+		return nil, err
+	}
+	resp, err := osrmClient.Do(req)
+	if err != nil {
+		osrmErrors.WithLabelValues("transport").Inc()
+		return nil, problem.Errorf(problem.UpstreamUnavailable, "OSRM request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read OSRM response: %v", err)
+		osrmErrors.WithLabelValues("read").Inc()
+		return nil, problem.Errorf(problem.UpstreamUnavailable, "failed to read OSRM response: %w", err)
 	}
 
 	var osrmResp OSRMResponse
 	if err := json.Unmarshal(body, &osrmResp); err != nil {
-		return nil, fmt.Errorf("failed to parse OSRM response: %v", err)
+		osrmErrors.WithLabelValues("parse").Inc()
+		return nil, problem.Errorf(problem.UpstreamUnavailable, "failed to parse OSRM response: %w", err)
 	}
 
 	if osrmResp.Code != "Ok" || len(osrmResp.Routes) == 0 {
-		return nil, fmt.Errorf("OSRM returned no routes (code: %s)", osrmResp.Code)
+		osrmErrors.WithLabelValues("no_route").Inc()
+		return nil, problem.Errorf(problem.NoRoute, "OSRM returned no routes (code: %s)", osrmResp.Code)
 	}
 
 	return &osrmResp, nil
 }
 
-func fetchAndCacheRoute(startID, endID string) (*EnhancedResponse, error) {
-	var startLoc, endLoc Location
-	for _, loc := range locations {
-		if loc.ID == startID {
-			startLoc = loc
-		}
-		if loc.ID == endID {
-			endLoc = loc
-		}
+func (s *Server) fetchAndCacheRoute(ctx context.Context, startID, endID string) (*EnhancedResponse, error) {
+	startLoc, ok := findLocation(startID)
+	if !ok {
+		return nil, problem.Errorf(problem.UnknownLocation, "unknown start location: %s", startID)
 	}
-
-	if startLoc.ID == "" || endLoc.ID == "" {
-		return nil, fmt.Errorf("unknown location ID")
+	endLoc, ok := findLocation(endID)
+	if !ok {
+		return nil, problem.Errorf(problem.UnknownLocation, "unknown end location: %s", endID)
 	}
 
-	osrmResp, err := fetchOSRMRoute(startLoc.Lon, startLoc.Lat, endLoc.Lon, endLoc.Lat, true)
+	osrmResp, err := fetchOSRMRoute(ctx, startLoc.Lon, startLoc.Lat, endLoc.Lon, endLoc.Lat, true)
 	if err != nil {
 		return nil, err
 	}
@@ -431,7 +856,6 @@ func fetchAndCacheRoute(startID, endID string) (*EnhancedResponse, error) {
 	enhancedResp := EnhancedResponse{Routes: []EnhancedRoute{}}
 
 	labels := []string{"Fastest", "Scenic Route", "Alternative"}
-	speeds := []float64{90.0, 85.0, 80.0}
 
 	for i, osrmRoute := range osrmResp.Routes {
 		coords := make([][2]float64, len(osrmRoute.Geometry.Coordinates))
@@ -439,90 +863,177 @@ func fetchAndCacheRoute(startID, endID string) (*EnhancedResponse, error) {
 			coords[j] = [2]float64{c[0], c[1]} // [lon, lat]
 		}
 
-		fc := buildTrafficFeatureCollection(coords, i)
-
 		label := "Alternative"
 		if i < len(labels) {
 			label = labels[i]
 		}
-		speed := speeds[0]
-		if i < len(speeds) {
-			speed = speeds[i]
-		}
+		steps := legSteps(osrmRoute.Legs)
 
 		enhancedResp.Routes = append(enhancedResp.Routes, EnhancedRoute{
-			Geometry:   fc,
-			Distance:   osrmRoute.Distance,
-			Duration:   (osrmRoute.Distance / 1000.0 / speed) * 3600,
-			Label:      label,
-			FullCoords: coords,
+			Geometry:         buildTrafficFeatureCollection(coords, nil),
+			Distance:         osrmRoute.Distance,
+			Duration:         osrmRoute.Duration,
+			FreeFlowDuration: osrmRoute.Duration,
+			Label:            label,
+			FullCoords:       coords,
+			Segments:         stepSegments(steps),
+			Instructions:     stepInstructions(osrmRoute.Legs, nil),
 		})
 	}
 
-	// Cache in DB
+	// Cache in route store
 	data, _ := json.Marshal(enhancedResp)
-	db.Create(&RouteCache{
-		StartID: startID,
-		EndID:   endID,
-		Data:    data,
-	})
-	log.Printf("💾 Cached real OSRM route: %s → %s (%d coords)", startID, endID, len(osrmResp.Routes[0].Geometry.Coordinates))
+	if err := s.store.Put(ctx, startID, endID, data); err != nil {
+		slog.WarnContext(ctx, "failed to cache route", logging.KeyStart, startID, logging.KeyEnd, endID, logging.Err(err))
+	}
+	if s.spatial != nil {
+		if err := s.spatial.IndexRoutes(ctx, startID, endID, routeLines(&enhancedResp)); err != nil {
+			slog.WarnContext(ctx, "failed to index route geometry", logging.KeyStart, startID, logging.KeyEnd, endID, logging.Err(err))
+		}
+	}
+	slog.InfoContext(ctx, "route cached", logging.KeyStart, startID, logging.KeyEnd, endID, "coords", len(osrmResp.Routes[0].Geometry.Coordinates))
 
 	return &enhancedResp, nil
 }
 
 // ── Pre-calculation ──
 
-func preCalculateRealRoutes() {
-	log.Println("🚀 Starting pre-calculation with REAL OSRM road geometry...")
+// precalcJob names the pre-calculation's checkpoint.
+const precalcJob = "precalc"
 
-	// Clear old fake routes
-	db.Where("1 = 1").Delete(&RouteCache{})
-	log.Println("🗑️ Cleared old fake route cache")
+// precalcDelay spaces OSRM calls: the demo server allows ~1 req/sec.
+var precalcDelay = 1100 * time.Millisecond
 
-	count := 0
-	failed := 0
+// precalcCheckpoint is the pre-calculation's progress through
+// locationPairs, saved on shutdown and with each progress report.
+type precalcCheckpoint struct {
+	Next   int `json:"next"`
+	Total  int `json:"total"`
+	Cached int `json:"cached"`
+	Failed int `json:"failed"`
+}
 
-	for i, l1 := range locations {
-		for j, l2 := range locations {
-			if l1.ID == l2.ID {
-				continue
+// locationPairs lists every ordered pair of distinct catalog locations.
+func locationPairs() [][2]Location {
+	var pairs [][2]Location
+	for _, l1 := range locations {
+		for _, l2 := range locations {
+			if l1.ID != l2.ID {
+				pairs = append(pairs, [2]Location{l1, l2})
 			}
+		}
+	}
+	return pairs
+}
 
-			// Rate limit: OSRM demo server allows ~1 req/sec
-			time.Sleep(1100 * time.Millisecond)
-
-			_, err := fetchAndCacheRoute(l1.ID, l2.ID)
-			if err != nil {
-				log.Printf("⚠️ Failed %s → %s: %v", l1.ID, l2.ID, err)
-				failed++
-				continue
-			}
-			count++
-			log.Printf("📍 [%d/%d] Cached: %s → %s", count, len(locations)*(len(locations)-1), l1.ID, l2.ID)
-
-			// Log progress every batch
-			if count%25 == 0 {
-				total := len(locations) * (len(locations) - 1)
-				pct := float64(count+failed) / float64(total) * 100
-				log.Printf("📊 Progress: %.1f%% (%d cached, %d failed)", pct, count, failed)
+// preCalculateRealRoutes caches every catalog pair until done or ctx ends.
+// When stopped it saves a checkpoint, and the next run resumes from there
+// instead of clearing the cache and starting over.
+func (s *Server) preCalculateRealRoutes(ctx context.Context) {
+	pairs := locationPairs()
+	cp := precalcCheckpoint{Total: len(pairs)}
+	if saved, ok := s.loadPrecalcCheckpoint(ctx); ok && saved.Total == len(pairs) && saved.Next < len(pairs) {
+		cp = saved
+		slog.Info("pre-calculation resuming", "next", cp.Next, "total", cp.Total)
+	} else {
+		slog.Info("pre-calculation starting", "total", cp.Total)
+
+		// Clear old fake routes
+		if err := s.store.Clear(ctx); err != nil {
+			slog.Warn("failed to clear route cache", logging.Err(err))
+		}
+		if s.spatial != nil {
+			if err := s.spatial.Clear(ctx); err != nil {
+				slog.Warn("failed to clear route geometries", logging.Err(err))
 			}
+		}
+		slog.Info("cleared route cache")
+	}
+
+	precalcRoutes.WithLabelValues("total").Set(float64(cp.Total))
+	precalcRoutes.WithLabelValues("cached").Set(float64(cp.Cached))
+	precalcRoutes.WithLabelValues("failed").Set(float64(cp.Failed))
 
-			_ = i
-			_ = j
+	for cp.Next < len(pairs) {
+		select {
+		case <-ctx.Done():
+			s.savePrecalcCheckpoint(ctx, cp)
+			return
+		case <-time.After(precalcDelay):
+		}
+
+		l1, l2 := pairs[cp.Next][0], pairs[cp.Next][1]
+		_, err := s.fetchAndCacheRoute(ctx, l1.ID, l2.ID)
+		if ctx.Err() != nil {
+			// Interrupted mid-fetch; the pair is retried on resume
+			s.savePrecalcCheckpoint(ctx, cp)
+			return
+		}
+		cp.Next++
+		if err != nil {
+			slog.Warn("pre-calculation failed", logging.KeyStart, l1.ID, logging.KeyEnd, l2.ID, logging.Err(err))
+			cp.Failed++
+			precalcRoutes.WithLabelValues("failed").Inc()
+			continue
 		}
+		cp.Cached++
+		precalcRoutes.WithLabelValues("cached").Inc()
+		slog.Debug("pre-calculated route", logging.KeyStart, l1.ID, logging.KeyEnd, l2.ID, "done", cp.Next, "total", cp.Total)
+
+		// Log progress every batch
+		if cp.Cached%25 == 0 {
+			pct := float64(cp.Next) / float64(cp.Total) * 100
+			slog.Info("pre-calculation progress", "percent", math.Round(pct*10)/10, "cached", cp.Cached, "failed", cp.Failed)
+			s.savePrecalcCheckpoint(ctx, cp)
+		}
+	}
+
+	if err := s.checkpoints.Delete(ctx, precalcJob); err != nil {
+		slog.Warn("failed to delete pre-calculation checkpoint", logging.Err(err))
 	}
+	slog.Info("pre-calculation complete", "cached", cp.Cached, "failed", cp.Failed)
+}
 
-	log.Printf("✅ Pre-calculation complete! %d routes cached, %d failed.", count, failed)
+func (s *Server) loadPrecalcCheckpoint(ctx context.Context) (precalcCheckpoint, bool) {
+	var cp precalcCheckpoint
+	data, err := s.checkpoints.Load(ctx, precalcJob)
+	if err != nil {
+		if !errors.Is(err, store.ErrNoCheckpoint) {
+			slog.Warn("failed to load pre-calculation checkpoint", logging.Err(err))
+		}
+		return cp, false
+	}
+	if err := json.Unmarshal(data, &cp); err != nil {
+		slog.Warn("discarding unreadable pre-calculation checkpoint", logging.Err(err))
+		return cp, false
+	}
+	return cp, true
+}
+
+// savePrecalcCheckpoint records cp even when ctx is already cancelled.
+func (s *Server) savePrecalcCheckpoint(ctx context.Context, cp precalcCheckpoint) {
+	data, _ := json.Marshal(cp)
+	if err := s.checkpoints.Save(context.WithoutCancel(ctx), precalcJob, data); err != nil {
+		slog.Warn("failed to save pre-calculation checkpoint", logging.Err(err))
+		return
+	}
+	if ctx.Err() != nil {
+		slog.Info("pre-calculation checkpointed", "next", cp.Next, "total", cp.Total)
+	}
 }
 
 // ── Traffic Segmentation ──
 
-func buildTrafficFeatureCollection(coords [][2]float64, routeIndex int) FeatureCollection {
-	fc := FeatureCollection{Type: "FeatureCollection", Features: make([]Feature, 0)}
+type trafficSegment struct {
+	coords [][2]float64
+	distKm float64
+}
 
+// segmentRoute splits a route into ~1-8 km stretches, the granularity at
+// which congestion is rated and drawn.
+func segmentRoute(coords [][2]float64) []trafficSegment {
 	if len(coords) < 2 {
-		return fc
+		return nil
 	}
 
 	// Calculate total distance for realistic segment sizing
@@ -539,21 +1050,9 @@ func buildTrafficFeatureCollection(coords [][2]float64, routeIndex int) FeatureC
 		targetSegmentDist = 8.0
 	}
 
-	// Congestion patterns (weighted toward low for highway driving)
-	congestionWeights := []struct {
-		level  string
-		weight float64
-	}{
-		{"low", 0.55},
-		{"moderate", 0.20},
-		{"low", 0.10},
-		{"high", 0.08},
-		{"low", 0.07},
-	}
-
+	var segments []trafficSegment
 	segStart := 0
 	segDist := 0.0
-	segIdx := 0
 
 	for i := 0; i < len(coords)-1; i++ {
 		d := haversine(coords[i][1], coords[i][0], coords[i+1][1], coords[i+1][0])
@@ -564,50 +1063,131 @@ func buildTrafficFeatureCollection(coords [][2]float64, routeIndex int) FeatureC
 			if segEnd > len(coords) {
 				segEnd = len(coords)
 			}
-
-			segCoords := coords[segStart:segEnd]
-
-			// Determine congestion based on position + route index for variety
-			hash := (segIdx + routeIndex*7) % len(congestionWeights)
-			congestion := congestionWeights[hash].level
-
-			// Urban areas (near city centers) get more congestion
-			midIdx := (segStart + segEnd) / 2
-			if midIdx < len(coords) {
-				midCoord := coords[midIdx]
-				for _, loc := range locations {
-					distToCity := haversine(midCoord[1], midCoord[0], loc.Lat, loc.Lon)
-					if distToCity < 5.0 { // Within 5km of a city
-						if segIdx%3 == 0 {
-							congestion = "high"
-						} else {
-							congestion = "moderate"
-						}
-						break
-					}
-				}
-			}
-
-			fc.Features = append(fc.Features, Feature{
-				Type: "Feature",
-				Properties: map[string]interface{}{
-					"congestion": congestion,
-				},
-				Geometry: map[string]interface{}{
-					"type":        "LineString",
-					"coordinates": segCoords,
-				},
-			})
+			segments = append(segments, trafficSegment{coords: coords[segStart:segEnd], distKm: segDist})
 
 			segStart = i + 1
 			segDist = 0
-			segIdx++
 		}
 	}
 
+	return segments
+}
+
+// buildTrafficFeatureCollection colors each segment by the speeds vehicles
+// actually reported there. Segments without recent telemetry are "unknown".
+func buildTrafficFeatureCollection(coords [][2]float64, model *traffic.Model) FeatureCollection {
+	fc, _ := trafficGeometry(coords, model)
 	return fc
 }
 
+// trafficGeometry builds the segmented FeatureCollection and returns the
+// factor by which observed traffic stretches the free-flow travel time.
+func trafficGeometry(coords [][2]float64, model *traffic.Model) (FeatureCollection, float64) {
+	fc := FeatureCollection{Type: "FeatureCollection", Features: make([]Feature, 0)}
+
+	totalDist, weighted := 0.0, 0.0
+	for _, seg := range segmentRoute(coords) {
+		est := model.EstimateLine(seg.coords)
+
+		props := map[string]interface{}{
+			"congestion":    est.Congestion(),
+			"free_flow_kmh": est.FreeFlowKmh,
+			"samples":       est.Samples,
+		}
+		if est.Known {
+			props["observed_kmh"] = math.Round(est.ObservedKmh*10) / 10
+			props["speed_ratio"] = math.Round(est.Ratio*100) / 100
+		}
+
+		fc.Features = append(fc.Features, Feature{
+			Type:       "Feature",
+			Properties: props,
+			Geometry: map[string]interface{}{
+				"type":        "LineString",
+				"coordinates": seg.coords,
+			},
+		})
+
+		totalDist += seg.distKm
+		weighted += seg.distKm / est.Ratio
+	}
+
+	if totalDist == 0 {
+		return fc, 1
+	}
+	return fc, weighted / totalDist
+}
+
+// estimateDurations replaces the upstream free-flow ETA with the duration
+// model for the requested vehicle.
+func (s *Server) estimateDurations(resp *EnhancedResponse, vehicle eta.VehicleProfile) {
+	for i := range resp.Routes {
+		route := &resp.Routes[i]
+		if len(route.Segments) > 0 {
+			b := s.eta.Estimate(route.Segments, vehicle)
+			route.ETA = &b
+			route.FreeFlowDuration = b.TotalS
+			route.Segments = nil
+		}
+	}
+}
+
+// stepSegments converts OSRM steps into duration model segments.
+func stepSegments(steps []OSRMStep) []eta.Segment {
+	segs := make([]eta.Segment, 0, len(steps))
+	for _, st := range steps {
+		if st.Distance <= 0 {
+			continue
+		}
+		var classes []string
+		seg := eta.Segment{
+			DistanceM:     st.Distance,
+			DurationS:     st.Duration,
+			Intersections: len(st.Intersections),
+		}
+		for _, in := range st.Intersections {
+			classes = append(classes, in.Classes...)
+		}
+		if len(st.Intersections) > 0 {
+			seg.Lon, seg.Lat = st.Intersections[0].Location[0], st.Intersections[0].Location[1]
+		}
+		speed := 0.0
+		if st.Duration > 0 {
+			speed = st.Distance / 1000 / (st.Duration / 3600)
+		}
+		seg.Class = eta.ClassifyStep(st.Ref, classes, speed)
+		segs = append(segs, seg)
+	}
+	return segs
+}
+
+// catalogDensity approximates urban density from how many catalog
+// locations lie within 10 km; four or more counts as fully urban.
+func catalogDensity(lat, lon float64) float64 {
+	n := 0
+	for _, loc := range locations {
+		if haversine(lat, lon, loc.Lat, loc.Lon) <= 10 {
+			n++
+		}
+	}
+	return math.Min(1, float64(n)/4)
+}
+
+// applyTraffic recomputes congestion and duration from live telemetry. It
+// runs on every response so cached routes reflect current conditions.
+func (s *Server) applyTraffic(resp *EnhancedResponse) {
+	for i := range resp.Routes {
+		route := &resp.Routes[i]
+		if route.FreeFlowDuration == 0 {
+			// Entries cached before the traffic model stored only Duration
+			route.FreeFlowDuration = route.Duration
+		}
+		fc, factor := trafficGeometry(route.FullCoords, s.traffic)
+		route.Geometry = fc
+		route.Duration = route.FreeFlowDuration * factor
+	}
+}
+
 func haversine(lat1, lon1, lat2, lon2 float64) float64 {
 	const R = 6371.0 // Earth radius in km
 	dLat := (lat2 - lat1) * math.Pi / 180
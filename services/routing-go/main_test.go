@@ -1,14 +1,60 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"navifly/platform/auth"
+	"navifly/platform/logging"
+	"navifly/platform/problem"
+	"navifly/platform/ratelimit"
+	"navifly/platform/tracing"
+	routingv1 "navifly/routing/api/routing/v1"
+	"navifly/routing/internal/avoid"
+	"navifly/routing/internal/elevation"
+	"navifly/routing/internal/energy"
+	"navifly/routing/internal/eta"
+	"navifly/routing/internal/polyline"
+	"navifly/routing/internal/routing"
+	"navifly/routing/internal/store"
+	"navifly/routing/internal/traffic"
 )
 
+func newTestServer() (*Server, *store.MemoryStore) {
+	mem := store.NewMemory(10)
+	return NewServer(mem), mem
+}
+
 func TestFindLocation(t *testing.T) {
 	// Test existing location
 	loc, ok := findLocation("phx")
@@ -70,7 +116,7 @@ func TestBuildTrafficFeatureCollection(t *testing.T) {
 		{-111.9400, 33.4255}, // Tempe
 	}
 
-	fc := buildTrafficFeatureCollection(coords, 0)
+	fc := buildTrafficFeatureCollection(coords, nil)
 	assert.Equal(t, "FeatureCollection", fc.Type)
 	assert.NotEmpty(t, fc.Features)
 
@@ -110,12 +156,12 @@ func TestLocationsEndpoint(t *testing.T) {
 	locationsHandler.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusOK, rr.Code)
-	
+
 	var locs []Location
 	err := json.Unmarshal(rr.Body.Bytes(), &locs)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, locs)
-	
+
 	// Verify PHX is in the list
 	found := false
 	for _, l := range locs {
@@ -129,25 +175,81 @@ func TestLocationsEndpoint(t *testing.T) {
 }
 
 func TestHandleRoute_MissingParams(t *testing.T) {
+	srv, _ := newTestServer()
+
 	// Missing both start and end
 	req, _ := http.NewRequest("GET", "/route", nil)
 	rr := httptest.NewRecorder()
-	HandleRoute(rr, req)
+	srv.HandleRoute(rr, req)
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
 
 	// Missing end param only
 	req2, _ := http.NewRequest("GET", "/route?start=phx", nil)
 	rr2 := httptest.NewRecorder()
-	HandleRoute(rr2, req2)
+	srv.HandleRoute(rr2, req2)
 	assert.Equal(t, http.StatusBadRequest, rr2.Code)
 
 	// Missing start param only
 	req3, _ := http.NewRequest("GET", "/route?end=scottsdale", nil)
 	rr3 := httptest.NewRecorder()
-	HandleRoute(rr3, req3)
+	srv.HandleRoute(rr3, req3)
 	assert.Equal(t, http.StatusBadRequest, rr3.Code)
 }
 
+func TestHandleRoute_StoreHit(t *testing.T) {
+	srv, mem := newTestServer()
+	cached := []byte(`{"routes":[{"label":"Fastest"}]}`)
+	assert.NoError(t, mem.Put(context.Background(), "phx", "tempe", cached))
+
+	req, _ := http.NewRequest("GET", "/route?start=phx&end=tempe", nil)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var resp EnhancedResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, "Fastest", resp.Routes[0].Label)
+}
+
+func TestApplyTraffic_SlowsCongestedSegments(t *testing.T) {
+	srv, _ := newTestServer()
+	coords := [][2]float64{
+		{-112.0740, 33.4484},
+		{-112.0700, 33.4484},
+		{-112.0660, 33.4484},
+	}
+	for i := 0; i < 5; i++ {
+		srv.traffic.Add(traffic.Observation{
+			VehicleID: "car-001",
+			Lat:       33.4484,
+			Lon:       -112.0700,
+			SpeedKmh:  30,
+			Time:      time.Now(),
+		})
+	}
+
+	resp := EnhancedResponse{Routes: []EnhancedRoute{{
+		Duration:   600,
+		FullCoords: coords,
+	}}}
+	srv.applyTraffic(&resp)
+
+	route := resp.Routes[0]
+	assert.Equal(t, 600.0, route.FreeFlowDuration, "legacy entries fall back to Duration")
+	assert.InDelta(t, 1800.0, route.Duration, 1.0, "30 km/h against 90 km/h free flow triples the ETA")
+	assert.Equal(t, "high", route.Geometry.Features[0].Properties["congestion"])
+}
+
+func TestBuildTrafficFeatureCollection_NoTelemetry(t *testing.T) {
+	coords := [][2]float64{
+		{-112.0740, 33.4484},
+		{-112.0078, 33.4373},
+	}
+	fc := buildTrafficFeatureCollection(coords, traffic.NewModel(traffic.DefaultConfig()))
+	assert.Equal(t, "unknown", fc.Features[0].Properties["congestion"])
+}
+
 func TestHaversine_SymmetryAndPrecision(t *testing.T) {
 	// Haversine should be symmetric (A→B == B→A)
 	d1 := haversine(33.4484, -112.0740, 33.4255, -111.9400)
@@ -165,7 +267,7 @@ func TestBuildTrafficFeatureCollection_TwoPoints(t *testing.T) {
 		{-112.0740, 33.4484},
 		{-112.0078, 33.4373},
 	}
-	fc := buildTrafficFeatureCollection(coords, 0)
+	fc := buildTrafficFeatureCollection(coords, nil)
 	assert.Equal(t, 1, len(fc.Features), "2 points should produce 1 segment")
 }
 
@@ -174,6 +276,1579 @@ func TestBuildTrafficFeatureCollection_SinglePoint(t *testing.T) {
 	coords := [][2]float64{
 		{-112.0740, 33.4484},
 	}
-	fc := buildTrafficFeatureCollection(coords, 0)
+	fc := buildTrafficFeatureCollection(coords, nil)
 	assert.Empty(t, fc.Features, "1 point should produce no segments")
 }
+
+// ── Spatial Query Tests ──
+
+type fakeSpatialIndex struct {
+	bbox   store.BBox
+	radius float64
+}
+
+func (f *fakeSpatialIndex) IndexRoutes(ctx context.Context, startID, endID string, lines []store.RouteLine) error {
+	return nil
+}
+
+func (f *fakeSpatialIndex) RoutesThrough(ctx context.Context, bbox store.BBox) ([]store.RouteMatch, error) {
+	f.bbox = bbox
+	return []store.RouteMatch{{StartID: "phx", EndID: "flagstaff", Label: "Fastest"}}, nil
+}
+
+func (f *fakeSpatialIndex) RoutesNear(ctx context.Context, lat, lon, radiusM float64) ([]store.RouteMatch, error) {
+	f.radius = radiusM
+	return nil, nil
+}
+
+func (f *fakeSpatialIndex) Clear(ctx context.Context) error { return nil }
+
+func TestParseBBox(t *testing.T) {
+	bbox, err := parseBBox("-112.1,33.4,-112.0,33.5")
+	assert.NoError(t, err)
+	assert.Equal(t, store.BBox{MinLon: -112.1, MinLat: 33.4, MaxLon: -112.0, MaxLat: 33.5}, bbox)
+
+	_, err = parseBBox("")
+	assert.Error(t, err)
+	_, err = parseBBox("-112.0,33.5,-112.1,33.4") // min > max
+	assert.Error(t, err)
+	_, err = parseBBox("a,b,c,d")
+	assert.Error(t, err)
+}
+
+func TestRoutesThrough_WithoutPostGIS(t *testing.T) {
+	srv, _ := newTestServer()
+	req, _ := http.NewRequest("GET", "/routes/through?bbox=-112.1,33.4,-112.0,33.5", nil)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotImplemented, rr.Code)
+}
+
+func TestRoutesThrough(t *testing.T) {
+	srv, _ := newTestServer()
+	idx := &fakeSpatialIndex{}
+	srv.spatial = idx
+
+	req, _ := http.NewRequest("GET", "/routes/through?bbox=-112.1,33.4,-112.0,33.5", nil)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 33.5, idx.bbox.MaxLat)
+	var resp struct {
+		Routes []store.RouteMatch `json:"routes"`
+		Count  int                `json:"count"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Count)
+	assert.Equal(t, "flagstaff", resp.Routes[0].EndID)
+}
+
+func TestRoutesNear(t *testing.T) {
+	srv, _ := newTestServer()
+	idx := &fakeSpatialIndex{}
+	srv.spatial = idx
+
+	req, _ := http.NewRequest("GET", "/routes/near?lat=33.45&lon=-112.07&radius=2500", nil)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 2500.0, idx.radius)
+	assert.JSONEq(t, `{"routes":[],"count":0}`, rr.Body.String())
+
+	for _, target := range []string{"/routes/near?lat=95&lon=-112.07", "/routes/near?lat=33.45&lon=-112.07&radius=60000"} {
+		req, _ = http.NewRequest("GET", target, nil)
+		rr = httptest.NewRecorder()
+		srv.Router().ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, target)
+	}
+}
+
+// ── Time-Dependent Routing Tests ──
+
+func TestBuildCorridorGraph_Connected(t *testing.T) {
+	g := buildCorridorGraph(locations)
+	assert.Len(t, g.Nodes, len(locations))
+	assert.Nil(t, components(g, locations), "every catalog location must be reachable")
+}
+
+func TestParseTripTime(t *testing.T) {
+	q := url.Values{"depart_at": {"2026-10-13T17:00"}}
+	depart, arrive, err := parseTripTime(q)
+	assert.NoError(t, err)
+	assert.True(t, arrive.IsZero())
+	assert.Equal(t, 17, depart.Hour())
+	assert.Equal(t, time.Tuesday, depart.Weekday())
+
+	q = url.Values{"arrive_by": {"2026-10-14T00:30:00Z"}}
+	_, arrive, err = parseTripTime(q)
+	assert.NoError(t, err)
+	assert.Equal(t, 17, arrive.Hour(), "UTC is converted to Arizona time")
+
+	_, _, err = parseTripTime(url.Values{"depart_at": {"now"}, "arrive_by": {"now"}})
+	assert.Error(t, err)
+	_, _, err = parseTripTime(url.Values{"depart_at": {"tomorrow"}})
+	assert.Error(t, err)
+}
+
+func TestScheduleRoutes_RushHourIsSlower(t *testing.T) {
+	srv, _ := newTestServer()
+	waypoints := []string{"phx", "tempe"}
+	newResp := func() *EnhancedResponse {
+		return &EnhancedResponse{Routes: []EnhancedRoute{{FreeFlowDuration: 1200}}}
+	}
+
+	night := time.Date(2026, 10, 13, 3, 0, 0, 0, arizonaTime)
+	calm := newResp()
+	assert.NoError(t, srv.scheduleRoutes(calm, waypoints, night, time.Time{}))
+	assert.InDelta(t, 1200.0, calm.Routes[0].Duration, 1.0)
+	assert.Equal(t, night, *calm.Routes[0].DepartAt)
+
+	fivePM := time.Date(2026, 10, 13, 17, 0, 0, 0, arizonaTime)
+	rush := newResp()
+	assert.NoError(t, srv.scheduleRoutes(rush, waypoints, fivePM, time.Time{}))
+	assert.Greater(t, rush.Routes[0].Duration, 1.5*calm.Routes[0].Duration)
+
+	// arrive_by works backwards to a departure that makes it on time
+	arriveBy := time.Date(2026, 10, 13, 17, 30, 0, 0, arizonaTime)
+	back := newResp()
+	assert.NoError(t, srv.scheduleRoutes(back, waypoints, time.Time{}, arriveBy))
+	assert.WithinDuration(t, arriveBy, *back.Routes[0].ArriveAt, time.Minute)
+	assert.True(t, back.Routes[0].DepartAt.Before(arriveBy.Add(-20*time.Minute)))
+}
+
+func TestHandleRoute_InvalidDepartAt(t *testing.T) {
+	srv, _ := newTestServer()
+	req, _ := http.NewRequest("GET", "/route?start=phx&end=tempe&depart_at=soon", nil)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+// ── Duration Model Tests ──
+
+func TestStepSegments(t *testing.T) {
+	var steps []OSRMStep
+	assert.NoError(t, json.Unmarshal([]byte(`[
+		{"distance": 12000, "duration": 400, "ref": "I 10",
+		 "intersections": [{"location": [-112.07, 33.44], "classes": ["motorway"]}, {"location": [-112.0, 33.43]}]},
+		{"distance": 0, "duration": 0, "intersections": [{"location": [-111.94, 33.42]}]}
+	]`), &steps))
+
+	segs := stepSegments(steps)
+	assert.Len(t, segs, 1, "zero-length arrive step is dropped")
+	assert.Equal(t, eta.Motorway, segs[0].Class)
+	assert.Equal(t, 2, segs[0].Intersections)
+	assert.Equal(t, 33.44, segs[0].Lat)
+}
+
+func TestEstimateDurations_ByVehicle(t *testing.T) {
+	srv, _ := newTestServer()
+	newResp := func() *EnhancedResponse {
+		return &EnhancedResponse{Routes: []EnhancedRoute{{
+			FreeFlowDuration: 5400,
+			Segments: []eta.Segment{
+				{DistanceM: 150000, DurationS: 5000, Class: eta.Motorway, Lat: 34.5, Lon: -111.8},
+			},
+		}}}
+	}
+
+	car := newResp()
+	srv.estimateDurations(car, eta.Profiles["car"])
+	truck := newResp()
+	srv.estimateDurations(truck, eta.Profiles["truck"])
+
+	assert.Nil(t, car.Routes[0].Segments, "segments are not sent to clients")
+	assert.Equal(t, "car", car.Routes[0].ETA.Vehicle)
+	assert.Equal(t, car.Routes[0].ETA.TotalS, car.Routes[0].FreeFlowDuration)
+	assert.Greater(t, truck.Routes[0].FreeFlowDuration, car.Routes[0].FreeFlowDuration)
+}
+
+func TestHandleRoute_UnknownVehicle(t *testing.T) {
+	srv, _ := newTestServer()
+	req, _ := http.NewRequest("GET", "/route?start=phx&end=tempe&vehicle=drone", nil)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+// ── Geometry Format Tests ──
+
+func longRouteResponse() *EnhancedResponse {
+	// ~4 km straight line with a vertex every ~10 m
+	coords := make([][2]float64, 400)
+	for i := range coords {
+		coords[i] = [2]float64{-112.0740 + float64(i)*0.0001, 33.4484}
+	}
+	resp := &EnhancedResponse{Routes: []EnhancedRoute{{FreeFlowDuration: 300, FullCoords: coords}}}
+	srv, _ := newTestServer()
+	srv.applyTraffic(resp)
+	return resp
+}
+
+func TestParseGeometryOptions(t *testing.T) {
+	format, tol, err := parseGeometryOptions(url.Values{})
+	assert.NoError(t, err)
+	assert.Equal(t, "geojson", format)
+	assert.Equal(t, 0.0, tol)
+
+	format, tol, err = parseGeometryOptions(url.Values{"geometry": {"polyline6"}, "simplify": {"25"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "polyline6", format)
+	assert.Equal(t, 25.0, tol)
+
+	_, _, err = parseGeometryOptions(url.Values{"geometry": {"wkt"}})
+	assert.Error(t, err)
+	_, _, err = parseGeometryOptions(url.Values{"simplify": {"-5"}})
+	assert.Error(t, err)
+}
+
+func TestFormatGeometry_Simplify(t *testing.T) {
+	resp := longRouteResponse()
+	segments := len(resp.Routes[0].Geometry.Features)
+	formatGeometry(resp, "geojson", 10)
+
+	route := resp.Routes[0]
+	assert.Len(t, route.FullCoords, 2, "a straight line reduces to its endpoints")
+	assert.Len(t, route.Geometry.Features, segments, "segments are simplified, not dropped")
+	for _, f := range route.Geometry.Features {
+		coords := f.Geometry.(map[string]interface{})["coordinates"].([][2]float64)
+		assert.Len(t, coords, 2)
+	}
+	assert.Empty(t, resp.GeometryFormat)
+}
+
+func TestFormatGeometry_Polyline(t *testing.T) {
+	resp := longRouteResponse()
+	formatGeometry(resp, "polyline6", 0)
+
+	route := resp.Routes[0]
+	assert.Equal(t, "polyline6", resp.GeometryFormat)
+	assert.Nil(t, route.FullCoords)
+	decoded, err := polyline.Decode(route.FullPolyline, 6)
+	assert.NoError(t, err)
+	assert.Len(t, decoded, 400)
+
+	geom := route.Geometry.Features[0].Geometry.(map[string]interface{})
+	assert.NotEmpty(t, geom["polyline"])
+	assert.NotContains(t, geom, "coordinates")
+
+	body, _ := json.Marshal(resp)
+	assert.NotContains(t, string(body), "full_coords")
+}
+
+// ── Export Tests ──
+
+func seedExportRoute(t *testing.T, mem *store.MemoryStore) {
+	resp := EnhancedResponse{Routes: []EnhancedRoute{{
+		Label:            "Fastest",
+		Distance:         180000,
+		FreeFlowDuration: 6600,
+		FullCoords:       [][2]float64{{-112.0740, 33.4484}, {-111.5, 32.8}, {-110.9747, 32.2226}},
+		Instructions: []TurnInstruction{
+			{Text: "Start journey on I-10", DistanceM: 180000, Lat: 33.4484, Lon: -112.0740},
+			{Text: "Arrive at destination", Lat: 32.2226, Lon: -110.9747},
+		},
+	}}}
+	data, _ := json.Marshal(resp)
+	assert.NoError(t, mem.Put(context.Background(), "phx", "tucson", data))
+}
+
+func TestRouteExport_GPX(t *testing.T) {
+	srv, mem := newTestServer()
+	seedExportRoute(t, mem)
+
+	req, _ := http.NewRequest("GET", "/route/export?start=phx&end=tucson&format=gpx", nil)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/gpx+xml", rr.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="navifly-phx-tucson.gpx"`, rr.Header().Get("Content-Disposition"))
+
+	var doc gpxDoc
+	assert.NoError(t, xml.Unmarshal(rr.Body.Bytes(), &doc))
+	assert.Len(t, doc.Wpts, 2)
+	assert.Equal(t, "Phoenix Downtown", doc.Wpts[0].Name)
+	assert.Equal(t, "end", doc.Wpts[1].Type)
+	assert.Len(t, doc.TrkPts, 3)
+	assert.Equal(t, "Start journey on I-10", doc.RtePts[0].Name)
+}
+
+func TestRouteExport_KML(t *testing.T) {
+	srv, mem := newTestServer()
+	seedExportRoute(t, mem)
+
+	req, _ := http.NewRequest("GET", "/route/export?start=phx&end=tucson&format=kml", nil)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/vnd.google-earth.kml+xml", rr.Header().Get("Content-Type"))
+	body := rr.Body.String()
+	assert.Contains(t, body, `<kml xmlns="http://www.opengis.net/kml/2.2">`)
+	assert.Contains(t, body, "<coordinates>-112.074,33.4484 -111.5,32.8 -110.9747,32.2226</coordinates>")
+	assert.Contains(t, body, "<name>Tucson</name>")
+	assert.Contains(t, body, "<name>Directions</name>")
+}
+
+func TestRouteExport_GeoJSON(t *testing.T) {
+	srv, mem := newTestServer()
+	seedExportRoute(t, mem)
+
+	req, _ := http.NewRequest("GET", "/route/export?start=phx&end=tucson&format=geojson", nil)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/geo+json", rr.Header().Get("Content-Type"))
+	var fc FeatureCollection
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &fc))
+	assert.Len(t, fc.Features, 5, "line, two waypoints, two instructions")
+	assert.Equal(t, "start", fc.Features[1].Properties["role"])
+}
+
+func TestRouteExport_BadParams(t *testing.T) {
+	srv, mem := newTestServer()
+	seedExportRoute(t, mem)
+
+	for path, code := range map[string]int{
+		"/route/export?start=phx&end=tucson":                            http.StatusBadRequest,
+		"/route/export?start=phx&end=tucson&format=shp":                 http.StatusBadRequest,
+		"/route/export?start=phx&end=tucson&format=gpx&alternative=2":   http.StatusNotFound,
+		"/route/export?start=phx&end=tucson&format=gpx&alternative=one": http.StatusBadRequest,
+	} {
+		req, _ := http.NewRequest("GET", path, nil)
+		rr := httptest.NewRecorder()
+		srv.Router().ServeHTTP(rr, req)
+		assert.Equal(t, code, rr.Code, path)
+	}
+}
+
+func TestRouteExport_RenderFailure(t *testing.T) {
+	srv, mem := newTestServer()
+	seedExportRoute(t, mem)
+	prev := exportFormats["gpx"]
+	defer func() { exportFormats["gpx"] = prev }()
+	exportFormats["gpx"] = exportFormat{prev.contentType, prev.extension,
+		func(w io.Writer, _ string, _ EnhancedRoute, _ []exportWaypoint) error {
+			io.WriteString(w, "<gpx>")
+			return errors.New("disk full")
+		}}
+
+	req, _ := http.NewRequest("GET", "/route/export?start=phx&end=tucson&format=gpx", nil)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+
+	// Nothing of the half-rendered file is sent
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, problem.ContentType, rr.Header().Get("Content-Type"))
+	assert.Empty(t, rr.Header().Get("Content-Disposition"))
+	assert.NotContains(t, rr.Body.String(), "<gpx>")
+}
+
+func TestManeuverText(t *testing.T) {
+	var st OSRMStep
+	st.Name, st.Ref = "Papago Freeway", "I 10"
+	st.Maneuver.Type, st.Maneuver.Modifier = "turn", "left"
+	assert.Equal(t, "Turn left onto Papago Freeway (I 10)", maneuverText(st))
+
+	st.Maneuver.Type, st.Maneuver.Modifier = "roundabout", ""
+	st.Maneuver.Exit = 2
+	assert.Equal(t, "At the roundabout, take exit 2 onto Papago Freeway (I 10)", maneuverText(st))
+
+	st = OSRMStep{}
+	st.Maneuver.Type = "arrive"
+	assert.Equal(t, "Arrive at destination", maneuverText(st))
+}
+
+func TestStepInstructions_NamesStopsAndPassesVias(t *testing.T) {
+	step := func(typ, modifier string, dist float64) OSRMStep {
+		st := OSRMStep{Distance: dist}
+		st.Maneuver.Type, st.Maneuver.Modifier = typ, modifier
+		return st
+	}
+	legs := []OSRMLeg{
+		{Steps: []OSRMStep{step("depart", "", 1000), step("arrive", "right", 0)}},
+		{Steps: []OSRMStep{step("depart", "", 2000), step("arrive", "", 0)}},
+		{Steps: []OSRMStep{step("depart", "", 3000), step("turn", "left", 500), step("arrive", "", 0)}},
+	}
+
+	var texts []string
+	var dists []float64
+	for _, in := range stepInstructions(legs, []string{"Tempe", ""}) {
+		texts = append(texts, in.Text)
+		dists = append(dists, in.DistanceM)
+	}
+	// The via point between legs 2 and 3 is driven through, not announced
+	assert.Equal(t, []string{"Start journey", "Arrive at Tempe, on the right", "Start journey", "Turn left", "Arrive at destination"}, texts)
+	assert.Equal(t, []float64{1000, 0, 5000, 500, 0}, dists)
+}
+
+func TestStopNames_SkipsVias(t *testing.T) {
+	opts := routeOptions{waypoints: []string{"phx", "tempe", "tucson"}}
+	assert.Equal(t, []string{"Tempe (ASU)"}, opts.stopNames())
+
+	// Via points are catalog locations too, told apart by their position
+	opts.detour = &RestrictionReport{waypoints: []string{"phx", "mesa", "tempe", "tucson"}}
+	assert.Equal(t, []string{"", "Tempe (ASU)"}, opts.stopNames())
+}
+
+func dialRoutingGRPC(t *testing.T, srv *Server) routingv1.RoutingServiceClient {
+	lis := bufconn.Listen(1 << 20)
+	gs := srv.GRPCServer()
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return routingv1.NewRoutingServiceClient(conn)
+}
+
+func TestGRPCRoute(t *testing.T) {
+	srv, mem := newTestServer()
+	seedExportRoute(t, mem)
+	client := dialRoutingGRPC(t, srv)
+
+	resp, err := client.Route(context.Background(), &routingv1.RouteRequest{
+		Start:    "phx",
+		End:      "tucson",
+		Geometry: routingv1.GeometryFormat_GEOMETRY_FORMAT_POLYLINE5,
+		DepartAt: timestamppb.New(time.Date(2026, 3, 4, 10, 0, 0, 0, arizonaTime)),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Routes, 1)
+
+	route := resp.Routes[0]
+	assert.Equal(t, "Fastest", route.Label)
+	assert.Equal(t, 180000.0, route.DistanceM)
+	assert.NotEmpty(t, route.Geometry.Polyline)
+	assert.Empty(t, route.Geometry.Coordinates)
+	assert.NotEmpty(t, route.Segments)
+	assert.Equal(t, "unknown", route.Segments[0].Congestion)
+	assert.Len(t, route.Instructions, 2)
+	assert.NotNil(t, route.DepartAt)
+	assert.True(t, route.ArriveAt.AsTime().After(route.DepartAt.AsTime()))
+}
+
+func TestGRPCRoute_InvalidArgument(t *testing.T) {
+	srv, _ := newTestServer()
+	client := dialRoutingGRPC(t, srv)
+
+	_, err := client.Route(context.Background(), &routingv1.RouteRequest{Start: "phx"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.Route(context.Background(), &routingv1.RouteRequest{Start: "phx", End: "tucson", Vehicle: "hovercraft"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// terrain is an elevation.Source for tests.
+type terrain func(lat, lon float64) (float64, bool)
+
+func (f terrain) Elevation(lat, lon float64) (float64, bool) { return f(lat, lon) }
+
+// seedGradeRoutes stores a steep western alternative (8%) and a flat
+// eastern one, with terrain to match.
+func seedGradeRoutes(t *testing.T, srv *Server, mem *store.MemoryStore) {
+	srv.dem = terrain(func(lat, lon float64) (float64, bool) {
+		if lon < -111.9 {
+			return 1000 + (lat-33)*111195*0.08, true
+		}
+		return 1000, true
+	})
+	resp := EnhancedResponse{Routes: []EnhancedRoute{
+		{Label: "Fastest", Distance: 11000, FreeFlowDuration: 600, FullCoords: [][2]float64{{-112, 33.0}, {-112, 33.1}}},
+		{Label: "Alternative", Distance: 12000, FreeFlowDuration: 700, FullCoords: [][2]float64{{-111.5, 33.0}, {-111.5, 33.1}}},
+	}}
+	data, _ := json.Marshal(resp)
+	assert.NoError(t, mem.Put(context.Background(), "phx", "flagstaff", data))
+}
+
+func TestHandleRoute_ElevationProfile(t *testing.T) {
+	srv, mem := newTestServer()
+	seedGradeRoutes(t, srv, mem)
+
+	req, _ := http.NewRequest("GET", "/route?start=phx&end=flagstaff", nil)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp EnhancedResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Len(t, resp.Routes, 2)
+	assert.InDelta(t, 8.0, resp.Routes[0].Elevation.MaxGradePct, 0.1)
+	assert.InDelta(t, 890, resp.Routes[0].Elevation.AscentM, 10)
+	assert.Equal(t, 0.0, resp.Routes[1].Elevation.MaxGradePct)
+}
+
+func TestHandleRoute_MaxGrade(t *testing.T) {
+	srv, mem := newTestServer()
+	seedGradeRoutes(t, srv, mem)
+
+	req, _ := http.NewRequest("GET", "/route?start=phx&end=flagstaff&max_grade=6", nil)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp EnhancedResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Len(t, resp.Routes, 1)
+	assert.Equal(t, "Alternative", resp.Routes[0].Label)
+
+	// Too strict for every alternative
+	srv.dem = terrain(func(lat, lon float64) (float64, bool) { return 1000 + (lat-33)*111195*0.08, true })
+	req, _ = http.NewRequest("GET", "/route?start=phx&end=flagstaff&max_grade=6", nil)
+	rr = httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, rr.Body.String(), "least steep is 8.0%")
+}
+
+func TestHandleRoute_MaxGradeErrors(t *testing.T) {
+	srv, mem := newTestServer()
+	seedExportRoute(t, mem)
+
+	for query, code := range map[string]int{
+		"max_grade=steep": http.StatusBadRequest,
+		"max_grade=-3":    http.StatusBadRequest,
+		"max_grade=6":     http.StatusServiceUnavailable, // no DEM configured
+	} {
+		req, _ := http.NewRequest("GET", "/route?start=phx&end=tucson&"+query, nil)
+		rr := httptest.NewRecorder()
+		srv.Router().ServeHTTP(rr, req)
+		assert.Equal(t, code, rr.Code, query)
+	}
+}
+
+// seedEVRoute stores a ~500 km eastbound motorway route along 33°N.
+func seedEVRoute(t *testing.T, mem *store.MemoryStore) EnhancedRoute {
+	var coords [][2]float64
+	for lon := -114.0; lon <= -108.6; lon += 0.05 {
+		coords = append(coords, [2]float64{lon, 33})
+	}
+	route := EnhancedRoute{
+		Label:            "Fastest",
+		Distance:         503000,
+		FreeFlowDuration: 17000,
+		FullCoords:       coords,
+		Segments:         []eta.Segment{{DistanceM: 503000, DurationS: 17000, Class: eta.Motorway, Lat: 33, Lon: -114}},
+		Instructions: []TurnInstruction{
+			{Text: "Start journey on I-8", DistanceM: 503000, Lat: 33, Lon: -114},
+			{Text: "Arrive at destination", Lat: 33, Lon: -108.6},
+		},
+	}
+	data, _ := json.Marshal(EnhancedResponse{Routes: []EnhancedRoute{route}})
+	assert.NoError(t, mem.Put(context.Background(), "phx", "tucson", data))
+	return route
+}
+
+func TestHandleRoute_EVChargingStops(t *testing.T) {
+	srv, mem := newTestServer()
+	seedEVRoute(t, mem)
+	srv.chargers = []energy.Charger{
+		{ID: "gila-bend", Name: "Gila Bend Supercharger", Lat: 33.005, Lon: -112.0, PowerKw: 250},
+		{ID: "far-away", Name: "Flagstaff", Lat: 35.19, Lon: -111.65, PowerKw: 250},
+	}
+
+	req, _ := http.NewRequest("GET", "/route?start=phx&end=tucson&ev=car&soc=90", nil)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp EnhancedResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	route := resp.Routes[0]
+	assert.NotNil(t, route.Energy)
+	assert.Equal(t, "car", route.Energy.Vehicle)
+	assert.Greater(t, route.Energy.KWh, 60.0)
+	assert.Len(t, route.Energy.Stops, 1)
+	assert.Equal(t, "gila-bend", route.Energy.Stops[0].Charger.ID)
+	assert.GreaterOrEqual(t, route.Energy.ArrivalSoCPct, 10.0)
+
+	// The stop is in the directions and its time is in the ETA
+	assert.Len(t, route.Instructions, 3)
+	assert.Contains(t, route.Instructions[1].Text, "Charge at Gila Bend Supercharger")
+	assert.InDelta(t, 503000, route.Instructions[0].DistanceM+route.Instructions[1].DistanceM, 1)
+	assert.InDelta(t, route.ETA.TotalS+route.Energy.AddedS, route.FreeFlowDuration, 0.5)
+}
+
+func TestHandleRoute_EVUnreachable(t *testing.T) {
+	srv, mem := newTestServer()
+	seedEVRoute(t, mem)
+
+	req, _ := http.NewRequest("GET", "/route?start=phx&end=tucson&ev=car", nil)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, rr.Body.String(), "no reachable charger")
+
+	// A bigger battery makes it without stopping
+	req, _ = http.NewRequest("GET", "/route?start=phx&end=tucson&ev=car&battery_kwh=120", nil)
+	rr = httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestParseEVOptions(t *testing.T) {
+	opts, err := parseEVOptions(url.Values{})
+	assert.NoError(t, err)
+	assert.Nil(t, opts)
+
+	opts, err = parseEVOptions(url.Values{"ev": {"van"}, "soc": {"55"}, "charge_to": {"90"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 55.0, opts.plan.StartSoCPct)
+	assert.Equal(t, 90.0, opts.plan.ChargeToPct)
+	assert.Equal(t, 110.0, opts.vehicle.BatteryKWh)
+
+	for _, q := range []url.Values{
+		{"soc": {"50"}},
+		{"ev": {"hovercraft"}},
+		{"ev": {"car"}, "soc": {"150"}},
+		{"ev": {"car"}, "battery_kwh": {"-1"}},
+		{"ev": {"car"}, "reserve_soc": {"85"}},
+	} {
+		_, err := parseEVOptions(q)
+		assert.Error(t, err, q.Encode())
+	}
+}
+
+func TestRouteLegs_FollowElevation(t *testing.T) {
+	route := EnhancedRoute{
+		Distance: 10000,
+		Segments: []eta.Segment{
+			{DistanceM: 4000, DurationS: 240, Class: eta.Arterial},
+			{DistanceM: 6000, DurationS: 240, Class: eta.Motorway},
+		},
+		Elevation: &elevation.Profile{Points: [][2]float64{{0, 300}, {5000, 800}, {10000, 500}}},
+	}
+	legs := routeLegs(&route, eta.Profiles["truck"])
+
+	assert.Len(t, legs, 3, "the motorway segment is split at the summit")
+	assert.Equal(t, 400.0, legs[0].ClimbM)
+	assert.InDelta(t, 100, legs[1].ClimbM, 1e-9)
+	assert.InDelta(t, -300, legs[2].ClimbM, 1e-9)
+	assert.Less(t, legs[0].SpeedKmh, legs[2].SpeedKmh)
+}
+
+func TestGRPCRoute_EV(t *testing.T) {
+	srv, mem := newTestServer()
+	seedEVRoute(t, mem)
+	srv.chargers = []energy.Charger{{ID: "gila-bend", Name: "Gila Bend Supercharger", Lat: 33.005, Lon: -112.0, PowerKw: 250}}
+	client := dialRoutingGRPC(t, srv)
+
+	resp, err := client.Route(context.Background(), &routingv1.RouteRequest{Start: "phx", End: "tucson", Ev: "car", SocPct: 90})
+	assert.NoError(t, err)
+	e := resp.Routes[0].Energy
+	assert.NotNil(t, e)
+	assert.Len(t, e.ChargingStops, 1)
+	assert.Equal(t, "gila-bend", e.ChargingStops[0].ChargerId)
+
+	_, err = client.Route(context.Background(), &routingv1.RouteRequest{Start: "phx", End: "tucson", SocPct: 90})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestPlanTruck_DetoursAroundLowBridge(t *testing.T) {
+	srv, _ := newTestServer()
+	direct, _ := routing.AStar(srv.graph, "phx", "tucson")
+	assert.GreaterOrEqual(t, len(direct), 2)
+	from, to := direct[0], direct[1]
+	srv.graph.ApplyRestrictions(map[string]*routing.Restrictions{
+		routing.EdgeID(from, to): {MaxHeightM: 4.0},
+		routing.EdgeID(to, from): {MaxHeightM: 4.0},
+	})
+
+	// A 2.8 m box truck fits under the bridge
+	opts, err := parseRouteOptions(url.Values{"start": {"phx"}, "end": {"tucson"}, "vehicle": {"truck"}, "height_m": {"2.8"}})
+	assert.NoError(t, err)
+	stops, err := srv.planCorridor(&opts)
+	assert.NoError(t, err)
+	assert.Empty(t, stops)
+	assert.Nil(t, opts.detour)
+
+	// A default 4.1 m tractor-trailer does not
+	opts, err = parseRouteOptions(url.Values{"start": {"phx"}, "end": {"tucson"}, "vehicle": {"truck"}})
+	assert.NoError(t, err)
+	stops, err = srv.planCorridor(&opts)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, stops)
+
+	report := opts.detour
+	assert.NotNil(t, report)
+	assert.Equal(t, []AvoidedRoad{{
+		Edge: routing.EdgeID(from, to), From: from, To: to,
+		Violations: []routing.Violation{{Tag: "maxheight", Limit: 4.0, Vehicle: 4.1}},
+	}}, report.Avoided)
+	assert.Equal(t, strings.Join(report.Via, ","), stops)
+	assert.Equal(t, "phx", report.waypoints[0])
+	assert.Equal(t, "tucson", report.waypoints[len(report.waypoints)-1])
+	assert.GreaterOrEqual(t, report.DetourKm, 0.0)
+	for i := 0; i < len(report.waypoints)-1; i++ {
+		assert.False(t, report.waypoints[i] == from && report.waypoints[i+1] == to, "detour still uses the low bridge")
+	}
+}
+
+func TestHandleRoute_TruckRestrictions(t *testing.T) {
+	srv, mem := newTestServer()
+	seedExportRoute(t, mem)
+	banned := map[string]*routing.Restrictions{}
+	for _, e := range srv.graph.Edges["phx"] {
+		banned[e.ID] = &routing.Restrictions{Hazmat: "no"}
+	}
+	srv.graph.ApplyRestrictions(banned)
+
+	req, _ := http.NewRequest("GET", "/route?start=phx&end=tucson&vehicle=truck", nil)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), `"restrictions"`)
+
+	req, _ = http.NewRequest("GET", "/route?start=phx&end=tucson&vehicle=truck&hazmat=true", nil)
+	rr = httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, rr.Body.String(), "no allowed route phx → tucson")
+	assert.Contains(t, rr.Body.String(), "(no hazmat)")
+
+	for _, q := range []string{"vehicle=car&height_m=3", "vehicle=truck&weight_t=-2", "vehicle=truck&hazmat=maybe"} {
+		req, _ = http.NewRequest("GET", "/route?start=phx&end=tucson&"+q, nil)
+		rr = httptest.NewRecorder()
+		srv.Router().ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, q)
+	}
+}
+
+func TestHandleRoute_AvoidRejectsCrossingRoutes(t *testing.T) {
+	srv, mem := newTestServer()
+	seedExportRoute(t, mem)
+
+	// A closure on the cached road geometry, away from any corridor edge
+	fire := `{"type":"Feature","id":"fire","geometry":{"type":"Polygon","coordinates":[[[-111.55,32.75],[-111.45,32.75],[-111.45,32.85],[-111.55,32.85],[-111.55,32.75]]]}}`
+	req, _ := http.NewRequest("GET", "/route?start=phx&end=tucson&avoid="+url.QueryEscape(fire), nil)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, rr.Body.String(), "every route crosses an avoid area (fire)")
+
+	// Far away, nothing changes
+	elsewhere := `{"type":"Polygon","coordinates":[[[-114,36],[-113.9,36],[-113.9,36.1],[-114,36]]]}`
+	req, _ = http.NewRequest("GET", "/route?start=phx&end=tucson&avoid="+url.QueryEscape(elsewhere), nil)
+	rr = httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req, _ = http.NewRequest("GET", "/route?start=phx&end=tucson&avoid=%7B%22type%22%3A%22Point%22%7D", nil)
+	rr = httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestPlanCorridor_AvoidArea(t *testing.T) {
+	srv, _ := newTestServer()
+	tempe, _ := findLocation("tempe")
+	zone := fmt.Sprintf(`{"type":"Feature","id":"event","geometry":{"type":"Polygon","coordinates":[[[%f,%f],[%f,%f],[%f,%f],[%f,%f],[%f,%f]]]}}`,
+		tempe.Lon-0.02, tempe.Lat-0.02, tempe.Lon+0.02, tempe.Lat-0.02, tempe.Lon+0.02, tempe.Lat+0.02,
+		tempe.Lon-0.02, tempe.Lat+0.02, tempe.Lon-0.02, tempe.Lat-0.02)
+
+	opts, err := parseRouteOptions(url.Values{"start": {"phx"}, "end": {"tucson"}, "avoid": {zone}})
+	assert.NoError(t, err)
+	stops, err := srv.planCorridor(&opts)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, stops)
+
+	report := opts.detour
+	assert.NotNil(t, report)
+	assert.Nil(t, report.Vehicle)
+	assert.NotEmpty(t, report.Avoided)
+	for _, road := range report.Avoided {
+		assert.Equal(t, []string{"event"}, road.Areas)
+	}
+	assert.NotContains(t, report.waypoints, "tempe")
+}
+
+func TestHandleRoute_AvoidFences(t *testing.T) {
+	srv, mem := newTestServer()
+	seedExportRoute(t, mem)
+	mapmatch := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/geofences/Downtown-Zone-1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"id":"Downtown-Zone-1","polygon":[[[-112.08,33.44],[-112.06,33.44],[-112.06,33.46],[-112.08,33.46],[-112.08,33.44]]]}`))
+	}))
+	defer mapmatch.Close()
+	srv.fences = avoid.NewFenceClient(mapmatch.URL)
+
+	// Downtown Phoenix is the start itself, so every corridor road is blocked
+	req, _ := http.NewRequest("GET", "/route?start=phx&end=tucson&avoid_fences=Downtown-Zone-1", nil)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, rr.Body.String(), "crosses Downtown-Zone-1")
+
+	req, _ = http.NewRequest("GET", "/route?start=phx&end=tucson&avoid_fences=nope", nil)
+	rr = httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mapmatch.Close()
+	req, _ = http.NewRequest("GET", "/route?start=phx&end=tucson&avoid_fences=Downtown-Zone-1", nil)
+	rr = httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadGateway, rr.Code)
+}
+
+// fakeOSRM serves one route and records the last request.
+func fakeOSRM(t *testing.T) *url.URL {
+	last := &url.URL{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*last = *r.URL
+		w.Write([]byte(`{"code":"Ok","routes":[{
+			"geometry":{"coordinates":[[-111.95,33.40],[-111.90,33.38],[-111.65,32.88],[-110.97,32.22]]},
+			"distance":152000,"duration":5400,
+			"legs":[{"steps":[
+				{"distance":2000,"duration":120,"name":"Broadway Rd","maneuver":{"type":"depart","location":[-111.95,33.40]}},
+				{"distance":150000,"duration":5280,"name":"","ref":"I 10","maneuver":{"type":"turn","modifier":"right","location":[-111.90,33.38]}},
+				{"distance":0,"duration":0,"name":"","maneuver":{"type":"arrive","location":[-110.97,32.22]}}
+			]}]
+		}]}`))
+	}))
+	t.Cleanup(ts.Close)
+	prev := osrmURL
+	osrmURL = ts.URL
+	t.Cleanup(func() { osrmURL = prev })
+	return last
+}
+
+// withOSRM points osrmURL at h for the test.
+func withOSRM(t *testing.T, h http.HandlerFunc) {
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+	prev := osrmURL
+	osrmURL = ts.URL
+	t.Cleanup(func() { osrmURL = prev })
+}
+
+func TestHandleRoute_ProblemCodes(t *testing.T) {
+	srv, _ := newTestServer()
+	get := func(target string) (int, problem.Problem) {
+		rr := httptest.NewRecorder()
+		srv.Router().ServeHTTP(rr, httptest.NewRequest("GET", target, nil))
+		assert.Equal(t, problem.ContentType, rr.Header().Get("Content-Type"))
+		var p problem.Problem
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &p), rr.Body.String())
+		return rr.Code, p
+	}
+
+	// Unknown locations are the caller's mistake, not a missing route
+	code, p := get("/route?start=atlantis&end=tucson")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, problem.UnknownLocation, p.Code)
+	assert.Equal(t, `query parameter "start": unknown location "atlantis"`, p.Detail)
+	assert.Equal(t, "/route", p.Instance)
+	assert.NotEmpty(t, p.RequestID)
+
+	withOSRM(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":"NoRoute","routes":[]}`))
+	})
+	code, p = get("/route?start=phx&end=tucson")
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Equal(t, problem.NoRoute, p.Code)
+
+	withOSRM(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`<html>maintenance</html>`))
+	})
+	code, p = get("/route?start=phx&end=tucson&stops=tempe")
+	assert.Equal(t, http.StatusBadGateway, code)
+	assert.Equal(t, problem.UpstreamUnavailable, p.Code)
+	assert.Equal(t, "urn:navifly:problem:upstream-unavailable", p.Type)
+}
+
+func TestGRPCRoute_ProblemCode(t *testing.T) {
+	srv, _ := newTestServer()
+	client := dialRoutingGRPC(t, srv)
+
+	_, err := client.Route(context.Background(), &routingv1.RouteRequest{Start: "atlantis", End: "tucson"})
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	require.Len(t, st.Details(), 1)
+	assert.Equal(t, "UNKNOWN_LOCATION", st.Details()[0].(*errdetails.ErrorInfo).Reason)
+}
+
+func TestCheckWaypoints(t *testing.T) {
+	kept, skipped, err := catalogWaypoints.check("phx", []string{"tempe", "mesa"}, "tucson", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tempe", "mesa"}, kept)
+	assert.Empty(t, skipped)
+
+	// Every unknown ID is reported, with the closest catalog ID
+	_, _, err = catalogWaypoints.check("phx", []string{"tuscon", "tempe", "atlantis"}, "Flagstaf", "fail")
+	e := problem.As(err, problem.Internal)
+	assert.Equal(t, problem.UnknownLocation, e.Code)
+	assert.Equal(t, []problem.InvalidParam{
+		{Name: "stops[0]", In: "query", Reason: `unknown location "tuscon"`, Code: problem.UnknownLocation, Suggestion: "tucson"},
+		{Name: "stops[2]", In: "query", Reason: `unknown location "atlantis"`, Code: problem.UnknownLocation},
+		{Name: "end", In: "query", Reason: `unknown location "Flagstaf"`, Code: problem.UnknownLocation, Suggestion: "flagstaff"},
+	}, e.InvalidParams)
+
+	// Skipping drops unknown stops but never the start or end
+	kept, skipped, err = catalogWaypoints.check("phx", []string{"tuscon", "tempe"}, "tucson", "skip")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tempe"}, kept)
+	assert.Equal(t, []string{"tuscon"}, skipped)
+	_, _, err = catalogWaypoints.check("atlantis", []string{"tuscon"}, "tucson", "skip")
+	assert.Equal(t, []string{"start"}, paramNames(err))
+
+	// Consecutive repeats, including one left by a skipped stop
+	_, _, err = catalogWaypoints.check("phx", []string{"phx", "tempe", "nowhere", "tempe"}, "tempe", "skip")
+	assert.Equal(t, problem.DuplicateStop, problem.As(err, problem.Internal).Code)
+	assert.Equal(t, []string{"stops[0]", "stops[3]", "end"}, paramNames(err))
+	_, _, err = catalogWaypoints.check("phx", nil, "phx", "")
+	assert.NoError(t, err, "a round trip without stops is fine")
+
+	many := make([]string, maxStops+1)
+	for i := range many {
+		many[i] = locations[i%2].ID
+	}
+	_, _, err = catalogWaypoints.check("tucson", many, "yuma", "")
+	assert.Equal(t, problem.TooManyStops, problem.As(err, problem.Internal).Code)
+
+	_, _, err = catalogWaypoints.check("phx", nil, "tucson", "ignore")
+	assert.Equal(t, []string{"on_unknown"}, paramNames(err))
+}
+
+func paramNames(err error) []string {
+	var names []string
+	for _, p := range problem.As(err, problem.Internal).InvalidParams {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func TestSuggest(t *testing.T) {
+	ids := locationIDs()
+	assert.Equal(t, "grand-canyon", suggest("grandcanyon", ids))
+	assert.Equal(t, "phx-airport", suggest("PHX-Airprot", ids))
+	assert.Equal(t, "", suggest("atlantis", ids))
+	assert.Equal(t, "", suggest("x", ids))
+}
+
+func TestHandleRoute_StrictStops(t *testing.T) {
+	srv, _ := newTestServer()
+	last := fakeOSRM(t)
+	get := func(target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		srv.Router().ServeHTTP(rr, httptest.NewRequest("GET", target, nil))
+		return rr
+	}
+
+	// Unknown stops used to be dropped silently
+	rr := get("/route?start=phx&stops=tuscon,casa-grande&end=yuma")
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	var p problem.Problem
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &p))
+	assert.Equal(t, problem.UnknownLocation, p.Code)
+	require.Len(t, p.InvalidParams, 1)
+	assert.Equal(t, "tucson", p.InvalidParams[0].Suggestion)
+	assert.Equal(t, `query parameter "stops[0]": unknown location "tuscon" (did you mean "tucson"?)`, p.Detail)
+
+	rr = get("/route?start=phx&stops=tuscon,casa-grande&end=yuma&on_unknown=skip")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp EnhancedResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, []string{"tuscon"}, resp.SkippedStops)
+	casaGrande, _ := findLocation("casa-grande")
+	assert.Equal(t, 2, strings.Count(last.Path, ";"), "routes start, casa-grande, end")
+	assert.Contains(t, last.Path, fmt.Sprintf("%f,%f", casaGrande.Lon, casaGrande.Lat))
+
+	rr = get("/route?start=phx&stops=tempe,tempe&end=yuma")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"DUPLICATE_STOP"`)
+
+	rr = get("/route?start=phx&stops=" + strings.Repeat("tempe,mesa,", maxStops) + "&end=yuma")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"TOO_MANY_STOPS"`)
+
+	// Reroute checks its remaining stops the same way
+	rr = httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, httptest.NewRequest("POST", "/reroute",
+		strings.NewReader(`{"lat":33.40,"lon":-111.95,"end":"tucson","stops":["casa-grand"],"options":{"on_unknown":"skip"}}`)))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), `"skipped_stops":["casa-grand"]`)
+}
+
+func TestGRPCRoute_OnUnknown(t *testing.T) {
+	srv, _ := newTestServer()
+	fakeOSRM(t)
+	client := dialRoutingGRPC(t, srv)
+
+	_, err := client.Route(context.Background(), &routingv1.RouteRequest{Start: "phx", End: "yuma", Stops: []string{"mesaa"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), `did you mean "mesa"?`)
+
+	resp, err := client.Route(context.Background(), &routingv1.RouteRequest{Start: "phx", End: "yuma", Stops: []string{"mesaa"}, OnUnknown: "skip"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mesaa"}, resp.GetSkippedStops())
+}
+
+func TestHandleReroute(t *testing.T) {
+	srv, _ := newTestServer()
+	last := fakeOSRM(t)
+
+	body := `{"lat":33.40,"lon":-111.95,"heading":135,"end":"tucson","stops":["casa-grande"],"options":{"vehicle":"truck"}}`
+	req, _ := http.NewRequest("POST", "/reroute", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	// Starts at the vehicle, heading south-east, through the remaining stop
+	casaGrande, _ := findLocation("casa-grande")
+	assert.True(t, strings.HasPrefix(last.Path, "/route/v1/driving/-111.950000,33.400000;"))
+	assert.Contains(t, last.Path, fmt.Sprintf("%f,%f", casaGrande.Lon, casaGrande.Lat))
+	assert.Equal(t, "135,45;;", last.Query().Get("bearings"))
+	assert.Equal(t, "false", last.Query().Get("alternatives"))
+
+	var resp EnhancedResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, "Reroute", resp.Routes[0].Label)
+	assert.Equal(t, "truck", resp.Routes[0].ETA.Vehicle)
+	assert.Contains(t, resp.Routes[0].Instructions[1].Text, "Turn right onto I 10")
+}
+
+func TestHandleReroute_NearNextStop(t *testing.T) {
+	srv, _ := newTestServer()
+	fakeOSRM(t)
+
+	// Off route just before the next stop, which is also the nearest
+	// catalog location
+	casaGrande, _ := findLocation("casa-grande")
+	require.Equal(t, "casa-grande", nearestLocation(casaGrande.Lat+0.01, casaGrande.Lon).ID)
+	body := fmt.Sprintf(`{"lat":%f,"lon":%f,"end":"tucson","stops":["casa-grande"]}`, casaGrande.Lat+0.01, casaGrande.Lon)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, httptest.NewRequest("POST", "/reroute", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	// The stops as sent are still checked, as body fields
+	rr = httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, httptest.NewRequest("POST", "/reroute",
+		strings.NewReader(`{"lat":33.4,"lon":-111.9,"end":"tucson","stops":["casa-grande","casa-grande"]}`)))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	var p problem.Problem
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &p))
+	assert.Equal(t, problem.DuplicateStop, p.Code)
+	assert.Equal(t, []problem.InvalidParam{{Name: "stops[1]", In: "body",
+		Reason: `repeats the previous waypoint "casa-grande"`, Code: problem.DuplicateStop}}, p.InvalidParams)
+}
+
+func TestHandleReroute_NoHeading(t *testing.T) {
+	srv, _ := newTestServer()
+	last := fakeOSRM(t)
+
+	req, _ := http.NewRequest("POST", "/reroute", strings.NewReader(`{"lat":33.40,"lon":-111.95,"end":"tucson"}`))
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, last.Query().Get("bearings"))
+	assert.Equal(t, "true", last.Query().Get("alternatives"))
+}
+
+func TestHandleReroute_BadRequest(t *testing.T) {
+	srv, _ := newTestServer()
+	for _, body := range []string{
+		`not json`,
+		`{"lat":33.4,"lon":-111.9}`,
+		`{"lat":133.4,"lon":-111.9,"end":"tucson"}`,
+		`{"lat":33.4,"lon":-111.9,"heading":400,"end":"tucson"}`,
+		`{"lat":33.4,"lon":-111.9,"end":"tucson","stops":["atlantis"]}`,
+		`{"lat":33.4,"lon":-111.9,"end":"tucson","options":{"vehicle":"hovercraft"}}`,
+	} {
+		req, _ := http.NewRequest("POST", "/reroute", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		srv.Router().ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+	}
+}
+
+func TestSavedRoutes_SaveGetShareDelete(t *testing.T) {
+	srv, _ := newTestServer()
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		srv.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do("POST", "/routes", `{"label":"Depot run","ttl":"24h","response":{"routes":[{"label":"Fastest","distance":12000,"duration":900,"full_coords":[[-112.07,33.45],[-111.94,33.43]]}]}}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var created SavedRouteView
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "/share/"+created.ShareToken, created.ShareURL)
+	assert.Equal(t, "/routes/"+created.ID, rr.Header().Get("Location"))
+	require.NotNil(t, created.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), *created.ExpiresAt, time.Minute)
+
+	rr = do("GET", "/routes/"+created.ID, "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var got SavedRouteView
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	assert.Equal(t, "Depot run", got.Label)
+	var resp EnhancedResponse
+	require.NoError(t, json.Unmarshal(got.Response, &resp))
+	assert.Equal(t, 12000.0, resp.Routes[0].Distance)
+
+	// The share link is read-only: it does not reveal the ID
+	rr = do("GET", created.ShareURL, "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var shared SavedRouteView
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &shared))
+	assert.Empty(t, shared.ID)
+	assert.Empty(t, shared.ShareToken)
+	assert.JSONEq(t, string(got.Response), string(shared.Response))
+
+	assert.Equal(t, http.StatusNoContent, do("DELETE", "/routes/"+created.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/routes/"+created.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", created.ShareURL, "").Code)
+	assert.Equal(t, http.StatusNotFound, do("DELETE", "/routes/"+created.ID, "").Code)
+
+	// Spatial queries still take precedence over /routes/{id}
+	assert.Equal(t, http.StatusNotImplemented, do("GET", "/routes/near?lat=33.4&lon=-112", "").Code)
+}
+
+func TestSavedRoutes_Expiry(t *testing.T) {
+	srv, _ := newTestServer()
+	past := time.Now().Add(-time.Minute)
+	require.NoError(t, srv.saved.Save(context.Background(), &store.SavedRoute{ID: "old", ShareToken: "old-tok", Data: []byte(`{"routes":[]}`), ExpiresAt: &past}))
+
+	req, _ := http.NewRequest("GET", "/share/old-tok", nil)
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusGone, rr.Code)
+	_, err := srv.saved.Get(context.Background(), "old")
+	assert.ErrorIs(t, err, store.ErrNoSavedRoute)
+
+	for _, body := range []string{
+		`not json`,
+		`{"ttl":"1h"}`,
+		`{"response":{"routes":[]}}`,
+		`{"ttl":"soon","response":{"routes":[{"label":"Fastest"}]}}`,
+		`{"ttl":"2400h","response":{"routes":[{"label":"Fastest"}]}}`,
+		`{"expires_at":"2001-01-01T00:00:00Z","response":{"routes":[{"label":"Fastest"}]}}`,
+		`{"ttl":"1h","expires_at":"2099-01-01T00:00:00Z","response":{"routes":[{"label":"Fastest"}]}}`,
+	} {
+		req, _ := http.NewRequest("POST", "/routes", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		srv.Router().ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+	}
+}
+
+func TestUserProfiles_PlacesFavoritesReplay(t *testing.T) {
+	srv, _ := newTestServer()
+	last := fakeOSRM(t)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		srv.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusNotFound, do("PUT", "/users/ana/places/home", `{"lat":33.4,"lon":-111.95}`).Code)
+	assert.Equal(t, http.StatusCreated, do("PUT", "/users/ana", `{"name":"Ana"}`).Code)
+	assert.Equal(t, http.StatusOK, do("PUT", "/users/ana", `{"name":"Ana R."}`).Code)
+	rr := do("PUT", "/users/ana/places/home", `{"label":"Home","lat":33.40,"lon":-111.95}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, http.StatusBadRequest, do("PUT", "/users/ana/places/bad", `{"lat":133.4,"lon":-111.95}`).Code)
+
+	rr = do("POST", "/users/ana/favorites", `{"label":"Home to Tucson","start":"place:home","stops":["casa-grande"],"end":"tucson","options":{"vehicle":"truck"}}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var fav TripView
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &fav))
+	assert.Equal(t, "truck", fav.Options["vehicle"])
+
+	// Replays through the place's own coordinates
+	rr = do("GET", fmt.Sprintf("/users/ana/favorites/%d/route", fav.ID), "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	casaGrande, _ := findLocation("casa-grande")
+	assert.True(t, strings.HasPrefix(last.Path, "/route/v1/driving/-111.950000,33.400000;"))
+	assert.Contains(t, last.Path, fmt.Sprintf("%f,%f", casaGrande.Lon, casaGrande.Lat))
+	var resp EnhancedResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, "truck", resp.Routes[0].ETA.Vehicle)
+
+	// The replay and a direct user route both land in recent trips
+	rr = do("GET", "/users/ana/route?start=place:home&end=tucson", "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	rr = do("GET", "/users/ana", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var user UserView
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &user))
+	assert.Equal(t, "Ana R.", user.Name)
+	assert.Len(t, user.Places, 1)
+	assert.Len(t, user.Favorites, 1)
+	require.Len(t, user.Recent, 2)
+	assert.Empty(t, user.Recent[0].Stops)
+	assert.Equal(t, []string{"casa-grande"}, user.Recent[1].Stops)
+
+	rr = do("GET", fmt.Sprintf("/users/ana/trips/%d/route", user.Recent[1].ID), "")
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	// A favorite's ID is not a recent trip
+	assert.Equal(t, http.StatusNotFound, do("GET", fmt.Sprintf("/users/ana/trips/%d/route", fav.ID), "").Code)
+
+	// Deleting the place breaks the favorite until it is saved again
+	assert.Equal(t, http.StatusNoContent, do("DELETE", "/users/ana/places/home", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do("GET", fmt.Sprintf("/users/ana/favorites/%d/route", fav.ID), "").Code)
+
+	assert.Equal(t, http.StatusNoContent, do("DELETE", fmt.Sprintf("/users/ana/favorites/%d", fav.ID), "").Code)
+	assert.Equal(t, http.StatusNoContent, do("DELETE", "/users/ana", "").Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/users/ana", "").Code)
+}
+
+func TestUserRoute_PlacesSharingAStandIn(t *testing.T) {
+	srv, _ := newTestServer()
+	fakeOSRM(t)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		srv.Router().ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rr
+	}
+	do("PUT", "/users/ana", `{"name":"Ana"}`)
+	do("PUT", "/users/ana/places/home", `{"label":"Home","lat":33.40,"lon":-111.95}`)
+	do("PUT", "/users/ana/places/gym", `{"label":"Gym","lat":33.41,"lon":-111.94}`)
+
+	// Both places plan on the same catalog location; only the stops as sent
+	// may not repeat
+	rr := do("GET", "/users/ana/route?start=place:home&stops=place:gym&end=tucson", "")
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	rr = do("GET", "/users/ana/route?start=place:home&stops=place:gim&end=tucson", "")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"suggestion":"place:gym"`)
+}
+
+func TestUserProfiles_BadFavorites(t *testing.T) {
+	srv, _ := newTestServer()
+	req, _ := http.NewRequest("PUT", "/users/ana", nil)
+	srv.Router().ServeHTTP(httptest.NewRecorder(), req)
+
+	for _, body := range []string{
+		`not json`,
+		`{"start":"phx"}`,
+		`{"start":"phx","end":"atlantis"}`,
+		`{"start":"place:home","end":"tucson"}`,
+		`{"start":"phx","end":"tucson","options":{"end":"yuma"}}`,
+		`{"start":"phx","end":"tucson","options":{"vehicle":"hovercraft"}}`,
+		`{"start":"phx","stops":["place:hom"],"end":"tucson"}`,
+		`{"start":"phx","stops":["tempe","tempe"],"end":"tucson"}`,
+	} {
+		req, _ := http.NewRequest("POST", "/users/ana/favorites", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		srv.Router().ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+	}
+}
+
+func TestResolvedTrip_WithVias(t *testing.T) {
+	home := Location{ID: "place:home", Lat: 33.40, Lon: -111.95}
+	depot := Location{ID: "place:depot", Name: "Depot", Lat: 32.90, Lon: -111.70}
+	tucson, _ := findLocation("tucson")
+	rt := &resolvedTrip{points: []Location{home, depot, tucson}, standIns: []string{"tempe", "casa-grande", "tucson"}}
+
+	// A corridor detour adds a catalog via before the depot
+	points, stops := rt.withVias([]string{"maricopa", "casa-grande"})
+	ids := make([]string, len(points))
+	for i, p := range points {
+		ids[i] = p.ID
+	}
+	assert.Equal(t, []string{"place:home", "maricopa", "place:depot", "tucson"}, ids)
+	assert.Equal(t, []string{"", "Depot"}, stops)
+}
+
+func TestAuth_ScopesAndUserProfiles(t *testing.T) {
+	srv, _ := newTestServer()
+	keys := []auth.APIKey{
+		{ID: "ana", SHA256: auth.HashKey("ana-key"), Scopes: []string{auth.ScopeRoutesRead}},
+		{ID: "ops", SHA256: auth.HashKey("ops-key"), Scopes: []string{auth.ScopeUsersAdmin}},
+	}
+	path := filepath.Join(t.TempDir(), "keys.json")
+	data, _ := json.Marshal(keys)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	var err error
+	srv.auth, err = auth.New(auth.Config{APIKeysPath: path})
+	require.NoError(t, err)
+	router := srv.Router()
+
+	do := func(method, target, key string) int {
+		req := httptest.NewRequest(method, target, strings.NewReader(`{}`))
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, do("GET", "/health", ""))
+	assert.Equal(t, http.StatusUnauthorized, do("GET", "/route?start=phx&end=tucson", ""))
+	assert.Equal(t, http.StatusForbidden, do("POST", "/routes", "ana-key"))
+	assert.Equal(t, http.StatusNotFound, do("GET", "/routes/missing", "ana-key"))
+
+	assert.Equal(t, http.StatusCreated, do("PUT", "/users/ana", "ana-key"))
+	assert.Equal(t, http.StatusForbidden, do("GET", "/users/bob", "ana-key"))
+	assert.Equal(t, http.StatusOK, do("GET", "/users/ana", "ops-key"))
+}
+
+func TestRateLimit_PerKeyAndQuota(t *testing.T) {
+	srv, _ := newTestServer()
+	srv.limiter = ratelimit.New(ratelimit.Config{Rate: 100, Burst: 100, DailyQuota: 2}, ratelimit.NewMemoryQuotas())
+	router := srv.Router()
+
+	get := func(addr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/routes/missing", nil)
+		req.RemoteAddr = addr
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("10.0.0.1:1000")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, http.StatusNotFound, get("10.0.0.1:1001").Code)
+
+	rr = get("10.0.0.1:1002")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+
+	// Other clients and public endpoints are unaffected
+	assert.Equal(t, http.StatusNotFound, get("10.0.0.2:1000").Code)
+	req := httptest.NewRequest("GET", "/health", nil)
+	req.RemoteAddr = "10.0.0.1:1003"
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestMetrics_CacheAndOSRM(t *testing.T) {
+	srv, _ := newTestServer()
+	fakeOSRM(t)
+	router := srv.Router()
+	misses := testutil.ToFloat64(cacheLookups.WithLabelValues("miss"))
+	hits := testutil.ToFloat64(cacheLookups.WithLabelValues("hit"))
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/route?start=phx&end=tucson", nil))
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	}
+	assert.Equal(t, misses+1, testutil.ToFloat64(cacheLookups.WithLabelValues("miss")))
+	assert.Equal(t, hits+1, testutil.ToFloat64(cacheLookups.WithLabelValues("hit")))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "navifly_routing_osrm_request_duration_seconds_count")
+	assert.Contains(t, rr.Body.String(), `navifly_http_request_duration_seconds_count{code="200",method="GET",route="/route"}`)
+}
+
+func TestTracing_RouteCacheMissSpans(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	defer otel.SetTracerProvider(prev)
+	_, err := tracing.Setup(context.Background(), "routing", tracing.Config{Exporter: tracing.ExporterNone})
+	require.NoError(t, err)
+
+	srv, _ := newTestServer()
+	fakeOSRM(t)
+	req := httptest.NewRequest("GET", "/route?start=phx&end=tucson", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rr := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	names := map[string]bool{}
+	for _, span := range rec.Ended() {
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String(), span.Name())
+		names[span.Name()] = true
+	}
+	assert.True(t, names["GET /route"], "server span")
+	assert.True(t, names["HTTP GET"], "OSRM client span")
+}
+
+func TestLogging_RequestIDAndCacheFields(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(logging.New(&buf, "routing", slog.LevelInfo))
+	defer slog.SetDefault(prev)
+
+	srv, _ := newTestServer()
+	fakeOSRM(t)
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("GET", "/route?start=phx&end=tucson", nil)
+		req.Header.Set(logging.HeaderRequestID, fmt.Sprintf("req-%d", i))
+		rr := httptest.NewRecorder()
+		srv.Router().ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, fmt.Sprintf("req-%d", i), rr.Header().Get(logging.HeaderRequestID))
+	}
+
+	var lookups, upstream []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &rec), line)
+		assert.Equal(t, "routing", rec["service"])
+		switch rec["msg"] {
+		case "route lookup":
+			lookups = append(lookups, rec)
+		case "upstream request":
+			upstream = append(upstream, rec)
+		}
+	}
+	require.Len(t, lookups, 2)
+	assert.Equal(t, "miss", lookups[0]["cache"])
+	assert.Equal(t, "req-0", lookups[0]["request_id"])
+	assert.Equal(t, "hit", lookups[1]["cache"])
+	assert.Equal(t, "tucson", lookups[1]["end"])
+	require.Len(t, upstream, 1)
+	assert.Equal(t, "osrm", upstream[0]["upstream"])
+	assert.Contains(t, upstream[0], "upstream_ms")
+}
+
+func TestProbes_ReadinessPingsDB(t *testing.T) {
+	db, err := store.OpenSQLite(filepath.Join(t.TempDir(), "routes.db"))
+	require.NoError(t, err)
+	srv := NewServer(db)
+	r := srv.Router()
+
+	probe := func(path string) (int, string) {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		return rr.Code, rr.Body.String()
+	}
+	code, body := probe("/readyz")
+	assert.Equal(t, http.StatusOK, code, body)
+	assert.Contains(t, body, `"db":"ok"`)
+
+	require.NoError(t, db.Close())
+	code, body = probe("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, body, "closed")
+	code, _ = probe("/livez")
+	assert.Equal(t, http.StatusOK, code, "liveness ignores the DB")
+}
+
+func TestPreCalculate_CheckpointsAndResumes(t *testing.T) {
+	prevLocations, prevDelay := locations, precalcDelay
+	locations, precalcDelay = locations[:3], 0
+	t.Cleanup(func() { locations, precalcDelay = prevLocations, prevDelay })
+	fakeOSRM(t)
+	srv, mem := newTestServer()
+	ctx := context.Background()
+
+	// Stopped before the first pair: progress is saved, nothing is fetched
+	stopped, cancel := context.WithCancel(ctx)
+	cancel()
+	srv.preCalculateRealRoutes(stopped)
+	cp, ok := srv.loadPrecalcCheckpoint(ctx)
+	require.True(t, ok)
+	assert.Equal(t, precalcCheckpoint{Next: 0, Total: 6}, cp)
+
+	// A later run resumes without clearing what earlier runs cached
+	require.NoError(t, mem.Put(ctx, "sentinel", "pair", []byte(`{}`)))
+	srv.savePrecalcCheckpoint(ctx, precalcCheckpoint{Next: 4, Total: 6, Cached: 4})
+	srv.preCalculateRealRoutes(ctx)
+
+	_, err := mem.Get(ctx, "sentinel", "pair")
+	assert.NoError(t, err, "cache kept on resume")
+	assert.Equal(t, 3, mem.Len(), "only the last two pairs fetched")
+	_, ok = srv.loadPrecalcCheckpoint(ctx)
+	assert.False(t, ok, "checkpoint removed when done")
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, defaultConfig().Validate())
+
+	cfg := defaultConfig()
+	cfg.Store.Driver = "mongo"
+	cfg.OSRMURL = "router.project-osrm.org"
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, `unknown store driver "mongo"`)
+	assert.ErrorContains(t, err, "osrm_url")
+}
+
+func TestOpenAPI_DocumentsEveryRoute(t *testing.T) {
+	srv, _ := newTestServer()
+	served := map[string]bool{}
+	err := srv.Router().Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		require.NoError(t, err)
+		methods, err := route.GetMethods()
+		require.NoError(t, err)
+		for _, m := range methods {
+			served[m+" "+path] = true
+			assert.NotNil(t, apiSpec.Operation(m, path), "%s %s is not in openapi.yaml", m, path)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	for path, item := range apiSpec.Doc.Paths.Map() {
+		for m := range item.Operations() {
+			assert.True(t, served[m+" "+path], "openapi.yaml documents %s %s, which is not served", m, path)
+		}
+	}
+
+	var documented []problem.Code
+	for _, v := range apiSpec.Doc.Components.Schemas["ErrorCode"].Value.Enum {
+		documented = append(documented, problem.Code(v.(string)))
+	}
+	assert.ElementsMatch(t, problem.Codes(), documented, "openapi.yaml ErrorCode lists every problem code")
+}
+
+func TestOpenAPI_RejectsInvalidRequests(t *testing.T) {
+	srv, _ := newTestServer()
+	r := srv.Router()
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rr
+	}
+	var p problem.Problem
+	details := func(rr *httptest.ResponseRecorder) []string {
+		require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+		p = problem.Problem{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &p))
+		var out []string
+		for _, param := range p.InvalidParams {
+			out = append(out, param.String())
+		}
+		return out
+	}
+
+	got := details(do("GET", "/routes/near?lat=abc&lon=-200", ""))
+	require.Len(t, got, 2)
+	assert.Contains(t, got[0], `query parameter "lat"`)
+	assert.Contains(t, got[1], `query parameter "lon": number must be at least -180`)
+	assert.Equal(t, problem.InvalidCoordinate, p.Code)
+
+	got = details(do("GET", "/route?start=phx&end=tucson&vehicle=bike&geometry=wkt", ""))
+	assert.Len(t, got, 2)
+
+	got = details(do("POST", "/reroute", `{"lat": 33.4, "lon": -112, "heading": 400}`))
+	assert.ElementsMatch(t, []string{
+		`request body: end: property "end" is missing`,
+		`request body: heading: number must be at most 360`,
+	}, got)
+
+	rr := do("GET", "/openapi.json", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"/routes/near"`)
+	assert.Equal(t, http.StatusOK, do("GET", "/docs", "").Code)
+}
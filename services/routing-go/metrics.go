@@ -0,0 +1,41 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"navifly/platform/metrics"
+)
+
+// ── Prometheus Metrics ──
+
+var (
+	cacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "routing",
+		Name:      "cache_lookups_total",
+		Help:      "Route store lookups for direct routes, by result (hit, miss, error).",
+	}, []string{"result"})
+
+	osrmDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "routing",
+		Name:      "osrm_request_duration_seconds",
+		Help:      "Latency of OSRM upstream requests, including failed ones.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2, 5, 10, 30},
+	})
+
+	osrmErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "routing",
+		Name:      "osrm_errors_total",
+		Help:      "Failed OSRM requests, by reason (transport, read, parse, no_route).",
+	}, []string{"reason"})
+
+	precalcRoutes = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "routing",
+		Name:      "precalc_routes",
+		Help:      "Startup pre-calculation progress: total pairs, cached and failed so far.",
+	}, []string{"state"})
+)
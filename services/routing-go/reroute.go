@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"navifly/platform/logging"
+	"navifly/platform/problem"
+)
+
+// ── Rerouting ──
+
+// headingRangeDeg is how far the first road may deviate from the vehicle's
+// heading. OSRM only snaps the start to roads within this bearing, so the
+// route begins in the direction of travel instead of with a U-turn.
+const headingRangeDeg = 45
+
+// RerouteRequest is the body of POST /reroute.
+type RerouteRequest struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+	// Heading is the direction of travel in degrees clockwise from north.
+	// Omit it when the vehicle is stationary.
+	Heading *float64 `json:"heading,omitempty"`
+	// End and Stops are catalog IDs; Stops are the ones not yet visited, in
+	// order.
+	End   string   `json:"end"`
+	Stops []string `json:"stops,omitempty"`
+	// Options are any other /route parameters, e.g. {"vehicle": "truck"}.
+	Options map[string]string `json:"options,omitempty"`
+}
+
+func (req RerouteRequest) validate() error {
+	if req.Lat < -90 || req.Lat > 90 || req.Lon < -180 || req.Lon > 180 {
+		return problem.New(problem.InvalidCoordinate, "lat/lon out of range")
+	}
+	if req.Heading != nil && (*req.Heading < 0 || *req.Heading > 360 || math.IsNaN(*req.Heading)) {
+		return problem.New(problem.InvalidRequest, "heading must be between 0 and 360 degrees")
+	}
+	if req.End == "" {
+		return problem.New(problem.InvalidRequest, "end is required")
+	}
+	return nil
+}
+
+// HandleReroute routes from the vehicle's current position through its
+// remaining stops to the destination. Route options apply as for /route;
+// the nearest catalog location stands in for the start in the corridor
+// graph (truck restrictions, avoid areas and timed ETAs).
+func (s *Server) HandleReroute(w http.ResponseWriter, r *http.Request) {
+	var req RerouteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, r, problem.InvalidRequest, "invalid JSON body: "+err.Error())
+		return
+	}
+	if err := req.validate(); err != nil {
+		problem.WriteError(w, r, err, problem.InvalidRequest)
+		return
+	}
+
+	// Only the stops and end as sent are checked: the stand-in start may
+	// well be the next stop when the vehicle leaves its route just before it
+	check := catalogWaypoints
+	check.in = "body"
+	stops, skipped, err := check.check("", req.Stops, req.End, req.Options["on_unknown"])
+	if err != nil {
+		problem.WriteError(w, r, err, problem.InvalidRequest)
+		return
+	}
+	q := url.Values{}
+	for k, v := range req.Options {
+		q.Set(k, v)
+	}
+	waypoints := append(append([]string{nearestLocation(req.Lat, req.Lon).ID}, stops...), req.End)
+	opts, err := parseTripOptions(q, waypoints)
+	if err != nil {
+		problem.WriteError(w, r, err, problem.InvalidRequest)
+		return
+	}
+	opts.skippedStops = skipped
+	if err := s.resolveAvoid(r.Context(), &opts); err != nil {
+		problem.WriteError(w, r, err, problem.UpstreamUnavailable)
+		return
+	}
+	stopsParam, err := s.planCorridor(&opts)
+	if err != nil {
+		problem.WriteError(w, r, err, problem.NoRoute)
+		return
+	}
+
+	resp, err := fetchReroute(r.Context(), req.Lat, req.Lon, req.Heading, splitStops(stopsParam), opts.stopNames(), req.End)
+	if err != nil {
+		problem.WriteError(w, r, err, problem.UpstreamUnavailable)
+		return
+	}
+	if err := s.finishRoute(resp, opts); err != nil {
+		problem.WriteError(w, r, err, problem.NoRoute)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// fetchReroute asks OSRM for a route from a free position through catalog
+// stops, named by stopNames. With a heading, the start is snapped to a road
+// in that direction.
+func fetchReroute(ctx context.Context, lat, lon float64, heading *float64, stops, stopNames []string, endID string) (*EnhancedResponse, error) {
+	points := []Location{{ID: "vehicle", Lat: lat, Lon: lon}}
+	for _, id := range append(append([]string{}, stops...), endID) {
+		loc, ok := findLocation(id)
+		if !ok {
+			return nil, problem.Errorf(problem.UnknownLocation, "unknown location: %s", id)
+		}
+		points = append(points, loc)
+	}
+
+	resp, err := fetchPointRoute(ctx, points, stopNames, heading, "Reroute")
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "reroute", "lat", lat, "lon", lon, "stops", len(stops), logging.KeyEnd, endID, "distance_km", math.Round(resp.Routes[0].Distance/100)/10)
+	return resp, nil
+}
+
+// fetchPointRoute asks OSRM for a route through arbitrary points, which need
+// not be in the catalog; stopNames names the points between the first and
+// last. Direct trips get alternatives; the first route is labelled label. A
+// heading constrains the first point only.
+func fetchPointRoute(ctx context.Context, points []Location, stopNames []string, heading *float64, label string) (*EnhancedResponse, error) {
+	coords := make([]string, len(points))
+	for i, p := range points {
+		coords[i] = fmt.Sprintf("%f,%f", p.Lon, p.Lat)
+	}
+
+	params := url.Values{
+		"overview":     {"full"},
+		"geometries":   {"geojson"},
+		"steps":        {"true"},
+		"alternatives": {fmt.Sprint(len(coords) == 2)},
+	}
+	if heading != nil {
+		// Only the start is constrained; later waypoints take any bearing
+		params.Set("bearings", fmt.Sprintf("%.0f,%d", math.Mod(*heading, 360), headingRangeDeg)+strings.Repeat(";", len(coords)-1))
+	}
+	osrmResp, err := getOSRM(ctx, fmt.Sprintf("%s/route/v1/driving/%s?%s", osrmURL, strings.Join(coords, ";"), params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	enhancedResp := EnhancedResponse{Routes: []EnhancedRoute{}}
+	for i, osrmRoute := range osrmResp.Routes {
+		coords := make([][2]float64, len(osrmRoute.Geometry.Coordinates))
+		for j, c := range osrmRoute.Geometry.Coordinates {
+			coords[j] = [2]float64{c[0], c[1]}
+		}
+		steps := legSteps(osrmRoute.Legs)
+
+		routeLabel := label
+		if i > 0 {
+			routeLabel = "Alternative"
+		}
+		enhancedResp.Routes = append(enhancedResp.Routes, EnhancedRoute{
+			Geometry:         buildTrafficFeatureCollection(coords, nil),
+			Distance:         osrmRoute.Distance,
+			Duration:         osrmRoute.Duration,
+			FreeFlowDuration: osrmRoute.Duration,
+			Label:            routeLabel,
+			FullCoords:       coords,
+			Segments:         stepSegments(steps),
+			Instructions:     stepInstructions(osrmRoute.Legs, stopNames),
+		})
+	}
+	return &enhancedResp, nil
+}
+
+// nearestLocation is the catalog location closest to lat/lon.
+func nearestLocation(lat, lon float64) Location {
+	best, bestKm := locations[0], math.Inf(1)
+	for _, loc := range locations {
+		if d := haversine(lat, lon, loc.Lat, loc.Lon); d < bestKm {
+			best, bestKm = loc, d
+		}
+	}
+	return best
+}
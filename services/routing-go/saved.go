@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"navifly/platform/logging"
+	"navifly/platform/problem"
+	"navifly/routing/internal/store"
+)
+
+// ── Saved & Shared Routes ──
+
+// maxSavedTTL caps how long a saved route may be kept when it expires at all.
+const maxSavedTTL = 90 * 24 * time.Hour
+
+// SaveRouteRequest is the body of POST /routes.
+type SaveRouteRequest struct {
+	Label string `json:"label,omitempty"`
+	// Response is a computed /route, /reroute or gRPC response, saved as-is.
+	Response *EnhancedResponse `json:"response"`
+	// TTL (a duration such as "72h") or ExpiresAt sets the expiry; with
+	// neither the route is kept until deleted.
+	TTL       string     `json:"ttl,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// SavedRouteView is a saved route as returned by the API. ID and the share
+// token are only shown to whoever saved it; GET /share/{token} omits them.
+type SavedRouteView struct {
+	ID         string          `json:"id,omitempty"`
+	ShareToken string          `json:"share_token,omitempty"`
+	ShareURL   string          `json:"share_url,omitempty"`
+	Label      string          `json:"label,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	ExpiresAt  *time.Time      `json:"expires_at,omitempty"`
+	Response   json.RawMessage `json:"response,omitempty"`
+}
+
+// expiry resolves ttl/expires_at against now.
+func (req SaveRouteRequest) expiry(now time.Time) (*time.Time, error) {
+	if req.TTL != "" && req.ExpiresAt != nil {
+		return nil, fmt.Errorf("use ttl or expires_at, not both")
+	}
+	var at time.Time
+	switch {
+	case req.TTL != "":
+		d, err := time.ParseDuration(req.TTL)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("ttl must be a positive duration such as 72h")
+		}
+		at = now.Add(d)
+	case req.ExpiresAt != nil:
+		at = *req.ExpiresAt
+		if !at.After(now) {
+			return nil, fmt.Errorf("expires_at must be in the future")
+		}
+	default:
+		return nil, nil
+	}
+	if at.Sub(now) > maxSavedTTL {
+		return nil, fmt.Errorf("routes can be kept for at most %s", maxSavedTTL)
+	}
+	at = at.UTC().Truncate(time.Second)
+	return &at, nil
+}
+
+// HandleSaveRoute stores a computed route under a new ID and share token.
+func (s *Server) HandleSaveRoute(w http.ResponseWriter, r *http.Request) {
+	var req SaveRouteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, r, problem.InvalidRequest, "invalid JSON body: "+err.Error())
+		return
+	}
+	if req.Response == nil || len(req.Response.Routes) == 0 {
+		problem.Write(w, r, problem.InvalidRequest, "response must contain at least one route")
+		return
+	}
+	now := time.Now()
+	expiresAt, err := req.expiry(now)
+	if err != nil {
+		problem.WriteError(w, r, err, problem.InvalidRequest)
+		return
+	}
+
+	for i := range req.Response.Routes {
+		req.Response.Routes[i].Segments = nil
+	}
+	data, _ := json.Marshal(req.Response)
+	saved := &store.SavedRoute{
+		ID:         randomID(12, hex.EncodeToString),
+		ShareToken: randomID(18, base64.RawURLEncoding.EncodeToString),
+		Label:      req.Label,
+		Data:       data,
+		CreatedAt:  now.UTC().Truncate(time.Second),
+		ExpiresAt:  expiresAt,
+	}
+	if err := s.saved.Save(r.Context(), saved); err != nil {
+		slog.ErrorContext(r.Context(), "failed to save route", logging.Err(err))
+		problem.Write(w, r, problem.Internal, "Failed to save route")
+		return
+	}
+	slog.InfoContext(r.Context(), "route saved", "saved_route_id", saved.ID, "alternatives", len(req.Response.Routes))
+
+	view := savedView(saved, true)
+	view.Response = nil
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/routes/"+saved.ID)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(view)
+}
+
+// HandleGetSavedRoute returns a saved route by ID.
+func (s *Server) HandleGetSavedRoute(w http.ResponseWriter, r *http.Request) {
+	saved, err := s.saved.Get(r.Context(), mux.Vars(r)["id"])
+	s.writeSavedRoute(w, r, saved, err, true)
+}
+
+// HandleSharedRoute returns a saved route by share token, read-only.
+func (s *Server) HandleSharedRoute(w http.ResponseWriter, r *http.Request) {
+	saved, err := s.saved.GetByToken(r.Context(), mux.Vars(r)["token"])
+	s.writeSavedRoute(w, r, saved, err, false)
+}
+
+// HandleDeleteSavedRoute removes a saved route, revoking its share link.
+func (s *Server) HandleDeleteSavedRoute(w http.ResponseWriter, r *http.Request) {
+	err := s.saved.Delete(r.Context(), mux.Vars(r)["id"])
+	if errors.Is(err, store.ErrNoSavedRoute) {
+		problem.Write(w, r, problem.NotFound, "Saved route not found")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to delete saved route", logging.Err(err))
+		problem.Write(w, r, problem.Internal, "Failed to delete route")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeSavedRoute answers a lookup; expired routes are 410 Gone and removed.
+func (s *Server) writeSavedRoute(w http.ResponseWriter, r *http.Request, saved *store.SavedRoute, err error, owner bool) {
+	if errors.Is(err, store.ErrNoSavedRoute) {
+		problem.Write(w, r, problem.NotFound, "Saved route not found")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "saved route lookup failed", logging.Err(err))
+		problem.Write(w, r, problem.Internal, "Saved route lookup failed")
+		return
+	}
+	if saved.Expired(time.Now()) {
+		_ = s.saved.Delete(r.Context(), saved.ID)
+		problem.Write(w, r, problem.Expired, "Saved route has expired")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(savedView(saved, owner))
+}
+
+func savedView(saved *store.SavedRoute, owner bool) SavedRouteView {
+	view := SavedRouteView{
+		Label:     saved.Label,
+		CreatedAt: saved.CreatedAt.UTC(),
+		ExpiresAt: saved.ExpiresAt,
+		Response:  saved.Data,
+	}
+	if view.ExpiresAt != nil {
+		at := view.ExpiresAt.UTC()
+		view.ExpiresAt = &at
+	}
+	if owner {
+		view.ID = saved.ID
+		view.ShareToken = saved.ShareToken
+		view.ShareURL = "/share/" + saved.ShareToken
+	}
+	return view
+}
+
+// purgeSavedRoutes drops expired saved routes every interval.
+func (s *Server) purgeSavedRoutes(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n, err := s.saved.PurgeExpired(ctx, now)
+			if err != nil {
+				slog.ErrorContext(ctx, "saved route purge failed", logging.Err(err))
+			} else if n > 0 {
+				slog.InfoContext(ctx, "purged expired saved routes", "count", n)
+			}
+		}
+	}
+}
+
+// randomID encodes n random bytes. IDs and share tokens are unguessable
+// because either one grants access to the route.
+func randomID(n int, encode func([]byte) string) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+	return encode(b)
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"navifly/platform/problem"
+	"navifly/routing/internal/routing"
+)
+
+// ── Time-Dependent Scheduling ──
+
+// arizonaTime is local time for speed profiles. Arizona does not observe
+// DST, so a fixed offset is exact and needs no tzdata in the container.
+var arizonaTime = time.FixedZone("MST", -7*60*60)
+
+const (
+	metroFreeFlowKmh  = 70.0
+	ruralFreeFlowKmh  = 105.0
+	roadDetourFactor  = 1.25 // road distance over straight-line distance
+	corridorNeighbors = 4
+)
+
+type metroArea struct {
+	centerID string
+	radiusKm float64
+	profile  *routing.SpeedProfile
+}
+
+var metroAreas = []metroArea{
+	{"phx", 45, routing.CommuterProfile("Phoenix metro freeway", 0.55, 0.4)},
+	{"tucson", 25, routing.CommuterProfile("Tucson metro freeway", 0.7, 0.6)},
+}
+
+var ruralProfile = routing.FlatProfile("Rural highway")
+
+func metroOf(loc Location) *metroArea {
+	for i := range metroAreas {
+		center, ok := findLocation(metroAreas[i].centerID)
+		if ok && haversine(loc.Lat, loc.Lon, center.Lat, center.Lon) <= metroAreas[i].radiusKm {
+			return &metroAreas[i]
+		}
+	}
+	return nil
+}
+
+// buildCorridorGraph links every catalog location to its nearest neighbours,
+// then bridges any disconnected clusters. Roads inside one metro area get
+// that area's commuter profile; everything else is rural highway.
+func buildCorridorGraph(locs []Location) *routing.Graph {
+	g := routing.NewGraph()
+	for _, loc := range locs {
+		g.AddNode(&routing.Node{ID: loc.ID, Name: loc.Name, Lat: loc.Lat, Lon: loc.Lon})
+	}
+
+	addRoad := func(a, b Location) {
+		if a.ID == b.ID || g.HasEdge(a.ID, b.ID) {
+			return
+		}
+		length := haversine(a.Lat, a.Lon, b.Lat, b.Lon) * roadDetourFactor
+		ma, mb := metroOf(a), metroOf(b)
+		if ma != nil && ma == mb {
+			g.AddRoad(a.ID, b.ID, length, metroFreeFlowKmh, ma.profile)
+		} else {
+			g.AddRoad(a.ID, b.ID, length, ruralFreeFlowKmh, ruralProfile)
+		}
+	}
+
+	for _, a := range locs {
+		others := append([]Location(nil), locs...)
+		sort.Slice(others, func(i, j int) bool {
+			return haversine(a.Lat, a.Lon, others[i].Lat, others[i].Lon) <
+				haversine(a.Lat, a.Lon, others[j].Lat, others[j].Lon)
+		})
+		for i := 1; i <= corridorNeighbors && i < len(others); i++ {
+			addRoad(a, others[i])
+		}
+	}
+
+	// Bridge clusters (e.g. the far north-east) with their shortest link
+	for {
+		comp := components(g, locs)
+		if len(comp) <= 1 {
+			break
+		}
+		best, bestA, bestB := math.Inf(1), Location{}, Location{}
+		for _, a := range locs {
+			for _, b := range locs {
+				if comp[a.ID] != 0 || comp[b.ID] == 0 {
+					continue
+				}
+				if d := haversine(a.Lat, a.Lon, b.Lat, b.Lon); d < best {
+					best, bestA, bestB = d, a, b
+				}
+			}
+		}
+		addRoad(bestA, bestB)
+	}
+	return g
+}
+
+// components labels each node with a connected-component index, 0 being the
+// component containing the first location. It returns nil when connected.
+func components(g *routing.Graph, locs []Location) map[string]int {
+	label := make(map[string]int)
+	next := 0
+	for _, loc := range locs {
+		if _, seen := label[loc.ID]; seen {
+			continue
+		}
+		stack := []string{loc.ID}
+		label[loc.ID] = next
+		for len(stack) > 0 {
+			id := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, e := range g.Edges[id] {
+				if _, seen := label[e.To]; !seen {
+					label[e.To] = next
+					stack = append(stack, e.To)
+				}
+			}
+		}
+		next++
+	}
+	if next <= 1 {
+		return nil
+	}
+	return label
+}
+
+// parseTripTime reads depart_at and arrive_by. Values may be RFC 3339,
+// a local Arizona "2006-01-02T15:04", unix seconds, or "now".
+func parseTripTime(q url.Values) (departAt, arriveBy time.Time, err error) {
+	d, a := q.Get("depart_at"), q.Get("arrive_by")
+	if d != "" && a != "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("use either depart_at or arrive_by, not both")
+	}
+	if d != "" {
+		departAt, err = parseClock(d)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid depart_at: %v", err)
+		}
+	}
+	if a != "" {
+		arriveBy, err = parseClock(a)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid arrive_by: %v", err)
+		}
+	}
+	return departAt, arriveBy, nil
+}
+
+func parseClock(v string) (time.Time, error) {
+	if v == "now" {
+		return time.Now().In(arizonaTime), nil
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(secs, 0).In(arizonaTime), nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.In(arizonaTime), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04", v, arizonaTime); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not RFC 3339, YYYY-MM-DDTHH:MM, unix seconds or \"now\"", v)
+}
+
+// tripFactor runs time-dependent A* leg by leg through waypoints leaving at
+// depart, and returns how much the historical profiles stretch the trip
+// compared with free flow.
+func (s *Server) tripFactor(waypoints []string, depart time.Time) (float64, error) {
+	clock := depart
+	var profiled, freeFlow time.Duration
+	for i := 0; i < len(waypoints)-1; i++ {
+		path, arrive, ok := routing.TimeDependentAStar(s.graph, waypoints[i], waypoints[i+1], clock)
+		if !ok {
+			return 0, problem.Errorf(problem.NoRoute, "no corridor path %s → %s", waypoints[i], waypoints[i+1])
+		}
+		profiled += arrive.Sub(clock)
+		freeFlow += routing.FreeFlowPathTime(s.graph, path)
+		clock = arrive
+	}
+	if freeFlow == 0 {
+		return 1, nil
+	}
+	return profiled.Seconds() / freeFlow.Seconds(), nil
+}
+
+// scheduleRoutes replaces live-traffic ETAs with historical ones for the
+// requested departure, or finds the latest departure meeting arriveBy.
+func (s *Server) scheduleRoutes(resp *EnhancedResponse, waypoints []string, departAt, arriveBy time.Time) error {
+	for i := range resp.Routes {
+		route := &resp.Routes[i]
+		depart := departAt
+		if !arriveBy.IsZero() {
+			// Fixed-point iteration: the duration depends on when we leave.
+			depart = arriveBy.Add(-seconds(route.FreeFlowDuration))
+			for iter := 0; iter < 6; iter++ {
+				factor, err := s.tripFactor(waypoints, depart)
+				if err != nil {
+					return err
+				}
+				next := arriveBy.Add(-seconds(route.FreeFlowDuration * factor))
+				settled := math.Abs(next.Sub(depart).Seconds()) < 30
+				depart = next
+				if settled {
+					break
+				}
+			}
+		}
+
+		factor, err := s.tripFactor(waypoints, depart)
+		if err != nil {
+			return err
+		}
+		route.Duration = route.FreeFlowDuration * factor
+		arrive := depart.Add(seconds(route.Duration))
+		route.DepartAt = &depart
+		route.ArriveAt = &arrive
+	}
+	return nil
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"navifly/platform/logging"
+	"navifly/platform/problem"
+	"navifly/routing/internal/store"
+)
+
+// ── Spatial Queries over Cached Routes ──
+
+const defaultNearRadiusM = 1000.0
+
+// maxNearRadiusM caps /routes/near, so one query cannot match most of the
+// cache.
+const maxNearRadiusM = 50000.0
+
+// HandleRoutesThrough lists cached routes crossing a bounding box given as
+// bbox=minLon,minLat,maxLon,maxLat.
+func (s *Server) HandleRoutesThrough(w http.ResponseWriter, r *http.Request) {
+	if s.spatial == nil {
+		problem.Write(w, r, problem.NotImplemented, "Spatial queries require a PostGIS route store")
+		return
+	}
+
+	bbox, err := parseBBox(r.URL.Query().Get("bbox"))
+	if err != nil {
+		problem.WriteError(w, r, err, problem.InvalidRequest)
+		return
+	}
+
+	matches, err := s.spatial.RoutesThrough(r.Context(), bbox)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "spatial bbox query failed", logging.Err(err))
+		problem.Write(w, r, problem.Internal, "Spatial query failed")
+		return
+	}
+	writeRouteMatches(w, matches)
+}
+
+// HandleRoutesNear lists cached routes passing within radius metres of a point.
+func (s *Server) HandleRoutesNear(w http.ResponseWriter, r *http.Request) {
+	if s.spatial == nil {
+		problem.Write(w, r, problem.NotImplemented, "Spatial queries require a PostGIS route store")
+		return
+	}
+
+	q := r.URL.Query()
+	lat, err := strconv.ParseFloat(q.Get("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		problem.Write(w, r, problem.InvalidCoordinate, "Invalid or missing lat parameter")
+		return
+	}
+	lon, err := strconv.ParseFloat(q.Get("lon"), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		problem.Write(w, r, problem.InvalidCoordinate, "Invalid or missing lon parameter")
+		return
+	}
+	radius := defaultNearRadiusM
+	if v := q.Get("radius"); v != "" {
+		radius, err = strconv.ParseFloat(v, 64)
+		if err != nil || radius <= 0 || radius > maxNearRadiusM {
+			problem.Write(w, r, problem.InvalidRequest, fmt.Sprintf("Invalid radius parameter (metres, at most %.0f)", maxNearRadiusM))
+			return
+		}
+	}
+
+	matches, err := s.spatial.RoutesNear(r.Context(), lat, lon, radius)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "spatial radius query failed", logging.Err(err))
+		problem.Write(w, r, problem.Internal, "Spatial query failed")
+		return
+	}
+	writeRouteMatches(w, matches)
+}
+
+func writeRouteMatches(w http.ResponseWriter, matches []store.RouteMatch) {
+	if matches == nil {
+		matches = []store.RouteMatch{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"routes": matches,
+		"count":  len(matches),
+	})
+}
+
+func parseBBox(s string) (store.BBox, error) {
+	parts := splitString(s, ',')
+	if s == "" || len(parts) != 4 {
+		return store.BBox{}, fmt.Errorf("bbox must be minLon,minLat,maxLon,maxLat")
+	}
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(trimSpace(p), 64)
+		if err != nil {
+			return store.BBox{}, fmt.Errorf("invalid bbox value %q", p)
+		}
+		v[i] = f
+	}
+	bbox := store.BBox{MinLon: v[0], MinLat: v[1], MaxLon: v[2], MaxLat: v[3]}
+	if bbox.MinLon >= bbox.MaxLon || bbox.MinLat >= bbox.MaxLat {
+		return store.BBox{}, fmt.Errorf("bbox min values must be below max values")
+	}
+	if bbox.MinLat < -90 || bbox.MaxLat > 90 || bbox.MinLon < -180 || bbox.MaxLon > 180 {
+		return store.BBox{}, problem.New(problem.InvalidCoordinate, "bbox is outside WGS84 bounds")
+	}
+	return bbox, nil
+}
+
+// routeLines extracts the geometry of every alternative for spatial indexing.
+func routeLines(resp *EnhancedResponse) []store.RouteLine {
+	lines := make([]store.RouteLine, 0, len(resp.Routes))
+	for i, route := range resp.Routes {
+		lines = append(lines, store.RouteLine{Index: i, Label: route.Label, Coords: route.FullCoords})
+	}
+	return lines
+}
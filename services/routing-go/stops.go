@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"navifly/platform/problem"
+)
+
+// ── Stop Validation ──
+
+// maxStops caps the stops between start and end, so one request cannot
+// tie up OSRM with an arbitrarily long tour.
+const maxStops = 25
+
+// on_unknown modes. fail rejects a request naming an unknown stop; skip
+// routes without it and lists it in skipped_stops. Unknown starts and ends
+// always fail.
+const (
+	onUnknownFail = "fail"
+	onUnknownSkip = "skip"
+)
+
+// waypointCheck validates the start, stops and end of one trip.
+type waypointCheck struct {
+	// known reports whether id names a waypoint.
+	known func(id string) bool
+	// candidates are the IDs suggested in place of unknown ones.
+	candidates []string
+	// in is where the waypoints were sent, e.g. query.
+	in string
+}
+
+// catalogWaypoints checks waypoints against the location catalog.
+var catalogWaypoints = waypointCheck{
+	known: func(id string) bool {
+		_, ok := findLocation(id)
+		return ok
+	},
+	candidates: locationIDs(),
+	in:         "query",
+}
+
+func locationIDs() []string {
+	ids := make([]string, len(locations))
+	for i, loc := range locations {
+		ids[i] = loc.ID
+	}
+	return ids
+}
+
+// check returns the stops to route through and the unknown stops skipped.
+// It rejects more than maxStops stops, and otherwise reports every unknown
+// ID, with the closest known one as a suggestion, and every stop that
+// repeats the waypoint before it. An empty start is a free position, such as
+// a vehicle's, and is neither looked up nor compared with the first stop.
+func (c waypointCheck) check(start string, stops []string, end, onUnknown string) (kept, skipped []string, err error) {
+	switch onUnknown {
+	case "", onUnknownFail, onUnknownSkip:
+	default:
+		return nil, nil, problem.Invalid(problem.InvalidParam{Name: "on_unknown", In: c.in,
+			Reason: fmt.Sprintf("must be %s or %s", onUnknownSkip, onUnknownFail), Code: problem.InvalidRequest})
+	}
+	if len(stops) > maxStops {
+		return nil, nil, problem.Invalid(problem.InvalidParam{Name: "stops", In: c.in,
+			Reason: fmt.Sprintf("%d stops, at most %d are allowed", len(stops), maxStops), Code: problem.TooManyStops})
+	}
+
+	var params []problem.InvalidParam
+	unknown := func(name, id string) {
+		params = append(params, problem.InvalidParam{Name: name, In: c.in, Reason: fmt.Sprintf("unknown location %q", id),
+			Code: problem.UnknownLocation, Suggestion: suggest(id, c.candidates)})
+	}
+	if start != "" && !c.known(start) {
+		unknown("start", start)
+	}
+
+	prev := start
+	for i, id := range stops {
+		name := fmt.Sprintf("stops[%d]", i)
+		if !c.known(id) {
+			if onUnknown == onUnknownSkip {
+				skipped = append(skipped, id)
+				continue
+			}
+			unknown(name, id)
+		}
+		if id == prev {
+			params = append(params, problem.InvalidParam{Name: name, In: c.in,
+				Reason: fmt.Sprintf("repeats the previous waypoint %q", id), Code: problem.DuplicateStop})
+		}
+		kept = append(kept, id)
+		prev = id
+	}
+
+	if !c.known(end) {
+		unknown("end", end)
+	}
+	if len(kept) > 0 && end == prev {
+		params = append(params, problem.InvalidParam{Name: "end", In: c.in,
+			Reason: fmt.Sprintf("repeats the last stop %q", end), Code: problem.DuplicateStop})
+	}
+	if len(params) > 0 {
+		return nil, nil, problem.Invalid(params...)
+	}
+	return kept, skipped, nil
+}
+
+// suggest returns the candidate closest to id, ignoring case, or "" when
+// none is close enough to be a likely typo.
+func suggest(id string, candidates []string) string {
+	id = strings.ToLower(id)
+	best, bestDist := "", max(1, len(id)/3)+1
+	for _, c := range candidates {
+		if d := editDistance(id, strings.ToLower(c)); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
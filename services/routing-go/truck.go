@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+
+	"navifly/routing/internal/routing"
+)
+
+// ── Truck Restrictions ──
+
+// defaultTruck is a US legal tractor-trailer: 13'6" high, 80,000 lb and
+// about 22 m long.
+var defaultTruck = routing.Dimensions{HeightM: 4.1, WeightT: 36.3, LengthM: 22}
+
+// parseTruckOptions reads the vehicle dimensions. Trucks default to
+// defaultTruck; other vehicles are not restricted.
+func parseTruckOptions(q url.Values, vehicle string) (*routing.Dimensions, error) {
+	if vehicle != "truck" {
+		for _, p := range []string{"height_m", "weight_t", "length_m", "hazmat"} {
+			if q.Get(p) != "" {
+				return nil, fmt.Errorf("%s needs vehicle=truck", p)
+			}
+		}
+		return nil, nil
+	}
+
+	dims := defaultTruck
+	for _, p := range []struct {
+		name string
+		into *float64
+	}{
+		{"height_m", &dims.HeightM},
+		{"weight_t", &dims.WeightT},
+		{"length_m", &dims.LengthM},
+	} {
+		raw := q.Get(p.name)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v <= 0 || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("%s must be a positive number", p.name)
+		}
+		*p.into = v
+	}
+	if raw := q.Get("hazmat"); raw != "" {
+		h, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("hazmat must be true or false")
+		}
+		dims.Hazmat = h
+	}
+	return &dims, nil
+}
@@ -0,0 +1,515 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"navifly/platform/auth"
+	"navifly/platform/logging"
+	"navifly/platform/problem"
+	"navifly/routing/internal/store"
+)
+
+// ── User Profiles ──
+
+const (
+	// placePrefix marks a waypoint as one of the user's saved places, e.g.
+	// start=place:home.
+	placePrefix = "place:"
+	// keepRecentTrips is how many recent trips each user keeps.
+	keepRecentTrips = 20
+)
+
+// profileIDPattern constrains user and place IDs so they stay readable in
+// URLs and waypoint references.
+var profileIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// tripParams are set from a trip's waypoints and may not appear in options.
+var tripParams = map[string]bool{"start": true, "stops": true, "end": true}
+
+// UserView is a user with everything saved for them.
+type UserView struct {
+	ID        string      `json:"id"`
+	Name      string      `json:"name,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	Places    []PlaceView `json:"places"`
+	Favorites []TripView  `json:"favorites"`
+	Recent    []TripView  `json:"recent"`
+}
+
+// PlaceView is a saved place; route to it with place:<id>.
+type PlaceView struct {
+	ID    string  `json:"id"`
+	Label string  `json:"label"`
+	Lat   float64 `json:"lat"`
+	Lon   float64 `json:"lon"`
+}
+
+// TripView is a favorite or recent trip. Waypoints are catalog IDs or
+// place:<id>; Options are any other /route parameters.
+type TripView struct {
+	ID        uint              `json:"id,omitempty"`
+	Label     string            `json:"label,omitempty"`
+	Start     string            `json:"start"`
+	Stops     []string          `json:"stops,omitempty"`
+	End       string            `json:"end"`
+	Options   map[string]string `json:"options,omitempty"`
+	CreatedAt time.Time         `json:"created_at,omitempty"`
+}
+
+func placeView(p store.Place) PlaceView {
+	return PlaceView{ID: p.ID, Label: p.Label, Lat: p.Lat, Lon: p.Lon}
+}
+
+func tripView(t store.Trip) TripView {
+	v := TripView{ID: t.ID, Label: t.Label, Start: t.Start, Stops: splitStops(t.Stops), End: t.End, CreatedAt: t.CreatedAt.UTC()}
+	if q, err := url.ParseQuery(t.Options); err == nil && len(q) > 0 {
+		v.Options = make(map[string]string, len(q))
+		for k := range q {
+			v.Options[k] = q.Get(k)
+		}
+	}
+	return v
+}
+
+// record converts a trip view for storage.
+func (v TripView) record(userID, kind string) store.Trip {
+	q := url.Values{}
+	for k, val := range v.Options {
+		q.Set(k, val)
+	}
+	return store.Trip{UserID: userID, Kind: kind, Label: v.Label,
+		Start: v.Start, Stops: strings.Join(v.Stops, ","), End: v.End, Options: q.Encode()}
+}
+
+// query builds /route parameters for the trip with waypoints replaced.
+func (v TripView) query(start string, stops []string, end string) url.Values {
+	q := url.Values{}
+	for k, val := range v.Options {
+		q.Set(k, val)
+	}
+	q.Set("start", start)
+	q.Set("stops", strings.Join(stops, ","))
+	q.Set("end", end)
+	return q
+}
+
+// resolvedTrip is a trip with each waypoint looked up. standIns are the
+// nearest catalog IDs, used for corridor planning and scheduling.
+type resolvedTrip struct {
+	points   []Location
+	standIns []string
+	// places is true when any waypoint is a saved place.
+	places bool
+	// skipped are the unknown stops on_unknown=skip left out.
+	skipped []string
+}
+
+// resolveTrip checks the trip's waypoints as /route does and looks them up
+// among the catalog and the user's places.
+func (s *Server) resolveTrip(ctx context.Context, userID string, v TripView) (*resolvedTrip, error) {
+	if v.Start == "" || v.End == "" {
+		return nil, problem.New(problem.InvalidRequest, "start and end are required")
+	}
+	for k := range v.Options {
+		if tripParams[k] {
+			return nil, problem.New(problem.InvalidRequest, "options may not set "+k)
+		}
+	}
+	places, err := s.profiles.Places(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byRef := map[string]store.Place{}
+	check := waypointCheck{candidates: locationIDs()}
+	for _, p := range places {
+		byRef[placePrefix+p.ID] = p
+		check.candidates = append(check.candidates, placePrefix+p.ID)
+	}
+	check.known = func(ref string) bool {
+		_, place := byRef[ref]
+		_, loc := findLocation(ref)
+		return place || loc
+	}
+	stops, skipped, err := check.check(v.Start, v.Stops, v.End, v.Options["on_unknown"])
+	if err != nil {
+		return nil, err
+	}
+
+	rt := &resolvedTrip{skipped: skipped}
+	for _, ref := range append(append([]string{v.Start}, stops...), v.End) {
+		if p, ok := byRef[ref]; ok {
+			loc := Location{ID: ref, Name: p.Label, Lat: p.Lat, Lon: p.Lon}
+			rt.points = append(rt.points, loc)
+			rt.standIns = append(rt.standIns, nearestLocation(loc.Lat, loc.Lon).ID)
+			rt.places = true
+			continue
+		}
+		loc, _ := findLocation(ref)
+		rt.points = append(rt.points, loc)
+		rt.standIns = append(rt.standIns, loc.ID)
+	}
+	return rt, nil
+}
+
+// routeTrip runs a trip through the /route pipeline. Trips using saved
+// places are planned on the nearest catalog locations but routed through
+// the places' own coordinates. Routing errors are *problem.Error.
+func (s *Server) routeTrip(ctx context.Context, userID string, v TripView) (*EnhancedResponse, error) {
+	rt, err := s.resolveTrip(ctx, userID, v)
+	if err != nil {
+		return nil, err
+	}
+	n := len(rt.standIns)
+	q := v.query(rt.standIns[0], rt.standIns[1:n-1], rt.standIns[n-1])
+
+	opts, err := parseTripOptions(q, rt.standIns)
+	if err != nil {
+		return nil, problem.As(err, problem.InvalidRequest)
+	}
+	opts.skippedStops = rt.skipped
+	if err := s.resolveAvoid(ctx, &opts); err != nil {
+		return nil, err
+	}
+	stopsParam, err := s.planCorridor(&opts)
+	if err != nil {
+		return nil, problem.As(err, problem.NoRoute)
+	}
+
+	var resp *EnhancedResponse
+	if !rt.places {
+		resp, err = s.loadRoute(ctx, q.Get("start"), q.Get("end"), stopsParam, opts.stopNames())
+	} else {
+		points, stops := rt.withVias(splitStops(stopsParam))
+		resp, err = fetchPointRoute(ctx, points, stops, nil, "Fastest")
+	}
+	if err != nil {
+		return nil, problem.As(err, problem.UpstreamUnavailable)
+	}
+	if err := s.finishRoute(resp, opts); err != nil {
+		return nil, problem.As(err, problem.NoRoute)
+	}
+	return resp, nil
+}
+
+// withVias merges planned stops (the stand-in stops plus any corridor via
+// points, in order) back with the trip's own points. It also names each
+// planned stop for stepInstructions, with "" for a via point.
+func (rt *resolvedTrip) withVias(planned []string) (points []Location, stops []string) {
+	points = []Location{rt.points[0]}
+	next := 1 // next trip stop to match
+	for _, id := range planned {
+		if next < len(rt.points)-1 && id == rt.standIns[next] {
+			points = append(points, rt.points[next])
+			stops = append(stops, rt.points[next].Name)
+			next++
+			continue
+		}
+		loc, _ := findLocation(id)
+		points = append(points, loc)
+		stops = append(stops, "")
+	}
+	return append(points, rt.points[len(rt.points)-1]), stops
+}
+
+// ── Handlers ──
+
+// HandlePutUser creates a user or renames one.
+func (s *Server) HandlePutUser(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if !profileIDPattern.MatchString(id) {
+		problem.Write(w, r, problem.InvalidRequest, "user IDs are 1-64 letters, digits, '-' or '_'")
+		return
+	}
+	var body struct {
+		Name string `json:"name"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			problem.Write(w, r, problem.InvalidRequest, "invalid JSON body: "+err.Error())
+			return
+		}
+	}
+
+	status := http.StatusOK
+	if _, err := s.profiles.GetUser(r.Context(), id); errors.Is(err, store.ErrNoUser) {
+		status = http.StatusCreated
+	}
+	u := &store.User{ID: id, Name: body.Name, CreatedAt: time.Now().UTC()}
+	if err := s.profiles.PutUser(r.Context(), u); err != nil {
+		writeProfileError(w, r, err)
+		return
+	}
+	writeJSON(w, status, UserView{ID: u.ID, Name: u.Name, CreatedAt: u.CreatedAt.UTC(),
+		Places: []PlaceView{}, Favorites: []TripView{}, Recent: []TripView{}})
+}
+
+// HandleGetUser returns a user with their places, favorites and recent trips.
+func (s *Server) HandleGetUser(w http.ResponseWriter, r *http.Request) {
+	ctx, id := r.Context(), mux.Vars(r)["id"]
+	u, err := s.profiles.GetUser(ctx, id)
+	if err != nil {
+		writeProfileError(w, r, err)
+		return
+	}
+	view := UserView{ID: u.ID, Name: u.Name, CreatedAt: u.CreatedAt.UTC()}
+	if view.Places, err = s.placeViews(ctx, id); err != nil {
+		writeProfileError(w, r, err)
+		return
+	}
+	if view.Favorites, err = s.tripViews(ctx, id, store.TripFavorite); err != nil {
+		writeProfileError(w, r, err)
+		return
+	}
+	if view.Recent, err = s.tripViews(ctx, id, store.TripRecent); err != nil {
+		writeProfileError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+// HandleDeleteUser removes a user and everything saved for them.
+func (s *Server) HandleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	if err := s.profiles.DeleteUser(r.Context(), mux.Vars(r)["id"]); err != nil {
+		writeProfileError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleListPlaces lists a user's saved places.
+func (s *Server) HandleListPlaces(w http.ResponseWriter, r *http.Request) {
+	places, err := s.placeViews(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeProfileError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, places)
+}
+
+// HandlePutPlace saves a labelled position under a user-chosen ID.
+func (s *Server) HandlePutPlace(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if !profileIDPattern.MatchString(vars["place"]) {
+		problem.Write(w, r, problem.InvalidRequest, "place IDs are 1-64 letters, digits, '-' or '_'")
+		return
+	}
+	var body PlaceView
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		problem.Write(w, r, problem.InvalidRequest, "invalid JSON body: "+err.Error())
+		return
+	}
+	if body.Lat < -90 || body.Lat > 90 || body.Lon < -180 || body.Lon > 180 {
+		problem.Write(w, r, problem.InvalidCoordinate, "lat/lon out of range")
+		return
+	}
+	if body.Label == "" {
+		body.Label = vars["place"]
+	}
+
+	p := &store.Place{UserID: vars["id"], ID: vars["place"], Label: body.Label, Lat: body.Lat, Lon: body.Lon, CreatedAt: time.Now().UTC()}
+	if err := s.profiles.PutPlace(r.Context(), p); err != nil {
+		writeProfileError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, placeView(*p))
+}
+
+// HandleDeletePlace removes a saved place. Trips using it fail to replay
+// until it is saved again.
+func (s *Server) HandleDeletePlace(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := s.profiles.DeletePlace(r.Context(), vars["id"], vars["place"]); err != nil {
+		if errors.Is(err, store.ErrNoPlace) {
+			problem.Write(w, r, problem.NotFound, "Place not found")
+			return
+		}
+		writeProfileError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleListTrips lists favorites or recent trips, newest first.
+func (s *Server) HandleListTrips(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		trips, err := s.tripViews(r.Context(), mux.Vars(r)["id"], kind)
+		if err != nil {
+			writeProfileError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, trips)
+	}
+}
+
+// HandleAddFavorite saves a start/stops/end combination with options.
+func (s *Server) HandleAddFavorite(w http.ResponseWriter, r *http.Request) {
+	ctx, userID := r.Context(), mux.Vars(r)["id"]
+	var body TripView
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		problem.Write(w, r, problem.InvalidRequest, "invalid JSON body: "+err.Error())
+		return
+	}
+	rt, err := s.resolveTrip(ctx, userID, body)
+	if err != nil {
+		writeProfileError(w, r, err)
+		return
+	}
+	n := len(rt.standIns)
+	if _, err := parseTripOptions(body.query(rt.standIns[0], rt.standIns[1:n-1], rt.standIns[n-1]), rt.standIns); err != nil {
+		problem.WriteError(w, r, err, problem.InvalidRequest)
+		return
+	}
+
+	t := body.record(userID, store.TripFavorite)
+	t.CreatedAt = time.Now().UTC()
+	if err := s.profiles.AddTrip(ctx, &t); err != nil {
+		writeProfileError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, tripView(t))
+}
+
+// HandleDeleteTrip removes a favorite or recent trip.
+func (s *Server) HandleDeleteTrip(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["id"]
+	t, err := s.lookupTrip(r)
+	if err == nil {
+		err = s.profiles.DeleteTrip(r.Context(), userID, t.ID)
+	}
+	if err != nil {
+		writeProfileError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleReplayTrip recomputes a favorite or recent trip.
+func (s *Server) HandleReplayTrip(w http.ResponseWriter, r *http.Request) {
+	t, err := s.lookupTrip(r)
+	if err != nil {
+		writeProfileError(w, r, err)
+		return
+	}
+	s.writeUserRoute(w, r, tripView(*t))
+}
+
+// HandleUserRoute is /route for a user: waypoints may be saved places, and
+// the trip is added to their recent trips.
+func (s *Server) HandleUserRoute(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	v := TripView{Start: q.Get("start"), Stops: splitStops(q.Get("stops")), End: q.Get("end"), Options: map[string]string{}}
+	for k := range q {
+		if !tripParams[k] {
+			v.Options[k] = q.Get(k)
+		}
+	}
+	s.writeUserRoute(w, r, v)
+}
+
+func (s *Server) writeUserRoute(w http.ResponseWriter, r *http.Request, v TripView) {
+	ctx, userID := r.Context(), mux.Vars(r)["id"]
+	resp, err := s.routeTrip(ctx, userID, v)
+	if err != nil {
+		writeProfileError(w, r, err)
+		return
+	}
+
+	recent := v.record(userID, store.TripRecent)
+	recent.CreatedAt = time.Now().UTC()
+	if err := s.profiles.RecordRecent(ctx, &recent, keepRecentTrips); err != nil {
+		slog.WarnContext(ctx, "failed to record recent trip", "user_id", userID, logging.Err(err))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// lookupTrip finds the {trip} of user {id} in the kind named by the path.
+func (s *Server) lookupTrip(r *http.Request) (*store.Trip, error) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["trip"], 10, 64)
+	if err != nil {
+		return nil, store.ErrNoTrip
+	}
+	t, err := s.profiles.GetTrip(r.Context(), vars["id"], uint(id))
+	if err != nil {
+		return nil, err
+	}
+	kind := store.TripRecent
+	if strings.Contains(r.URL.Path, "/favorites/") {
+		kind = store.TripFavorite
+	}
+	if t.Kind != kind {
+		return nil, store.ErrNoTrip
+	}
+	return t, nil
+}
+
+func (s *Server) placeViews(ctx context.Context, userID string) ([]PlaceView, error) {
+	places, err := s.profiles.Places(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]PlaceView, len(places))
+	for i, p := range places {
+		views[i] = placeView(p)
+	}
+	return views, nil
+}
+
+func (s *Server) tripViews(ctx context.Context, userID, kind string) ([]TripView, error) {
+	trips, err := s.profiles.Trips(ctx, userID, kind)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]TripView, len(trips))
+	for i, t := range trips {
+		views[i] = tripView(t)
+	}
+	return views, nil
+}
+
+// userOnly lets a caller reach /users/{id}/... only as that user (the token
+// subject or API key ID) or with users:admin.
+func (s *Server) userOnly(h http.HandlerFunc) http.Handler {
+	return s.auth.Require("", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := auth.FromContext(r.Context())
+		if p.Subject != mux.Vars(r)["id"] && !p.Has(auth.ScopeUsersAdmin) {
+			problem.Write(w, r, problem.Forbidden, "Profile belongs to another user")
+			return
+		}
+		s.limiter.Limit(apiSpec.Middleware(h)).ServeHTTP(w, r)
+	}))
+}
+
+// writeProfileError answers with err's problem, or a store error's.
+func writeProfileError(w http.ResponseWriter, r *http.Request, err error) {
+	var p *problem.Error
+	switch {
+	case errors.As(err, &p):
+		problem.WriteError(w, r, p, problem.Internal)
+	case errors.Is(err, store.ErrNoUser):
+		problem.Write(w, r, problem.NotFound, "User not found")
+	case errors.Is(err, store.ErrNoTrip):
+		problem.Write(w, r, problem.NotFound, "Trip not found")
+	default:
+		slog.ErrorContext(r.Context(), "profile store error", logging.Err(err))
+		problem.Write(w, r, problem.Internal, "Profile store error")
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,314 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: api/telemetry/v1/telemetry.proto
+
+package telemetryv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TelemetryPing struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	VehicleId string                 `protobuf:"bytes,1,opt,name=vehicle_id,json=vehicleId,proto3" json:"vehicle_id,omitempty"`
+	Lat       float64                `protobuf:"fixed64,2,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lon       float64                `protobuf:"fixed64,3,opt,name=lon,proto3" json:"lon,omitempty"`
+	// speed is in km/h; heading in degrees clockwise from north.
+	Speed   float64 `protobuf:"fixed64,4,opt,name=speed,proto3" json:"speed,omitempty"`
+	Heading float64 `protobuf:"fixed64,5,opt,name=heading,proto3" json:"heading,omitempty"`
+	// timestamp is unix seconds; zero means the time the ping was received.
+	Timestamp     int64 `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TelemetryPing) Reset() {
+	*x = TelemetryPing{}
+	mi := &file_api_telemetry_v1_telemetry_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TelemetryPing) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TelemetryPing) ProtoMessage() {}
+
+func (x *TelemetryPing) ProtoReflect() protoreflect.Message {
+	mi := &file_api_telemetry_v1_telemetry_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TelemetryPing.ProtoReflect.Descriptor instead.
+func (*TelemetryPing) Descriptor() ([]byte, []int) {
+	return file_api_telemetry_v1_telemetry_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TelemetryPing) GetVehicleId() string {
+	if x != nil {
+		return x.VehicleId
+	}
+	return ""
+}
+
+func (x *TelemetryPing) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *TelemetryPing) GetLon() float64 {
+	if x != nil {
+		return x.Lon
+	}
+	return 0
+}
+
+func (x *TelemetryPing) GetSpeed() float64 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
+}
+
+func (x *TelemetryPing) GetHeading() float64 {
+	if x != nil {
+		return x.Heading
+	}
+	return 0
+}
+
+func (x *TelemetryPing) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+type IngestSummary struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Accepted int64                  `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	Rejected int64                  `protobuf:"varint,2,opt,name=rejected,proto3" json:"rejected,omitempty"`
+	// errors describe up to the first ten rejected pings.
+	Errors        []string `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IngestSummary) Reset() {
+	*x = IngestSummary{}
+	mi := &file_api_telemetry_v1_telemetry_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IngestSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestSummary) ProtoMessage() {}
+
+func (x *IngestSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_api_telemetry_v1_telemetry_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestSummary.ProtoReflect.Descriptor instead.
+func (*IngestSummary) Descriptor() ([]byte, []int) {
+	return file_api_telemetry_v1_telemetry_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *IngestSummary) GetAccepted() int64 {
+	if x != nil {
+		return x.Accepted
+	}
+	return 0
+}
+
+func (x *IngestSummary) GetRejected() int64 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+func (x *IngestSummary) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type StreamVehiclesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// vehicle_ids limits the stream to these vehicles; empty means all.
+	VehicleIds    []string `protobuf:"bytes,1,rep,name=vehicle_ids,json=vehicleIds,proto3" json:"vehicle_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamVehiclesRequest) Reset() {
+	*x = StreamVehiclesRequest{}
+	mi := &file_api_telemetry_v1_telemetry_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamVehiclesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamVehiclesRequest) ProtoMessage() {}
+
+func (x *StreamVehiclesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_telemetry_v1_telemetry_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamVehiclesRequest.ProtoReflect.Descriptor instead.
+func (*StreamVehiclesRequest) Descriptor() ([]byte, []int) {
+	return file_api_telemetry_v1_telemetry_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StreamVehiclesRequest) GetVehicleIds() []string {
+	if x != nil {
+		return x.VehicleIds
+	}
+	return nil
+}
+
+var File_api_telemetry_v1_telemetry_proto protoreflect.FileDescriptor
+
+var file_api_telemetry_v1_telemetry_proto_rawDesc = string([]byte{
+	0x0a, 0x20, 0x61, 0x70, 0x69, 0x2f, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2f,
+	0x76, 0x31, 0x2f, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x14, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x74, 0x65, 0x6c, 0x65,
+	0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x76, 0x31, 0x22, 0xa0, 0x01, 0x0a, 0x0d, 0x54, 0x65, 0x6c,
+	0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x50, 0x69, 0x6e, 0x67, 0x12, 0x1d, 0x0a, 0x0a, 0x76, 0x65,
+	0x68, 0x69, 0x63, 0x6c, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x49, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x61, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x61, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6c,
+	0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x6f, 0x6e, 0x12, 0x14, 0x0a,
+	0x05, 0x73, 0x70, 0x65, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x70,
+	0x65, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x68, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x68, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x1c, 0x0a,
+	0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0x5f, 0x0a, 0x0d, 0x49,
+	0x6e, 0x67, 0x65, 0x73, 0x74, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x1a, 0x0a, 0x08,
+	0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08,
+	0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x6a, 0x65,
+	0x63, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x6a, 0x65,
+	0x63, 0x74, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x22, 0x38, 0x0a, 0x15,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x56, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65,
+	0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x76, 0x65, 0x68, 0x69,
+	0x63, 0x6c, 0x65, 0x49, 0x64, 0x73, 0x32, 0xd7, 0x01, 0x0a, 0x10, 0x54, 0x65, 0x6c, 0x65, 0x6d,
+	0x65, 0x74, 0x72, 0x79, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x5d, 0x0a, 0x0f, 0x49,
+	0x6e, 0x67, 0x65, 0x73, 0x74, 0x54, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x12, 0x23,
+	0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74,
+	0x72, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x50,
+	0x69, 0x6e, 0x67, 0x1a, 0x23, 0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x74, 0x65,
+	0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x67, 0x65, 0x73,
+	0x74, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x28, 0x01, 0x12, 0x64, 0x0a, 0x0e, 0x53, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x56, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x73, 0x12, 0x2b, 0x2e, 0x6e,
+	0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x56, 0x65, 0x68, 0x69, 0x63, 0x6c,
+	0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e, 0x6e, 0x61, 0x76, 0x69,
+	0x66, 0x6c, 0x79, 0x2e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x76, 0x31,
+	0x2e, 0x54, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x50, 0x69, 0x6e, 0x67, 0x30, 0x01,
+	0x42, 0x30, 0x5a, 0x2e, 0x6e, 0x61, 0x76, 0x69, 0x66, 0x6c, 0x79, 0x2f, 0x74, 0x65, 0x6c, 0x65,
+	0x6d, 0x65, 0x74, 0x72, 0x79, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65,
+	0x74, 0x72, 0x79, 0x2f, 0x76, 0x31, 0x3b, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79,
+	0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_api_telemetry_v1_telemetry_proto_rawDescOnce sync.Once
+	file_api_telemetry_v1_telemetry_proto_rawDescData []byte
+)
+
+func file_api_telemetry_v1_telemetry_proto_rawDescGZIP() []byte {
+	file_api_telemetry_v1_telemetry_proto_rawDescOnce.Do(func() {
+		file_api_telemetry_v1_telemetry_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_telemetry_v1_telemetry_proto_rawDesc), len(file_api_telemetry_v1_telemetry_proto_rawDesc)))
+	})
+	return file_api_telemetry_v1_telemetry_proto_rawDescData
+}
+
+var file_api_telemetry_v1_telemetry_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_api_telemetry_v1_telemetry_proto_goTypes = []any{
+	(*TelemetryPing)(nil),         // 0: navifly.telemetry.v1.TelemetryPing
+	(*IngestSummary)(nil),         // 1: navifly.telemetry.v1.IngestSummary
+	(*StreamVehiclesRequest)(nil), // 2: navifly.telemetry.v1.StreamVehiclesRequest
+}
+var file_api_telemetry_v1_telemetry_proto_depIdxs = []int32{
+	0, // 0: navifly.telemetry.v1.TelemetryService.IngestTelemetry:input_type -> navifly.telemetry.v1.TelemetryPing
+	2, // 1: navifly.telemetry.v1.TelemetryService.StreamVehicles:input_type -> navifly.telemetry.v1.StreamVehiclesRequest
+	1, // 2: navifly.telemetry.v1.TelemetryService.IngestTelemetry:output_type -> navifly.telemetry.v1.IngestSummary
+	0, // 3: navifly.telemetry.v1.TelemetryService.StreamVehicles:output_type -> navifly.telemetry.v1.TelemetryPing
+	2, // [2:4] is the sub-list for method output_type
+	0, // [0:2] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_api_telemetry_v1_telemetry_proto_init() }
+func file_api_telemetry_v1_telemetry_proto_init() {
+	if File_api_telemetry_v1_telemetry_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_telemetry_v1_telemetry_proto_rawDesc), len(file_api_telemetry_v1_telemetry_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_telemetry_v1_telemetry_proto_goTypes,
+		DependencyIndexes: file_api_telemetry_v1_telemetry_proto_depIdxs,
+		MessageInfos:      file_api_telemetry_v1_telemetry_proto_msgTypes,
+	}.Build()
+	File_api_telemetry_v1_telemetry_proto = out.File
+	file_api_telemetry_v1_telemetry_proto_goTypes = nil
+	file_api_telemetry_v1_telemetry_proto_depIdxs = nil
+}
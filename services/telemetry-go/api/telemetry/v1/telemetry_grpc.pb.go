@@ -0,0 +1,167 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: api/telemetry/v1/telemetry.proto
+
+package telemetryv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TelemetryService_IngestTelemetry_FullMethodName = "/navifly.telemetry.v1.TelemetryService/IngestTelemetry"
+	TelemetryService_StreamVehicles_FullMethodName  = "/navifly.telemetry.v1.TelemetryService/StreamVehicles"
+)
+
+// TelemetryServiceClient is the client API for TelemetryService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TelemetryService is the gRPC twin of POST /ingest and GET /vehicles.
+type TelemetryServiceClient interface {
+	// IngestTelemetry accepts a stream of pings and replies once the client
+	// closes the stream.
+	IngestTelemetry(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[TelemetryPing, IngestSummary], error)
+	// StreamVehicles sends the latest ping of every vehicle, then each new ping
+	// as it is ingested.
+	StreamVehicles(ctx context.Context, in *StreamVehiclesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TelemetryPing], error)
+}
+
+type telemetryServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTelemetryServiceClient(cc grpc.ClientConnInterface) TelemetryServiceClient {
+	return &telemetryServiceClient{cc}
+}
+
+func (c *telemetryServiceClient) IngestTelemetry(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[TelemetryPing, IngestSummary], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TelemetryService_ServiceDesc.Streams[0], TelemetryService_IngestTelemetry_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TelemetryPing, IngestSummary]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TelemetryService_IngestTelemetryClient = grpc.ClientStreamingClient[TelemetryPing, IngestSummary]
+
+func (c *telemetryServiceClient) StreamVehicles(ctx context.Context, in *StreamVehiclesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TelemetryPing], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TelemetryService_ServiceDesc.Streams[1], TelemetryService_StreamVehicles_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamVehiclesRequest, TelemetryPing]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TelemetryService_StreamVehiclesClient = grpc.ServerStreamingClient[TelemetryPing]
+
+// TelemetryServiceServer is the server API for TelemetryService service.
+// All implementations must embed UnimplementedTelemetryServiceServer
+// for forward compatibility.
+//
+// TelemetryService is the gRPC twin of POST /ingest and GET /vehicles.
+type TelemetryServiceServer interface {
+	// IngestTelemetry accepts a stream of pings and replies once the client
+	// closes the stream.
+	IngestTelemetry(grpc.ClientStreamingServer[TelemetryPing, IngestSummary]) error
+	// StreamVehicles sends the latest ping of every vehicle, then each new ping
+	// as it is ingested.
+	StreamVehicles(*StreamVehiclesRequest, grpc.ServerStreamingServer[TelemetryPing]) error
+	mustEmbedUnimplementedTelemetryServiceServer()
+}
+
+// UnimplementedTelemetryServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTelemetryServiceServer struct{}
+
+func (UnimplementedTelemetryServiceServer) IngestTelemetry(grpc.ClientStreamingServer[TelemetryPing, IngestSummary]) error {
+	return status.Errorf(codes.Unimplemented, "method IngestTelemetry not implemented")
+}
+func (UnimplementedTelemetryServiceServer) StreamVehicles(*StreamVehiclesRequest, grpc.ServerStreamingServer[TelemetryPing]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamVehicles not implemented")
+}
+func (UnimplementedTelemetryServiceServer) mustEmbedUnimplementedTelemetryServiceServer() {}
+func (UnimplementedTelemetryServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeTelemetryServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TelemetryServiceServer will
+// result in compilation errors.
+type UnsafeTelemetryServiceServer interface {
+	mustEmbedUnimplementedTelemetryServiceServer()
+}
+
+func RegisterTelemetryServiceServer(s grpc.ServiceRegistrar, srv TelemetryServiceServer) {
+	// If the following call pancis, it indicates UnimplementedTelemetryServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TelemetryService_ServiceDesc, srv)
+}
+
+func _TelemetryService_IngestTelemetry_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TelemetryServiceServer).IngestTelemetry(&grpc.GenericServerStream[TelemetryPing, IngestSummary]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TelemetryService_IngestTelemetryServer = grpc.ClientStreamingServer[TelemetryPing, IngestSummary]
+
+func _TelemetryService_StreamVehicles_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamVehiclesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TelemetryServiceServer).StreamVehicles(m, &grpc.GenericServerStream[StreamVehiclesRequest, TelemetryPing]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TelemetryService_StreamVehiclesServer = grpc.ServerStreamingServer[TelemetryPing]
+
+// TelemetryService_ServiceDesc is the grpc.ServiceDesc for TelemetryService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TelemetryService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "navifly.telemetry.v1.TelemetryService",
+	HandlerType: (*TelemetryServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "IngestTelemetry",
+			Handler:       _TelemetryService_IngestTelemetry_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "StreamVehicles",
+			Handler:       _TelemetryService_StreamVehicles_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/telemetry/v1/telemetry.proto",
+}
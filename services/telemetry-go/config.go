@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+
+	"navifly/platform/config"
+)
+
+// Config is the telemetry service's configuration. config.Load reads it
+// from the YAML file named by CONFIG_FILE and then the environment
+// variables named in the tags.
+type Config struct {
+	config.Server `yaml:",inline"`
+
+	// RedisURL is the host:port of the Redis holding vehicle state.
+	RedisURL string `yaml:"redis_url" env:"REDIS_URL"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		Server:   config.DefaultServer(8081, 9091),
+		RedisURL: "localhost:6379",
+	}
+}
+
+func (c Config) Validate() error {
+	if c.RedisURL == "" {
+		return errors.Join(c.Server.Validate(), errors.New("redis_url is required"))
+	}
+	return c.Server.Validate()
+}
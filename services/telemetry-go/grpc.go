@@ -0,0 +1,142 @@
+package main
+
+//go:generate protoc -I . --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative api/telemetry/v1/telemetry.proto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	"navifly/platform/auth"
+	"navifly/platform/logging"
+	"navifly/platform/problem"
+	"navifly/platform/tracing"
+	telemetryv1 "navifly/telemetry/api/telemetry/v1"
+)
+
+// maxIngestErrors caps how many rejection reasons an IngestSummary carries.
+const maxIngestErrors = 10
+
+// telemetryGRPC serves TelemetryService from the same Redis logic as the
+// REST handlers.
+type telemetryGRPC struct {
+	telemetryv1.UnimplementedTelemetryServiceServer
+}
+
+func newGRPCServer() *grpc.Server {
+	opts := logging.ServerOptions()
+	opts = append(opts, authn.ServerOptions(auth.MethodScopes{
+		telemetryv1.TelemetryService_IngestTelemetry_FullMethodName: auth.ScopeTelemetryWrite,
+		telemetryv1.TelemetryService_StreamVehicles_FullMethodName:  auth.ScopeTelemetryRead,
+	})...)
+	opts = append(opts, limiter.ServerOptions()...)
+	gs := grpc.NewServer(append(opts, tracing.ServerOptions()...)...)
+	telemetryv1.RegisterTelemetryServiceServer(gs, telemetryGRPC{})
+	reflection.Register(gs)
+	return gs
+}
+
+func (telemetryGRPC) IngestTelemetry(stream telemetryv1.TelemetryService_IngestTelemetryServer) error {
+	summary := &telemetryv1.IngestSummary{}
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(summary)
+		}
+		if err != nil {
+			return err
+		}
+
+		ping := pingFromProto(msg)
+		if p, ok := auth.FromContext(stream.Context()); ok && !p.MayReport(ping.VehicleID) {
+			err = fmt.Errorf("key %s may not report vehicle %s", p.Subject, ping.VehicleID)
+		} else {
+			err = storePing(stream.Context(), ping)
+		}
+		if err != nil {
+			summary.Rejected++
+			if len(summary.Errors) < maxIngestErrors {
+				summary.Errors = append(summary.Errors, err.Error())
+			}
+			continue
+		}
+		summary.Accepted++
+	}
+}
+
+func (telemetryGRPC) StreamVehicles(req *telemetryv1.StreamVehiclesRequest, stream telemetryv1.TelemetryService_StreamVehiclesServer) error {
+	c := stream.Context()
+	want := make(map[string]bool, len(req.GetVehicleIds()))
+	for _, id := range req.GetVehicleIds() {
+		want[id] = true
+	}
+
+	// Subscribe before the snapshot so no ping falls between the two
+	sub := rdb.Subscribe(c, vehicleChannel)
+	defer sub.Close()
+	if _, err := sub.Receive(c); err != nil {
+		return problem.Errorf(problem.Unavailable, "%w", err)
+	}
+
+	send := func(data []byte) error {
+		var ping TelemetryPing
+		if err := json.Unmarshal(data, &ping); err != nil {
+			return nil
+		}
+		if len(want) > 0 && !want[ping.VehicleID] {
+			return nil
+		}
+		return stream.Send(pingToProto(ping))
+	}
+
+	snapshot, err := latestVehicles(c)
+	if err != nil {
+		return problem.Errorf(problem.Unavailable, "%w", err)
+	}
+	for _, data := range snapshot {
+		if err := send(data); err != nil {
+			return err
+		}
+	}
+
+	updates := sub.Channel()
+	for {
+		select {
+		case <-c.Done():
+			return nil
+		case msg, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			if err := send([]byte(msg.Payload)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func pingFromProto(p *telemetryv1.TelemetryPing) TelemetryPing {
+	return TelemetryPing{
+		VehicleID: p.GetVehicleId(),
+		Lat:       p.GetLat(),
+		Lon:       p.GetLon(),
+		Speed:     p.GetSpeed(),
+		Heading:   p.GetHeading(),
+		Timestamp: p.GetTimestamp(),
+	}
+}
+
+func pingToProto(p TelemetryPing) *telemetryv1.TelemetryPing {
+	return &telemetryv1.TelemetryPing{
+		VehicleId: p.VehicleID,
+		Lat:       p.Lat,
+		Lon:       p.Lon,
+		Speed:     p.Speed,
+		Heading:   p.Heading,
+		Timestamp: p.Timestamp,
+	}
+}
@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	_ "embed"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"sync"
 	"time"
 
 	"io"
@@ -15,13 +17,44 @@ import (
 	"github.com/go-redis/redis/v8"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+
+	"navifly/platform/auth"
+	"navifly/platform/config"
+	"navifly/platform/logging"
+	"navifly/platform/metrics"
+	"navifly/platform/openapi"
+	"navifly/platform/problem"
+	"navifly/platform/ratelimit"
+	"navifly/platform/server"
+	"navifly/platform/tracing"
 )
 
 var (
 	ctx = context.Background()
 	rdb *redis.Client
+	// authn checks credentials; main replaces it when keys are configured.
+	authn = auth.Disabled()
+	// limiter throttles each API key or IP; main configures it.
+	limiter = ratelimit.New(ratelimit.Config{}, nil)
+	// upstreamLimiter throttles the Windy and OpenSky proxies instead of
+	// limiter, so clients cannot get us banned by either; main configures it.
+	upstreamLimiter = ratelimit.New(ratelimit.Config{}, nil)
+	// upstream calls Windy and OpenSky with trace context.
+	upstream = &http.Client{Timeout: 10 * time.Second, Transport: tracing.Transport(nil)}
 )
 
+// protect requires scope, charges the caller's rate limit and then
+// validates the request against the API spec.
+func protect(scope string, h http.HandlerFunc) http.Handler {
+	return authn.Require(scope, limiter.Limit(apiSpec.Middleware(h)))
+}
+
+// protectUpstream is protect for handlers that call a third-party API,
+// charging upstreamLimiter instead.
+func protectUpstream(scope string, h http.HandlerFunc) http.Handler {
+	return authn.Require(scope, upstreamLimiter.Limit(apiSpec.Middleware(h)))
+}
+
 type TelemetryPing struct {
 	VehicleID string  `json:"vehicle_id"`
 	Lat       float64 `json:"lat"`
@@ -31,26 +64,66 @@ type TelemetryPing struct {
 	Timestamp int64   `json:"timestamp"`
 }
 
-func IngestTelemetry(w http.ResponseWriter, r *http.Request) {
-	var ping TelemetryPing
-	if err := json.NewDecoder(r.Body).Decode(&ping); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+// vehicleChannel carries every stored ping to StreamVehicles subscribers.
+const vehicleChannel = "vehicles:updates"
+
+func validatePing(ping TelemetryPing) error {
+	if ping.VehicleID == "" {
+		return problem.New(problem.InvalidRequest, "vehicle_id is required")
+	}
+	if ping.Lat < -90 || ping.Lat > 90 || ping.Lon < -180 || ping.Lon > 180 {
+		return problem.Errorf(problem.InvalidCoordinate, "lat/lon out of range for %s", ping.VehicleID)
 	}
+	return nil
+}
 
+// storePing records a ping as the vehicle's latest state, appends it to its
+// history and publishes it. Both the REST and gRPC ingest paths use it.
+func storePing(c context.Context, ping TelemetryPing) error {
+	if err := validatePing(ping); err != nil {
+		pingsRejected.Inc()
+		return err
+	}
 	if ping.Timestamp == 0 {
 		ping.Timestamp = time.Now().Unix()
 	}
 
 	// Store latest state in Redis
 	data, _ := json.Marshal(ping)
-	_ = rdb.Set(ctx, fmt.Sprintf("vehicle:%s", ping.VehicleID), data, 0).Err()
+	_ = rdb.Set(c, fmt.Sprintf("vehicle:%s", ping.VehicleID), data, 0).Err()
 
 	// Push to a list for historical tracking (MVP)
-	_ = rdb.LPush(ctx, fmt.Sprintf("history:%s", ping.VehicleID), data).Err()
-	_ = rdb.LTrim(ctx, fmt.Sprintf("history:%s", ping.VehicleID), 0, 100).Err()
+	_ = rdb.LPush(c, fmt.Sprintf("history:%s", ping.VehicleID), data).Err()
+	_ = rdb.LTrim(c, fmt.Sprintf("history:%s", ping.VehicleID), 0, 100).Err()
+
+	// Fan out to live streams
+	_ = rdb.Publish(c, vehicleChannel, data).Err()
+
+	// Check progress along the vehicle's active route, if it has one
+	if err := trackPing(c, ping); err != nil {
+		slog.WarnContext(c, "route tracking failed", logging.KeyVehicleID, ping.VehicleID, logging.Err(err))
+	}
+
+	pingsIngested.WithLabelValues(ping.VehicleID).Inc()
+	slog.DebugContext(c, "ping stored", logging.KeyVehicleID, ping.VehicleID, "lat", ping.Lat, "lon", ping.Lon, "speed_kmh", ping.Speed)
+	return nil
+}
+
+func IngestTelemetry(w http.ResponseWriter, r *http.Request) {
+	var ping TelemetryPing
+	if err := json.NewDecoder(r.Body).Decode(&ping); err != nil {
+		problem.Write(w, r, problem.InvalidRequest, "invalid JSON body: "+err.Error())
+		return
+	}
+	if p, ok := auth.FromContext(r.Context()); ok && !p.MayReport(ping.VehicleID) {
+		problem.Write(w, r, problem.Forbidden, fmt.Sprintf("key %s may not report vehicle %s", p.Subject, ping.VehicleID))
+		return
+	}
 
-	log.Printf("Telemetry: [%s] %.4f, %.4f | Speed: %.1f km/h", ping.VehicleID, ping.Lat, ping.Lon, ping.Speed)
+	if err := storePing(r.Context(), ping); err != nil {
+		problem.WriteError(w, r, err, problem.InvalidRequest)
+		return
+	}
 	w.WriteHeader(http.StatusAccepted)
 }
 
@@ -58,9 +131,9 @@ func GetLatest(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	vid := vars["id"]
 
-	val, err := rdb.Get(ctx, fmt.Sprintf("vehicle:%s", vid)).Result()
+	val, err := rdb.Get(r.Context(), fmt.Sprintf("vehicle:%s", vid)).Result()
 	if err != nil {
-		http.Error(w, "Vehicle not found", http.StatusNotFound)
+		problem.Write(w, r, problem.NotFound, "Vehicle not found")
 		return
 	}
 
@@ -68,17 +141,17 @@ func GetLatest(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprint(w, val)
 }
 
-func GetAllVehicles(w http.ResponseWriter, r *http.Request) {
+// latestVehicles returns the stored latest ping of every vehicle.
+func latestVehicles(c context.Context) ([]json.RawMessage, error) {
 	// Scan all vehicle:* keys
 	var cursor uint64
 	var keys []string
 	for {
 		var batch []string
 		var err error
-		batch, cursor, err = rdb.Scan(ctx, cursor, "vehicle:*", 100).Result()
+		batch, cursor, err = rdb.Scan(c, cursor, "vehicle:*", 100).Result()
 		if err != nil {
-			http.Error(w, "Redis scan error", http.StatusInternalServerError)
-			return
+			return nil, err
 		}
 		keys = append(keys, batch...)
 		if cursor == 0 {
@@ -88,16 +161,23 @@ func GetAllVehicles(w http.ResponseWriter, r *http.Request) {
 
 	vehicles := make([]json.RawMessage, 0, len(keys))
 	for _, k := range keys {
-		val, err := rdb.Get(ctx, k).Result()
+		val, err := rdb.Get(c, k).Result()
 		if err == nil {
 			vehicles = append(vehicles, json.RawMessage(val))
 		}
 	}
+	return vehicles, nil
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(vehicles); err != nil {
-		http.Error(w, "Encode error", http.StatusInternalServerError)
+func GetAllVehicles(w http.ResponseWriter, r *http.Request) {
+	vehicles, err := latestVehicles(r.Context())
+	if err != nil {
+		problem.Write(w, r, problem.Internal, "Redis scan error")
+		return
 	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(vehicles)
 }
 
 func GetNearbyWebcams(w http.ResponseWriter, r *http.Request) {
@@ -115,7 +195,7 @@ func GetNearbyWebcams(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if apiKey == "" {
-		http.Error(w, "Windy API key not configured on server", http.StatusServiceUnavailable)
+		problem.Write(w, r, problem.Unavailable, "Windy API key not configured on server")
 		return
 	}
 
@@ -123,76 +203,194 @@ func GetNearbyWebcams(w http.ResponseWriter, r *http.Request) {
 	windyUrl := fmt.Sprintf("https://api.windy.com/webcams/api/v3/webcams?latitude=%s&longitude=%s&radius=%s&include=location,images,urls,player",
 		url.QueryEscape(lat), url.QueryEscape(lon), url.QueryEscape(radius))
 
-	req, _ := http.NewRequest("GET", windyUrl, nil)
+	req, _ := http.NewRequestWithContext(r.Context(), "GET", windyUrl, nil)
 	req.Header.Set("x-windy-api-key", apiKey)
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := upstream.Do(req)
 	if err != nil {
-		http.Error(w, "Failed to connect to Windy API", http.StatusBadGateway)
+		problem.Write(w, r, problem.UpstreamUnavailable, "Failed to connect to Windy API")
 		return
 	}
 	defer resp.Body.Close()
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(resp.StatusCode)
-	io.Copy(w, resp.Body)
+	proxyJSON(w, r, resp, "Windy API")
 }
 
-func GetAircraft(w http.ResponseWriter, r *http.Request) {
-	// Proxy OpenSky for the AZ bounding box
-	openskyUrl := "https://opensky-network.org/api/states/all?lamin=31.0&lomin=-115.0&lamax=37.0&lomax=-109.0"
+// aircraftTTL is how long one OpenSky response is served to every client.
+// OpenSky refreshes anonymous state vectors every 10 seconds, so polling it
+// faster gains nothing.
+const aircraftTTL = 10 * time.Second
 
-	resp, err := http.Get(openskyUrl)
+// aircraft caches the OpenSky response, so client traffic never maps onto
+// upstream calls.
+var aircraft = &upstreamCache{ttl: aircraftTTL}
+
+func GetAircraft(w http.ResponseWriter, r *http.Request) {
+	body, err := aircraft.get(func() ([]byte, error) {
+		// Proxy OpenSky for the AZ bounding box. The call is shared by every
+		// waiting client, so one hanging up must not cancel it.
+		openskyUrl := "https://opensky-network.org/api/states/all?lamin=31.0&lomin=-115.0&lamax=37.0&lomax=-109.0"
+		c := context.WithoutCancel(r.Context())
+		req, _ := http.NewRequestWithContext(c, "GET", openskyUrl, nil)
+		resp, err := upstream.Do(req)
+		if err != nil {
+			return nil, problem.New(problem.UpstreamUnavailable, "Failed to connect to OpenSky")
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			slog.WarnContext(c, "upstream request failed", logging.KeyUpstream, "OpenSky", "status", resp.StatusCode)
+			return nil, problem.Errorf(problem.UpstreamUnavailable, "OpenSky returned %s", resp.Status)
+		}
+		return io.ReadAll(resp.Body)
+	})
 	if err != nil {
-		http.Error(w, "Failed to connect to OpenSky", http.StatusBadGateway)
+		problem.WriteError(w, r, err, problem.UpstreamUnavailable)
 		return
 	}
-	defer resp.Body.Close()
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
 
+// upstreamCache keeps the last result of a third-party call for ttl.
+// Failures are kept too, so an outage is not retried by every client.
+type upstreamCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	body    []byte
+	err     error
+	fetched time.Time
+}
+
+// get returns the cached result while it is fresh. Otherwise it calls fetch,
+// holding the lock so concurrent callers wait for that one call.
+func (c *upstreamCache) get(fetch func() ([]byte, error)) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.fetched.IsZero() && time.Since(c.fetched) < c.ttl {
+		return c.body, c.err
+	}
+	c.body, c.err = fetch()
+	c.fetched = time.Now()
+	return c.body, c.err
+}
+
+// proxyJSON passes a successful upstream response through and reports a
+// failed one as UPSTREAM_UNAVAILABLE, so clients never see a third party's
+// error format.
+func proxyJSON(w http.ResponseWriter, r *http.Request, resp *http.Response, name string) {
+	if resp.StatusCode >= 400 {
+		slog.WarnContext(r.Context(), "upstream request failed", logging.KeyUpstream, name, "status", resp.StatusCode)
+		problem.Write(w, r, problem.UpstreamUnavailable, fmt.Sprintf("%s returned %s", name, resp.Status))
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(resp.StatusCode)
 	io.Copy(w, resp.Body)
 }
 
-func main() {
-	redisAddr := os.Getenv("REDIS_URL")
-	if redisAddr == "" {
-		redisAddr = "localhost:6379"
-	}
+//go:embed openapi.yaml
+var openapiYAML []byte
 
-	rdb = redis.NewClient(&redis.Options{
-		Addr: redisAddr,
-	})
+// apiSpec documents every route in newRouter; protect validates requests
+// against it.
+var apiSpec = openapi.MustLoad(openapiYAML)
 
+// newRouter registers every telemetry endpoint on a fresh mux.Router.
+func newRouter(probes *server.Probes) *mux.Router {
 	r := mux.NewRouter()
-	r.HandleFunc("/ingest", IngestTelemetry).Methods("POST")
-	r.HandleFunc("/vehicles", GetAllVehicles).Methods("GET")
-	r.HandleFunc("/vehicle/{id}", GetLatest).Methods("GET")
-	r.HandleFunc("/api/webcams/nearby", GetNearbyWebcams).Methods("GET")
-	r.HandleFunc("/api/traffic/aircraft", GetAircraft).Methods("GET")
+	r.Use(tracing.Middleware("telemetry"), logging.Middleware, metrics.Middleware)
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
+	apiSpec.Register(r)
+	r.Handle("/ingest", protect(auth.ScopeTelemetryWrite, IngestTelemetry)).Methods("POST")
+	r.Handle("/vehicles", protect(auth.ScopeTelemetryRead, GetAllVehicles)).Methods("GET")
+	r.Handle("/vehicle/{id}", protect(auth.ScopeTelemetryRead, GetLatest)).Methods("GET")
+	r.Handle("/vehicle/{id}/route", protect(auth.ScopeTelemetryWrite, AssignRoute)).Methods("PUT")
+	r.Handle("/vehicle/{id}/route", protect(auth.ScopeTelemetryRead, GetActiveRoute)).Methods("GET")
+	r.Handle("/vehicle/{id}/route", protect(auth.ScopeTelemetryWrite, ClearRoute)).Methods("DELETE")
+	r.Handle("/vehicle/{id}/events", protect(auth.ScopeTelemetryRead, GetRouteEvents)).Methods("GET")
+	r.Handle("/api/webcams/nearby", protectUpstream(auth.ScopeTelemetryRead, GetNearbyWebcams)).Methods("GET")
+	r.Handle("/api/traffic/aircraft", protectUpstream(auth.ScopeTelemetryRead, GetAircraft)).Methods("GET")
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		if err := rdb.Ping(ctx).Err(); err != nil {
-			http.Error(w, "Redis Down", http.StatusServiceUnavailable)
+		if err := rdb.Ping(r.Context()).Err(); err != nil {
+			problem.Write(w, r, problem.Unavailable, "Redis is unreachable")
 			return
 		}
 		fmt.Fprint(w, "OK")
 	}).Methods("GET")
+	probes.Register(r)
 
 	// Add Root Handler
 	r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		fmt.Fprint(w, "NaviFly Telemetry Service Online 🛰️")
 	}).Methods("GET")
+	return r
+}
+
+func main() {
+	if err := logging.Setup("telemetry"); err != nil {
+		logging.Fatal("invalid logging config", logging.Err(err))
+	}
+	cfg := defaultConfig()
+	if err := config.Load(&cfg); err != nil {
+		logging.Fatal("failed to load config", logging.Err(err))
+	}
+	traceCfg, err := tracing.ConfigFromEnv()
+	if err != nil {
+		logging.Fatal("invalid tracing config", logging.Err(err))
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), "telemetry", traceCfg)
+	if err != nil {
+		logging.Fatal("failed to set up tracing", logging.Err(err))
+	}
+	defer shutdownTracing(context.Background())
+
+	rdb = redis.NewClient(&redis.Options{
+		Addr: cfg.RedisURL,
+	})
+	rdb.AddHook(metrics.RedisHook{})
+	rdb.AddHook(tracing.RedisHook{})
+
+	if authn, err = auth.New(auth.ConfigFromEnv()); err != nil {
+		logging.Fatal("failed to load credentials", logging.Err(err))
+	}
+	if !authn.Enabled() {
+		slog.Warn("no API_KEYS_PATH or JWKS_PATH set, authentication is disabled")
+	}
 
-	log.Println("Telemetry service starting on :8081...")
+	// Devices report about once a second, so there is no daily quota by default
+	limits, err := ratelimit.ConfigFromEnv(ratelimit.Config{Rate: 20, Burst: 40})
+	if err != nil {
+		logging.Fatal("invalid rate limits", logging.Err(err))
+	}
+	limiter = ratelimit.New(limits, &ratelimit.RedisQuotas{Client: rdb})
+	// The head unit polls aircraft every 10 seconds; anything faster only
+	// risks an OpenSky or Windy ban
+	upstreamLimiter = ratelimit.New(ratelimit.Config{Rate: 0.2, Burst: 5, DailyQuota: 10000, TrustProxy: limits.TrustProxy, Scope: "upstream"},
+		&ratelimit.RedisQuotas{Client: rdb})
+
+	probes := server.NewProbes()
+	probes.Check("redis", func(ctx context.Context) error { return rdb.Ping(ctx).Err() })
+	r := newRouter(probes)
 
 	// CORS Headers
-	headersOk := handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization"})
+	headersOk := handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization", "X-API-Key", logging.HeaderRequestID})
 	originsOk := handlers.AllowedOrigins([]string{"*"})
-	methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "OPTIONS"})
-
-	// Wrap with Logging and CORS
-	loggedRouter := handlers.LoggingHandler(os.Stdout, r)
-	log.Fatal(http.ListenAndServe(":8081", handlers.CORS(originsOk, headersOk, methodsOk)(loggedRouter)))
+	methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "DELETE", "OPTIONS"})
+	exposedOk := handlers.ExposedHeaders([]string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After", logging.HeaderRequestID})
+
+	// Wrap with CORS; the router logs each request itself. gRPC API
+	// alongside REST
+	app := server.New(cfg.Server, handlers.CORS(originsOk, headersOk, methodsOk, exposedOk)(r))
+	app.GRPC = newGRPCServer()
+	app.Probes = probes
+	app.OnShutdown(func(context.Context) error { return rdb.Close() })
+
+	sig, stop := server.SignalContext()
+	defer stop()
+	slog.Info("telemetry service starting")
+	if err := app.Run(sig); err != nil {
+		slog.Error("telemetry service stopped", logging.Err(err))
+		os.Exit(1)
+	}
 }
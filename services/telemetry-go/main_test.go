@@ -4,14 +4,37 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/go-redis/redis/v8"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"navifly/platform/auth"
+	"navifly/platform/problem"
+	"navifly/platform/ratelimit"
+	"navifly/platform/server"
+	"navifly/platform/tracing"
+	telemetryv1 "navifly/telemetry/api/telemetry/v1"
 )
 
 func setupMockRedis(t *testing.T) *miniredis.Miniredis {
@@ -19,12 +42,12 @@ func setupMockRedis(t *testing.T) *miniredis.Miniredis {
 	if err != nil {
 		t.Fatalf("failed to start miniredis: %v", err)
 	}
-	
+
 	rdb = redis.NewClient(&redis.Options{
 		Addr: mr.Addr(),
 	})
 	ctx = context.Background()
-	
+
 	return mr
 }
 
@@ -44,7 +67,7 @@ func TestIngestTelemetry(t *testing.T) {
 	body, _ := json.Marshal(ping)
 	req, _ := http.NewRequest("POST", "/ingest", bytes.NewBuffer(body))
 	rr := httptest.NewRecorder()
-	
+
 	handler := http.HandlerFunc(IngestTelemetry)
 	handler.ServeHTTP(rr, req)
 
@@ -53,7 +76,7 @@ func TestIngestTelemetry(t *testing.T) {
 	// Verify Redis storage
 	val, err := mr.Get("vehicle:v123")
 	assert.NoError(t, err)
-	
+
 	var stored TelemetryPing
 	json.Unmarshal([]byte(val), &stored)
 	assert.Equal(t, "v123", stored.VehicleID)
@@ -87,7 +110,7 @@ func TestGetLatest(t *testing.T) {
 	router.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusOK, rr.Code)
-	
+
 	var received TelemetryPing
 	json.Unmarshal(rr.Body.Bytes(), &received)
 	assert.Equal(t, "v456", received.VehicleID)
@@ -106,3 +129,453 @@ func TestGetLatest_NotFound(t *testing.T) {
 
 	assert.Equal(t, http.StatusNotFound, rr.Code)
 }
+
+func TestIngestTelemetry_MissingVehicleID(t *testing.T) {
+	mr := setupMockRedis(t)
+	defer mr.Close()
+
+	req, _ := http.NewRequest("POST", "/ingest", bytes.NewBufferString(`{"lat":33.4,"lon":-112.0}`))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(IngestTelemetry).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func dialTelemetryGRPC(t *testing.T) telemetryv1.TelemetryServiceClient {
+	lis := bufconn.Listen(1 << 20)
+	gs := newGRPCServer()
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(c context.Context, _ string) (net.Conn, error) { return lis.DialContext(c) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return telemetryv1.NewTelemetryServiceClient(conn)
+}
+
+func TestGRPCIngestTelemetry(t *testing.T) {
+	mr := setupMockRedis(t)
+	defer mr.Close()
+	client := dialTelemetryGRPC(t)
+
+	stream, err := client.IngestTelemetry(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, stream.Send(&telemetryv1.TelemetryPing{VehicleId: "v1", Lat: 33.45, Lon: -112.07, Speed: 50}))
+	assert.NoError(t, stream.Send(&telemetryv1.TelemetryPing{VehicleId: "v1", Lat: 33.46, Lon: -112.07, Speed: 55}))
+	assert.NoError(t, stream.Send(&telemetryv1.TelemetryPing{Lat: 33.46, Lon: -112.07}))
+
+	summary, err := stream.CloseAndRecv()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), summary.Accepted)
+	assert.Equal(t, int64(1), summary.Rejected)
+	assert.Len(t, summary.Errors, 1)
+
+	val, err := mr.Get("vehicle:v1")
+	assert.NoError(t, err)
+	assert.Contains(t, val, `"speed":55`)
+	list, _ := mr.List("history:v1")
+	assert.Len(t, list, 2)
+}
+
+func TestGRPCStreamVehicles(t *testing.T) {
+	mr := setupMockRedis(t)
+	defer mr.Close()
+	mr.Set("vehicle:v1", `{"vehicle_id":"v1","lat":33.4,"lon":-112.0}`)
+	mr.Set("vehicle:v2", `{"vehicle_id":"v2","lat":32.2,"lon":-110.9}`)
+	client := dialTelemetryGRPC(t)
+
+	c, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.StreamVehicles(c, &telemetryv1.StreamVehiclesRequest{VehicleIds: []string{"v2"}})
+	assert.NoError(t, err)
+
+	// Snapshot first, filtered to v2
+	ping, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", ping.VehicleId)
+
+	// Then live updates
+	assert.NoError(t, storePing(context.Background(), TelemetryPing{VehicleID: "v1", Lat: 33.5, Lon: -112.1}))
+	assert.NoError(t, storePing(context.Background(), TelemetryPing{VehicleID: "v2", Lat: 32.3, Lon: -110.8, Speed: 40}))
+	ping, err = stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", ping.VehicleId)
+	assert.Equal(t, 40.0, ping.Speed)
+}
+
+func TestRouteTracking_Events(t *testing.T) {
+	mr := setupMockRedis(t)
+	defer mr.Close()
+	router := mux.NewRouter()
+	router.HandleFunc("/vehicle/{id}/route", AssignRoute).Methods("PUT")
+	router.HandleFunc("/vehicle/{id}/route", GetActiveRoute).Methods("GET")
+	router.HandleFunc("/vehicle/{id}/events", GetRouteEvents).Methods("GET")
+
+	// About 11 km due north along -112.0, planned at 10 minutes
+	body := `{"label":"Fastest","full_coords":[[-112.0,33.4],[-112.0,33.45],[-112.0,33.5]],"distance":11100,"duration":600}`
+	req, _ := http.NewRequest("PUT", "/vehicle/v1/route", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	c := context.Background()
+	send := func(lat, lon, heading float64) {
+		require.NoError(t, storePing(c, TelemetryPing{VehicleID: "v1", Lat: lat, Lon: lon, Speed: 50, Heading: heading, Timestamp: 1000}))
+	}
+	send(33.42, -112.0, 0)   // on route
+	send(33.43, -111.99, 90) // ~930 m east: one strike
+	send(33.44, -111.98, 90) // second strike: off_route
+	send(33.45, -112.0, 0)   // back on the line
+	send(33.4999, -112.0, 0) // at the end
+
+	req, _ = http.NewRequest("GET", "/vehicle/v1/events", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	var events []RouteEvent
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &events))
+	require.Len(t, events, 3)
+	assert.Equal(t, "arrived", events[0].Type)
+	assert.Equal(t, "back_on_route", events[1].Type)
+	assert.Equal(t, "off_route", events[2].Type)
+	assert.Greater(t, events[2].CrossTrackM, 75.0)
+
+	// Halfway back on route: ~5.5 km and ~5 minutes to go
+	assert.InDelta(t, 5550, events[1].RemainingM, 100)
+	assert.InDelta(t, 300, events[1].RemainingS, 10)
+	assert.Equal(t, int64(1000)+int64(events[1].RemainingS), events[1].ETA)
+
+	req, _ = http.NewRequest("GET", "/vehicle/v1/route", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	var route ActiveRoute
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &route))
+	assert.Equal(t, "arrived", route.Status)
+}
+
+func TestRouteTracking_ConcurrentPings(t *testing.T) {
+	mr := setupMockRedis(t)
+	defer mr.Close()
+	c := context.Background()
+	route, err := newActiveRoute(ActiveRoute{Coords: [][2]float64{{-112.0, 33.4}, {-112.0, 33.5}}, DurationS: 600}, time.Unix(0, 0))
+	require.NoError(t, err)
+	data, _ := json.Marshal(route)
+	require.NoError(t, rdb.Set(c, routeKey("v1"), data, 0).Err())
+
+	// Two strikes arriving together still add up to off_route
+	var wg sync.WaitGroup
+	for _, lat := range []float64{33.43, 33.44} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, trackPing(c, TelemetryPing{VehicleID: "v1", Lat: lat, Lon: -111.98, Speed: 50, Heading: 90, Timestamp: 1000}))
+		}()
+	}
+	wg.Wait()
+
+	data, err = rdb.Get(c, routeKey("v1")).Bytes()
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &route))
+	assert.Equal(t, statusOffRoute, route.Status)
+	assert.Equal(t, int64(1), rdb.LLen(c, eventsKey("v1")).Val())
+
+	// A cleared route stays cleared
+	require.NoError(t, rdb.Del(c, routeKey("v1")).Err())
+	require.NoError(t, trackPing(c, TelemetryPing{VehicleID: "v1", Lat: 33.45, Lon: -112.0, Timestamp: 1001}))
+	assert.Zero(t, rdb.Exists(c, routeKey("v1")).Val())
+}
+
+func TestRouteTracking_WrongWayAndJitter(t *testing.T) {
+	route, err := newActiveRoute(ActiveRoute{Coords: [][2]float64{{-112.0, 33.4}, {-112.0, 33.5}}, DurationS: 600}, time.Unix(0, 0))
+	require.NoError(t, err)
+
+	// A single stray fix does not trip off_route
+	assert.Nil(t, route.advance(TelemetryPing{VehicleID: "v1", Lat: 33.42, Lon: -111.99, Speed: 50}))
+	assert.Nil(t, route.advance(TelemetryPing{VehicleID: "v1", Lat: 33.43, Lon: -112.0, Speed: 50}))
+	assert.Equal(t, 0, route.Strikes)
+
+	// Driving south on a northbound route is off route even on the line
+	assert.Nil(t, route.advance(TelemetryPing{VehicleID: "v1", Lat: 33.43, Lon: -112.0, Speed: 50, Heading: 180}))
+	ev := route.advance(TelemetryPing{VehicleID: "v1", Lat: 33.42, Lon: -112.0, Speed: 50, Heading: 180})
+	require.NotNil(t, ev)
+	assert.Equal(t, "off_route", ev.Type)
+	assert.Equal(t, 180.0, ev.HeadingDiffDeg)
+
+	// Heading is ignored while crawling
+	ev = route.advance(TelemetryPing{VehicleID: "v1", Lat: 33.42, Lon: -112.0, Speed: 3, Heading: 180})
+	require.NotNil(t, ev)
+	assert.Equal(t, "back_on_route", ev.Type)
+}
+
+func TestAssignRoute_Invalid(t *testing.T) {
+	mr := setupMockRedis(t)
+	defer mr.Close()
+	router := mux.NewRouter()
+	router.HandleFunc("/vehicle/{id}/route", AssignRoute).Methods("PUT")
+	router.HandleFunc("/vehicle/{id}/route", ClearRoute).Methods("DELETE")
+
+	for _, body := range []string{
+		`{"full_coords":[[-112.0,33.4]],"duration":60}`,
+		`{"full_coords":[[33.4,-112.0],[33.5,-112.0]],"duration":60}`,
+		`{"full_coords":[[-112.0,33.4],[-112.0,33.5]]}`,
+		`{"full_coords":[[-112.0,33.4],[-112.0,33.4]],"duration":60}`,
+		`nope`,
+	} {
+		req, _ := http.NewRequest("PUT", "/vehicle/v1/route", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+	}
+
+	req, _ := http.NewRequest("DELETE", "/vehicle/v1/route", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestIngestTelemetry_VehicleBoundKey(t *testing.T) {
+	mr := setupMockRedis(t)
+	defer mr.Close()
+
+	keys := []auth.APIKey{{ID: "dev-1", SHA256: auth.HashKey("secret-1"), Scopes: []string{auth.ScopeTelemetryWrite}, Vehicles: []string{"v1"}}}
+	path := filepath.Join(t.TempDir(), "keys.json")
+	data, _ := json.Marshal(keys)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	a, err := auth.New(auth.Config{APIKeysPath: path})
+	require.NoError(t, err)
+	handler := a.Require(auth.ScopeTelemetryWrite, http.HandlerFunc(IngestTelemetry))
+
+	post := func(key, vehicle string) int {
+		body, _ := json.Marshal(TelemetryPing{VehicleID: vehicle, Lat: 33.45, Lon: -112.07})
+		req := httptest.NewRequest("POST", "/ingest", bytes.NewReader(body))
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post("", "v1"))
+	assert.Equal(t, http.StatusUnauthorized, post("wrong", "v1"))
+	assert.Equal(t, http.StatusForbidden, post("secret-1", "v2"))
+	assert.Equal(t, http.StatusAccepted, post("secret-1", "v1"))
+	assert.False(t, mr.Exists("vehicle:v2"))
+}
+
+func TestProtect_RateLimited(t *testing.T) {
+	mr := setupMockRedis(t)
+	defer mr.Close()
+	defer func(l *ratelimit.Limiter) { limiter = l }(limiter)
+	limiter = ratelimit.New(ratelimit.Config{Rate: 0.1, Burst: 1, DailyQuota: 50}, &ratelimit.RedisQuotas{Client: rdb})
+	handler := protect(auth.ScopeTelemetryRead, GetAllVehicles)
+
+	req := httptest.NewRequest("GET", "/vehicles", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "10", rr.Header().Get("Retry-After"))
+
+	// Only the admitted request counts against the daily quota
+	keys, _ := rdb.Keys(ctx, "quota:ip:*").Result()
+	require.Len(t, keys, 1)
+	n, _ := mr.Get(keys[0])
+	assert.Equal(t, "1", n)
+}
+
+func TestStorePing_Metrics(t *testing.T) {
+	mr := setupMockRedis(t)
+	defer mr.Close()
+	before := testutil.ToFloat64(pingsIngested.WithLabelValues("m1"))
+	rejected := testutil.ToFloat64(pingsRejected)
+
+	require.NoError(t, storePing(ctx, TelemetryPing{VehicleID: "m1", Lat: 33.45, Lon: -112.07}))
+	require.NoError(t, storePing(ctx, TelemetryPing{VehicleID: "m1", Lat: 33.46, Lon: -112.07}))
+	assert.Error(t, storePing(ctx, TelemetryPing{VehicleID: "m1", Lat: 91}))
+
+	assert.Equal(t, before+2, testutil.ToFloat64(pingsIngested.WithLabelValues("m1")))
+	assert.Equal(t, rejected+1, testutil.ToFloat64(pingsRejected))
+}
+
+func TestIngestTelemetry_TracesRedis(t *testing.T) {
+	mr := setupMockRedis(t)
+	defer mr.Close()
+	rdb.AddHook(tracing.RedisHook{})
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	defer otel.SetTracerProvider(prev)
+
+	c, parent := otel.Tracer("test").Start(context.Background(), "POST /ingest")
+	body, _ := json.Marshal(TelemetryPing{VehicleID: "tr1", Lat: 33.45, Lon: -112.07})
+	req := httptest.NewRequest("POST", "/ingest", bytes.NewReader(body)).WithContext(c)
+	rr := httptest.NewRecorder()
+	IngestTelemetry(rr, req)
+	parent.End()
+	require.Equal(t, http.StatusAccepted, rr.Code)
+
+	var names []string
+	for _, span := range rec.Ended() {
+		if span.Parent().SpanID() == parent.SpanContext().SpanID() {
+			names = append(names, span.Name())
+		}
+	}
+	assert.Contains(t, names, "redis set")
+	assert.Contains(t, names, "redis publish")
+}
+
+func TestOpenAPI_DocumentsEveryRoute(t *testing.T) {
+	served := map[string]bool{}
+	err := newRouter(server.NewProbes()).Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		require.NoError(t, err)
+		methods, err := route.GetMethods()
+		require.NoError(t, err)
+		for _, m := range methods {
+			served[m+" "+path] = true
+			assert.NotNil(t, apiSpec.Operation(m, path), "%s %s is not in openapi.yaml", m, path)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	for path, item := range apiSpec.Doc.Paths.Map() {
+		for m := range item.Operations() {
+			assert.True(t, served[m+" "+path], "openapi.yaml documents %s %s, which is not served", m, path)
+		}
+	}
+
+	var documented []problem.Code
+	for _, v := range apiSpec.Doc.Components.Schemas["ErrorCode"].Value.Enum {
+		documented = append(documented, problem.Code(v.(string)))
+	}
+	assert.ElementsMatch(t, problem.Codes(), documented, "openapi.yaml ErrorCode lists every problem code")
+}
+
+// roundTripFunc is an http.RoundTripper for faking third-party APIs.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestProblems(t *testing.T) {
+	mr := setupMockRedis(t)
+	defer mr.Close()
+	r := newRouter(server.NewProbes())
+	get := func(target string) (int, problem.Problem) {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest("GET", target, nil))
+		assert.Equal(t, problem.ContentType, rr.Header().Get("Content-Type"))
+		var p problem.Problem
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &p), rr.Body.String())
+		return rr.Code, p
+	}
+
+	code, p := get("/vehicle/ghost")
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, problem.NotFound, p.Code)
+	assert.Equal(t, "/vehicle/ghost", p.Instance)
+
+	// Third-party errors are reported in our format, not passed through
+	t.Setenv("WINDY_API_KEY", "test")
+	prev := upstream
+	t.Cleanup(func() { upstream = prev })
+	upstream = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusUnauthorized, Status: "401 Unauthorized",
+			Body: io.NopCloser(strings.NewReader(`{"message":"bad key"}`)), Header: http.Header{}}, nil
+	})}
+	code, p = get("/api/webcams/nearby?lat=33.4&lon=-112")
+	assert.Equal(t, http.StatusBadGateway, code)
+	assert.Equal(t, problem.UpstreamUnavailable, p.Code)
+	assert.Equal(t, "Windy API returned 401 Unauthorized", p.Detail)
+
+	mr.Close()
+	code, p = get("/health")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, problem.Unavailable, p.Code)
+}
+
+func TestGetAircraft_CachedAndLimited(t *testing.T) {
+	mr := setupMockRedis(t)
+	defer mr.Close()
+	defer func(l *ratelimit.Limiter, c *upstreamCache, u *http.Client) {
+		upstreamLimiter, aircraft, upstream = l, c, u
+	}(upstreamLimiter, aircraft, upstream)
+	upstreamLimiter = ratelimit.New(ratelimit.Config{Rate: 0.1, Burst: 2, Scope: "upstream"}, nil)
+	aircraft = &upstreamCache{ttl: time.Minute}
+	var calls int32
+	upstream = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return &http.Response{StatusCode: http.StatusOK, Status: "200 OK",
+			Body: io.NopCloser(strings.NewReader(`{"time":1,"states":[]}`)), Header: http.Header{}}, nil
+	})}
+	r := newRouter(server.NewProbes())
+	get := func(target, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		req.RemoteAddr = ip + ":5555"
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Clients share one OpenSky call
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.2"} {
+		rr := get("/api/traffic/aircraft", ip)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"time":1,"states":[]}`, rr.Body.String())
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// The proxies have their own, stricter limit
+	assert.Equal(t, http.StatusTooManyRequests, get("/api/traffic/aircraft", "10.0.0.2").Code)
+	assert.Equal(t, http.StatusOK, get("/vehicles", "10.0.0.2").Code)
+}
+
+func TestUpstreamCache_KeepsFailures(t *testing.T) {
+	c := &upstreamCache{ttl: time.Minute}
+	calls := 0
+	fetch := func() ([]byte, error) {
+		calls++
+		return nil, problem.New(problem.UpstreamUnavailable, "OpenSky returned 429 Too Many Requests")
+	}
+	_, err := c.get(fetch)
+	assert.Error(t, err)
+	_, err = c.get(fetch)
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+
+	c.fetched = time.Now().Add(-2 * time.Minute)
+	_, _ = c.get(fetch)
+	assert.Equal(t, 2, calls)
+}
+
+func TestOpenAPI_RejectsInvalidRequests(t *testing.T) {
+	mr := setupMockRedis(t)
+	defer mr.Close()
+	r := newRouter(server.NewProbes())
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rr
+	}
+
+	rr := do("GET", "/api/webcams/nearby?lat=33.4&lon=west", "")
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `query parameter \"lon\"`)
+
+	rr = do("POST", "/ingest", `{"vehicle_id": "truck-1", "lat": 133.4, "lon": -112}`)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "lat: number must be at most 90")
+	assert.Contains(t, rr.Body.String(), `"code":"INVALID_COORDINATE"`)
+
+	rr = do("POST", "/ingest", `{"vehicle_id": "truck-1", "lat": 33.4, "lon": -112}`)
+	assert.Equal(t, http.StatusAccepted, rr.Code, rr.Body.String())
+
+	assert.Equal(t, http.StatusOK, do("GET", "/openapi.json", "").Code)
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"navifly/platform/metrics"
+)
+
+var (
+	pingsIngested = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "telemetry",
+		Name:      "pings_ingested_total",
+		Help:      "Pings stored, by vehicle. rate() gives each vehicle's ingest rate.",
+	}, []string{"vehicle"})
+
+	pingsRejected = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "telemetry",
+		Name:      "pings_rejected_total",
+		Help:      "Pings that failed validation.",
+	})
+)
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/gorilla/mux"
+
+	"navifly/platform/logging"
+	"navifly/platform/problem"
+)
+
+// ── Route Tracking ──
+
+const (
+	// A vehicle is off route after offRoutePings consecutive pings more than
+	// offRouteM from the line or heading the wrong way, and back on route
+	// within backOnRouteM. The gap keeps GPS jitter from flapping.
+	offRouteM     = 75.0
+	backOnRouteM  = 35.0
+	offRoutePings = 2
+	// A heading more than wrongWayDeg off the route's bearing is the wrong
+	// way; below minHeadingKmh headings are unreliable and ignored.
+	wrongWayDeg   = 120.0
+	minHeadingKmh = 10.0
+	// arrivedM is the remaining distance at which the trip ends.
+	arrivedM = 40.0
+
+	// eventChannel carries every route event; the last maxEvents per vehicle
+	// are kept.
+	eventChannel = "vehicles:events"
+	maxEvents    = 100
+)
+
+// Route states; the event types are off_route, back_on_route and arrived.
+const (
+	statusOnRoute  = "on_route"
+	statusOffRoute = "off_route"
+	statusArrived  = "arrived"
+)
+
+// ActiveRoute is the route a vehicle is following and its progress. The
+// geometry fields match a routing service route, so one from GET /route can
+// be assigned as-is.
+type ActiveRoute struct {
+	Label     string       `json:"label,omitempty"`
+	Coords    [][2]float64 `json:"full_coords"`
+	DistanceM float64      `json:"distance"`
+	DurationS float64      `json:"duration"`
+
+	AssignedAt int64  `json:"assigned_at"`
+	Status     string `json:"status"`
+	// Strikes counts consecutive off-route pings while on route.
+	Strikes    int     `json:"strikes,omitempty"`
+	RemainingM float64 `json:"remaining_m"`
+	RemainingS float64 `json:"remaining_s"`
+	ETA        int64   `json:"eta,omitempty"`
+	UpdatedAt  int64   `json:"updated_at,omitempty"`
+	// CumM is the distance along the route at each vertex.
+	CumM []float64 `json:"cum_m"`
+}
+
+// RouteEvent reports a change in how a vehicle follows its route.
+type RouteEvent struct {
+	Type           string  `json:"type"`
+	VehicleID      string  `json:"vehicle_id"`
+	Timestamp      int64   `json:"timestamp"`
+	Lat            float64 `json:"lat"`
+	Lon            float64 `json:"lon"`
+	CrossTrackM    float64 `json:"cross_track_m"`
+	HeadingDiffDeg float64 `json:"heading_diff_deg"`
+	RemainingM     float64 `json:"remaining_m"`
+	RemainingS     float64 `json:"remaining_s"`
+	ETA            int64   `json:"eta"`
+}
+
+func routeKey(vehicleID string) string  { return fmt.Sprintf("route:%s", vehicleID) }
+func eventsKey(vehicleID string) string { return fmt.Sprintf("events:%s", vehicleID) }
+
+// newActiveRoute validates a route and indexes its length.
+func newActiveRoute(r ActiveRoute, now time.Time) (ActiveRoute, error) {
+	if len(r.Coords) < 2 {
+		return r, fmt.Errorf("full_coords needs at least 2 [lon, lat] points")
+	}
+	for _, c := range r.Coords {
+		if c[1] < -90 || c[1] > 90 || c[0] < -180 || c[0] > 180 {
+			return r, fmt.Errorf("%v is not a [lon, lat] position", c)
+		}
+	}
+	if r.DurationS <= 0 {
+		return r, fmt.Errorf("duration is required for ETAs")
+	}
+	r.CumM = make([]float64, len(r.Coords))
+	for i := 1; i < len(r.Coords); i++ {
+		r.CumM[i] = r.CumM[i-1] + distanceM(r.Coords[i-1], r.Coords[i])
+	}
+	if r.CumM[len(r.CumM)-1] <= 0 {
+		return r, fmt.Errorf("full_coords has zero length")
+	}
+	if r.DistanceM <= 0 {
+		r.DistanceM = r.CumM[len(r.CumM)-1]
+	}
+	r.AssignedAt = now.Unix()
+	r.Status = statusOnRoute
+	r.Strikes = 0
+	r.RemainingM = math.Round(r.DistanceM)
+	r.RemainingS = math.Round(r.DurationS)
+	r.ETA = now.Unix() + int64(r.DurationS)
+	return r, nil
+}
+
+// match projects a position onto the route. It returns the distance from
+// the line, the distance along it and the route's bearing there.
+func (r *ActiveRoute) match(lat, lon float64) (crossM, alongM, bearing float64) {
+	p := [2]float64{lon, lat}
+	crossM = math.Inf(1)
+	for i := 1; i < len(r.Coords); i++ {
+		a, b := r.Coords[i-1], r.Coords[i]
+		// Local equirectangular metres around p
+		kx := 111320 * math.Cos(lat*math.Pi/180)
+		ky := 110540.0
+		ax, ay := (a[0]-p[0])*kx, (a[1]-p[1])*ky
+		bx, by := (b[0]-p[0])*kx, (b[1]-p[1])*ky
+		dx, dy := bx-ax, by-ay
+		t := 0.0
+		if l2 := dx*dx + dy*dy; l2 > 0 {
+			t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/l2))
+		}
+		qx, qy := ax+t*dx, ay+t*dy
+		if d := math.Hypot(qx, qy); d < crossM {
+			crossM = d
+			alongM = r.CumM[i-1] + t*(r.CumM[i]-r.CumM[i-1])
+			bearing = math.Mod(math.Atan2(dx, dy)*180/math.Pi+360, 360)
+		}
+	}
+	return crossM, alongM, bearing
+}
+
+// headingDiff is the absolute angle between two bearings, 0–180°.
+func headingDiff(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	if d > 180 {
+		d = 360 - d
+	}
+	return d
+}
+
+// advance updates the route's progress with a ping and returns the event it
+// causes, if any.
+func (r *ActiveRoute) advance(ping TelemetryPing) *RouteEvent {
+	if r.Status == statusArrived {
+		return nil
+	}
+	crossM, alongM, bearing := r.match(ping.Lat, ping.Lon)
+	diff := 0.0
+	if ping.Speed >= minHeadingKmh {
+		diff = headingDiff(ping.Heading, bearing)
+	}
+
+	r.RemainingM = math.Round(math.Max(0, r.DistanceM-alongM*r.DistanceM/r.CumM[len(r.CumM)-1]))
+	r.RemainingS = math.Round(r.DurationS * r.RemainingM / r.DistanceM)
+	r.ETA = ping.Timestamp + int64(r.RemainingS)
+	r.UpdatedAt = ping.Timestamp
+
+	event := ""
+	off := crossM > offRouteM || diff > wrongWayDeg
+	switch {
+	case r.RemainingM <= arrivedM && crossM <= offRouteM:
+		r.Status, r.Strikes, event = statusArrived, 0, statusArrived
+	case r.Status == statusOnRoute && off:
+		r.Strikes++
+		if r.Strikes >= offRoutePings {
+			r.Status, r.Strikes, event = statusOffRoute, 0, statusOffRoute
+		}
+	case r.Status == statusOnRoute:
+		r.Strikes = 0
+	case r.Status == statusOffRoute && crossM <= backOnRouteM && diff <= wrongWayDeg:
+		r.Status, event = statusOnRoute, "back_on_route"
+	}
+	if event == "" {
+		return nil
+	}
+	return &RouteEvent{
+		Type:           event,
+		VehicleID:      ping.VehicleID,
+		Timestamp:      ping.Timestamp,
+		Lat:            ping.Lat,
+		Lon:            ping.Lon,
+		CrossTrackM:    math.Round(crossM*10) / 10,
+		HeadingDiffDeg: math.Round(diff),
+		RemainingM:     r.RemainingM,
+		RemainingS:     r.RemainingS,
+		ETA:            r.ETA,
+	}
+}
+
+// trackAttempts bounds how often trackPing retries when another ping for
+// the same vehicle updates the route first.
+const trackAttempts = 5
+
+// trackPing checks a stored ping against the vehicle's active route, saves
+// the progress and publishes any event. The route is read and written in a
+// WATCH transaction, so concurrent pings for one vehicle neither lose a
+// strike nor revive a cleared route.
+func trackPing(c context.Context, ping TelemetryPing) error {
+	key := routeKey(ping.VehicleID)
+	var event *RouteEvent
+	advance := func(tx *redis.Tx) error {
+		data, err := tx.Get(c, key).Bytes()
+		if err != nil {
+			return err
+		}
+		var route ActiveRoute
+		if err := json.Unmarshal(data, &route); err != nil {
+			return fmt.Errorf("unreadable active route for %s: %v", ping.VehicleID, err)
+		}
+		event = route.advance(ping)
+		if data, err = json.Marshal(route); err != nil {
+			return fmt.Errorf("encode active route for %s: %w", ping.VehicleID, err)
+		}
+		_, err = tx.TxPipelined(c, func(pipe redis.Pipeliner) error {
+			pipe.Set(c, key, data, 0)
+			return nil
+		})
+		return err
+	}
+
+	var err error
+	for i := 0; i < trackAttempts; i++ {
+		if err = rdb.Watch(c, advance, key); !errors.Is(err, redis.TxFailedErr) {
+			break
+		}
+	}
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if event == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode route event for %s: %w", ping.VehicleID, err)
+	}
+	_ = rdb.LPush(c, eventsKey(ping.VehicleID), data).Err()
+	_ = rdb.LTrim(c, eventsKey(ping.VehicleID), 0, maxEvents-1).Err()
+	_ = rdb.Publish(c, eventChannel, data).Err()
+	slog.InfoContext(c, "route event", logging.KeyVehicleID, ping.VehicleID, "type", event.Type,
+		"cross_track_m", math.Round(event.CrossTrackM), "remaining_km", math.Round(event.RemainingM/100)/10)
+	return nil
+}
+
+// AssignRoute sets the route a vehicle is following, replacing any other.
+func AssignRoute(w http.ResponseWriter, r *http.Request) {
+	vid := mux.Vars(r)["id"]
+	var route ActiveRoute
+	if err := json.NewDecoder(r.Body).Decode(&route); err != nil {
+		problem.Write(w, r, problem.InvalidRequest, "invalid JSON body: "+err.Error())
+		return
+	}
+	route, err := newActiveRoute(route, time.Now())
+	if err != nil {
+		problem.WriteError(w, r, err, problem.InvalidRequest)
+		return
+	}
+
+	data, err := json.Marshal(route)
+	if err != nil {
+		problem.WriteError(w, r, err, problem.Internal)
+		return
+	}
+	if err := rdb.Set(r.Context(), routeKey(vid), data, 0).Err(); err != nil {
+		problem.Write(w, r, problem.Internal, "Redis error")
+		return
+	}
+	_ = rdb.Del(r.Context(), eventsKey(vid)).Err()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(data)
+}
+
+// GetActiveRoute returns the vehicle's route with its latest progress.
+func GetActiveRoute(w http.ResponseWriter, r *http.Request) {
+	val, err := rdb.Get(r.Context(), routeKey(mux.Vars(r)["id"])).Result()
+	if err != nil {
+		problem.Write(w, r, problem.NotFound, "No active route")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, val)
+}
+
+// ClearRoute stops tracking the vehicle against a route.
+func ClearRoute(w http.ResponseWriter, r *http.Request) {
+	n, err := rdb.Del(r.Context(), routeKey(mux.Vars(r)["id"])).Result()
+	if err != nil {
+		problem.Write(w, r, problem.Internal, "Redis error")
+		return
+	}
+	if n == 0 {
+		problem.Write(w, r, problem.NotFound, "No active route")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetRouteEvents returns the vehicle's route events, newest first.
+func GetRouteEvents(w http.ResponseWriter, r *http.Request) {
+	vals, err := rdb.LRange(r.Context(), eventsKey(mux.Vars(r)["id"]), 0, -1).Result()
+	if err != nil {
+		problem.Write(w, r, problem.Internal, "Redis error")
+		return
+	}
+	events := make([]json.RawMessage, 0, len(vals))
+	for _, v := range vals {
+		events = append(events, json.RawMessage(v))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
+
+// distanceM is the haversine distance between [lon, lat] points.
+func distanceM(a, b [2]float64) float64 {
+	const R = 6371000.0
+	dLat := (b[1] - a[1]) * math.Pi / 180
+	dLon := (b[0] - a[0]) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(a[1]*math.Pi/180)*math.Cos(b[1]*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * R * math.Asin(math.Sqrt(h))
+}
@@ -456,6 +456,13 @@ const MapView: React.FC<MapProps> = ({ onLoaded }) => {
                         }));
                     }
                 }
+            } else {
+                // Errors are application/problem+json; detail explains the code
+                const problem = await response.json().catch(() => null) as { code?: string; detail?: string } | null;
+                pushToast({
+                    type: problem?.code === 'UPSTREAM_UNAVAILABLE' ? 'warning' : 'danger',
+                    message: problem?.detail ? `Route unavailable: ${problem.detail}` : `Route request failed (HTTP ${response.status})`
+                });
             }
         } catch (proxyError) {
             console.warn('OSRM proxy failed, adhering to persistent geometry:', proxyError);